@@ -1,43 +1,122 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"flag"
 	"log"
 	"net/http"
-	"os"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
 	_ "github.com/lib/pq"
 
+	"github.com/LlirikP/pr_dispenser/internal/auth"
 	"github.com/LlirikP/pr_dispenser/internal/config"
 	"github.com/LlirikP/pr_dispenser/internal/database"
+	"github.com/LlirikP/pr_dispenser/internal/database/memory"
+	"github.com/LlirikP/pr_dispenser/internal/database/sqlite"
 	"github.com/LlirikP/pr_dispenser/internal/handlers"
+	"github.com/LlirikP/pr_dispenser/internal/outbox"
+	"github.com/LlirikP/pr_dispenser/internal/webhooks"
 
 	"github.com/go-chi/cors"
 )
 
-func main() {
-	portStr := os.Getenv("PORT_AUTH")
+func openStorage(cfg config.Config) (database.Storage, *sql.DB, error) {
+	var (
+		conn *sql.DB
+		err  error
+	)
+
+	switch cfg.Storage {
+	case "memory":
+		return memory.New(), nil, nil
+	case "sqlite":
+		conn, err = sqlite.OpenDB(cfg.SQLitePath)
+		if err != nil {
+			return nil, nil, err
+		}
+		return sqlite.NewStore(conn), conn, nil
+	}
+
+	conn, err = sql.Open("postgres", cfg.DBURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	return database.NewStore(conn), conn, nil
+}
 
-	if portStr == "" {
-		log.Fatal("Could not get PORT from .env file")
+func ensureAdminToken(ctx context.Context, store database.Storage, token string) error {
+	hash := auth.HashToken(token)
+
+	existing, err := store.FindAPITokenByHash(ctx, hash)
+	if err == nil {
+		if existing.RevokedAt.Valid {
+			log.Printf("warning: ADMIN_TOKEN matches token %s revoked at %v, it stays revoked", existing.ID, existing.RevokedAt.Time)
+		}
+		return nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	return store.CreateAPIToken(ctx, database.CreateAPITokenParams{
+		ID:        uuid.NewString(),
+		Name:      "bootstrap",
+		TokenHash: hash,
+		Scope:     string(auth.ScopeAdmin),
+	})
+}
+
+func main() {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal(err)
 	}
 
-	dbUrl := os.Getenv("DB_URL")
-	if dbUrl == "" {
-		log.Fatal("Could not get db url")
+	flag.StringVar(&cfg.Storage, "storage", cfg.Storage, "storage backend: postgres, sqlite or memory")
+	flag.BoolVar(&cfg.AutoMigrate, "migrate", cfg.AutoMigrate, "apply pending migrations before starting the server")
+	flag.Parse()
+
+	if err := cfg.Validate(); err != nil {
+		log.Fatal(err)
 	}
 
-	connection, err := sql.Open("postgres", dbUrl)
+	db, conn, err := openStorage(cfg)
 	if err != nil {
 		log.Fatal("Could not connect to the database")
 	}
 
-	db := database.New(connection)
-	config.ApiCfg = &config.ApiConfig{
-		DB: db,
+	if flag.Arg(0) == "migrate" {
+		if err := runMigrate(context.Background(), cfg, conn, flag.Args()[1:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if err := cfg.ValidateServer(); err != nil {
+		log.Fatal(err)
+	}
+
+	if cfg.AutoMigrate && conn != nil {
+		if err := autoMigrate(context.Background(), cfg, conn); err != nil {
+			log.Fatal(err)
+		}
 	}
 
+	if cfg.AdminToken != "" {
+		if err := ensureAdminToken(context.Background(), db, cfg.AdminToken); err != nil {
+			log.Fatalf("Could not create bootstrap admin token: %v", err)
+		}
+	}
+
+	server := handlers.NewServer(db, cfg)
+
+	go outbox.NewRelay(db).Run(context.Background())
+	go webhooks.NewDispatcher(db).Run(context.Background())
+
 	router := chi.NewRouter()
 	router.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"https//*", "http//*"},
@@ -48,26 +127,14 @@ func main() {
 		MaxAge:           300,
 	}))
 
-	v1router := chi.NewRouter()
-
-	v1router.Post("/team/add", handlers.CreateTeamHandler)
-	v1router.Get("/team/get", handlers.GetTeamHandler)
-
-	v1router.Post("/users/setIsActive", handlers.SetUserActiveHandler)
-	v1router.Get("/users/getReview", handlers.ReviewListHandler)
-
-	v1router.Post("/pullRequest/create", handlers.CreatePRHandler)
-	v1router.Post("/pullRequest/merge", handlers.MergePRHandler)
-	v1router.Post("/pullRequest/reassign", handlers.AssignReviewerHandler)
-
-	router.Mount("/v1", v1router)
+	router.Mount("/v1", server.Routes())
 
 	srv := &http.Server{
 		Handler: router,
-		Addr:    ":" + portStr,
+		Addr:    ":" + cfg.Port,
 	}
 
-	log.Printf("Server starting on port %v", portStr)
+	log.Printf("Server starting on port %v with %v storage", cfg.Port, cfg.Storage)
 	err = srv.ListenAndServe()
 	if err != nil {
 		log.Fatal(err)
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/LlirikP/pr_dispenser/internal/config"
+	"github.com/LlirikP/pr_dispenser/internal/migrate"
+)
+
+func autoMigrate(ctx context.Context, cfg config.Config, conn *sql.DB) error {
+	migrator, err := migrate.New(cfg.Storage, conn)
+	if err != nil {
+		return err
+	}
+
+	results, err := migrator.Up(ctx)
+	if err != nil {
+		return fmt.Errorf("auto-migrate: %w", err)
+	}
+
+	for _, result := range results {
+		log.Printf("Applied migration %s", result)
+	}
+
+	return nil
+}
+
+func runMigrate(ctx context.Context, cfg config.Config, conn *sql.DB, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: migrate status|up|down|version")
+	}
+
+	migrator, err := migrate.New(cfg.Storage, conn)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		results, err := migrator.Up(ctx)
+		for _, result := range results {
+			fmt.Println(result)
+		}
+		if err != nil {
+			return err
+		}
+		if len(results) == 0 {
+			fmt.Println("no migrations to apply")
+		}
+
+	case "down":
+		result, err := migrator.Down(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Println(result)
+
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tSTATE\tAPPLIED AT\tFILE")
+		for _, status := range statuses {
+			appliedAt := "-"
+			if !status.AppliedAt.IsZero() {
+				appliedAt = status.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", status.Source.Version, status.State, appliedAt, status.Source.Path)
+		}
+		return w.Flush()
+
+	case "version":
+		current, target, err := migrator.Version(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("current: %d, latest: %d\n", current, target)
+
+	default:
+		return fmt.Errorf("unknown migrate command %q, expected status, up, down or version", args[0])
+	}
+
+	return nil
+}
@@ -0,0 +1,130 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/LlirikP/pr_dispenser/internal/database"
+)
+
+const (
+	TeamCreated              = "team.created"
+	TeamStrategySet          = "team.strategy_set"
+	TeamCapacitySet          = "team.capacity_set"
+	TeamReviewersRequiredSet = "team.reviewers_required_set"
+	TeamApprovalsRequiredSet = "team.approvals_required_set"
+	TeamFallbacksSet         = "team.fallbacks_set"
+	UserUpserted             = "user.upserted"
+	UserActiveSet            = "user.active_set"
+	UserRoleSet              = "user.role_set"
+	UserCapacitySet          = "user.capacity_set"
+	PRCreated                = "pr.created"
+	PRMerged                 = "pr.merged"
+	PRReassigned             = "pr.reassigned"
+	PRClosed                 = "pr.closed"
+	PRReopened               = "pr.reopened"
+	PRReady                  = "pr.ready"
+	PRReviewSubmitted        = "pr.review_submitted"
+	TokenCreated             = "token.created"
+	TokenRevoked             = "token.revoked"
+	SubscriptionCreated      = "subscription.created"
+	SubscriptionDeleted      = "subscription.deleted"
+	DeliveryRetried          = "subscription.delivery_retried"
+)
+
+const (
+	TargetTeam         = "team"
+	TargetUser         = "user"
+	TargetPR           = "pull_request"
+	TargetToken        = "api_token"
+	TargetSubscription = "webhook_subscription"
+	TargetDelivery     = "webhook_delivery"
+)
+
+const (
+	defaultActor    = "system"
+	maxRequestIDLen = 128
+)
+
+var Actions = []string{
+	TeamCreated,
+	TeamStrategySet,
+	TeamCapacitySet,
+	TeamReviewersRequiredSet,
+	TeamApprovalsRequiredSet,
+	TeamFallbacksSet,
+	UserUpserted,
+	UserActiveSet,
+	UserRoleSet,
+	UserCapacitySet,
+	PRCreated,
+	PRMerged,
+	PRReassigned,
+	PRClosed,
+	PRReopened,
+	PRReady,
+	PRReviewSubmitted,
+	TokenCreated,
+	TokenRevoked,
+	SubscriptionCreated,
+	SubscriptionDeleted,
+	DeliveryRetried,
+}
+
+func IsValidAction(action string) bool {
+	for _, a := range Actions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+type Entry struct {
+	Action     string
+	TargetType string
+	TargetID   string
+	Before     any
+	After      any
+}
+
+type requestIDKey struct{}
+
+func WithRequestID(ctx context.Context, id string) context.Context {
+	if len(id) > maxRequestIDLen {
+		id = id[:maxRequestIDLen]
+	}
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+func Write(ctx context.Context, q database.Querier, actor string, e Entry) error {
+	if actor == "" {
+		actor = defaultActor
+	}
+
+	before, err := json.Marshal(e.Before)
+	if err != nil {
+		return fmt.Errorf("encode audit before: %w", err)
+	}
+
+	after, err := json.Marshal(e.After)
+	if err != nil {
+		return fmt.Errorf("encode audit after: %w", err)
+	}
+
+	return q.InsertAuditEntry(ctx, database.InsertAuditEntryParams{
+		Actor:      actor,
+		Action:     e.Action,
+		TargetType: e.TargetType,
+		TargetID:   e.TargetID,
+		Before:     before,
+		After:      after,
+		RequestID:  RequestID(ctx),
+	})
+}
@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+)
+
+type Scope string
+
+const (
+	ScopeAdmin     Scope = "admin"
+	ScopeTeamAdmin Scope = "team-admin"
+	ScopeUser      Scope = "user"
+	ScopeWebhook   Scope = "webhook"
+)
+
+const tokenPrefix = "prd_"
+
+func IsValidScope(scope string) bool {
+	switch Scope(scope) {
+	case ScopeAdmin, ScopeTeamAdmin, ScopeUser, ScopeWebhook:
+		return true
+	}
+	return false
+}
+
+func (s Scope) Allows(required Scope) bool {
+	switch s {
+	case ScopeAdmin:
+		return true
+	case ScopeTeamAdmin:
+		return required == ScopeTeamAdmin || required == ScopeUser
+	default:
+		return s == required
+	}
+}
+
+type Principal struct {
+	TokenID string
+	Scope   Scope
+	UserID  string
+}
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+func FromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
+
+func GenerateToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return tokenPrefix + base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func IsJWT(token string) bool {
+	return !strings.HasPrefix(token, tokenPrefix) && strings.Count(token, ".") == 2
+}
+
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,176 @@
+package authtest
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/LlirikP/pr_dispenser/internal/auth"
+)
+
+const (
+	rsaKeyID = "test-rs256"
+	ecKeyID  = "test-es256"
+)
+
+type Issuer struct {
+	server *httptest.Server
+	rsaKey *rsa.PrivateKey
+	ecKey  *ecdsa.PrivateKey
+}
+
+func NewIssuer() (*Issuer, error) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	i := &Issuer{rsaKey: rsaKey, ecKey: ecKey}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", i.discoveryHandler)
+	mux.HandleFunc("/jwks.json", i.jwksHandler)
+	i.server = httptest.NewServer(mux)
+
+	return i, nil
+}
+
+func (i *Issuer) URL() string {
+	return i.server.URL
+}
+
+func (i *Issuer) JWKSURL() string {
+	return i.server.URL + "/jwks.json"
+}
+
+func (i *Issuer) Close() {
+	i.server.Close()
+}
+
+func (i *Issuer) VerifierConfig(audience string) auth.VerifierConfig {
+	return auth.VerifierConfig{
+		Issuer:   i.URL(),
+		Audience: audience,
+	}
+}
+
+func (i *Issuer) Claims(subject, audience string, ttl time.Duration) map[string]any {
+	now := time.Now()
+	return map[string]any{
+		"iss": i.URL(),
+		"sub": subject,
+		"aud": audience,
+		"iat": now.Unix(),
+		"exp": now.Add(ttl).Unix(),
+	}
+}
+
+func (i *Issuer) Token(subject, audience string) (string, error) {
+	return i.Sign("RS256", i.Claims(subject, audience, time.Hour))
+}
+
+func (i *Issuer) Sign(alg string, claims map[string]any) (string, error) {
+	switch alg {
+	case "RS256":
+		return i.SignWithKeyID(alg, rsaKeyID, claims)
+	case "ES256":
+		return i.SignWithKeyID(alg, ecKeyID, claims)
+	default:
+		return "", fmt.Errorf("unsupported alg %q", alg)
+	}
+}
+
+func (i *Issuer) SignWithKeyID(alg, kid string, claims map[string]any) (string, error) {
+	if alg != "RS256" && alg != "ES256" {
+		return "", fmt.Errorf("unsupported alg %q", alg)
+	}
+
+	header, err := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signingInput))
+
+	var signature []byte
+	if alg == "RS256" {
+		signature, err = rsa.SignPKCS1v15(rand.Reader, i.rsaKey, crypto.SHA256, digest[:])
+		if err != nil {
+			return "", err
+		}
+	} else {
+		r, s, err := ecdsa.Sign(rand.Reader, i.ecKey, digest[:])
+		if err != nil {
+			return "", err
+		}
+		signature = make([]byte, 64)
+		r.FillBytes(signature[:32])
+		s.FillBytes(signature[32:])
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+func encodeInt(v *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(v.Bytes())
+}
+
+func (i *Issuer) discoveryHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"issuer":                                i.URL(),
+		"jwks_uri":                              i.JWKSURL(),
+		"id_token_signing_alg_values_supported": []string{"RS256", "ES256"},
+	})
+}
+
+func (i *Issuer) jwksHandler(w http.ResponseWriter, r *http.Request) {
+	ecX := make([]byte, 32)
+	ecY := make([]byte, 32)
+	i.ecKey.X.FillBytes(ecX)
+	i.ecKey.Y.FillBytes(ecY)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"keys": []map[string]string{
+			{
+				"kty": "RSA",
+				"kid": rsaKeyID,
+				"use": "sig",
+				"alg": "RS256",
+				"n":   encodeInt(i.rsaKey.N),
+				"e":   encodeInt(big.NewInt(int64(i.rsaKey.E))),
+			},
+			{
+				"kty": "EC",
+				"kid": ecKeyID,
+				"use": "sig",
+				"alg": "ES256",
+				"crv": "P-256",
+				"x":   base64.RawURLEncoding.EncodeToString(ecX),
+				"y":   base64.RawURLEncoding.EncodeToString(ecY),
+			},
+		},
+	})
+}
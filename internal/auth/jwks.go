@@ -0,0 +1,141 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+)
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jwkSet struct {
+	Keys []jwk `json:"keys"`
+}
+
+type publicKey struct {
+	kid string
+	alg string
+	key crypto.PublicKey
+}
+
+func decodeBigInt(raw string) (*big.Int, error) {
+	buf, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(buf), nil
+}
+
+func (k jwk) publicKey() (publicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return publicKey{}, fmt.Errorf("key %s: bad modulus: %w", k.Kid, err)
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil || !e.IsInt64() {
+			return publicKey{}, fmt.Errorf("key %s: bad exponent", k.Kid)
+		}
+		return publicKey{kid: k.Kid, alg: "RS256", key: &rsa.PublicKey{N: n, E: int(e.Int64())}}, nil
+
+	case "EC":
+		if k.Crv != "P-256" {
+			return publicKey{}, fmt.Errorf("key %s: unsupported curve %s", k.Kid, k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return publicKey{}, fmt.Errorf("key %s: bad x: %w", k.Kid, err)
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return publicKey{}, fmt.Errorf("key %s: bad y: %w", k.Kid, err)
+		}
+		if !elliptic.P256().IsOnCurve(x, y) {
+			return publicKey{}, fmt.Errorf("key %s: point is not on P-256", k.Kid)
+		}
+		return publicKey{kid: k.Kid, alg: "ES256", key: &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}}, nil
+
+	default:
+		return publicKey{}, fmt.Errorf("key %s: unsupported key type %s", k.Kid, k.Kty)
+	}
+}
+
+func getJSON(ctx context.Context, client *http.Client, url string, dst any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: status %d", url, resp.StatusCode)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(dst)
+}
+
+func discoverJWKSURL(ctx context.Context, client *http.Client, issuer string) (string, error) {
+	doc := struct {
+		JWKSURI string `json:"jwks_uri"`
+	}{}
+
+	url := strings.TrimSuffix(issuer, "/") + "/.well-known/openid-configuration"
+	if err := getJSON(ctx, client, url, &doc); err != nil {
+		return "", fmt.Errorf("oidc discovery: %w", err)
+	}
+
+	if doc.JWKSURI == "" {
+		return "", errors.New("oidc discovery: jwks_uri is missing")
+	}
+	return doc.JWKSURI, nil
+}
+
+func fetchKeys(ctx context.Context, client *http.Client, url string) ([]publicKey, error) {
+	set := jwkSet{}
+	if err := getJSON(ctx, client, url, &set); err != nil {
+		return nil, fmt.Errorf("fetch jwks: %w", err)
+	}
+
+	keys := make([]publicKey, 0, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		key, err := k.publicKey()
+		if err != nil {
+			continue
+		}
+		keys = append(keys, key)
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("fetch jwks: no usable signing keys")
+	}
+	return keys, nil
+}
@@ -0,0 +1,284 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrInvalidToken = errors.New("invalid token")
+
+const (
+	clockLeeway     = 30 * time.Second
+	keysMaxAge      = time.Hour
+	minRefreshDelay = time.Minute
+)
+
+type VerifierConfig struct {
+	Issuer    string
+	Audience  string
+	JWKSURL   string
+	UserClaim string
+}
+
+type Verifier struct {
+	cfg    VerifierConfig
+	client *http.Client
+
+	mu          sync.Mutex
+	jwksURL     string
+	keys        []publicKey
+	fetchedAt   time.Time
+	attemptedAt time.Time
+	fetchErr    error
+	inflight    chan struct{}
+}
+
+func NewVerifier(cfg VerifierConfig) *Verifier {
+	if cfg.UserClaim == "" {
+		cfg.UserClaim = "sub"
+	}
+
+	return &Verifier{
+		cfg:     cfg,
+		client:  &http.Client{Timeout: 5 * time.Second},
+		jwksURL: cfg.JWKSURL,
+	}
+}
+
+func invalidToken(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrInvalidToken, fmt.Sprintf(format, args...))
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return err
+	}
+	*a = many
+	return nil
+}
+
+type registeredClaims struct {
+	Issuer    string   `json:"iss"`
+	Audience  audience `json:"aud"`
+	ExpiresAt *int64   `json:"exp"`
+	NotBefore *int64   `json:"nbf"`
+}
+
+func decodeSegment(segment string, dst any) error {
+	raw, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, dst)
+}
+
+func (v *Verifier) Verify(ctx context.Context, token string) (Principal, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return Principal{}, invalidToken("malformed jwt")
+	}
+
+	header := jwtHeader{}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return Principal{}, invalidToken("malformed header")
+	}
+
+	if header.Alg != "RS256" && header.Alg != "ES256" {
+		return Principal{}, invalidToken("unsupported alg %q", header.Alg)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return Principal{}, invalidToken("malformed signature")
+	}
+
+	key, err := v.key(ctx, header.Kid, header.Alg)
+	if err != nil {
+		return Principal{}, err
+	}
+
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if !verifySignature(key, digest[:], signature) {
+		return Principal{}, invalidToken("signature mismatch")
+	}
+
+	claims := map[string]any{}
+	registered := registeredClaims{}
+	if decodeSegment(parts[1], &claims) != nil || decodeSegment(parts[1], &registered) != nil {
+		return Principal{}, invalidToken("malformed claims")
+	}
+
+	if err := v.validateClaims(registered, time.Now()); err != nil {
+		return Principal{}, err
+	}
+
+	userID, _ := claims[v.cfg.UserClaim].(string)
+	if userID == "" {
+		return Principal{}, invalidToken("claim %q is missing", v.cfg.UserClaim)
+	}
+
+	return Principal{Scope: ScopeUser, UserID: userID}, nil
+}
+
+func (v *Verifier) validateClaims(claims registeredClaims, now time.Time) error {
+	if claims.Issuer != v.cfg.Issuer {
+		return invalidToken("unexpected issuer %q", claims.Issuer)
+	}
+
+	found := false
+	for _, aud := range claims.Audience {
+		if aud == v.cfg.Audience {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return invalidToken("audience mismatch")
+	}
+
+	if claims.ExpiresAt == nil {
+		return invalidToken("exp is missing")
+	}
+	if now.Add(-clockLeeway).After(time.Unix(*claims.ExpiresAt, 0)) {
+		return invalidToken("token expired")
+	}
+
+	if claims.NotBefore != nil && now.Add(clockLeeway).Before(time.Unix(*claims.NotBefore, 0)) {
+		return invalidToken("token not valid yet")
+	}
+
+	return nil
+}
+
+func verifySignature(key publicKey, digest, signature []byte) bool {
+	switch pub := key.key.(type) {
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest, signature) == nil
+	case *ecdsa.PublicKey:
+		if len(signature) != 64 {
+			return false
+		}
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		return ecdsa.Verify(pub, digest, r, s)
+	}
+	return false
+}
+
+func (v *Verifier) key(ctx context.Context, kid, alg string) (publicKey, error) {
+	v.mu.Lock()
+	key, ok := findKey(v.keys, kid, alg)
+	stale := time.Since(v.fetchedAt) > keysMaxAge
+
+	wait := v.inflight
+	if wait == nil && (!ok || stale) && time.Since(v.attemptedAt) > minRefreshDelay {
+		v.attemptedAt = time.Now()
+		wait = make(chan struct{})
+		v.inflight = wait
+		go v.refresh(wait)
+	}
+	v.mu.Unlock()
+
+	if ok {
+		return key, nil
+	}
+
+	if wait != nil {
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			return publicKey{}, ctx.Err()
+		}
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if key, ok := findKey(v.keys, kid, alg); ok {
+		return key, nil
+	}
+
+	if len(v.keys) == 0 && v.fetchErr != nil {
+		return publicKey{}, v.fetchErr
+	}
+	return publicKey{}, invalidToken("no signing key for kid %q", kid)
+}
+
+func (v *Verifier) refresh(done chan struct{}) {
+	defer close(done)
+
+	v.mu.Lock()
+	url := v.jwksURL
+	v.mu.Unlock()
+
+	ctx := context.Background()
+
+	var (
+		keys []publicKey
+		err  error
+	)
+
+	if url == "" {
+		url, err = discoverJWKSURL(ctx, v.client, v.cfg.Issuer)
+	}
+	if err == nil {
+		keys, err = fetchKeys(ctx, v.client, url)
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.inflight = nil
+	v.fetchErr = err
+	if err != nil {
+		return
+	}
+
+	v.jwksURL = url
+	v.keys = keys
+	v.fetchedAt = time.Now()
+}
+
+func findKey(keys []publicKey, kid, alg string) (publicKey, bool) {
+	var match []publicKey
+	for _, key := range keys {
+		if key.alg != alg {
+			continue
+		}
+		if kid != "" && key.kid == kid {
+			return key, true
+		}
+		match = append(match, key)
+	}
+
+	if kid == "" && len(match) == 1 {
+		return match[0], true
+	}
+	return publicKey{}, false
+}
@@ -0,0 +1,170 @@
+package auth_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/LlirikP/pr_dispenser/internal/auth"
+	"github.com/LlirikP/pr_dispenser/internal/auth/authtest"
+)
+
+const testAudience = "pr-dispenser"
+
+func newIssuer(t *testing.T) *authtest.Issuer {
+	t.Helper()
+
+	issuer, err := authtest.NewIssuer()
+	if err != nil {
+		t.Fatalf("new issuer: %v", err)
+	}
+	t.Cleanup(issuer.Close)
+	return issuer
+}
+
+func TestVerifierAcceptsSupportedAlgorithms(t *testing.T) {
+	issuer := newIssuer(t)
+	verifier := auth.NewVerifier(issuer.VerifierConfig(testAudience))
+
+	for _, alg := range []string{"RS256", "ES256"} {
+		t.Run(alg, func(t *testing.T) {
+			token, err := issuer.Sign(alg, issuer.Claims("u1", testAudience, time.Hour))
+			if err != nil {
+				t.Fatalf("sign: %v", err)
+			}
+
+			principal, err := verifier.Verify(context.Background(), token)
+			if err != nil {
+				t.Fatalf("verify: %v", err)
+			}
+			if principal.UserID != "u1" {
+				t.Fatalf("user id = %q, want u1", principal.UserID)
+			}
+			if principal.TokenID != "" {
+				t.Fatalf("token id = %q, want empty", principal.TokenID)
+			}
+		})
+	}
+}
+
+func TestVerifierRejectsInvalidTokens(t *testing.T) {
+	issuer := newIssuer(t)
+	verifier := auth.NewVerifier(issuer.VerifierConfig(testAudience))
+
+	tests := []struct {
+		name  string
+		kid   string
+		claim func(claims map[string]any)
+	}{
+		{
+			name:  "wrong audience",
+			claim: func(claims map[string]any) { claims["aud"] = "someone-else" },
+		},
+		{
+			name:  "wrong issuer",
+			claim: func(claims map[string]any) { claims["iss"] = "https://evil.example.com" },
+		},
+		{
+			name:  "expired",
+			claim: func(claims map[string]any) { claims["exp"] = time.Now().Add(-time.Hour).Unix() },
+		},
+		{
+			name:  "missing exp",
+			claim: func(claims map[string]any) { delete(claims, "exp") },
+		},
+		{
+			name:  "not valid yet",
+			claim: func(claims map[string]any) { claims["nbf"] = time.Now().Add(time.Hour).Unix() },
+		},
+		{
+			name:  "missing subject",
+			claim: func(claims map[string]any) { delete(claims, "sub") },
+		},
+		{
+			name: "unknown kid",
+			kid:  "rotated-away",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := issuer.Claims("u1", testAudience, time.Hour)
+			if tt.claim != nil {
+				tt.claim(claims)
+			}
+
+			var (
+				token string
+				err   error
+			)
+			if tt.kid != "" {
+				token, err = issuer.SignWithKeyID("RS256", tt.kid, claims)
+			} else {
+				token, err = issuer.Sign("RS256", claims)
+			}
+			if err != nil {
+				t.Fatalf("sign: %v", err)
+			}
+
+			_, err = verifier.Verify(context.Background(), token)
+			if !errors.Is(err, auth.ErrInvalidToken) {
+				t.Fatalf("err = %v, want ErrInvalidToken", err)
+			}
+		})
+	}
+}
+
+func TestVerifierRejectsTamperedToken(t *testing.T) {
+	issuer := newIssuer(t)
+	verifier := auth.NewVerifier(issuer.VerifierConfig(testAudience))
+
+	token, err := issuer.Token("u1", testAudience)
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+
+	forged, err := issuer.Token("admin", testAudience)
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+
+	parts := strings.Split(token, ".")
+	forgedParts := strings.Split(forged, ".")
+
+	_, err = verifier.Verify(context.Background(), parts[0]+"."+forgedParts[1]+"."+parts[2])
+	if !errors.Is(err, auth.ErrInvalidToken) {
+		t.Fatalf("err = %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestVerifierConcurrentFirstUse(t *testing.T) {
+	issuer := newIssuer(t)
+	verifier := auth.NewVerifier(issuer.VerifierConfig(testAudience))
+
+	token, err := issuer.Token("u1", testAudience)
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 16)
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := verifier.Verify(context.Background(), token)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("verify: %v", err)
+		}
+	}
+}
@@ -1,9 +1,78 @@
 package config
 
-import "github.com/LlirikP/pr_dispenser/internal/database"
+import (
+	"errors"
+	"fmt"
+	"os"
+)
 
-type ApiConfig struct {
-	DB *database.Queries
+type Config struct {
+	Port                string
+	Storage             string
+	DBURL               string
+	SQLitePath          string
+	AutoMigrate         bool
+	AdminToken          string
+	OIDCIssuer          string
+	OIDCAudience        string
+	OIDCJWKSURL         string
+	OIDCUserClaim       string
+	GithubWebhookSecret string
+	GitlabWebhookToken  string
 }
 
-var ApiCfg *ApiConfig
+func Load() (Config, error) {
+	cfg := Config{
+		Port:                os.Getenv("PORT_AUTH"),
+		Storage:             os.Getenv("STORAGE"),
+		DBURL:               os.Getenv("DB_URL"),
+		SQLitePath:          os.Getenv("SQLITE_PATH"),
+		AutoMigrate:         os.Getenv("AUTO_MIGRATE") == "true",
+		AdminToken:          os.Getenv("ADMIN_TOKEN"),
+		OIDCIssuer:          os.Getenv("OIDC_ISSUER"),
+		OIDCAudience:        os.Getenv("OIDC_AUDIENCE"),
+		OIDCJWKSURL:         os.Getenv("OIDC_JWKS_URL"),
+		OIDCUserClaim:       os.Getenv("OIDC_USER_CLAIM"),
+		GithubWebhookSecret: os.Getenv("GITHUB_WEBHOOK_SECRET"),
+		GitlabWebhookToken:  os.Getenv("GITLAB_WEBHOOK_TOKEN"),
+	}
+
+	if cfg.Storage == "" {
+		cfg.Storage = "postgres"
+	}
+
+	if cfg.SQLitePath == "" {
+		cfg.SQLitePath = "pr_dispenser.db"
+	}
+
+	return cfg, nil
+}
+
+func (c Config) ValidateServer() error {
+	if c.Port == "" {
+		return errors.New("could not get PORT from .env file")
+	}
+	return nil
+}
+
+func (c Config) Validate() error {
+	if c.AdminToken != "" && len(c.AdminToken) < 16 {
+		return errors.New("ADMIN_TOKEN must be at least 16 characters")
+	}
+
+	if c.OIDCIssuer != "" && c.OIDCAudience == "" {
+		return errors.New("OIDC_AUDIENCE is required when OIDC_ISSUER is set")
+	}
+
+	switch c.Storage {
+	case "postgres":
+		if c.DBURL == "" {
+			return errors.New("could not get db url")
+		}
+	case "sqlite", "memory":
+	default:
+		return fmt.Errorf("unknown storage %q, expected postgres, sqlite or memory", c.Storage)
+	}
+
+	return nil
+}
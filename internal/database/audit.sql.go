@@ -0,0 +1,110 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: audit.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+)
+
+const insertAuditEntry = `-- name: InsertAuditEntry :exec
+INSERT INTO audit_log (actor, action, target_type, target_id, before, after, request_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+`
+
+type InsertAuditEntryParams struct {
+	Actor      string
+	Action     string
+	TargetType string
+	TargetID   string
+	Before     json.RawMessage
+	After      json.RawMessage
+	RequestID  string
+}
+
+func (q *Queries) InsertAuditEntry(ctx context.Context, arg InsertAuditEntryParams) error {
+	_, err := q.db.ExecContext(ctx, insertAuditEntry,
+		arg.Actor,
+		arg.Action,
+		arg.TargetType,
+		arg.TargetID,
+		arg.Before,
+		arg.After,
+		arg.RequestID,
+	)
+	return err
+}
+
+const listAuditEntries = `-- name: ListAuditEntries :many
+SELECT id, actor, action, target_type, target_id, before, after, request_id, created_at
+FROM audit_log
+WHERE ($1::text IS NULL OR actor = $1)
+  AND ($2::text IS NULL OR action = $2)
+  AND ($3::text IS NULL OR target_type = $3)
+  AND ($4::text IS NULL OR target_id = $4)
+  AND ($5::text IS NULL OR request_id = $5)
+  AND ($6::timestamptz IS NULL OR created_at >= $6)
+  AND ($7::timestamptz IS NULL OR created_at < $7)
+  AND ($8::bigint IS NULL OR id < $8)
+ORDER BY id DESC
+LIMIT $9
+`
+
+type ListAuditEntriesParams struct {
+	Actor      sql.NullString
+	Action     sql.NullString
+	TargetType sql.NullString
+	TargetID   sql.NullString
+	RequestID  sql.NullString
+	Since      sql.NullTime
+	Until      sql.NullTime
+	BeforeID   sql.NullInt64
+	MaxRows    int32
+}
+
+func (q *Queries) ListAuditEntries(ctx context.Context, arg ListAuditEntriesParams) ([]AuditLog, error) {
+	rows, err := q.db.QueryContext(ctx, listAuditEntries,
+		arg.Actor,
+		arg.Action,
+		arg.TargetType,
+		arg.TargetID,
+		arg.RequestID,
+		arg.Since,
+		arg.Until,
+		arg.BeforeID,
+		arg.MaxRows,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AuditLog
+	for rows.Next() {
+		var i AuditLog
+		if err := rows.Scan(
+			&i.ID,
+			&i.Actor,
+			&i.Action,
+			&i.TargetType,
+			&i.TargetID,
+			&i.Before,
+			&i.After,
+			&i.RequestID,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
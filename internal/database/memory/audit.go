@@ -0,0 +1,72 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/LlirikP/pr_dispenser/internal/database"
+)
+
+func (q *Queries) InsertAuditEntry(ctx context.Context, arg database.InsertAuditEntryParams) error {
+	defer q.lock()()
+
+	id := int64(len(q.st.audit)) + 1
+	appendRow(q.st, &q.st.audit, database.AuditLog{
+		ID:         id,
+		Actor:      arg.Actor,
+		Action:     arg.Action,
+		TargetType: arg.TargetType,
+		TargetID:   arg.TargetID,
+		Before:     jsonOrNull(arg.Before),
+		After:      jsonOrNull(arg.After),
+		RequestID:  arg.RequestID,
+		CreatedAt:  time.Now(),
+	})
+
+	return nil
+}
+
+func (q *Queries) ListAuditEntries(ctx context.Context, arg database.ListAuditEntriesParams) ([]database.AuditLog, error) {
+	defer q.lock()()
+
+	rows := []database.AuditLog{}
+	for i := len(q.st.audit) - 1; i >= 0 && len(rows) < int(arg.MaxRows); i-- {
+		e := q.st.audit[i]
+		if arg.Actor.Valid && e.Actor != arg.Actor.String {
+			continue
+		}
+		if arg.Action.Valid && e.Action != arg.Action.String {
+			continue
+		}
+		if arg.TargetType.Valid && e.TargetType != arg.TargetType.String {
+			continue
+		}
+		if arg.TargetID.Valid && e.TargetID != arg.TargetID.String {
+			continue
+		}
+		if arg.RequestID.Valid && e.RequestID != arg.RequestID.String {
+			continue
+		}
+		if arg.Since.Valid && e.CreatedAt.Before(arg.Since.Time) {
+			continue
+		}
+		if arg.Until.Valid && !e.CreatedAt.Before(arg.Until.Time) {
+			continue
+		}
+		if arg.BeforeID.Valid && e.ID >= arg.BeforeID.Int64 {
+			continue
+		}
+
+		rows = append(rows, e)
+	}
+
+	return rows, nil
+}
+
+func jsonOrNull(raw json.RawMessage) json.RawMessage {
+	if len(raw) == 0 {
+		return json.RawMessage("null")
+	}
+	return raw
+}
@@ -0,0 +1,177 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"time"
+
+	"github.com/LlirikP/pr_dispenser/internal/database"
+)
+
+func (q *Queries) InsertOutboxEvent(ctx context.Context, arg database.InsertOutboxEventParams) error {
+	defer q.lock()()
+
+	for _, entry := range q.st.outbox {
+		if entry.EventID == arg.EventID {
+			return constraintError("event %s already recorded", arg.EventID)
+		}
+	}
+
+	now := time.Now()
+	appendRow(q.st, &q.st.outbox, database.Outbox{
+		ID:            q.st.next(&q.st.lastOutbox),
+		EventID:       arg.EventID,
+		EventType:     arg.EventType,
+		PrID:          arg.PrID,
+		UserIds:       slices.Clone(arg.UserIds),
+		Payload:       arg.Payload,
+		Status:        "PENDING",
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	})
+
+	return nil
+}
+
+func (q *Queries) ClaimNextOutboxEvent(ctx context.Context) (database.Outbox, error) {
+	defer q.lock()()
+
+	now := time.Now()
+	blocked := map[string]bool{}
+
+	for _, entry := range q.st.outbox {
+		if entry.Status != "PENDING" {
+			continue
+		}
+
+		if blocked[entry.PrID] {
+			continue
+		}
+		blocked[entry.PrID] = true
+
+		if !entry.NextAttemptAt.After(now) {
+			return entry, nil
+		}
+	}
+
+	return database.Outbox{}, sql.ErrNoRows
+}
+
+func (q *Queries) updateOutbox(id int64, fn func(*database.Outbox)) {
+	defer q.lock()()
+
+	for i := range q.st.outbox {
+		if q.st.outbox[i].ID == id {
+			touch(q.st, &q.st.outbox, i)
+			fn(&q.st.outbox[i])
+			return
+		}
+	}
+}
+
+func (q *Queries) MarkOutboxDispatched(ctx context.Context, id int64) error {
+	q.updateOutbox(id, func(entry *database.Outbox) {
+		entry.Status = "DISPATCHED"
+		entry.Attempts++
+		entry.LastError.Valid = false
+		entry.DispatchedAt.Time, entry.DispatchedAt.Valid = time.Now(), true
+	})
+	return nil
+}
+
+func (q *Queries) MarkOutboxAttemptFailed(ctx context.Context, arg database.MarkOutboxAttemptFailedParams) error {
+	q.updateOutbox(arg.ID, func(entry *database.Outbox) {
+		entry.Status = arg.Status
+		entry.Attempts++
+		entry.LastError = arg.LastError
+		entry.NextAttemptAt = arg.NextAttemptAt
+	})
+	return nil
+}
+
+func (q *Queries) LockOutboxSequencer(ctx context.Context) error {
+	return nil
+}
+
+func (q *Queries) SequenceOutboxEvents(ctx context.Context) (int64, error) {
+	defer q.lock()()
+
+	var last, sequenced int64
+	for _, entry := range q.st.outbox {
+		if entry.StreamSeq.Valid {
+			last = max(last, entry.StreamSeq.Int64)
+		}
+	}
+
+	for i := range q.st.outbox {
+		if !q.st.outbox[i].StreamSeq.Valid {
+			last++
+			sequenced++
+			touch(q.st, &q.st.outbox, i)
+			q.st.outbox[i].StreamSeq = sql.NullInt64{Int64: last, Valid: true}
+		}
+	}
+
+	return sequenced, nil
+}
+
+func (q *Queries) GetLatestStreamSeq(ctx context.Context) (int64, error) {
+	defer q.lock()()
+
+	var last int64
+	for _, entry := range q.st.outbox {
+		if entry.StreamSeq.Valid {
+			last = max(last, entry.StreamSeq.Int64)
+		}
+	}
+	return last, nil
+}
+
+func (q *Queries) GetStreamEventsAfter(ctx context.Context, arg database.GetStreamEventsAfterParams) ([]database.GetStreamEventsAfterRow, error) {
+	defer q.lock()()
+
+	sequenced := []database.Outbox{}
+	for _, entry := range q.st.outbox {
+		if entry.StreamSeq.Valid && entry.StreamSeq.Int64 > arg.AfterSeq {
+			sequenced = append(sequenced, entry)
+		}
+	}
+
+	slices.SortFunc(sequenced, func(a, b database.Outbox) int {
+		return int(a.StreamSeq.Int64 - b.StreamSeq.Int64)
+	})
+
+	rows := []database.GetStreamEventsAfterRow{}
+	for _, entry := range sequenced {
+		if len(rows) >= int(arg.BatchSize) {
+			break
+		}
+		if arg.PrID.Valid && entry.PrID != arg.PrID.String {
+			continue
+		}
+		if arg.UserID.Valid && !slices.Contains(entry.UserIds, arg.UserID.String) {
+			continue
+		}
+		if arg.TeamID.Valid && !q.st.involvesTeam(entry.UserIds, arg.TeamID.String) {
+			continue
+		}
+
+		rows = append(rows, database.GetStreamEventsAfterRow{
+			Seq:       entry.StreamSeq.Int64,
+			EventType: entry.EventType,
+			Payload:   entry.Payload,
+		})
+	}
+
+	return rows, nil
+}
+
+func (s *state) involvesTeam(userIDs []string, teamID string) bool {
+	for _, id := range userIDs {
+		if user, ok := s.users[id]; ok && user.TeamID == teamID {
+			return true
+		}
+	}
+	return false
+}
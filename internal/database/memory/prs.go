@@ -0,0 +1,383 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/LlirikP/pr_dispenser/internal/database"
+)
+
+func (s *state) openReviews(userID string) int64 {
+	var n int64
+	for key := range s.reviewers {
+		if key.reviewerID == userID && s.prs[key.prID].Status == "OPEN" {
+			n++
+		}
+	}
+	return n
+}
+
+func (s *state) capacity(user database.User) int64 {
+	if user.MaxOpenReviews.Valid {
+		return int64(user.MaxOpenReviews.Int32)
+	}
+	return int64(s.teams[user.TeamID].MaxOpenReviews)
+}
+
+func (s *state) reviewsByPR(prID string) []database.PrReviewer {
+	reviews := []database.PrReviewer{}
+	for key, review := range s.reviewers {
+		if key.prID == prID {
+			reviews = append(reviews, review)
+		}
+	}
+
+	sort.Slice(reviews, func(i, j int) bool {
+		if !reviews[i].AssignedAt.Equal(reviews[j].AssignedAt) {
+			return reviews[i].AssignedAt.Before(reviews[j].AssignedAt)
+		}
+		return reviews[i].ReviewerID < reviews[j].ReviewerID
+	})
+
+	return reviews
+}
+
+func (q *Queries) CreatePR(ctx context.Context, arg database.CreatePRParams) error {
+	defer q.lock()()
+
+	if _, ok := q.st.prs[arg.ID]; ok {
+		return constraintError("pr %s already exists", arg.ID)
+	}
+
+	if _, ok := q.st.users[arg.AuthorID]; !ok {
+		return constraintError("author %s does not exist", arg.AuthorID)
+	}
+
+	put(q.st, q.st.prs, arg.ID, database.Pr{
+		ID:                arg.ID,
+		Title:             arg.Title,
+		AuthorID:          arg.AuthorID,
+		Status:            "OPEN",
+		CreatedAt:         time.Now(),
+		ReviewersRequired: arg.ReviewersRequired,
+		IsDraft:           arg.IsDraft,
+	})
+
+	return nil
+}
+
+func (q *Queries) GetPRById(ctx context.Context, id string) (database.Pr, error) {
+	defer q.lock()()
+
+	pr, ok := q.st.prs[id]
+	if !ok {
+		return pr, sql.ErrNoRows
+	}
+	return pr, nil
+}
+
+func (q *Queries) GetPRsByIDs(ctx context.Context, ids []string) ([]database.Pr, error) {
+	defer q.lock()()
+
+	prs := []database.Pr{}
+	for id, pr := range q.st.prs {
+		if slices.Contains(ids, id) {
+			prs = append(prs, pr)
+		}
+	}
+
+	sort.Slice(prs, func(i, j int) bool { return prs[i].ID < prs[j].ID })
+	return prs, nil
+}
+
+func (q *Queries) AddReviewer(ctx context.Context, arg database.AddReviewerParams) error {
+	defer q.lock()()
+
+	if _, ok := q.st.prs[arg.PrID]; !ok {
+		return constraintError("pr %s does not exist", arg.PrID)
+	}
+
+	if _, ok := q.st.users[arg.ReviewerID]; !ok {
+		return constraintError("reviewer %s does not exist", arg.ReviewerID)
+	}
+
+	key := reviewKey{prID: arg.PrID, reviewerID: arg.ReviewerID}
+	if _, ok := q.st.reviewers[key]; ok {
+		return nil
+	}
+
+	put(q.st, q.st.reviewers, key, database.PrReviewer{
+		PrID:       arg.PrID,
+		ReviewerID: arg.ReviewerID,
+		State:      "PENDING",
+		AssignedAt: time.Now(),
+	})
+
+	return nil
+}
+
+func (q *Queries) DeleteReviewer(ctx context.Context, arg database.DeleteReviewerParams) error {
+	defer q.lock()()
+
+	remove(q.st, q.st.reviewers, reviewKey{prID: arg.PrID, reviewerID: arg.ReviewerID})
+	return nil
+}
+
+func (q *Queries) GetReviewersByPR(ctx context.Context, prID string) ([]string, error) {
+	defer q.lock()()
+
+	ids := []string{}
+	for _, review := range q.st.reviewsByPR(prID) {
+		ids = append(ids, review.ReviewerID)
+	}
+	return ids, nil
+}
+
+func (q *Queries) GetAvailableTeamMembersExceptAuthor(ctx context.Context, arg database.GetAvailableTeamMembersExceptAuthorParams) ([]string, error) {
+	defer q.lock()()
+
+	ids := []string{}
+	for _, user := range q.st.users {
+		if user.TeamID != arg.TeamID || !user.IsAvailable || user.ID == arg.ID {
+			continue
+		}
+
+		if q.st.openReviews(user.ID) < q.st.capacity(user) {
+			ids = append(ids, user.ID)
+		}
+	}
+
+	slices.Sort(ids)
+	return ids, nil
+}
+
+func (q *Queries) GetTeamCandidates(ctx context.Context, teamID string) ([]database.GetTeamCandidatesRow, error) {
+	defer q.lock()()
+
+	candidates := []database.GetTeamCandidatesRow{}
+	for _, user := range q.st.users {
+		if user.TeamID != teamID || !user.IsAvailable {
+			continue
+		}
+
+		candidates = append(candidates, database.GetTeamCandidatesRow{
+			ID:           user.ID,
+			ReviewWeight: user.ReviewWeight,
+			OpenReviews:  q.st.openReviews(user.ID),
+			Capacity:     q.st.capacity(user),
+		})
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].ID < candidates[j].ID
+	})
+	return candidates, nil
+}
+
+func (q *Queries) GetReviewersByPRs(ctx context.Context, prIds []string) ([]database.GetReviewersByPRsRow, error) {
+	defer q.lock()()
+
+	reviewers := []database.GetReviewersByPRsRow{}
+	for key := range q.st.reviewers {
+		if slices.Contains(prIds, key.prID) {
+			reviewers = append(reviewers, database.GetReviewersByPRsRow{
+				PrID:       key.prID,
+				ReviewerID: key.reviewerID,
+			})
+		}
+	}
+
+	sort.Slice(reviewers, func(i, j int) bool {
+		if reviewers[i].PrID != reviewers[j].PrID {
+			return reviewers[i].PrID < reviewers[j].PrID
+		}
+		return reviewers[i].ReviewerID < reviewers[j].ReviewerID
+	})
+	return reviewers, nil
+}
+
+func (q *Queries) IsReviewerAssigned(ctx context.Context, arg database.IsReviewerAssignedParams) (bool, error) {
+	defer q.lock()()
+
+	_, ok := q.st.reviewers[reviewKey{prID: arg.PrID, reviewerID: arg.ReviewerID}]
+	return ok, nil
+}
+
+func (q *Queries) MergePR(ctx context.Context, arg database.MergePRParams) error {
+	defer q.lock()()
+
+	pr, ok := q.st.prs[arg.ID]
+	if !ok {
+		return nil
+	}
+
+	pr.Status = "MERGED"
+	pr.MergedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	pr.ForceMerged = arg.ForceMerged
+	pr.ForceMergedBy = arg.ForceMergedBy
+	put(q.st, q.st.prs, arg.ID, pr)
+
+	return nil
+}
+
+func (q *Queries) ClosePR(ctx context.Context, id string) error {
+	defer q.lock()()
+
+	pr, ok := q.st.prs[id]
+	if !ok {
+		return nil
+	}
+
+	pr.Status = "CLOSED"
+	pr.ClosedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	put(q.st, q.st.prs, id, pr)
+
+	return nil
+}
+
+func (q *Queries) ReopenPR(ctx context.Context, id string) error {
+	defer q.lock()()
+
+	pr, ok := q.st.prs[id]
+	if !ok {
+		return nil
+	}
+
+	pr.Status = "OPEN"
+	pr.ClosedAt = sql.NullTime{}
+	put(q.st, q.st.prs, id, pr)
+
+	return nil
+}
+
+func (q *Queries) MarkPRReady(ctx context.Context, id string) error {
+	defer q.lock()()
+
+	pr, ok := q.st.prs[id]
+	if !ok {
+		return nil
+	}
+
+	pr.IsDraft = false
+	put(q.st, q.st.prs, id, pr)
+
+	return nil
+}
+
+func (q *Queries) CheckDuplicatePR(ctx context.Context, arg database.CheckDuplicatePRParams) (string, error) {
+	defer q.lock()()
+
+	for _, pr := range q.st.prs {
+		if pr.AuthorID == arg.AuthorID && pr.Title == arg.Title && pr.Status == "OPEN" {
+			return pr.ID, nil
+		}
+	}
+	return "", sql.ErrNoRows
+}
+
+func (q *Queries) CountOpenReviews(ctx context.Context, reviewerIds []string) ([]database.CountOpenReviewsRow, error) {
+	defer q.lock()()
+
+	rows := []database.CountOpenReviewsRow{}
+	for _, id := range uniqueSorted(reviewerIds) {
+		if n := q.st.openReviews(id); n > 0 {
+			rows = append(rows, database.CountOpenReviewsRow{
+				ReviewerID:  id,
+				OpenReviews: n,
+			})
+		}
+	}
+	return rows, nil
+}
+
+func (q *Queries) GetOpenReviewsByReviewers(ctx context.Context, reviewerIds []string) ([]database.GetOpenReviewsByReviewersRow, error) {
+	defer q.lock()()
+
+	rows := []database.GetOpenReviewsByReviewersRow{}
+	for key := range q.st.reviewers {
+		pr := q.st.prs[key.prID]
+		if pr.Status != "OPEN" || !slices.Contains(reviewerIds, key.reviewerID) {
+			continue
+		}
+
+		rows = append(rows, database.GetOpenReviewsByReviewersRow{
+			PrID:       key.prID,
+			ReviewerID: key.reviewerID,
+			AuthorID:   pr.AuthorID,
+		})
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].PrID != rows[j].PrID {
+			return rows[i].PrID < rows[j].PrID
+		}
+		return rows[i].ReviewerID < rows[j].ReviewerID
+	})
+
+	return rows, nil
+}
+
+func (q *Queries) GetReviewsByPR(ctx context.Context, prID string) ([]database.GetReviewsByPRRow, error) {
+	defer q.lock()()
+
+	rows := []database.GetReviewsByPRRow{}
+	for _, review := range q.st.reviewsByPR(prID) {
+		rows = append(rows, database.GetReviewsByPRRow{
+			ReviewerID: review.ReviewerID,
+			State:      review.State,
+			AssignedAt: review.AssignedAt,
+			ReviewedAt: review.ReviewedAt,
+		})
+	}
+	return rows, nil
+}
+
+func (q *Queries) SetReviewState(ctx context.Context, arg database.SetReviewStateParams) (int64, error) {
+	defer q.lock()()
+
+	key := reviewKey{prID: arg.PrID, reviewerID: arg.ReviewerID}
+	review, ok := q.st.reviewers[key]
+	if !ok {
+		return 0, nil
+	}
+
+	review.State = arg.State
+	review.ReviewedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	put(q.st, q.st.reviewers, key, review)
+
+	return 1, nil
+}
+
+func (q *Queries) GetIneligibleReviewersByPR(ctx context.Context, prID string) ([]database.GetIneligibleReviewersByPRRow, error) {
+	defer q.lock()()
+
+	rows := []database.GetIneligibleReviewersByPRRow{}
+	for _, review := range q.st.reviewsByPR(prID) {
+		user, ok := q.st.users[review.ReviewerID]
+		if !ok {
+			continue
+		}
+
+		if !user.IsAvailable || q.st.openReviews(user.ID) > q.st.capacity(user) {
+			rows = append(rows, database.GetIneligibleReviewersByPRRow{
+				ID:     user.ID,
+				TeamID: user.TeamID,
+			})
+		}
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i].ID < rows[j].ID
+	})
+
+	return rows, nil
+}
+
+func uniqueSorted(ids []string) []string {
+	out := slices.Clone(ids)
+	slices.Sort(out)
+	return slices.Compact(out)
+}
@@ -0,0 +1,177 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/LlirikP/pr_dispenser/internal/database"
+)
+
+var ErrConstraint = errors.New("constraint violation")
+
+func constraintError(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrConstraint, fmt.Sprintf(format, args...))
+}
+
+type reviewKey struct {
+	prID       string
+	reviewerID string
+}
+
+type state struct {
+	teams         map[string]database.Team
+	users         map[string]database.User
+	prs           map[string]database.Pr
+	reviewers     map[reviewKey]database.PrReviewer
+	fallbacks     map[string][]database.TeamFallback
+	subscriptions map[string]database.WebhookSubscription
+	tokens        map[string]database.ApiToken
+	deliveries    []database.WebhookDelivery
+	outbox        []database.Outbox
+	audit         []database.AuditLog
+	lastDelivery  int64
+	lastOutbox    int64
+
+	// While a transaction is open every write adds a step to undo, so that a
+	// failed transaction costs as much as the writes it made rather than a
+	// copy of the whole store. Tables must therefore only be changed through
+	// put, remove, appendRow, touch and replaceRows.
+	inTx bool
+	undo []func()
+}
+
+func newState() *state {
+	return &state{
+		teams:         map[string]database.Team{},
+		users:         map[string]database.User{},
+		prs:           map[string]database.Pr{},
+		reviewers:     map[reviewKey]database.PrReviewer{},
+		fallbacks:     map[string][]database.TeamFallback{},
+		subscriptions: map[string]database.WebhookSubscription{},
+		tokens:        map[string]database.ApiToken{},
+	}
+}
+
+// record remembers how to revert a write made inside a transaction. Outside
+// one writes are final and nothing is kept.
+func (s *state) record(undo func()) {
+	if s.inTx {
+		s.undo = append(s.undo, undo)
+	}
+}
+
+// rollback reverts the writes of the open transaction, newest first.
+func (s *state) rollback() {
+	for i := len(s.undo) - 1; i >= 0; i-- {
+		s.undo[i]()
+	}
+}
+
+// next bumps an id counter.
+func (s *state) next(counter *int64) int64 {
+	prev := *counter
+	s.record(func() { *counter = prev })
+	*counter++
+	return *counter
+}
+
+func put[K comparable, V any](s *state, m map[K]V, k K, v V) {
+	prev, ok := m[k]
+	s.record(func() {
+		if ok {
+			m[k] = prev
+		} else {
+			delete(m, k)
+		}
+	})
+	m[k] = v
+}
+
+func remove[K comparable, V any](s *state, m map[K]V, k K) {
+	prev, ok := m[k]
+	if !ok {
+		return
+	}
+	s.record(func() { m[k] = prev })
+	delete(m, k)
+}
+
+func appendRow[T any](s *state, rows *[]T, row T) {
+	n := len(*rows)
+	s.record(func() { *rows = (*rows)[:n] })
+	*rows = append(*rows, row)
+}
+
+// touch must be called before rows[i] is changed in place.
+func touch[T any](s *state, rows *[]T, i int) {
+	prev := (*rows)[i]
+	s.record(func() { (*rows)[i] = prev })
+}
+
+// replaceRows swaps in a new table; next must not share memory with the
+// current one.
+func replaceRows[T any](s *state, rows *[]T, next []T) {
+	prev := *rows
+	s.record(func() { *rows = prev })
+	*rows = next
+}
+
+type Queries struct {
+	mu *sync.Mutex
+	st *state
+}
+
+func (q *Queries) lock() func() {
+	if q.mu == nil {
+		return func() {}
+	}
+	q.mu.Lock()
+	return q.mu.Unlock
+}
+
+type Store struct {
+	*Queries
+}
+
+func New() *Store {
+	return &Store{
+		Queries: &Queries{
+			mu: &sync.Mutex{},
+			st: newState(),
+		},
+	}
+}
+
+func (s *Store) ExecTx(ctx context.Context, fn func(database.Querier) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	committed := false
+	s.st.inTx = true
+	defer func() {
+		if !committed {
+			s.st.rollback()
+		}
+		s.st.inTx, s.st.undo = false, nil
+	}()
+
+	tx := &Queries{st: s.st}
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	committed = true
+	return nil
+}
+
+var _ database.Storage = (*Store)(nil)
@@ -0,0 +1,15 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/LlirikP/pr_dispenser/internal/database"
+	"github.com/LlirikP/pr_dispenser/internal/database/memory"
+	"github.com/LlirikP/pr_dispenser/internal/database/storagetest"
+)
+
+func TestStorage(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) database.Storage {
+		return memory.New()
+	})
+}
@@ -0,0 +1,234 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"sort"
+
+	"github.com/LlirikP/pr_dispenser/internal/database"
+)
+
+func (q *Queries) CreateTeam(ctx context.Context, arg database.CreateTeamParams) error {
+	defer q.lock()()
+
+	if _, ok := q.st.teams[arg.ID]; ok {
+		return constraintError("team %s already exists", arg.ID)
+	}
+
+	for _, team := range q.st.teams {
+		if team.Teamname == arg.Teamname {
+			return constraintError("team name %s already taken", arg.Teamname)
+		}
+	}
+
+	put(q.st, q.st.teams, arg.ID, database.Team{
+		ID:                arg.ID,
+		Teamname:          arg.Teamname,
+		ReviewerStrategy:  arg.ReviewerStrategy,
+		MaxOpenReviews:    arg.MaxOpenReviews,
+		ReviewersRequired: arg.ReviewersRequired,
+		ApprovalsRequired: arg.ApprovalsRequired,
+	})
+
+	return nil
+}
+
+func (q *Queries) GetTeamByName(ctx context.Context, teamname string) (database.Team, error) {
+	defer q.lock()()
+
+	for _, team := range q.st.teams {
+		if team.Teamname == teamname {
+			return team, nil
+		}
+	}
+	return database.Team{}, sql.ErrNoRows
+}
+
+func (q *Queries) GetTeamByID(ctx context.Context, id string) (database.Team, error) {
+	defer q.lock()()
+
+	team, ok := q.st.teams[id]
+	if !ok {
+		return team, sql.ErrNoRows
+	}
+	return team, nil
+}
+
+func (q *Queries) updateTeam(id string, fn func(*database.Team)) error {
+	defer q.lock()()
+
+	team, ok := q.st.teams[id]
+	if !ok {
+		return nil
+	}
+
+	fn(&team)
+	put(q.st, q.st.teams, id, team)
+
+	return nil
+}
+
+func (q *Queries) SetTeamReviewerStrategy(ctx context.Context, arg database.SetTeamReviewerStrategyParams) error {
+	return q.updateTeam(arg.ID, func(t *database.Team) {
+		t.ReviewerStrategy = arg.ReviewerStrategy
+	})
+}
+
+func (q *Queries) SetTeamMaxOpenReviews(ctx context.Context, arg database.SetTeamMaxOpenReviewsParams) error {
+	return q.updateTeam(arg.ID, func(t *database.Team) {
+		t.MaxOpenReviews = arg.MaxOpenReviews
+	})
+}
+
+func (q *Queries) SetTeamReviewersRequired(ctx context.Context, arg database.SetTeamReviewersRequiredParams) error {
+	return q.updateTeam(arg.ID, func(t *database.Team) {
+		t.ReviewersRequired = arg.ReviewersRequired
+	})
+}
+
+func (q *Queries) SetTeamApprovalsRequired(ctx context.Context, arg database.SetTeamApprovalsRequiredParams) error {
+	return q.updateTeam(arg.ID, func(t *database.Team) {
+		t.ApprovalsRequired = arg.ApprovalsRequired
+	})
+}
+
+func (q *Queries) SetTeamRoundRobinCursor(ctx context.Context, arg database.SetTeamRoundRobinCursorParams) error {
+	return q.updateTeam(arg.ID, func(t *database.Team) {
+		t.RrLastReviewerID = arg.RrLastReviewerID
+	})
+}
+
+func (q *Queries) UpsertUser(ctx context.Context, arg database.UpsertUserParams) error {
+	defer q.lock()()
+
+	if _, ok := q.st.teams[arg.TeamID]; !ok {
+		return constraintError("team %s does not exist", arg.TeamID)
+	}
+
+	user, exists := q.st.users[arg.ID]
+	if !exists || arg.GithubLogin.Valid {
+		user.GithubLogin = arg.GithubLogin
+	}
+	if !exists || arg.GitlabUsername.Valid {
+		user.GitlabUsername = arg.GitlabUsername
+	}
+
+	for id, other := range q.st.users {
+		if id == arg.ID {
+			continue
+		}
+		if user.GithubLogin.Valid && other.GithubLogin == user.GithubLogin {
+			return constraintError("github login %s already mapped", user.GithubLogin.String)
+		}
+		if user.GitlabUsername.Valid && other.GitlabUsername == user.GitlabUsername {
+			return constraintError("gitlab username %s already mapped", user.GitlabUsername.String)
+		}
+	}
+
+	user.ID = arg.ID
+	user.Username = arg.Username
+	user.IsAvailable = arg.IsAvailable
+	user.TeamID = arg.TeamID
+	user.ReviewWeight = arg.ReviewWeight
+	user.MaxOpenReviews = arg.MaxOpenReviews
+	user.Role = arg.Role
+	put(q.st, q.st.users, arg.ID, user)
+
+	return nil
+}
+
+func (q *Queries) GetUsersByTeam(ctx context.Context, teamID string) ([]database.GetUsersByTeamRow, error) {
+	defer q.lock()()
+
+	rows := []database.GetUsersByTeamRow{}
+	for _, u := range q.st.users {
+		if u.TeamID != teamID {
+			continue
+		}
+
+		rows = append(rows, database.GetUsersByTeamRow{
+			ID:             u.ID,
+			Username:       u.Username,
+			IsAvailable:    u.IsAvailable,
+			TeamID:         u.TeamID,
+			ReviewWeight:   u.ReviewWeight,
+			MaxOpenReviews: u.MaxOpenReviews,
+			GithubLogin:    u.GithubLogin,
+			GitlabUsername: u.GitlabUsername,
+			Role:           u.Role,
+			OpenReviews:    q.st.openReviews(u.ID),
+		})
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Username != rows[j].Username {
+			return rows[i].Username < rows[j].Username
+		}
+		return rows[i].ID < rows[j].ID
+	})
+
+	return rows, nil
+}
+
+func (q *Queries) GetTeamNameByID(ctx context.Context, id string) (string, error) {
+	defer q.lock()()
+
+	team, ok := q.st.teams[id]
+	if !ok {
+		return "", sql.ErrNoRows
+	}
+	return team.Teamname, nil
+}
+
+func (q *Queries) GetTeamFallbacks(ctx context.Context, teamID string) ([]database.Team, error) {
+	defer q.lock()()
+
+	links := slices.Clone(q.st.fallbacks[teamID])
+	sort.SliceStable(links, func(i, j int) bool {
+		return links[i].Priority < links[j].Priority
+	})
+
+	teams := []database.Team{}
+	for _, link := range links {
+		if team, ok := q.st.teams[link.FallbackTeamID]; ok {
+			teams = append(teams, team)
+		}
+	}
+	return teams, nil
+}
+
+func (q *Queries) DeleteTeamFallbacks(ctx context.Context, teamID string) error {
+	defer q.lock()()
+
+	remove(q.st, q.st.fallbacks, teamID)
+	return nil
+}
+
+func (q *Queries) AddTeamFallback(ctx context.Context, arg database.AddTeamFallbackParams) error {
+	defer q.lock()()
+
+	if arg.TeamID == arg.FallbackTeamID {
+		return constraintError("team %s cannot fall back to itself", arg.TeamID)
+	}
+
+	for _, id := range []string{arg.TeamID, arg.FallbackTeamID} {
+		if _, ok := q.st.teams[id]; !ok {
+			return constraintError("team %s does not exist", id)
+		}
+	}
+
+	for _, link := range q.st.fallbacks[arg.TeamID] {
+		if link.FallbackTeamID == arg.FallbackTeamID {
+			return constraintError("fallback %s already set for team %s", arg.FallbackTeamID, arg.TeamID)
+		}
+	}
+
+	put(q.st, q.st.fallbacks, arg.TeamID, append(q.st.fallbacks[arg.TeamID], database.TeamFallback{
+		TeamID:         arg.TeamID,
+		FallbackTeamID: arg.FallbackTeamID,
+		Priority:       arg.Priority,
+	}))
+
+	return nil
+}
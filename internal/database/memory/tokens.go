@@ -0,0 +1,107 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+
+	"github.com/LlirikP/pr_dispenser/internal/database"
+)
+
+func (q *Queries) CreateAPIToken(ctx context.Context, arg database.CreateAPITokenParams) error {
+	defer q.lock()()
+
+	if _, ok := q.st.tokens[arg.ID]; ok {
+		return constraintError("token %s already exists", arg.ID)
+	}
+
+	for _, token := range q.st.tokens {
+		if token.TokenHash == arg.TokenHash {
+			return constraintError("token hash already exists")
+		}
+	}
+
+	if arg.UserID.Valid {
+		if _, ok := q.st.users[arg.UserID.String]; !ok {
+			return constraintError("user %s does not exist", arg.UserID.String)
+		}
+	}
+
+	put(q.st, q.st.tokens, arg.ID, database.ApiToken{
+		ID:        arg.ID,
+		Name:      arg.Name,
+		TokenHash: arg.TokenHash,
+		Scope:     arg.Scope,
+		UserID:    arg.UserID,
+		CreatedAt: time.Now(),
+	})
+
+	return nil
+}
+
+func (q *Queries) GetAPITokenByHash(ctx context.Context, tokenHash string) (database.ApiToken, error) {
+	defer q.lock()()
+
+	for _, token := range q.st.tokens {
+		if token.TokenHash == tokenHash && !token.RevokedAt.Valid {
+			return token, nil
+		}
+	}
+
+	return database.ApiToken{}, sql.ErrNoRows
+}
+
+func (q *Queries) FindAPITokenByHash(ctx context.Context, tokenHash string) (database.ApiToken, error) {
+	defer q.lock()()
+
+	for _, token := range q.st.tokens {
+		if token.TokenHash == tokenHash {
+			return token, nil
+		}
+	}
+
+	return database.ApiToken{}, sql.ErrNoRows
+}
+
+func (q *Queries) GetAPITokenByID(ctx context.Context, id string) (database.ApiToken, error) {
+	defer q.lock()()
+
+	token, ok := q.st.tokens[id]
+	if !ok {
+		return database.ApiToken{}, sql.ErrNoRows
+	}
+	return token, nil
+}
+
+func (q *Queries) ListAPITokens(ctx context.Context) ([]database.ApiToken, error) {
+	defer q.lock()()
+
+	tokens := make([]database.ApiToken, 0, len(q.st.tokens))
+	for _, token := range q.st.tokens {
+		tokens = append(tokens, token)
+	}
+
+	sort.Slice(tokens, func(i, j int) bool {
+		if !tokens[i].CreatedAt.Equal(tokens[j].CreatedAt) {
+			return tokens[i].CreatedAt.Before(tokens[j].CreatedAt)
+		}
+		return tokens[i].ID < tokens[j].ID
+	})
+
+	return tokens, nil
+}
+
+func (q *Queries) RevokeAPIToken(ctx context.Context, id string) (int64, error) {
+	defer q.lock()()
+
+	token, ok := q.st.tokens[id]
+	if !ok || token.RevokedAt.Valid {
+		return 0, nil
+	}
+
+	token.RevokedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	put(q.st, q.st.tokens, id, token)
+
+	return 1, nil
+}
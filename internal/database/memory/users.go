@@ -0,0 +1,139 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"sort"
+
+	"github.com/LlirikP/pr_dispenser/internal/database"
+)
+
+func (q *Queries) GetUserById(ctx context.Context, id string) (database.User, error) {
+	defer q.lock()()
+
+	user, ok := q.st.users[id]
+	if !ok {
+		return user, sql.ErrNoRows
+	}
+	return user, nil
+}
+
+func (q *Queries) GetUserByGithubLogin(ctx context.Context, githubLogin sql.NullString) (database.User, error) {
+	defer q.lock()()
+
+	for _, user := range q.st.users {
+		if githubLogin.Valid && user.GithubLogin == githubLogin {
+			return user, nil
+		}
+	}
+	return database.User{}, sql.ErrNoRows
+}
+
+func (q *Queries) GetUserByGitlabUsername(ctx context.Context, gitlabUsername sql.NullString) (database.User, error) {
+	defer q.lock()()
+
+	for _, user := range q.st.users {
+		if gitlabUsername.Valid && user.GitlabUsername == gitlabUsername {
+			return user, nil
+		}
+	}
+	return database.User{}, sql.ErrNoRows
+}
+
+func (q *Queries) GetUserOpenReviews(ctx context.Context, userID string) (int64, error) {
+	defer q.lock()()
+
+	if _, ok := q.st.users[userID]; !ok {
+		return 0, sql.ErrNoRows
+	}
+	return q.st.openReviews(userID), nil
+}
+
+func (q *Queries) GetUserWeights(ctx context.Context, ids []string) ([]database.GetUserWeightsRow, error) {
+	defer q.lock()()
+
+	rows := []database.GetUserWeightsRow{}
+	for _, id := range uniqueSorted(ids) {
+		if user, ok := q.st.users[id]; ok {
+			rows = append(rows, database.GetUserWeightsRow{
+				ID:           user.ID,
+				ReviewWeight: user.ReviewWeight,
+			})
+		}
+	}
+	return rows, nil
+}
+
+func (q *Queries) SetUserAvailability(ctx context.Context, arg database.SetUserAvailabilityParams) error {
+	defer q.lock()()
+
+	if user, ok := q.st.users[arg.ID]; ok {
+		user.IsAvailable = arg.IsAvailable
+		put(q.st, q.st.users, arg.ID, user)
+	}
+	return nil
+}
+
+func (q *Queries) SetUserMaxOpenReviews(ctx context.Context, arg database.SetUserMaxOpenReviewsParams) error {
+	defer q.lock()()
+
+	if user, ok := q.st.users[arg.ID]; ok {
+		user.MaxOpenReviews = arg.MaxOpenReviews
+		put(q.st, q.st.users, arg.ID, user)
+	}
+	return nil
+}
+
+func (q *Queries) SetUserRole(ctx context.Context, arg database.SetUserRoleParams) error {
+	defer q.lock()()
+
+	if user, ok := q.st.users[arg.ID]; ok {
+		user.Role = arg.Role
+		put(q.st, q.st.users, arg.ID, user)
+	}
+	return nil
+}
+
+func (q *Queries) GetReviewPRs(ctx context.Context, reviewerID string) ([]database.GetReviewPRsRow, error) {
+	defer q.lock()()
+
+	rows := []database.GetReviewPRsRow{}
+	for key, review := range q.st.reviewers {
+		if key.reviewerID != reviewerID {
+			continue
+		}
+
+		pr, ok := q.st.prs[key.prID]
+		if !ok {
+			continue
+		}
+
+		rows = append(rows, database.GetReviewPRsRow{
+			PrID:        pr.ID,
+			PrTitle:     pr.Title,
+			AuthorID:    pr.AuthorID,
+			Status:      pr.Status,
+			ReviewState: review.State,
+			ReviewedAt:  review.ReviewedAt,
+		})
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i].PrID < rows[j].PrID
+	})
+
+	return rows, nil
+}
+
+func (q *Queries) SetUsersAvailability(ctx context.Context, arg database.SetUsersAvailabilityParams) error {
+	defer q.lock()()
+
+	for id, user := range q.st.users {
+		if slices.Contains(arg.Ids, id) {
+			user.IsAvailable = arg.IsAvailable
+			put(q.st, q.st.users, id, user)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,263 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/LlirikP/pr_dispenser/internal/database"
+)
+
+func (q *Queries) CreateWebhookSubscription(ctx context.Context, arg database.CreateWebhookSubscriptionParams) error {
+	defer q.lock()()
+
+	if _, ok := q.st.subscriptions[arg.ID]; ok {
+		return constraintError("subscription %s already exists", arg.ID)
+	}
+
+	put(q.st, q.st.subscriptions, arg.ID, database.WebhookSubscription{
+		ID:        arg.ID,
+		Url:       arg.Url,
+		Events:    slices.Clone(arg.Events),
+		Secret:    arg.Secret,
+		CreatedAt: time.Now(),
+	})
+
+	return nil
+}
+
+func (q *Queries) sortedSubscriptions(keep func(database.WebhookSubscription) bool) []database.WebhookSubscription {
+	subs := []database.WebhookSubscription{}
+	for _, sub := range q.st.subscriptions {
+		if keep(sub) {
+			subs = append(subs, sub)
+		}
+	}
+
+	sort.Slice(subs, func(i, j int) bool {
+		if !subs[i].CreatedAt.Equal(subs[j].CreatedAt) {
+			return subs[i].CreatedAt.Before(subs[j].CreatedAt)
+		}
+		return subs[i].ID < subs[j].ID
+	})
+
+	return subs
+}
+
+func (q *Queries) ListWebhookSubscriptions(ctx context.Context) ([]database.WebhookSubscription, error) {
+	defer q.lock()()
+
+	return q.sortedSubscriptions(func(database.WebhookSubscription) bool { return true }), nil
+}
+
+func (q *Queries) GetWebhookSubscriptionByID(ctx context.Context, id string) (database.WebhookSubscription, error) {
+	defer q.lock()()
+
+	sub, ok := q.st.subscriptions[id]
+	if !ok {
+		return sub, sql.ErrNoRows
+	}
+	sub.Events = slices.Clone(sub.Events)
+	return sub, nil
+}
+
+func (q *Queries) DeleteWebhookSubscription(ctx context.Context, id string) (int64, error) {
+	defer q.lock()()
+
+	if _, ok := q.st.subscriptions[id]; !ok {
+		return 0, nil
+	}
+
+	remove(q.st, q.st.subscriptions, id)
+	replaceRows(q.st, &q.st.deliveries, slices.DeleteFunc(slices.Clone(q.st.deliveries), func(d database.WebhookDelivery) bool {
+		return d.SubscriptionID == id
+	}))
+
+	return 1, nil
+}
+
+func (q *Queries) GetSubscriptionsForEvent(ctx context.Context, eventType string) ([]database.WebhookSubscription, error) {
+	defer q.lock()()
+
+	return q.sortedSubscriptions(func(sub database.WebhookSubscription) bool {
+		return len(sub.Events) == 0 || slices.Contains(sub.Events, eventType)
+	}), nil
+}
+
+func (q *Queries) CreateWebhookDelivery(ctx context.Context, arg database.CreateWebhookDeliveryParams) error {
+	defer q.lock()()
+
+	if _, ok := q.st.subscriptions[arg.SubscriptionID]; !ok {
+		return constraintError("subscription %s does not exist", arg.SubscriptionID)
+	}
+
+	for _, d := range q.st.deliveries {
+		if d.SubscriptionID == arg.SubscriptionID && d.EventID == arg.EventID {
+			return nil
+		}
+	}
+
+	now := time.Now()
+	appendRow(q.st, &q.st.deliveries, database.WebhookDelivery{
+		ID:             q.st.next(&q.st.lastDelivery),
+		SubscriptionID: arg.SubscriptionID,
+		EventID:        arg.EventID,
+		EventType:      arg.EventType,
+		PrID:           arg.PrID,
+		Payload:        arg.Payload,
+		Status:         "PENDING",
+		NextAttemptAt:  now,
+		CreatedAt:      now,
+	})
+
+	return nil
+}
+
+func (q *Queries) ClaimDueWebhookDeliveries(ctx context.Context, arg database.ClaimDueWebhookDeliveriesParams) ([]database.ClaimDueWebhookDeliveriesRow, error) {
+	defer q.lock()()
+
+	type lane struct {
+		subscriptionID string
+		prID           string
+	}
+
+	now := time.Now()
+	blocked := map[lane]bool{}
+	rows := []database.ClaimDueWebhookDeliveriesRow{}
+
+	for i := range q.st.deliveries {
+		if len(rows) >= int(arg.BatchSize) {
+			break
+		}
+
+		d := &q.st.deliveries[i]
+		if d.Status != "PENDING" {
+			continue
+		}
+
+		key := lane{subscriptionID: d.SubscriptionID, prID: d.PrID}
+		if blocked[key] {
+			continue
+		}
+		blocked[key] = true
+
+		if d.NextAttemptAt.After(now) {
+			continue
+		}
+
+		sub := q.st.subscriptions[d.SubscriptionID]
+		touch(q.st, &q.st.deliveries, i)
+		d.NextAttemptAt = arg.LeaseUntil
+
+		rows = append(rows, database.ClaimDueWebhookDeliveriesRow{
+			ID:        d.ID,
+			EventID:   d.EventID,
+			EventType: d.EventType,
+			PrID:      d.PrID,
+			Payload:   d.Payload,
+			Attempts:  d.Attempts,
+			Url:       sub.Url,
+			Secret:    sub.Secret,
+		})
+	}
+
+	return rows, nil
+}
+
+func (q *Queries) updateDelivery(id int64, fn func(*database.WebhookDelivery) bool) int64 {
+	defer q.lock()()
+
+	for i := range q.st.deliveries {
+		if q.st.deliveries[i].ID == id {
+			touch(q.st, &q.st.deliveries, i)
+			if fn(&q.st.deliveries[i]) {
+				return 1
+			}
+			return 0
+		}
+	}
+	return 0
+}
+
+func (q *Queries) MarkWebhookDelivered(ctx context.Context, arg database.MarkWebhookDeliveredParams) error {
+	q.updateDelivery(arg.ID, func(d *database.WebhookDelivery) bool {
+		d.Status = "DELIVERED"
+		d.Attempts++
+		d.ResponseStatus = arg.ResponseStatus
+		d.LastError.Valid = false
+		d.DeliveredAt.Time, d.DeliveredAt.Valid = time.Now(), true
+		return true
+	})
+	return nil
+}
+
+func (q *Queries) MarkWebhookAttemptFailed(ctx context.Context, arg database.MarkWebhookAttemptFailedParams) error {
+	q.updateDelivery(arg.ID, func(d *database.WebhookDelivery) bool {
+		d.Status = arg.Status
+		d.Attempts++
+		d.ResponseStatus = arg.ResponseStatus
+		d.LastError = arg.LastError
+		d.NextAttemptAt = arg.NextAttemptAt
+		return true
+	})
+	return nil
+}
+
+func (q *Queries) GetWebhookDeliveryByID(ctx context.Context, id int64) (database.WebhookDelivery, error) {
+	defer q.lock()()
+
+	for _, d := range q.st.deliveries {
+		if d.ID == id {
+			return d, nil
+		}
+	}
+	return database.WebhookDelivery{}, sql.ErrNoRows
+}
+
+func (q *Queries) RequeueWebhookDelivery(ctx context.Context, id int64) (int64, error) {
+	return q.updateDelivery(id, func(d *database.WebhookDelivery) bool {
+		if d.Status != "DEAD" {
+			return false
+		}
+		d.Status = "PENDING"
+		d.Attempts = 0
+		d.LastError.Valid = false
+		d.NextAttemptAt = time.Now()
+		return true
+	}), nil
+}
+
+func (q *Queries) ListWebhookDeliveries(ctx context.Context, arg database.ListWebhookDeliveriesParams) ([]database.ListWebhookDeliveriesRow, error) {
+	defer q.lock()()
+
+	rows := []database.ListWebhookDeliveriesRow{}
+	for i := len(q.st.deliveries) - 1; i >= 0 && len(rows) < int(arg.Limit); i-- {
+		d := q.st.deliveries[i]
+		if arg.SubscriptionID.Valid && d.SubscriptionID != arg.SubscriptionID.String {
+			continue
+		}
+		if arg.Status.Valid && d.Status != arg.Status.String {
+			continue
+		}
+
+		rows = append(rows, database.ListWebhookDeliveriesRow{
+			ID:             d.ID,
+			SubscriptionID: d.SubscriptionID,
+			EventID:        d.EventID,
+			EventType:      d.EventType,
+			PrID:           d.PrID,
+			Payload:        d.Payload,
+			Status:         d.Status,
+			Attempts:       d.Attempts,
+			ResponseStatus: d.ResponseStatus,
+			LastError:      d.LastError,
+			NextAttemptAt:  d.NextAttemptAt,
+			CreatedAt:      d.CreatedAt,
+			DeliveredAt:    d.DeliveredAt,
+		})
+	}
+
+	return rows, nil
+}
@@ -6,31 +6,123 @@ package database
 
 import (
 	"database/sql"
+	"encoding/json"
 	"time"
 )
 
-type Pr struct {
+type ApiToken struct {
 	ID        string
-	Title     string
-	AuthorID  string
-	Status    string
+	Name      string
+	TokenHash string
+	Scope     string
+	UserID    sql.NullString
 	CreatedAt time.Time
-	MergedAt  sql.NullTime
+	RevokedAt sql.NullTime
+}
+
+type AuditLog struct {
+	ID         int64
+	Actor      string
+	Action     string
+	TargetType string
+	TargetID   string
+	Before     json.RawMessage
+	After      json.RawMessage
+	RequestID  string
+	CreatedAt  time.Time
+}
+
+type Outbox struct {
+	ID            int64
+	EventID       string
+	EventType     string
+	PrID          string
+	Payload       json.RawMessage
+	Status        string
+	Attempts      int32
+	LastError     sql.NullString
+	NextAttemptAt time.Time
+	CreatedAt     time.Time
+	DispatchedAt  sql.NullTime
+	UserIds       []string
+	StreamSeq     sql.NullInt64
+}
+
+type Pr struct {
+	ID                string
+	Title             string
+	AuthorID          string
+	Status            string
+	CreatedAt         time.Time
+	MergedAt          sql.NullTime
+	ReviewersRequired int32
+	ForceMerged       bool
+	ForceMergedBy     sql.NullString
+	ClosedAt          sql.NullTime
+	IsDraft           bool
 }
 
 type PrReviewer struct {
 	PrID       string
 	ReviewerID string
+	State      string
+	AssignedAt time.Time
+	ReviewedAt sql.NullTime
 }
 
 type Team struct {
-	ID       string
-	Teamname string
+	ID                string
+	Teamname          string
+	ReviewerStrategy  string
+	RrLastReviewerID  sql.NullString
+	MaxOpenReviews    int32
+	ReviewersRequired int32
+	ApprovalsRequired int32
+}
+
+type TeamFallback struct {
+	TeamID         string
+	FallbackTeamID string
+	Priority       int32
 }
 
 type User struct {
-	ID       string
-	Username string
-	IsActive bool
-	TeamID   string
+	ID             string
+	Username       string
+	IsAvailable    bool
+	TeamID         string
+	ReviewWeight   int32
+	MaxOpenReviews sql.NullInt32
+	GithubLogin    sql.NullString
+	GitlabUsername sql.NullString
+	Role           string
+}
+
+type UserWorkload struct {
+	UserID      string
+	OpenReviews int64
+}
+
+type WebhookDelivery struct {
+	ID             int64
+	SubscriptionID string
+	EventID        string
+	EventType      string
+	Payload        json.RawMessage
+	Status         string
+	Attempts       int32
+	ResponseStatus sql.NullInt32
+	LastError      sql.NullString
+	NextAttemptAt  time.Time
+	CreatedAt      time.Time
+	DeliveredAt    sql.NullTime
+	PrID           string
+}
+
+type WebhookSubscription struct {
+	ID        string
+	Url       string
+	Events    []string
+	Secret    string
+	CreatedAt time.Time
 }
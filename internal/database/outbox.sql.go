@@ -0,0 +1,221 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: outbox.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const claimNextOutboxEvent = `-- name: ClaimNextOutboxEvent :one
+SELECT id, event_id, event_type, pr_id, payload, status, attempts, last_error, next_attempt_at, created_at, dispatched_at, user_ids, stream_seq
+FROM outbox o
+WHERE o.status = 'PENDING'
+  AND o.next_attempt_at <= NOW()
+  AND NOT EXISTS (
+      SELECT 1
+      FROM outbox prev
+      WHERE prev.pr_id = o.pr_id
+        AND prev.status = 'PENDING'
+        AND prev.id < o.id
+  )
+ORDER BY o.id
+LIMIT 1
+FOR UPDATE SKIP LOCKED
+`
+
+func (q *Queries) ClaimNextOutboxEvent(ctx context.Context) (Outbox, error) {
+	row := q.db.QueryRowContext(ctx, claimNextOutboxEvent)
+	var i Outbox
+	err := row.Scan(
+		&i.ID,
+		&i.EventID,
+		&i.EventType,
+		&i.PrID,
+		&i.Payload,
+		&i.Status,
+		&i.Attempts,
+		&i.LastError,
+		&i.NextAttemptAt,
+		&i.CreatedAt,
+		&i.DispatchedAt,
+		pq.Array(&i.UserIds),
+		&i.StreamSeq,
+	)
+	return i, err
+}
+
+const getLatestStreamSeq = `-- name: GetLatestStreamSeq :one
+SELECT COALESCE(MAX(stream_seq), 0)::bigint
+FROM outbox
+`
+
+func (q *Queries) GetLatestStreamSeq(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getLatestStreamSeq)
+	var column_1 int64
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const getStreamEventsAfter = `-- name: GetStreamEventsAfter :many
+SELECT o.stream_seq::bigint AS seq, o.event_type, o.payload
+FROM outbox o
+WHERE o.stream_seq > $1::bigint
+  AND ($2::text IS NULL OR o.pr_id = $2)
+  AND ($3::text IS NULL OR $3::text = ANY(o.user_ids))
+  AND ($4::text IS NULL OR EXISTS (
+      SELECT 1
+      FROM users u
+      WHERE u.id = ANY(o.user_ids)
+        AND u.team_id = $4
+  ))
+ORDER BY o.stream_seq
+LIMIT $5
+`
+
+type GetStreamEventsAfterParams struct {
+	AfterSeq  int64
+	PrID      sql.NullString
+	UserID    sql.NullString
+	TeamID    sql.NullString
+	BatchSize int32
+}
+
+type GetStreamEventsAfterRow struct {
+	Seq       int64
+	EventType string
+	Payload   json.RawMessage
+}
+
+func (q *Queries) GetStreamEventsAfter(ctx context.Context, arg GetStreamEventsAfterParams) ([]GetStreamEventsAfterRow, error) {
+	rows, err := q.db.QueryContext(ctx, getStreamEventsAfter,
+		arg.AfterSeq,
+		arg.PrID,
+		arg.UserID,
+		arg.TeamID,
+		arg.BatchSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetStreamEventsAfterRow
+	for rows.Next() {
+		var i GetStreamEventsAfterRow
+		if err := rows.Scan(&i.Seq, &i.EventType, &i.Payload); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertOutboxEvent = `-- name: InsertOutboxEvent :exec
+INSERT INTO outbox (event_id, event_type, pr_id, user_ids, payload)
+VALUES ($1, $2, $3, $4, $5)
+`
+
+type InsertOutboxEventParams struct {
+	EventID   string
+	EventType string
+	PrID      string
+	UserIds   []string
+	Payload   json.RawMessage
+}
+
+func (q *Queries) InsertOutboxEvent(ctx context.Context, arg InsertOutboxEventParams) error {
+	_, err := q.db.ExecContext(ctx, insertOutboxEvent,
+		arg.EventID,
+		arg.EventType,
+		arg.PrID,
+		pq.Array(arg.UserIds),
+		arg.Payload,
+	)
+	return err
+}
+
+const lockOutboxSequencer = `-- name: LockOutboxSequencer :exec
+SELECT pg_advisory_xact_lock(hashtext('outbox_stream_seq'))
+`
+
+func (q *Queries) LockOutboxSequencer(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, lockOutboxSequencer)
+	return err
+}
+
+const markOutboxAttemptFailed = `-- name: MarkOutboxAttemptFailed :exec
+UPDATE outbox
+SET status = $1,
+    attempts = attempts + 1,
+    last_error = $2,
+    next_attempt_at = $3
+WHERE id = $4
+`
+
+type MarkOutboxAttemptFailedParams struct {
+	Status        string
+	LastError     sql.NullString
+	NextAttemptAt time.Time
+	ID            int64
+}
+
+func (q *Queries) MarkOutboxAttemptFailed(ctx context.Context, arg MarkOutboxAttemptFailedParams) error {
+	_, err := q.db.ExecContext(ctx, markOutboxAttemptFailed,
+		arg.Status,
+		arg.LastError,
+		arg.NextAttemptAt,
+		arg.ID,
+	)
+	return err
+}
+
+const markOutboxDispatched = `-- name: MarkOutboxDispatched :exec
+UPDATE outbox
+SET status = 'DISPATCHED',
+    attempts = attempts + 1,
+    last_error = NULL,
+    dispatched_at = NOW()
+WHERE id = $1
+`
+
+func (q *Queries) MarkOutboxDispatched(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, markOutboxDispatched, id)
+	return err
+}
+
+const sequenceOutboxEvents = `-- name: SequenceOutboxEvents :execrows
+WITH base AS (
+    SELECT COALESCE(MAX(stream_seq), 0)::bigint AS seq
+    FROM outbox
+),
+pending AS (
+    SELECT id, ROW_NUMBER() OVER (ORDER BY id) AS rn
+    FROM outbox
+    WHERE stream_seq IS NULL
+)
+UPDATE outbox o
+SET stream_seq = base.seq + pending.rn
+FROM base, pending
+WHERE o.id = pending.id
+`
+
+func (q *Queries) SequenceOutboxEvents(ctx context.Context) (int64, error) {
+	result, err := q.db.ExecContext(ctx, sequenceOutboxEvents)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
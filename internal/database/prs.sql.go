@@ -7,6 +7,10 @@ package database
 
 import (
 	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
 )
 
 const addReviewer = `-- name: AddReviewer :exec
@@ -46,19 +50,76 @@ func (q *Queries) CheckDuplicatePR(ctx context.Context, arg CheckDuplicatePRPara
 	return id, err
 }
 
+const closePR = `-- name: ClosePR :exec
+UPDATE prs
+SET status = 'CLOSED',
+    closed_at = NOW()
+WHERE id = $1
+`
+
+func (q *Queries) ClosePR(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, closePR, id)
+	return err
+}
+
+const countOpenReviews = `-- name: CountOpenReviews :many
+SELECT r.reviewer_id, COUNT(*) AS open_reviews
+FROM pr_reviewers r
+JOIN prs ON prs.id = r.pr_id
+WHERE prs.status = 'OPEN'
+  AND r.reviewer_id = ANY($1::text[])
+GROUP BY r.reviewer_id
+`
+
+type CountOpenReviewsRow struct {
+	ReviewerID  string
+	OpenReviews int64
+}
+
+func (q *Queries) CountOpenReviews(ctx context.Context, reviewerIds []string) ([]CountOpenReviewsRow, error) {
+	rows, err := q.db.QueryContext(ctx, countOpenReviews, pq.Array(reviewerIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountOpenReviewsRow
+	for rows.Next() {
+		var i CountOpenReviewsRow
+		if err := rows.Scan(&i.ReviewerID, &i.OpenReviews); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const createPR = `-- name: CreatePR :exec
-INSERT INTO prs (id, title, author_id, status)
-VALUES ($1, $2, $3, 'OPEN')
+INSERT INTO prs (id, title, author_id, status, reviewers_required, is_draft)
+VALUES ($1, $2, $3, 'OPEN', $4, $5)
 `
 
 type CreatePRParams struct {
-	ID       string
-	Title    string
-	AuthorID string
+	ID                string
+	Title             string
+	AuthorID          string
+	ReviewersRequired int32
+	IsDraft           bool
 }
 
 func (q *Queries) CreatePR(ctx context.Context, arg CreatePRParams) error {
-	_, err := q.db.ExecContext(ctx, createPR, arg.ID, arg.Title, arg.AuthorID)
+	_, err := q.db.ExecContext(ctx, createPR,
+		arg.ID,
+		arg.Title,
+		arg.AuthorID,
+		arg.ReviewersRequired,
+		arg.IsDraft,
+	)
 	return err
 }
 
@@ -77,21 +138,24 @@ func (q *Queries) DeleteReviewer(ctx context.Context, arg DeleteReviewerParams)
 	return err
 }
 
-const getActiveTeamMembersExceptAuthor = `-- name: GetActiveTeamMembersExceptAuthor :many
-SELECT id
-FROM users
-WHERE team_id = $1
-  AND is_active = TRUE
-  AND id <> $2
+const getAvailableTeamMembersExceptAuthor = `-- name: GetAvailableTeamMembersExceptAuthor :many
+SELECT u.id
+FROM users u
+JOIN teams t ON t.id = u.team_id
+JOIN user_workload w ON w.user_id = u.id
+WHERE u.team_id = $1
+  AND u.is_available = TRUE
+  AND u.id <> $2
+  AND w.open_reviews < COALESCE(u.max_open_reviews, t.max_open_reviews)
 `
 
-type GetActiveTeamMembersExceptAuthorParams struct {
+type GetAvailableTeamMembersExceptAuthorParams struct {
 	TeamID string
 	ID     string
 }
 
-func (q *Queries) GetActiveTeamMembersExceptAuthor(ctx context.Context, arg GetActiveTeamMembersExceptAuthorParams) ([]string, error) {
-	rows, err := q.db.QueryContext(ctx, getActiveTeamMembersExceptAuthor, arg.TeamID, arg.ID)
+func (q *Queries) GetAvailableTeamMembersExceptAuthor(ctx context.Context, arg GetAvailableTeamMembersExceptAuthorParams) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, getAvailableTeamMembersExceptAuthor, arg.TeamID, arg.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -113,8 +177,85 @@ func (q *Queries) GetActiveTeamMembersExceptAuthor(ctx context.Context, arg GetA
 	return items, nil
 }
 
+const getIneligibleReviewersByPR = `-- name: GetIneligibleReviewersByPR :many
+SELECT u.id, u.team_id
+FROM pr_reviewers r
+JOIN users u ON u.id = r.reviewer_id
+JOIN teams t ON t.id = u.team_id
+JOIN user_workload w ON w.user_id = u.id
+WHERE r.pr_id = $1
+  AND (u.is_available = FALSE OR w.open_reviews > COALESCE(u.max_open_reviews, t.max_open_reviews))
+ORDER BY u.id
+`
+
+type GetIneligibleReviewersByPRRow struct {
+	ID     string
+	TeamID string
+}
+
+func (q *Queries) GetIneligibleReviewersByPR(ctx context.Context, prID string) ([]GetIneligibleReviewersByPRRow, error) {
+	rows, err := q.db.QueryContext(ctx, getIneligibleReviewersByPR, prID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetIneligibleReviewersByPRRow
+	for rows.Next() {
+		var i GetIneligibleReviewersByPRRow
+		if err := rows.Scan(&i.ID, &i.TeamID); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getOpenReviewsByReviewers = `-- name: GetOpenReviewsByReviewers :many
+SELECT r.pr_id, r.reviewer_id, prs.author_id
+FROM pr_reviewers r
+JOIN prs ON prs.id = r.pr_id
+WHERE prs.status = 'OPEN'
+  AND r.reviewer_id = ANY($1::text[])
+ORDER BY r.pr_id, r.reviewer_id
+`
+
+type GetOpenReviewsByReviewersRow struct {
+	PrID       string
+	ReviewerID string
+	AuthorID   string
+}
+
+func (q *Queries) GetOpenReviewsByReviewers(ctx context.Context, reviewerIds []string) ([]GetOpenReviewsByReviewersRow, error) {
+	rows, err := q.db.QueryContext(ctx, getOpenReviewsByReviewers, pq.Array(reviewerIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetOpenReviewsByReviewersRow
+	for rows.Next() {
+		var i GetOpenReviewsByReviewersRow
+		if err := rows.Scan(&i.PrID, &i.ReviewerID, &i.AuthorID); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getPRById = `-- name: GetPRById :one
-SELECT id, title, author_id, status, created_at, merged_at
+SELECT id, title, author_id, status, created_at, merged_at, reviewers_required, force_merged, force_merged_by, closed_at, is_draft
 FROM prs
 WHERE id = $1
 `
@@ -129,10 +270,57 @@ func (q *Queries) GetPRById(ctx context.Context, id string) (Pr, error) {
 		&i.Status,
 		&i.CreatedAt,
 		&i.MergedAt,
+		&i.ReviewersRequired,
+		&i.ForceMerged,
+		&i.ForceMergedBy,
+		&i.ClosedAt,
+		&i.IsDraft,
 	)
 	return i, err
 }
 
+const getPRsByIDs = `-- name: GetPRsByIDs :many
+SELECT id, title, author_id, status, created_at, merged_at, reviewers_required, force_merged, force_merged_by, closed_at, is_draft
+FROM prs
+WHERE id = ANY($1::text[])
+ORDER BY id
+`
+
+func (q *Queries) GetPRsByIDs(ctx context.Context, ids []string) ([]Pr, error) {
+	rows, err := q.db.QueryContext(ctx, getPRsByIDs, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Pr
+	for rows.Next() {
+		var i Pr
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.AuthorID,
+			&i.Status,
+			&i.CreatedAt,
+			&i.MergedAt,
+			&i.ReviewersRequired,
+			&i.ForceMerged,
+			&i.ForceMergedBy,
+			&i.ClosedAt,
+			&i.IsDraft,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getReviewersByPR = `-- name: GetReviewersByPR :many
 SELECT reviewer_id
 FROM pr_reviewers
@@ -162,6 +350,129 @@ func (q *Queries) GetReviewersByPR(ctx context.Context, prID string) ([]string,
 	return items, nil
 }
 
+const getReviewersByPRs = `-- name: GetReviewersByPRs :many
+SELECT pr_id, reviewer_id
+FROM pr_reviewers
+WHERE pr_id = ANY($1::text[])
+ORDER BY pr_id, reviewer_id
+`
+
+type GetReviewersByPRsRow struct {
+	PrID       string
+	ReviewerID string
+}
+
+func (q *Queries) GetReviewersByPRs(ctx context.Context, prIds []string) ([]GetReviewersByPRsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getReviewersByPRs, pq.Array(prIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetReviewersByPRsRow
+	for rows.Next() {
+		var i GetReviewersByPRsRow
+		if err := rows.Scan(&i.PrID, &i.ReviewerID); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getReviewsByPR = `-- name: GetReviewsByPR :many
+SELECT reviewer_id, state, assigned_at, reviewed_at
+FROM pr_reviewers
+WHERE pr_id = $1
+ORDER BY assigned_at, reviewer_id
+`
+
+type GetReviewsByPRRow struct {
+	ReviewerID string
+	State      string
+	AssignedAt time.Time
+	ReviewedAt sql.NullTime
+}
+
+func (q *Queries) GetReviewsByPR(ctx context.Context, prID string) ([]GetReviewsByPRRow, error) {
+	rows, err := q.db.QueryContext(ctx, getReviewsByPR, prID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetReviewsByPRRow
+	for rows.Next() {
+		var i GetReviewsByPRRow
+		if err := rows.Scan(
+			&i.ReviewerID,
+			&i.State,
+			&i.AssignedAt,
+			&i.ReviewedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTeamCandidates = `-- name: GetTeamCandidates :many
+SELECT u.id, u.review_weight, w.open_reviews,
+       COALESCE(u.max_open_reviews, t.max_open_reviews)::bigint AS capacity
+FROM users u
+JOIN teams t ON t.id = u.team_id
+JOIN user_workload w ON w.user_id = u.id
+WHERE u.team_id = $1
+  AND u.is_available = TRUE
+ORDER BY u.id
+`
+
+type GetTeamCandidatesRow struct {
+	ID           string
+	ReviewWeight int32
+	OpenReviews  int64
+	Capacity     int64
+}
+
+func (q *Queries) GetTeamCandidates(ctx context.Context, teamID string) ([]GetTeamCandidatesRow, error) {
+	rows, err := q.db.QueryContext(ctx, getTeamCandidates, teamID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetTeamCandidatesRow
+	for rows.Next() {
+		var i GetTeamCandidatesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ReviewWeight,
+			&i.OpenReviews,
+			&i.Capacity,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const isReviewerAssigned = `-- name: IsReviewerAssigned :one
 SELECT COUNT(*) > 0 AS assigned
 FROM pr_reviewers
@@ -180,14 +491,66 @@ func (q *Queries) IsReviewerAssigned(ctx context.Context, arg IsReviewerAssigned
 	return assigned, err
 }
 
+const markPRReady = `-- name: MarkPRReady :exec
+UPDATE prs
+SET is_draft = FALSE
+WHERE id = $1
+`
+
+func (q *Queries) MarkPRReady(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, markPRReady, id)
+	return err
+}
+
 const mergePR = `-- name: MergePR :exec
 UPDATE prs
 SET status = 'MERGED',
-    merged_at = NOW()
+    merged_at = NOW(),
+    force_merged = $2,
+    force_merged_by = $3
+WHERE id = $1
+`
+
+type MergePRParams struct {
+	ID            string
+	ForceMerged   bool
+	ForceMergedBy sql.NullString
+}
+
+func (q *Queries) MergePR(ctx context.Context, arg MergePRParams) error {
+	_, err := q.db.ExecContext(ctx, mergePR, arg.ID, arg.ForceMerged, arg.ForceMergedBy)
+	return err
+}
+
+const reopenPR = `-- name: ReopenPR :exec
+UPDATE prs
+SET status = 'OPEN',
+    closed_at = NULL
 WHERE id = $1
 `
 
-func (q *Queries) MergePR(ctx context.Context, id string) error {
-	_, err := q.db.ExecContext(ctx, mergePR, id)
+func (q *Queries) ReopenPR(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, reopenPR, id)
 	return err
 }
+
+const setReviewState = `-- name: SetReviewState :execrows
+UPDATE pr_reviewers
+SET state = $3,
+    reviewed_at = NOW()
+WHERE pr_id = $1 AND reviewer_id = $2
+`
+
+type SetReviewStateParams struct {
+	PrID       string
+	ReviewerID string
+	State      string
+}
+
+func (q *Queries) SetReviewState(ctx context.Context, arg SetReviewStateParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, setReviewState, arg.PrID, arg.ReviewerID, arg.State)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
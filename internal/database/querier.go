@@ -0,0 +1,87 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+
+package database
+
+import (
+	"context"
+	"database/sql"
+)
+
+type Querier interface {
+	AddReviewer(ctx context.Context, arg AddReviewerParams) error
+	AddTeamFallback(ctx context.Context, arg AddTeamFallbackParams) error
+	CheckDuplicatePR(ctx context.Context, arg CheckDuplicatePRParams) (string, error)
+	ClaimDueWebhookDeliveries(ctx context.Context, arg ClaimDueWebhookDeliveriesParams) ([]ClaimDueWebhookDeliveriesRow, error)
+	ClaimNextOutboxEvent(ctx context.Context) (Outbox, error)
+	ClosePR(ctx context.Context, id string) error
+	CountOpenReviews(ctx context.Context, reviewerIds []string) ([]CountOpenReviewsRow, error)
+	CreateAPIToken(ctx context.Context, arg CreateAPITokenParams) error
+	CreatePR(ctx context.Context, arg CreatePRParams) error
+	CreateTeam(ctx context.Context, arg CreateTeamParams) error
+	CreateWebhookDelivery(ctx context.Context, arg CreateWebhookDeliveryParams) error
+	CreateWebhookSubscription(ctx context.Context, arg CreateWebhookSubscriptionParams) error
+	DeleteReviewer(ctx context.Context, arg DeleteReviewerParams) error
+	DeleteTeamFallbacks(ctx context.Context, teamID string) error
+	DeleteWebhookSubscription(ctx context.Context, id string) (int64, error)
+	FindAPITokenByHash(ctx context.Context, tokenHash string) (ApiToken, error)
+	GetAPITokenByHash(ctx context.Context, tokenHash string) (ApiToken, error)
+	GetAPITokenByID(ctx context.Context, id string) (ApiToken, error)
+	GetAvailableTeamMembersExceptAuthor(ctx context.Context, arg GetAvailableTeamMembersExceptAuthorParams) ([]string, error)
+	GetIneligibleReviewersByPR(ctx context.Context, prID string) ([]GetIneligibleReviewersByPRRow, error)
+	GetLatestStreamSeq(ctx context.Context) (int64, error)
+	GetOpenReviewsByReviewers(ctx context.Context, reviewerIds []string) ([]GetOpenReviewsByReviewersRow, error)
+	GetPRById(ctx context.Context, id string) (Pr, error)
+	GetPRsByIDs(ctx context.Context, ids []string) ([]Pr, error)
+	GetReviewPRs(ctx context.Context, reviewerID string) ([]GetReviewPRsRow, error)
+	GetReviewersByPR(ctx context.Context, prID string) ([]string, error)
+	GetReviewersByPRs(ctx context.Context, prIds []string) ([]GetReviewersByPRsRow, error)
+	GetReviewsByPR(ctx context.Context, prID string) ([]GetReviewsByPRRow, error)
+	GetStreamEventsAfter(ctx context.Context, arg GetStreamEventsAfterParams) ([]GetStreamEventsAfterRow, error)
+	GetSubscriptionsForEvent(ctx context.Context, eventType string) ([]WebhookSubscription, error)
+	GetTeamByID(ctx context.Context, id string) (Team, error)
+	GetTeamByName(ctx context.Context, teamname string) (Team, error)
+	GetTeamCandidates(ctx context.Context, teamID string) ([]GetTeamCandidatesRow, error)
+	GetTeamFallbacks(ctx context.Context, teamID string) ([]Team, error)
+	GetTeamNameByID(ctx context.Context, id string) (string, error)
+	GetUserByGithubLogin(ctx context.Context, githubLogin sql.NullString) (User, error)
+	GetUserByGitlabUsername(ctx context.Context, gitlabUsername sql.NullString) (User, error)
+	GetUserById(ctx context.Context, id string) (User, error)
+	GetUserOpenReviews(ctx context.Context, userID string) (int64, error)
+	GetUserWeights(ctx context.Context, ids []string) ([]GetUserWeightsRow, error)
+	GetUsersByTeam(ctx context.Context, teamID string) ([]GetUsersByTeamRow, error)
+	GetWebhookDeliveryByID(ctx context.Context, id int64) (WebhookDelivery, error)
+	GetWebhookSubscriptionByID(ctx context.Context, id string) (WebhookSubscription, error)
+	InsertAuditEntry(ctx context.Context, arg InsertAuditEntryParams) error
+	InsertOutboxEvent(ctx context.Context, arg InsertOutboxEventParams) error
+	IsReviewerAssigned(ctx context.Context, arg IsReviewerAssignedParams) (bool, error)
+	ListAPITokens(ctx context.Context) ([]ApiToken, error)
+	ListAuditEntries(ctx context.Context, arg ListAuditEntriesParams) ([]AuditLog, error)
+	ListWebhookDeliveries(ctx context.Context, arg ListWebhookDeliveriesParams) ([]ListWebhookDeliveriesRow, error)
+	ListWebhookSubscriptions(ctx context.Context) ([]WebhookSubscription, error)
+	LockOutboxSequencer(ctx context.Context) error
+	MarkOutboxAttemptFailed(ctx context.Context, arg MarkOutboxAttemptFailedParams) error
+	MarkOutboxDispatched(ctx context.Context, id int64) error
+	MarkPRReady(ctx context.Context, id string) error
+	MarkWebhookAttemptFailed(ctx context.Context, arg MarkWebhookAttemptFailedParams) error
+	MarkWebhookDelivered(ctx context.Context, arg MarkWebhookDeliveredParams) error
+	MergePR(ctx context.Context, arg MergePRParams) error
+	ReopenPR(ctx context.Context, id string) error
+	RequeueWebhookDelivery(ctx context.Context, id int64) (int64, error)
+	RevokeAPIToken(ctx context.Context, id string) (int64, error)
+	SequenceOutboxEvents(ctx context.Context) (int64, error)
+	SetReviewState(ctx context.Context, arg SetReviewStateParams) (int64, error)
+	SetTeamApprovalsRequired(ctx context.Context, arg SetTeamApprovalsRequiredParams) error
+	SetTeamMaxOpenReviews(ctx context.Context, arg SetTeamMaxOpenReviewsParams) error
+	SetTeamReviewerStrategy(ctx context.Context, arg SetTeamReviewerStrategyParams) error
+	SetTeamReviewersRequired(ctx context.Context, arg SetTeamReviewersRequiredParams) error
+	SetTeamRoundRobinCursor(ctx context.Context, arg SetTeamRoundRobinCursorParams) error
+	SetUserAvailability(ctx context.Context, arg SetUserAvailabilityParams) error
+	SetUserMaxOpenReviews(ctx context.Context, arg SetUserMaxOpenReviewsParams) error
+	SetUserRole(ctx context.Context, arg SetUserRoleParams) error
+	SetUsersAvailability(ctx context.Context, arg SetUsersAvailabilityParams) error
+	UpsertUser(ctx context.Context, arg UpsertUserParams) error
+}
+
+var _ Querier = (*Queries)(nil)
@@ -0,0 +1,61 @@
+package sqlite
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/LlirikP/pr_dispenser/internal/database"
+	"github.com/LlirikP/pr_dispenser/internal/database/sqlite/sqlitedb"
+)
+
+func (q *Queries) InsertAuditEntry(ctx context.Context, arg database.InsertAuditEntryParams) error {
+	return q.q.InsertAuditEntry(ctx, sqlitedb.InsertAuditEntryParams{
+		Actor:      arg.Actor,
+		Action:     arg.Action,
+		TargetType: arg.TargetType,
+		TargetID:   arg.TargetID,
+		Before:     jsonText(arg.Before),
+		After:      jsonText(arg.After),
+		RequestID:  arg.RequestID,
+	})
+}
+
+func (q *Queries) ListAuditEntries(ctx context.Context, arg database.ListAuditEntriesParams) ([]database.AuditLog, error) {
+	rows, err := q.q.ListAuditEntries(ctx, sqlitedb.ListAuditEntriesParams{
+		Actor:      arg.Actor,
+		Action:     arg.Action,
+		TargetType: arg.TargetType,
+		TargetID:   arg.TargetID,
+		RequestID:  arg.RequestID,
+		Since:      nullTimeString(arg.Since),
+		Until:      nullTimeString(arg.Until),
+		BeforeID:   arg.BeforeID,
+		MaxRows:    int64(arg.MaxRows),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]database.AuditLog, 0, len(rows))
+	for _, row := range rows {
+		entries = append(entries, database.AuditLog{
+			ID:         row.ID,
+			Actor:      row.Actor,
+			Action:     row.Action,
+			TargetType: row.TargetType,
+			TargetID:   row.TargetID,
+			Before:     json.RawMessage(row.Before),
+			After:      json.RawMessage(row.After),
+			RequestID:  row.RequestID,
+			CreatedAt:  row.CreatedAt,
+		})
+	}
+	return entries, nil
+}
+
+func jsonText(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return "null"
+	}
+	return string(raw)
+}
@@ -0,0 +1,100 @@
+package sqlite
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/LlirikP/pr_dispenser/internal/database"
+	"github.com/LlirikP/pr_dispenser/internal/database/sqlite/sqlitedb"
+)
+
+func (q *Queries) InsertOutboxEvent(ctx context.Context, arg database.InsertOutboxEventParams) error {
+	userIDs, err := encodeList(arg.UserIds)
+	if err != nil {
+		return err
+	}
+
+	return q.q.InsertOutboxEvent(ctx, sqlitedb.InsertOutboxEventParams{
+		EventID:   arg.EventID,
+		EventType: arg.EventType,
+		PrID:      arg.PrID,
+		UserIds:   userIDs,
+		Payload:   string(arg.Payload),
+	})
+}
+
+func (q *Queries) ClaimNextOutboxEvent(ctx context.Context) (database.Outbox, error) {
+	entry, err := q.q.ClaimNextOutboxEvent(ctx)
+	if err != nil {
+		return database.Outbox{}, err
+	}
+
+	userIDs, err := decodeList(entry.UserIds)
+	if err != nil {
+		return database.Outbox{}, err
+	}
+
+	return database.Outbox{
+		ID:            entry.ID,
+		EventID:       entry.EventID,
+		EventType:     entry.EventType,
+		PrID:          entry.PrID,
+		Payload:       json.RawMessage(entry.Payload),
+		Status:        entry.Status,
+		Attempts:      int32(entry.Attempts),
+		LastError:     entry.LastError,
+		NextAttemptAt: entry.NextAttemptAt,
+		CreatedAt:     entry.CreatedAt,
+		DispatchedAt:  entry.DispatchedAt,
+		UserIds:       userIDs,
+		StreamSeq:     entry.StreamSeq,
+	}, nil
+}
+
+func (q *Queries) MarkOutboxDispatched(ctx context.Context, id int64) error {
+	return q.q.MarkOutboxDispatched(ctx, id)
+}
+
+func (q *Queries) MarkOutboxAttemptFailed(ctx context.Context, arg database.MarkOutboxAttemptFailedParams) error {
+	return q.q.MarkOutboxAttemptFailed(ctx, sqlitedb.MarkOutboxAttemptFailedParams{
+		ID:            arg.ID,
+		Status:        arg.Status,
+		LastError:     arg.LastError,
+		NextAttemptAt: utc(arg.NextAttemptAt),
+	})
+}
+
+func (q *Queries) LockOutboxSequencer(ctx context.Context) error {
+	return nil
+}
+
+func (q *Queries) SequenceOutboxEvents(ctx context.Context) (int64, error) {
+	return q.q.SequenceOutboxEvents(ctx)
+}
+
+func (q *Queries) GetLatestStreamSeq(ctx context.Context) (int64, error) {
+	return q.q.GetLatestStreamSeq(ctx)
+}
+
+func (q *Queries) GetStreamEventsAfter(ctx context.Context, arg database.GetStreamEventsAfterParams) ([]database.GetStreamEventsAfterRow, error) {
+	rows, err := q.q.GetStreamEventsAfter(ctx, sqlitedb.GetStreamEventsAfterParams{
+		AfterSeq:  arg.AfterSeq,
+		PrID:      arg.PrID,
+		UserID:    arg.UserID,
+		TeamID:    arg.TeamID,
+		BatchSize: int64(arg.BatchSize),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]database.GetStreamEventsAfterRow, 0, len(rows))
+	for _, row := range rows {
+		events = append(events, database.GetStreamEventsAfterRow{
+			Seq:       row.Seq,
+			EventType: row.EventType,
+			Payload:   json.RawMessage(row.Payload),
+		})
+	}
+	return events, nil
+}
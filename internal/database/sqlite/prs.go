@@ -0,0 +1,223 @@
+package sqlite
+
+import (
+	"context"
+
+	"github.com/LlirikP/pr_dispenser/internal/database"
+	"github.com/LlirikP/pr_dispenser/internal/database/sqlite/sqlitedb"
+)
+
+func (q *Queries) CreatePR(ctx context.Context, arg database.CreatePRParams) error {
+	return q.q.CreatePR(ctx, sqlitedb.CreatePRParams{
+		ID:                arg.ID,
+		Title:             arg.Title,
+		AuthorID:          arg.AuthorID,
+		ReviewersRequired: int64(arg.ReviewersRequired),
+		IsDraft:           arg.IsDraft,
+	})
+}
+
+func (q *Queries) GetPRById(ctx context.Context, id string) (database.Pr, error) {
+	pr, err := q.q.GetPRById(ctx, id)
+	if err != nil {
+		return database.Pr{}, err
+	}
+	return toPR(pr), nil
+}
+
+func (q *Queries) GetPRsByIDs(ctx context.Context, ids []string) ([]database.Pr, error) {
+	rows, err := q.q.GetPRsByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	prs := make([]database.Pr, 0, len(rows))
+	for _, row := range rows {
+		prs = append(prs, toPR(row))
+	}
+	return prs, nil
+}
+
+func toPR(pr sqlitedb.Pr) database.Pr {
+	return database.Pr{
+		ID:                pr.ID,
+		Title:             pr.Title,
+		AuthorID:          pr.AuthorID,
+		Status:            pr.Status,
+		CreatedAt:         pr.CreatedAt,
+		MergedAt:          pr.MergedAt,
+		ReviewersRequired: int32(pr.ReviewersRequired),
+		ForceMerged:       pr.ForceMerged,
+		ForceMergedBy:     pr.ForceMergedBy,
+		ClosedAt:          pr.ClosedAt,
+		IsDraft:           pr.IsDraft,
+	}
+}
+
+func (q *Queries) AddReviewer(ctx context.Context, arg database.AddReviewerParams) error {
+	return q.q.AddReviewer(ctx, sqlitedb.AddReviewerParams{
+		PrID:       arg.PrID,
+		ReviewerID: arg.ReviewerID,
+	})
+}
+
+func (q *Queries) DeleteReviewer(ctx context.Context, arg database.DeleteReviewerParams) error {
+	return q.q.DeleteReviewer(ctx, sqlitedb.DeleteReviewerParams{
+		PrID:       arg.PrID,
+		ReviewerID: arg.ReviewerID,
+	})
+}
+
+func (q *Queries) GetReviewersByPR(ctx context.Context, prID string) ([]string, error) {
+	return q.q.GetReviewersByPR(ctx, prID)
+}
+
+func (q *Queries) GetAvailableTeamMembersExceptAuthor(ctx context.Context, arg database.GetAvailableTeamMembersExceptAuthorParams) ([]string, error) {
+	return q.q.GetAvailableTeamMembersExceptAuthor(ctx, sqlitedb.GetAvailableTeamMembersExceptAuthorParams{
+		TeamID:   arg.TeamID,
+		AuthorID: arg.ID,
+	})
+}
+
+func (q *Queries) GetTeamCandidates(ctx context.Context, teamID string) ([]database.GetTeamCandidatesRow, error) {
+	rows, err := q.q.GetTeamCandidates(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]database.GetTeamCandidatesRow, 0, len(rows))
+	for _, row := range rows {
+		candidates = append(candidates, database.GetTeamCandidatesRow{
+			ID:           row.ID,
+			ReviewWeight: int32(row.ReviewWeight),
+			OpenReviews:  row.OpenReviews,
+			Capacity:     row.Capacity,
+		})
+	}
+	return candidates, nil
+}
+
+func (q *Queries) GetReviewersByPRs(ctx context.Context, prIds []string) ([]database.GetReviewersByPRsRow, error) {
+	rows, err := q.q.GetReviewersByPRs(ctx, prIds)
+	if err != nil {
+		return nil, err
+	}
+
+	reviewers := make([]database.GetReviewersByPRsRow, 0, len(rows))
+	for _, row := range rows {
+		reviewers = append(reviewers, database.GetReviewersByPRsRow{
+			PrID:       row.PrID,
+			ReviewerID: row.ReviewerID,
+		})
+	}
+	return reviewers, nil
+}
+
+func (q *Queries) IsReviewerAssigned(ctx context.Context, arg database.IsReviewerAssignedParams) (bool, error) {
+	return q.q.IsReviewerAssigned(ctx, sqlitedb.IsReviewerAssignedParams{
+		PrID:       arg.PrID,
+		ReviewerID: arg.ReviewerID,
+	})
+}
+
+func (q *Queries) MergePR(ctx context.Context, arg database.MergePRParams) error {
+	return q.q.MergePR(ctx, sqlitedb.MergePRParams{
+		ID:            arg.ID,
+		ForceMerged:   arg.ForceMerged,
+		ForceMergedBy: arg.ForceMergedBy,
+	})
+}
+
+func (q *Queries) ClosePR(ctx context.Context, id string) error {
+	return q.q.ClosePR(ctx, id)
+}
+
+func (q *Queries) ReopenPR(ctx context.Context, id string) error {
+	return q.q.ReopenPR(ctx, id)
+}
+
+func (q *Queries) MarkPRReady(ctx context.Context, id string) error {
+	return q.q.MarkPRReady(ctx, id)
+}
+
+func (q *Queries) CheckDuplicatePR(ctx context.Context, arg database.CheckDuplicatePRParams) (string, error) {
+	return q.q.CheckDuplicatePR(ctx, sqlitedb.CheckDuplicatePRParams{
+		AuthorID: arg.AuthorID,
+		Title:    arg.Title,
+	})
+}
+
+func (q *Queries) CountOpenReviews(ctx context.Context, reviewerIds []string) ([]database.CountOpenReviewsRow, error) {
+	rows, err := q.q.CountOpenReviews(ctx, reviewerIds)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make([]database.CountOpenReviewsRow, 0, len(rows))
+	for _, row := range rows {
+		counts = append(counts, database.CountOpenReviewsRow{
+			ReviewerID:  row.ReviewerID,
+			OpenReviews: row.OpenReviews,
+		})
+	}
+	return counts, nil
+}
+
+func (q *Queries) GetOpenReviewsByReviewers(ctx context.Context, reviewerIds []string) ([]database.GetOpenReviewsByReviewersRow, error) {
+	rows, err := q.q.GetOpenReviewsByReviewers(ctx, reviewerIds)
+	if err != nil {
+		return nil, err
+	}
+
+	reviews := make([]database.GetOpenReviewsByReviewersRow, 0, len(rows))
+	for _, row := range rows {
+		reviews = append(reviews, database.GetOpenReviewsByReviewersRow{
+			PrID:       row.PrID,
+			ReviewerID: row.ReviewerID,
+			AuthorID:   row.AuthorID,
+		})
+	}
+	return reviews, nil
+}
+
+func (q *Queries) GetReviewsByPR(ctx context.Context, prID string) ([]database.GetReviewsByPRRow, error) {
+	rows, err := q.q.GetReviewsByPR(ctx, prID)
+	if err != nil {
+		return nil, err
+	}
+
+	reviews := make([]database.GetReviewsByPRRow, 0, len(rows))
+	for _, row := range rows {
+		reviews = append(reviews, database.GetReviewsByPRRow{
+			ReviewerID: row.ReviewerID,
+			State:      row.State,
+			AssignedAt: row.AssignedAt,
+			ReviewedAt: row.ReviewedAt,
+		})
+	}
+	return reviews, nil
+}
+
+func (q *Queries) SetReviewState(ctx context.Context, arg database.SetReviewStateParams) (int64, error) {
+	return q.q.SetReviewState(ctx, sqlitedb.SetReviewStateParams{
+		PrID:       arg.PrID,
+		ReviewerID: arg.ReviewerID,
+		State:      arg.State,
+	})
+}
+
+func (q *Queries) GetIneligibleReviewersByPR(ctx context.Context, prID string) ([]database.GetIneligibleReviewersByPRRow, error) {
+	rows, err := q.q.GetIneligibleReviewersByPR(ctx, prID)
+	if err != nil {
+		return nil, err
+	}
+
+	reviewers := make([]database.GetIneligibleReviewersByPRRow, 0, len(rows))
+	for _, row := range rows {
+		reviewers = append(reviewers, database.GetIneligibleReviewersByPRRow{
+			ID:     row.ID,
+			TeamID: row.TeamID,
+		})
+	}
+	return reviewers, nil
+}
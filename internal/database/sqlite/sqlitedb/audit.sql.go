@@ -0,0 +1,109 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: audit.sql
+
+package sqlitedb
+
+import (
+	"context"
+	"database/sql"
+)
+
+const insertAuditEntry = `-- name: InsertAuditEntry :exec
+INSERT INTO audit_log (actor, action, target_type, target_id, before, after, request_id)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+`
+
+type InsertAuditEntryParams struct {
+	Actor      string
+	Action     string
+	TargetType string
+	TargetID   string
+	Before     string
+	After      string
+	RequestID  string
+}
+
+func (q *Queries) InsertAuditEntry(ctx context.Context, arg InsertAuditEntryParams) error {
+	_, err := q.db.ExecContext(ctx, insertAuditEntry,
+		arg.Actor,
+		arg.Action,
+		arg.TargetType,
+		arg.TargetID,
+		arg.Before,
+		arg.After,
+		arg.RequestID,
+	)
+	return err
+}
+
+const listAuditEntries = `-- name: ListAuditEntries :many
+SELECT id, actor, action, target_type, target_id, before, after, request_id, created_at
+FROM audit_log
+WHERE (CAST(?1 AS TEXT) IS NULL OR actor = CAST(?1 AS TEXT))
+  AND (CAST(?2 AS TEXT) IS NULL OR action = CAST(?2 AS TEXT))
+  AND (CAST(?3 AS TEXT) IS NULL OR target_type = CAST(?3 AS TEXT))
+  AND (CAST(?4 AS TEXT) IS NULL OR target_id = CAST(?4 AS TEXT))
+  AND (CAST(?5 AS TEXT) IS NULL OR request_id = CAST(?5 AS TEXT))
+  AND (CAST(?6 AS TEXT) IS NULL OR created_at >= CAST(?6 AS TEXT))
+  AND (CAST(?7 AS TEXT) IS NULL OR created_at < CAST(?7 AS TEXT))
+  AND (CAST(?8 AS INTEGER) IS NULL OR id < CAST(?8 AS INTEGER))
+ORDER BY id DESC
+LIMIT ?9
+`
+
+type ListAuditEntriesParams struct {
+	Actor      sql.NullString
+	Action     sql.NullString
+	TargetType sql.NullString
+	TargetID   sql.NullString
+	RequestID  sql.NullString
+	Since      sql.NullString
+	Until      sql.NullString
+	BeforeID   sql.NullInt64
+	MaxRows    int64
+}
+
+func (q *Queries) ListAuditEntries(ctx context.Context, arg ListAuditEntriesParams) ([]AuditLog, error) {
+	rows, err := q.db.QueryContext(ctx, listAuditEntries,
+		arg.Actor,
+		arg.Action,
+		arg.TargetType,
+		arg.TargetID,
+		arg.RequestID,
+		arg.Since,
+		arg.Until,
+		arg.BeforeID,
+		arg.MaxRows,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AuditLog
+	for rows.Next() {
+		var i AuditLog
+		if err := rows.Scan(
+			&i.ID,
+			&i.Actor,
+			&i.Action,
+			&i.TargetType,
+			&i.TargetID,
+			&i.Before,
+			&i.After,
+			&i.RequestID,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,127 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+
+package sqlitedb
+
+import (
+	"database/sql"
+	"time"
+)
+
+type ApiToken struct {
+	ID        string
+	Name      string
+	TokenHash string
+	Scope     string
+	UserID    sql.NullString
+	CreatedAt time.Time
+	RevokedAt sql.NullTime
+}
+
+type AuditLog struct {
+	ID         int64
+	Actor      string
+	Action     string
+	TargetType string
+	TargetID   string
+	Before     string
+	After      string
+	RequestID  string
+	CreatedAt  time.Time
+}
+
+type Outbox struct {
+	ID            int64
+	EventID       string
+	EventType     string
+	PrID          string
+	UserIds       string
+	Payload       string
+	Status        string
+	Attempts      int64
+	LastError     sql.NullString
+	NextAttemptAt time.Time
+	CreatedAt     time.Time
+	DispatchedAt  sql.NullTime
+	StreamSeq     sql.NullInt64
+}
+
+type Pr struct {
+	ID                string
+	Title             string
+	AuthorID          string
+	Status            string
+	CreatedAt         time.Time
+	MergedAt          sql.NullTime
+	ReviewersRequired int64
+	ForceMerged       bool
+	ForceMergedBy     sql.NullString
+	ClosedAt          sql.NullTime
+	IsDraft           bool
+}
+
+type PrReviewer struct {
+	PrID       string
+	ReviewerID string
+	State      string
+	AssignedAt time.Time
+	ReviewedAt sql.NullTime
+}
+
+type Team struct {
+	ID                string
+	Teamname          string
+	ReviewerStrategy  string
+	RrLastReviewerID  sql.NullString
+	MaxOpenReviews    int64
+	ReviewersRequired int64
+	ApprovalsRequired int64
+}
+
+type TeamFallback struct {
+	TeamID         string
+	FallbackTeamID string
+	Priority       int64
+}
+
+type User struct {
+	ID             string
+	Username       string
+	IsAvailable    bool
+	TeamID         string
+	ReviewWeight   int64
+	MaxOpenReviews sql.NullInt64
+	GithubLogin    sql.NullString
+	GitlabUsername sql.NullString
+	Role           string
+}
+
+type UserWorkload struct {
+	UserID      string
+	OpenReviews int64
+}
+
+type WebhookDelivery struct {
+	ID             int64
+	SubscriptionID string
+	EventID        string
+	EventType      string
+	PrID           string
+	Payload        string
+	Status         string
+	Attempts       int64
+	ResponseStatus sql.NullInt64
+	LastError      sql.NullString
+	NextAttemptAt  time.Time
+	CreatedAt      time.Time
+	DeliveredAt    sql.NullTime
+}
+
+type WebhookSubscription struct {
+	ID        string
+	Url       string
+	Events    string
+	Secret    string
+	CreatedAt time.Time
+}
@@ -0,0 +1,198 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: outbox.sql
+
+package sqlitedb
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+const claimNextOutboxEvent = `-- name: ClaimNextOutboxEvent :one
+SELECT id, event_id, event_type, pr_id, user_ids, payload, status, attempts, last_error, next_attempt_at, created_at, dispatched_at, stream_seq
+FROM outbox o
+WHERE o.status = 'PENDING'
+  AND o.next_attempt_at <= strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')
+  AND NOT EXISTS (
+      SELECT 1
+      FROM outbox prev
+      WHERE prev.pr_id = o.pr_id
+        AND prev.status = 'PENDING'
+        AND prev.id < o.id
+  )
+ORDER BY o.id
+LIMIT 1
+`
+
+func (q *Queries) ClaimNextOutboxEvent(ctx context.Context) (Outbox, error) {
+	row := q.db.QueryRowContext(ctx, claimNextOutboxEvent)
+	var i Outbox
+	err := row.Scan(
+		&i.ID,
+		&i.EventID,
+		&i.EventType,
+		&i.PrID,
+		&i.UserIds,
+		&i.Payload,
+		&i.Status,
+		&i.Attempts,
+		&i.LastError,
+		&i.NextAttemptAt,
+		&i.CreatedAt,
+		&i.DispatchedAt,
+		&i.StreamSeq,
+	)
+	return i, err
+}
+
+const getLatestStreamSeq = `-- name: GetLatestStreamSeq :one
+SELECT CAST(COALESCE(MAX(stream_seq), 0) AS INTEGER)
+FROM outbox
+`
+
+func (q *Queries) GetLatestStreamSeq(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getLatestStreamSeq)
+	var column_1 int64
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const getStreamEventsAfter = `-- name: GetStreamEventsAfter :many
+SELECT CAST(o.stream_seq AS INTEGER) AS seq, o.event_type, o.payload
+FROM outbox o
+WHERE o.stream_seq > CAST(?1 AS INTEGER)
+  AND (CAST(?2 AS TEXT) IS NULL OR o.pr_id = CAST(?2 AS TEXT))
+  AND (CAST(?3 AS TEXT) IS NULL OR instr(o.user_ids, json_quote(CAST(?3 AS TEXT))) > 0)
+  AND (CAST(?4 AS TEXT) IS NULL OR EXISTS (
+      SELECT 1
+      FROM users u
+      WHERE instr(o.user_ids, json_quote(u.id)) > 0
+        AND u.team_id = CAST(?4 AS TEXT)
+  ))
+ORDER BY o.stream_seq
+LIMIT ?5
+`
+
+type GetStreamEventsAfterParams struct {
+	AfterSeq  int64
+	PrID      sql.NullString
+	UserID    sql.NullString
+	TeamID    sql.NullString
+	BatchSize int64
+}
+
+type GetStreamEventsAfterRow struct {
+	Seq       int64
+	EventType string
+	Payload   string
+}
+
+func (q *Queries) GetStreamEventsAfter(ctx context.Context, arg GetStreamEventsAfterParams) ([]GetStreamEventsAfterRow, error) {
+	rows, err := q.db.QueryContext(ctx, getStreamEventsAfter,
+		arg.AfterSeq,
+		arg.PrID,
+		arg.UserID,
+		arg.TeamID,
+		arg.BatchSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetStreamEventsAfterRow
+	for rows.Next() {
+		var i GetStreamEventsAfterRow
+		if err := rows.Scan(&i.Seq, &i.EventType, &i.Payload); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertOutboxEvent = `-- name: InsertOutboxEvent :exec
+INSERT INTO outbox (event_id, event_type, pr_id, user_ids, payload)
+VALUES (?, ?, ?, ?, ?)
+`
+
+type InsertOutboxEventParams struct {
+	EventID   string
+	EventType string
+	PrID      string
+	UserIds   string
+	Payload   string
+}
+
+func (q *Queries) InsertOutboxEvent(ctx context.Context, arg InsertOutboxEventParams) error {
+	_, err := q.db.ExecContext(ctx, insertOutboxEvent,
+		arg.EventID,
+		arg.EventType,
+		arg.PrID,
+		arg.UserIds,
+		arg.Payload,
+	)
+	return err
+}
+
+const markOutboxAttemptFailed = `-- name: MarkOutboxAttemptFailed :exec
+UPDATE outbox
+SET status = ?1,
+    attempts = attempts + 1,
+    last_error = ?2,
+    next_attempt_at = ?3
+WHERE id = ?4
+`
+
+type MarkOutboxAttemptFailedParams struct {
+	Status        string
+	LastError     sql.NullString
+	NextAttemptAt time.Time
+	ID            int64
+}
+
+func (q *Queries) MarkOutboxAttemptFailed(ctx context.Context, arg MarkOutboxAttemptFailedParams) error {
+	_, err := q.db.ExecContext(ctx, markOutboxAttemptFailed,
+		arg.Status,
+		arg.LastError,
+		arg.NextAttemptAt,
+		arg.ID,
+	)
+	return err
+}
+
+const markOutboxDispatched = `-- name: MarkOutboxDispatched :exec
+UPDATE outbox
+SET status = 'DISPATCHED',
+    attempts = attempts + 1,
+    last_error = NULL,
+    dispatched_at = strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')
+WHERE id = ?
+`
+
+func (q *Queries) MarkOutboxDispatched(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, markOutboxDispatched, id)
+	return err
+}
+
+const sequenceOutboxEvents = `-- name: SequenceOutboxEvents :execrows
+UPDATE outbox
+SET stream_seq = id
+WHERE stream_seq IS NULL
+`
+
+func (q *Queries) SequenceOutboxEvents(ctx context.Context) (int64, error) {
+	result, err := q.db.ExecContext(ctx, sequenceOutboxEvents)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,595 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: prs.sql
+
+package sqlitedb
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+)
+
+const addReviewer = `-- name: AddReviewer :exec
+INSERT INTO pr_reviewers (pr_id, reviewer_id)
+VALUES (?, ?)
+ON CONFLICT DO NOTHING
+`
+
+type AddReviewerParams struct {
+	PrID       string
+	ReviewerID string
+}
+
+func (q *Queries) AddReviewer(ctx context.Context, arg AddReviewerParams) error {
+	_, err := q.db.ExecContext(ctx, addReviewer, arg.PrID, arg.ReviewerID)
+	return err
+}
+
+const checkDuplicatePR = `-- name: CheckDuplicatePR :one
+SELECT id
+FROM prs
+WHERE author_id = ?
+  AND title = ?
+  AND status = 'OPEN'
+LIMIT 1
+`
+
+type CheckDuplicatePRParams struct {
+	AuthorID string
+	Title    string
+}
+
+func (q *Queries) CheckDuplicatePR(ctx context.Context, arg CheckDuplicatePRParams) (string, error) {
+	row := q.db.QueryRowContext(ctx, checkDuplicatePR, arg.AuthorID, arg.Title)
+	var id string
+	err := row.Scan(&id)
+	return id, err
+}
+
+const closePR = `-- name: ClosePR :exec
+UPDATE prs
+SET status = 'CLOSED',
+    closed_at = strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')
+WHERE id = ?
+`
+
+func (q *Queries) ClosePR(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, closePR, id)
+	return err
+}
+
+const countOpenReviews = `-- name: CountOpenReviews :many
+SELECT r.reviewer_id, COUNT(*) AS open_reviews
+FROM pr_reviewers r
+JOIN prs ON prs.id = r.pr_id
+WHERE prs.status = 'OPEN'
+  AND r.reviewer_id IN (/*SLICE:reviewer_ids*/?)
+GROUP BY r.reviewer_id
+`
+
+type CountOpenReviewsRow struct {
+	ReviewerID  string
+	OpenReviews int64
+}
+
+func (q *Queries) CountOpenReviews(ctx context.Context, reviewerIds []string) ([]CountOpenReviewsRow, error) {
+	query := countOpenReviews
+	var queryParams []interface{}
+	if len(reviewerIds) > 0 {
+		for _, v := range reviewerIds {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:reviewer_ids*/?", strings.Repeat(",?", len(reviewerIds))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:reviewer_ids*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountOpenReviewsRow
+	for rows.Next() {
+		var i CountOpenReviewsRow
+		if err := rows.Scan(&i.ReviewerID, &i.OpenReviews); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const createPR = `-- name: CreatePR :exec
+INSERT INTO prs (id, title, author_id, status, reviewers_required, is_draft)
+VALUES (?, ?, ?, 'OPEN', ?, ?)
+`
+
+type CreatePRParams struct {
+	ID                string
+	Title             string
+	AuthorID          string
+	ReviewersRequired int64
+	IsDraft           bool
+}
+
+func (q *Queries) CreatePR(ctx context.Context, arg CreatePRParams) error {
+	_, err := q.db.ExecContext(ctx, createPR,
+		arg.ID,
+		arg.Title,
+		arg.AuthorID,
+		arg.ReviewersRequired,
+		arg.IsDraft,
+	)
+	return err
+}
+
+const deleteReviewer = `-- name: DeleteReviewer :exec
+DELETE FROM pr_reviewers
+WHERE pr_id = ? AND reviewer_id = ?
+`
+
+type DeleteReviewerParams struct {
+	PrID       string
+	ReviewerID string
+}
+
+func (q *Queries) DeleteReviewer(ctx context.Context, arg DeleteReviewerParams) error {
+	_, err := q.db.ExecContext(ctx, deleteReviewer, arg.PrID, arg.ReviewerID)
+	return err
+}
+
+const getAvailableTeamMembersExceptAuthor = `-- name: GetAvailableTeamMembersExceptAuthor :many
+SELECT u.id
+FROM users u
+JOIN teams t ON t.id = u.team_id
+JOIN user_workload w ON w.user_id = u.id
+WHERE u.team_id = ?1
+  AND u.is_available = TRUE
+  AND u.id <> ?2
+  AND w.open_reviews < COALESCE(u.max_open_reviews, t.max_open_reviews)
+`
+
+type GetAvailableTeamMembersExceptAuthorParams struct {
+	TeamID   string
+	AuthorID string
+}
+
+func (q *Queries) GetAvailableTeamMembersExceptAuthor(ctx context.Context, arg GetAvailableTeamMembersExceptAuthorParams) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, getAvailableTeamMembersExceptAuthor, arg.TeamID, arg.AuthorID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getIneligibleReviewersByPR = `-- name: GetIneligibleReviewersByPR :many
+SELECT u.id, u.team_id
+FROM pr_reviewers r
+JOIN users u ON u.id = r.reviewer_id
+JOIN teams t ON t.id = u.team_id
+JOIN user_workload w ON w.user_id = u.id
+WHERE r.pr_id = ?
+  AND (u.is_available = FALSE OR w.open_reviews > COALESCE(u.max_open_reviews, t.max_open_reviews))
+ORDER BY u.id
+`
+
+type GetIneligibleReviewersByPRRow struct {
+	ID     string
+	TeamID string
+}
+
+func (q *Queries) GetIneligibleReviewersByPR(ctx context.Context, prID string) ([]GetIneligibleReviewersByPRRow, error) {
+	rows, err := q.db.QueryContext(ctx, getIneligibleReviewersByPR, prID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetIneligibleReviewersByPRRow
+	for rows.Next() {
+		var i GetIneligibleReviewersByPRRow
+		if err := rows.Scan(&i.ID, &i.TeamID); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getOpenReviewsByReviewers = `-- name: GetOpenReviewsByReviewers :many
+SELECT r.pr_id, r.reviewer_id, prs.author_id
+FROM pr_reviewers r
+JOIN prs ON prs.id = r.pr_id
+WHERE prs.status = 'OPEN'
+  AND r.reviewer_id IN (/*SLICE:reviewer_ids*/?)
+ORDER BY r.pr_id, r.reviewer_id
+`
+
+type GetOpenReviewsByReviewersRow struct {
+	PrID       string
+	ReviewerID string
+	AuthorID   string
+}
+
+func (q *Queries) GetOpenReviewsByReviewers(ctx context.Context, reviewerIds []string) ([]GetOpenReviewsByReviewersRow, error) {
+	query := getOpenReviewsByReviewers
+	var queryParams []interface{}
+	if len(reviewerIds) > 0 {
+		for _, v := range reviewerIds {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:reviewer_ids*/?", strings.Repeat(",?", len(reviewerIds))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:reviewer_ids*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetOpenReviewsByReviewersRow
+	for rows.Next() {
+		var i GetOpenReviewsByReviewersRow
+		if err := rows.Scan(&i.PrID, &i.ReviewerID, &i.AuthorID); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPRById = `-- name: GetPRById :one
+SELECT id, title, author_id, status, created_at, merged_at, reviewers_required, force_merged, force_merged_by, closed_at, is_draft
+FROM prs
+WHERE id = ?
+`
+
+func (q *Queries) GetPRById(ctx context.Context, id string) (Pr, error) {
+	row := q.db.QueryRowContext(ctx, getPRById, id)
+	var i Pr
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.AuthorID,
+		&i.Status,
+		&i.CreatedAt,
+		&i.MergedAt,
+		&i.ReviewersRequired,
+		&i.ForceMerged,
+		&i.ForceMergedBy,
+		&i.ClosedAt,
+		&i.IsDraft,
+	)
+	return i, err
+}
+
+const getPRsByIDs = `-- name: GetPRsByIDs :many
+SELECT id, title, author_id, status, created_at, merged_at, reviewers_required, force_merged, force_merged_by, closed_at, is_draft
+FROM prs
+WHERE id IN (/*SLICE:ids*/?)
+ORDER BY id
+`
+
+func (q *Queries) GetPRsByIDs(ctx context.Context, ids []string) ([]Pr, error) {
+	query := getPRsByIDs
+	var queryParams []interface{}
+	if len(ids) > 0 {
+		for _, v := range ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Pr
+	for rows.Next() {
+		var i Pr
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.AuthorID,
+			&i.Status,
+			&i.CreatedAt,
+			&i.MergedAt,
+			&i.ReviewersRequired,
+			&i.ForceMerged,
+			&i.ForceMergedBy,
+			&i.ClosedAt,
+			&i.IsDraft,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getReviewersByPR = `-- name: GetReviewersByPR :many
+SELECT reviewer_id
+FROM pr_reviewers
+WHERE pr_id = ?
+`
+
+func (q *Queries) GetReviewersByPR(ctx context.Context, prID string) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, getReviewersByPR, prID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var reviewer_id string
+		if err := rows.Scan(&reviewer_id); err != nil {
+			return nil, err
+		}
+		items = append(items, reviewer_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getReviewersByPRs = `-- name: GetReviewersByPRs :many
+SELECT pr_id, reviewer_id
+FROM pr_reviewers
+WHERE pr_id IN (/*SLICE:pr_ids*/?)
+ORDER BY pr_id, reviewer_id
+`
+
+type GetReviewersByPRsRow struct {
+	PrID       string
+	ReviewerID string
+}
+
+func (q *Queries) GetReviewersByPRs(ctx context.Context, prIds []string) ([]GetReviewersByPRsRow, error) {
+	query := getReviewersByPRs
+	var queryParams []interface{}
+	if len(prIds) > 0 {
+		for _, v := range prIds {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:pr_ids*/?", strings.Repeat(",?", len(prIds))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:pr_ids*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetReviewersByPRsRow
+	for rows.Next() {
+		var i GetReviewersByPRsRow
+		if err := rows.Scan(&i.PrID, &i.ReviewerID); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getReviewsByPR = `-- name: GetReviewsByPR :many
+SELECT reviewer_id, state, assigned_at, reviewed_at
+FROM pr_reviewers
+WHERE pr_id = ?
+ORDER BY assigned_at, reviewer_id
+`
+
+type GetReviewsByPRRow struct {
+	ReviewerID string
+	State      string
+	AssignedAt time.Time
+	ReviewedAt sql.NullTime
+}
+
+func (q *Queries) GetReviewsByPR(ctx context.Context, prID string) ([]GetReviewsByPRRow, error) {
+	rows, err := q.db.QueryContext(ctx, getReviewsByPR, prID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetReviewsByPRRow
+	for rows.Next() {
+		var i GetReviewsByPRRow
+		if err := rows.Scan(
+			&i.ReviewerID,
+			&i.State,
+			&i.AssignedAt,
+			&i.ReviewedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTeamCandidates = `-- name: GetTeamCandidates :many
+SELECT u.id, u.review_weight, w.open_reviews,
+       CAST(COALESCE(u.max_open_reviews, t.max_open_reviews) AS INTEGER) AS capacity
+FROM users u
+JOIN teams t ON t.id = u.team_id
+JOIN user_workload w ON w.user_id = u.id
+WHERE u.team_id = ?
+  AND u.is_available = TRUE
+ORDER BY u.id
+`
+
+type GetTeamCandidatesRow struct {
+	ID           string
+	ReviewWeight int64
+	OpenReviews  int64
+	Capacity     int64
+}
+
+func (q *Queries) GetTeamCandidates(ctx context.Context, teamID string) ([]GetTeamCandidatesRow, error) {
+	rows, err := q.db.QueryContext(ctx, getTeamCandidates, teamID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetTeamCandidatesRow
+	for rows.Next() {
+		var i GetTeamCandidatesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ReviewWeight,
+			&i.OpenReviews,
+			&i.Capacity,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const isReviewerAssigned = `-- name: IsReviewerAssigned :one
+SELECT COUNT(*) > 0 AS assigned
+FROM pr_reviewers
+WHERE pr_id = ? AND reviewer_id = ?
+`
+
+type IsReviewerAssignedParams struct {
+	PrID       string
+	ReviewerID string
+}
+
+func (q *Queries) IsReviewerAssigned(ctx context.Context, arg IsReviewerAssignedParams) (bool, error) {
+	row := q.db.QueryRowContext(ctx, isReviewerAssigned, arg.PrID, arg.ReviewerID)
+	var assigned bool
+	err := row.Scan(&assigned)
+	return assigned, err
+}
+
+const markPRReady = `-- name: MarkPRReady :exec
+UPDATE prs
+SET is_draft = FALSE
+WHERE id = ?
+`
+
+func (q *Queries) MarkPRReady(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, markPRReady, id)
+	return err
+}
+
+const mergePR = `-- name: MergePR :exec
+UPDATE prs
+SET status = 'MERGED',
+    merged_at = strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'),
+    force_merged = ?1,
+    force_merged_by = ?2
+WHERE id = ?3
+`
+
+type MergePRParams struct {
+	ForceMerged   bool
+	ForceMergedBy sql.NullString
+	ID            string
+}
+
+func (q *Queries) MergePR(ctx context.Context, arg MergePRParams) error {
+	_, err := q.db.ExecContext(ctx, mergePR, arg.ForceMerged, arg.ForceMergedBy, arg.ID)
+	return err
+}
+
+const reopenPR = `-- name: ReopenPR :exec
+UPDATE prs
+SET status = 'OPEN',
+    closed_at = NULL
+WHERE id = ?
+`
+
+func (q *Queries) ReopenPR(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, reopenPR, id)
+	return err
+}
+
+const setReviewState = `-- name: SetReviewState :execrows
+UPDATE pr_reviewers
+SET state = ?1,
+    reviewed_at = strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')
+WHERE pr_id = ?2 AND reviewer_id = ?3
+`
+
+type SetReviewStateParams struct {
+	State      string
+	PrID       string
+	ReviewerID string
+}
+
+func (q *Queries) SetReviewState(ctx context.Context, arg SetReviewStateParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, setReviewState, arg.State, arg.PrID, arg.ReviewerID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,333 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: team.sql
+
+package sqlitedb
+
+import (
+	"context"
+	"database/sql"
+)
+
+const addTeamFallback = `-- name: AddTeamFallback :exec
+INSERT INTO team_fallbacks (team_id, fallback_team_id, priority)
+VALUES (?, ?, ?)
+`
+
+type AddTeamFallbackParams struct {
+	TeamID         string
+	FallbackTeamID string
+	Priority       int64
+}
+
+func (q *Queries) AddTeamFallback(ctx context.Context, arg AddTeamFallbackParams) error {
+	_, err := q.db.ExecContext(ctx, addTeamFallback, arg.TeamID, arg.FallbackTeamID, arg.Priority)
+	return err
+}
+
+const createTeam = `-- name: CreateTeam :exec
+INSERT INTO teams (id, teamname, reviewer_strategy, max_open_reviews, reviewers_required, approvals_required)
+VALUES (?, ?, ?, ?, ?, ?)
+`
+
+type CreateTeamParams struct {
+	ID                string
+	Teamname          string
+	ReviewerStrategy  string
+	MaxOpenReviews    int64
+	ReviewersRequired int64
+	ApprovalsRequired int64
+}
+
+func (q *Queries) CreateTeam(ctx context.Context, arg CreateTeamParams) error {
+	_, err := q.db.ExecContext(ctx, createTeam,
+		arg.ID,
+		arg.Teamname,
+		arg.ReviewerStrategy,
+		arg.MaxOpenReviews,
+		arg.ReviewersRequired,
+		arg.ApprovalsRequired,
+	)
+	return err
+}
+
+const deleteTeamFallbacks = `-- name: DeleteTeamFallbacks :exec
+DELETE FROM team_fallbacks
+WHERE team_id = ?
+`
+
+func (q *Queries) DeleteTeamFallbacks(ctx context.Context, teamID string) error {
+	_, err := q.db.ExecContext(ctx, deleteTeamFallbacks, teamID)
+	return err
+}
+
+const getTeamByID = `-- name: GetTeamByID :one
+SELECT id, teamname, reviewer_strategy, rr_last_reviewer_id, max_open_reviews, reviewers_required, approvals_required
+FROM teams
+WHERE id = ?
+`
+
+func (q *Queries) GetTeamByID(ctx context.Context, id string) (Team, error) {
+	row := q.db.QueryRowContext(ctx, getTeamByID, id)
+	var i Team
+	err := row.Scan(
+		&i.ID,
+		&i.Teamname,
+		&i.ReviewerStrategy,
+		&i.RrLastReviewerID,
+		&i.MaxOpenReviews,
+		&i.ReviewersRequired,
+		&i.ApprovalsRequired,
+	)
+	return i, err
+}
+
+const getTeamByName = `-- name: GetTeamByName :one
+SELECT id, teamname, reviewer_strategy, rr_last_reviewer_id, max_open_reviews, reviewers_required, approvals_required
+FROM teams
+WHERE teamname = ?
+`
+
+func (q *Queries) GetTeamByName(ctx context.Context, teamname string) (Team, error) {
+	row := q.db.QueryRowContext(ctx, getTeamByName, teamname)
+	var i Team
+	err := row.Scan(
+		&i.ID,
+		&i.Teamname,
+		&i.ReviewerStrategy,
+		&i.RrLastReviewerID,
+		&i.MaxOpenReviews,
+		&i.ReviewersRequired,
+		&i.ApprovalsRequired,
+	)
+	return i, err
+}
+
+const getTeamFallbacks = `-- name: GetTeamFallbacks :many
+SELECT t.id, t.teamname, t.reviewer_strategy, t.rr_last_reviewer_id, t.max_open_reviews, t.reviewers_required, t.approvals_required
+FROM team_fallbacks f
+JOIN teams t ON t.id = f.fallback_team_id
+WHERE f.team_id = ?
+ORDER BY f.priority
+`
+
+func (q *Queries) GetTeamFallbacks(ctx context.Context, teamID string) ([]Team, error) {
+	rows, err := q.db.QueryContext(ctx, getTeamFallbacks, teamID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Team
+	for rows.Next() {
+		var i Team
+		if err := rows.Scan(
+			&i.ID,
+			&i.Teamname,
+			&i.ReviewerStrategy,
+			&i.RrLastReviewerID,
+			&i.MaxOpenReviews,
+			&i.ReviewersRequired,
+			&i.ApprovalsRequired,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTeamNameByID = `-- name: GetTeamNameByID :one
+SELECT teamname
+FROM teams
+WHERE id = ?
+`
+
+func (q *Queries) GetTeamNameByID(ctx context.Context, id string) (string, error) {
+	row := q.db.QueryRowContext(ctx, getTeamNameByID, id)
+	var teamname string
+	err := row.Scan(&teamname)
+	return teamname, err
+}
+
+const getUsersByTeam = `-- name: GetUsersByTeam :many
+SELECT u.id, u.username, u.is_available, u.team_id, u.review_weight, u.max_open_reviews, u.github_login, u.gitlab_username, u.role, w.open_reviews
+FROM users u
+JOIN user_workload w ON w.user_id = u.id
+WHERE u.team_id = ?
+ORDER BY u.username
+`
+
+type GetUsersByTeamRow struct {
+	ID             string
+	Username       string
+	IsAvailable    bool
+	TeamID         string
+	ReviewWeight   int64
+	MaxOpenReviews sql.NullInt64
+	GithubLogin    sql.NullString
+	GitlabUsername sql.NullString
+	Role           string
+	OpenReviews    int64
+}
+
+func (q *Queries) GetUsersByTeam(ctx context.Context, teamID string) ([]GetUsersByTeamRow, error) {
+	rows, err := q.db.QueryContext(ctx, getUsersByTeam, teamID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetUsersByTeamRow
+	for rows.Next() {
+		var i GetUsersByTeamRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.IsAvailable,
+			&i.TeamID,
+			&i.ReviewWeight,
+			&i.MaxOpenReviews,
+			&i.GithubLogin,
+			&i.GitlabUsername,
+			&i.Role,
+			&i.OpenReviews,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setTeamApprovalsRequired = `-- name: SetTeamApprovalsRequired :exec
+UPDATE teams
+SET approvals_required = ?
+WHERE id = ?
+`
+
+type SetTeamApprovalsRequiredParams struct {
+	ApprovalsRequired int64
+	ID                string
+}
+
+func (q *Queries) SetTeamApprovalsRequired(ctx context.Context, arg SetTeamApprovalsRequiredParams) error {
+	_, err := q.db.ExecContext(ctx, setTeamApprovalsRequired, arg.ApprovalsRequired, arg.ID)
+	return err
+}
+
+const setTeamMaxOpenReviews = `-- name: SetTeamMaxOpenReviews :exec
+UPDATE teams
+SET max_open_reviews = ?
+WHERE id = ?
+`
+
+type SetTeamMaxOpenReviewsParams struct {
+	MaxOpenReviews int64
+	ID             string
+}
+
+func (q *Queries) SetTeamMaxOpenReviews(ctx context.Context, arg SetTeamMaxOpenReviewsParams) error {
+	_, err := q.db.ExecContext(ctx, setTeamMaxOpenReviews, arg.MaxOpenReviews, arg.ID)
+	return err
+}
+
+const setTeamReviewerStrategy = `-- name: SetTeamReviewerStrategy :exec
+UPDATE teams
+SET reviewer_strategy = ?
+WHERE id = ?
+`
+
+type SetTeamReviewerStrategyParams struct {
+	ReviewerStrategy string
+	ID               string
+}
+
+func (q *Queries) SetTeamReviewerStrategy(ctx context.Context, arg SetTeamReviewerStrategyParams) error {
+	_, err := q.db.ExecContext(ctx, setTeamReviewerStrategy, arg.ReviewerStrategy, arg.ID)
+	return err
+}
+
+const setTeamReviewersRequired = `-- name: SetTeamReviewersRequired :exec
+UPDATE teams
+SET reviewers_required = ?
+WHERE id = ?
+`
+
+type SetTeamReviewersRequiredParams struct {
+	ReviewersRequired int64
+	ID                string
+}
+
+func (q *Queries) SetTeamReviewersRequired(ctx context.Context, arg SetTeamReviewersRequiredParams) error {
+	_, err := q.db.ExecContext(ctx, setTeamReviewersRequired, arg.ReviewersRequired, arg.ID)
+	return err
+}
+
+const setTeamRoundRobinCursor = `-- name: SetTeamRoundRobinCursor :exec
+UPDATE teams
+SET rr_last_reviewer_id = ?
+WHERE id = ?
+`
+
+type SetTeamRoundRobinCursorParams struct {
+	RrLastReviewerID sql.NullString
+	ID               string
+}
+
+func (q *Queries) SetTeamRoundRobinCursor(ctx context.Context, arg SetTeamRoundRobinCursorParams) error {
+	_, err := q.db.ExecContext(ctx, setTeamRoundRobinCursor, arg.RrLastReviewerID, arg.ID)
+	return err
+}
+
+const upsertUser = `-- name: UpsertUser :exec
+INSERT INTO users (id, username, is_available, team_id, review_weight, max_open_reviews, github_login, gitlab_username, role)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (id) DO UPDATE
+SET username = EXCLUDED.username,
+    is_available = EXCLUDED.is_available,
+    team_id = EXCLUDED.team_id,
+    review_weight = EXCLUDED.review_weight,
+    max_open_reviews = EXCLUDED.max_open_reviews,
+    github_login = COALESCE(EXCLUDED.github_login, users.github_login),
+    gitlab_username = COALESCE(EXCLUDED.gitlab_username, users.gitlab_username),
+    role = EXCLUDED.role
+`
+
+type UpsertUserParams struct {
+	ID             string
+	Username       string
+	IsAvailable    bool
+	TeamID         string
+	ReviewWeight   int64
+	MaxOpenReviews sql.NullInt64
+	GithubLogin    sql.NullString
+	GitlabUsername sql.NullString
+	Role           string
+}
+
+func (q *Queries) UpsertUser(ctx context.Context, arg UpsertUserParams) error {
+	_, err := q.db.ExecContext(ctx, upsertUser,
+		arg.ID,
+		arg.Username,
+		arg.IsAvailable,
+		arg.TeamID,
+		arg.ReviewWeight,
+		arg.MaxOpenReviews,
+		arg.GithubLogin,
+		arg.GitlabUsername,
+		arg.Role,
+	)
+	return err
+}
@@ -0,0 +1,151 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: tokens.sql
+
+package sqlitedb
+
+import (
+	"context"
+	"database/sql"
+)
+
+const createAPIToken = `-- name: CreateAPIToken :exec
+INSERT INTO api_tokens (id, name, token_hash, scope, user_id)
+VALUES (?, ?, ?, ?, ?)
+`
+
+type CreateAPITokenParams struct {
+	ID        string
+	Name      string
+	TokenHash string
+	Scope     string
+	UserID    sql.NullString
+}
+
+func (q *Queries) CreateAPIToken(ctx context.Context, arg CreateAPITokenParams) error {
+	_, err := q.db.ExecContext(ctx, createAPIToken,
+		arg.ID,
+		arg.Name,
+		arg.TokenHash,
+		arg.Scope,
+		arg.UserID,
+	)
+	return err
+}
+
+const findAPITokenByHash = `-- name: FindAPITokenByHash :one
+SELECT id, name, token_hash, scope, user_id, created_at, revoked_at
+FROM api_tokens
+WHERE token_hash = ?
+`
+
+func (q *Queries) FindAPITokenByHash(ctx context.Context, tokenHash string) (ApiToken, error) {
+	row := q.db.QueryRowContext(ctx, findAPITokenByHash, tokenHash)
+	var i ApiToken
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.TokenHash,
+		&i.Scope,
+		&i.UserID,
+		&i.CreatedAt,
+		&i.RevokedAt,
+	)
+	return i, err
+}
+
+const getAPITokenByHash = `-- name: GetAPITokenByHash :one
+SELECT id, name, token_hash, scope, user_id, created_at, revoked_at
+FROM api_tokens
+WHERE token_hash = ?
+  AND revoked_at IS NULL
+`
+
+func (q *Queries) GetAPITokenByHash(ctx context.Context, tokenHash string) (ApiToken, error) {
+	row := q.db.QueryRowContext(ctx, getAPITokenByHash, tokenHash)
+	var i ApiToken
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.TokenHash,
+		&i.Scope,
+		&i.UserID,
+		&i.CreatedAt,
+		&i.RevokedAt,
+	)
+	return i, err
+}
+
+const getAPITokenByID = `-- name: GetAPITokenByID :one
+SELECT id, name, token_hash, scope, user_id, created_at, revoked_at
+FROM api_tokens
+WHERE id = ?
+`
+
+func (q *Queries) GetAPITokenByID(ctx context.Context, id string) (ApiToken, error) {
+	row := q.db.QueryRowContext(ctx, getAPITokenByID, id)
+	var i ApiToken
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.TokenHash,
+		&i.Scope,
+		&i.UserID,
+		&i.CreatedAt,
+		&i.RevokedAt,
+	)
+	return i, err
+}
+
+const listAPITokens = `-- name: ListAPITokens :many
+SELECT id, name, token_hash, scope, user_id, created_at, revoked_at
+FROM api_tokens
+ORDER BY created_at, id
+`
+
+func (q *Queries) ListAPITokens(ctx context.Context) ([]ApiToken, error) {
+	rows, err := q.db.QueryContext(ctx, listAPITokens)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ApiToken
+	for rows.Next() {
+		var i ApiToken
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.TokenHash,
+			&i.Scope,
+			&i.UserID,
+			&i.CreatedAt,
+			&i.RevokedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const revokeAPIToken = `-- name: RevokeAPIToken :execrows
+UPDATE api_tokens
+SET revoked_at = strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')
+WHERE id = ?
+  AND revoked_at IS NULL
+`
+
+func (q *Queries) RevokeAPIToken(ctx context.Context, id string) (int64, error) {
+	result, err := q.db.ExecContext(ctx, revokeAPIToken, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,266 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: users.sql
+
+package sqlitedb
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+)
+
+const getReviewPRs = `-- name: GetReviewPRs :many
+SELECT
+    prs.id AS pr_id,
+    prs.title AS pr_title,
+    prs.author_id,
+    prs.status,
+    r.state AS review_state,
+    r.reviewed_at
+FROM pr_reviewers r
+JOIN prs ON prs.id = r.pr_id
+WHERE r.reviewer_id = ?
+ORDER BY prs.id
+`
+
+type GetReviewPRsRow struct {
+	PrID        string
+	PrTitle     string
+	AuthorID    string
+	Status      string
+	ReviewState string
+	ReviewedAt  sql.NullTime
+}
+
+func (q *Queries) GetReviewPRs(ctx context.Context, reviewerID string) ([]GetReviewPRsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getReviewPRs, reviewerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetReviewPRsRow
+	for rows.Next() {
+		var i GetReviewPRsRow
+		if err := rows.Scan(
+			&i.PrID,
+			&i.PrTitle,
+			&i.AuthorID,
+			&i.Status,
+			&i.ReviewState,
+			&i.ReviewedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserByGithubLogin = `-- name: GetUserByGithubLogin :one
+SELECT id, username, is_available, team_id, review_weight, max_open_reviews, github_login, gitlab_username, role
+FROM users
+WHERE github_login = ?
+`
+
+func (q *Queries) GetUserByGithubLogin(ctx context.Context, githubLogin sql.NullString) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByGithubLogin, githubLogin)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.IsAvailable,
+		&i.TeamID,
+		&i.ReviewWeight,
+		&i.MaxOpenReviews,
+		&i.GithubLogin,
+		&i.GitlabUsername,
+		&i.Role,
+	)
+	return i, err
+}
+
+const getUserByGitlabUsername = `-- name: GetUserByGitlabUsername :one
+SELECT id, username, is_available, team_id, review_weight, max_open_reviews, github_login, gitlab_username, role
+FROM users
+WHERE gitlab_username = ?
+`
+
+func (q *Queries) GetUserByGitlabUsername(ctx context.Context, gitlabUsername sql.NullString) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByGitlabUsername, gitlabUsername)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.IsAvailable,
+		&i.TeamID,
+		&i.ReviewWeight,
+		&i.MaxOpenReviews,
+		&i.GithubLogin,
+		&i.GitlabUsername,
+		&i.Role,
+	)
+	return i, err
+}
+
+const getUserById = `-- name: GetUserById :one
+SELECT id, username, is_available, team_id, review_weight, max_open_reviews, github_login, gitlab_username, role
+FROM users
+WHERE id = ?
+`
+
+func (q *Queries) GetUserById(ctx context.Context, id string) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserById, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.IsAvailable,
+		&i.TeamID,
+		&i.ReviewWeight,
+		&i.MaxOpenReviews,
+		&i.GithubLogin,
+		&i.GitlabUsername,
+		&i.Role,
+	)
+	return i, err
+}
+
+const getUserOpenReviews = `-- name: GetUserOpenReviews :one
+SELECT open_reviews
+FROM user_workload
+WHERE user_id = ?
+`
+
+func (q *Queries) GetUserOpenReviews(ctx context.Context, userID string) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getUserOpenReviews, userID)
+	var open_reviews int64
+	err := row.Scan(&open_reviews)
+	return open_reviews, err
+}
+
+const getUserWeights = `-- name: GetUserWeights :many
+SELECT id, review_weight
+FROM users
+WHERE id IN (/*SLICE:ids*/?)
+`
+
+type GetUserWeightsRow struct {
+	ID           string
+	ReviewWeight int64
+}
+
+func (q *Queries) GetUserWeights(ctx context.Context, ids []string) ([]GetUserWeightsRow, error) {
+	query := getUserWeights
+	var queryParams []interface{}
+	if len(ids) > 0 {
+		for _, v := range ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetUserWeightsRow
+	for rows.Next() {
+		var i GetUserWeightsRow
+		if err := rows.Scan(&i.ID, &i.ReviewWeight); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setUserAvailability = `-- name: SetUserAvailability :exec
+UPDATE users
+SET is_available = ?
+WHERE id = ?
+`
+
+type SetUserAvailabilityParams struct {
+	IsAvailable bool
+	ID          string
+}
+
+func (q *Queries) SetUserAvailability(ctx context.Context, arg SetUserAvailabilityParams) error {
+	_, err := q.db.ExecContext(ctx, setUserAvailability, arg.IsAvailable, arg.ID)
+	return err
+}
+
+const setUserMaxOpenReviews = `-- name: SetUserMaxOpenReviews :exec
+UPDATE users
+SET max_open_reviews = ?
+WHERE id = ?
+`
+
+type SetUserMaxOpenReviewsParams struct {
+	MaxOpenReviews sql.NullInt64
+	ID             string
+}
+
+func (q *Queries) SetUserMaxOpenReviews(ctx context.Context, arg SetUserMaxOpenReviewsParams) error {
+	_, err := q.db.ExecContext(ctx, setUserMaxOpenReviews, arg.MaxOpenReviews, arg.ID)
+	return err
+}
+
+const setUserRole = `-- name: SetUserRole :exec
+UPDATE users
+SET role = ?
+WHERE id = ?
+`
+
+type SetUserRoleParams struct {
+	Role string
+	ID   string
+}
+
+func (q *Queries) SetUserRole(ctx context.Context, arg SetUserRoleParams) error {
+	_, err := q.db.ExecContext(ctx, setUserRole, arg.Role, arg.ID)
+	return err
+}
+
+const setUsersAvailability = `-- name: SetUsersAvailability :exec
+UPDATE users
+SET is_available = ?1
+WHERE id IN (/*SLICE:ids*/?)
+`
+
+type SetUsersAvailabilityParams struct {
+	IsAvailable bool
+	Ids         []string
+}
+
+func (q *Queries) SetUsersAvailability(ctx context.Context, arg SetUsersAvailabilityParams) error {
+	query := setUsersAvailability
+	var queryParams []interface{}
+	queryParams = append(queryParams, arg.IsAvailable)
+	if len(arg.Ids) > 0 {
+		for _, v := range arg.Ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(arg.Ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	_, err := q.db.ExecContext(ctx, query, queryParams...)
+	return err
+}
@@ -0,0 +1,377 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: webhooks.sql
+
+package sqlitedb
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+const claimDueWebhookDeliveries = `-- name: ClaimDueWebhookDeliveries :many
+UPDATE webhook_deliveries
+SET next_attempt_at = ?1
+WHERE id IN (
+    SELECT w.id
+    FROM webhook_deliveries w
+    WHERE w.status = 'PENDING'
+      AND w.next_attempt_at <= strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')
+      AND NOT EXISTS (
+          SELECT 1
+          FROM webhook_deliveries prev
+          WHERE prev.subscription_id = w.subscription_id
+            AND prev.pr_id = w.pr_id
+            AND prev.status = 'PENDING'
+            AND prev.id < w.id
+      )
+    ORDER BY w.id
+    LIMIT ?2
+)
+RETURNING id, subscription_id, event_id, event_type, pr_id, payload, attempts
+`
+
+type ClaimDueWebhookDeliveriesParams struct {
+	LeaseUntil time.Time
+	BatchSize  int64
+}
+
+type ClaimDueWebhookDeliveriesRow struct {
+	ID             int64
+	SubscriptionID string
+	EventID        string
+	EventType      string
+	PrID           string
+	Payload        string
+	Attempts       int64
+}
+
+func (q *Queries) ClaimDueWebhookDeliveries(ctx context.Context, arg ClaimDueWebhookDeliveriesParams) ([]ClaimDueWebhookDeliveriesRow, error) {
+	rows, err := q.db.QueryContext(ctx, claimDueWebhookDeliveries, arg.LeaseUntil, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ClaimDueWebhookDeliveriesRow
+	for rows.Next() {
+		var i ClaimDueWebhookDeliveriesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.SubscriptionID,
+			&i.EventID,
+			&i.EventType,
+			&i.PrID,
+			&i.Payload,
+			&i.Attempts,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const createWebhookDelivery = `-- name: CreateWebhookDelivery :exec
+INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, pr_id, payload)
+VALUES (?, ?, ?, ?, ?)
+ON CONFLICT (subscription_id, event_id) DO NOTHING
+`
+
+type CreateWebhookDeliveryParams struct {
+	SubscriptionID string
+	EventID        string
+	EventType      string
+	PrID           string
+	Payload        string
+}
+
+func (q *Queries) CreateWebhookDelivery(ctx context.Context, arg CreateWebhookDeliveryParams) error {
+	_, err := q.db.ExecContext(ctx, createWebhookDelivery,
+		arg.SubscriptionID,
+		arg.EventID,
+		arg.EventType,
+		arg.PrID,
+		arg.Payload,
+	)
+	return err
+}
+
+const createWebhookSubscription = `-- name: CreateWebhookSubscription :exec
+INSERT INTO webhook_subscriptions (id, url, events, secret)
+VALUES (?, ?, ?, ?)
+`
+
+type CreateWebhookSubscriptionParams struct {
+	ID     string
+	Url    string
+	Events string
+	Secret string
+}
+
+func (q *Queries) CreateWebhookSubscription(ctx context.Context, arg CreateWebhookSubscriptionParams) error {
+	_, err := q.db.ExecContext(ctx, createWebhookSubscription,
+		arg.ID,
+		arg.Url,
+		arg.Events,
+		arg.Secret,
+	)
+	return err
+}
+
+const deleteWebhookSubscription = `-- name: DeleteWebhookSubscription :execrows
+DELETE FROM webhook_subscriptions
+WHERE id = ?
+`
+
+func (q *Queries) DeleteWebhookSubscription(ctx context.Context, id string) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteWebhookSubscription, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getSubscriptionsForEvent = `-- name: GetSubscriptionsForEvent :many
+SELECT id, url, events, secret, created_at
+FROM webhook_subscriptions s
+WHERE json_array_length(s.events) = 0
+   OR instr(s.events, json_quote(CAST(?1 AS TEXT))) > 0
+ORDER BY created_at, id
+`
+
+func (q *Queries) GetSubscriptionsForEvent(ctx context.Context, eventType string) ([]WebhookSubscription, error) {
+	rows, err := q.db.QueryContext(ctx, getSubscriptionsForEvent, eventType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WebhookSubscription
+	for rows.Next() {
+		var i WebhookSubscription
+		if err := rows.Scan(
+			&i.ID,
+			&i.Url,
+			&i.Events,
+			&i.Secret,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getWebhookDeliveryByID = `-- name: GetWebhookDeliveryByID :one
+SELECT id, subscription_id, event_id, event_type, pr_id, payload, status, attempts, response_status, last_error, next_attempt_at, created_at, delivered_at
+FROM webhook_deliveries
+WHERE id = ?
+`
+
+func (q *Queries) GetWebhookDeliveryByID(ctx context.Context, id int64) (WebhookDelivery, error) {
+	row := q.db.QueryRowContext(ctx, getWebhookDeliveryByID, id)
+	var i WebhookDelivery
+	err := row.Scan(
+		&i.ID,
+		&i.SubscriptionID,
+		&i.EventID,
+		&i.EventType,
+		&i.PrID,
+		&i.Payload,
+		&i.Status,
+		&i.Attempts,
+		&i.ResponseStatus,
+		&i.LastError,
+		&i.NextAttemptAt,
+		&i.CreatedAt,
+		&i.DeliveredAt,
+	)
+	return i, err
+}
+
+const getWebhookSubscriptionByID = `-- name: GetWebhookSubscriptionByID :one
+SELECT id, url, events, secret, created_at
+FROM webhook_subscriptions
+WHERE id = ?
+`
+
+func (q *Queries) GetWebhookSubscriptionByID(ctx context.Context, id string) (WebhookSubscription, error) {
+	row := q.db.QueryRowContext(ctx, getWebhookSubscriptionByID, id)
+	var i WebhookSubscription
+	err := row.Scan(
+		&i.ID,
+		&i.Url,
+		&i.Events,
+		&i.Secret,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listWebhookDeliveries = `-- name: ListWebhookDeliveries :many
+SELECT id, subscription_id, event_id, event_type, pr_id, payload, status, attempts, response_status, last_error, next_attempt_at, created_at, delivered_at
+FROM webhook_deliveries
+WHERE (CAST(?1 AS TEXT) IS NULL OR subscription_id = CAST(?1 AS TEXT))
+  AND (CAST(?2 AS TEXT) IS NULL OR status = CAST(?2 AS TEXT))
+ORDER BY id DESC
+LIMIT ?3
+`
+
+type ListWebhookDeliveriesParams struct {
+	SubscriptionID sql.NullString
+	Status         sql.NullString
+	Limit          int64
+}
+
+func (q *Queries) ListWebhookDeliveries(ctx context.Context, arg ListWebhookDeliveriesParams) ([]WebhookDelivery, error) {
+	rows, err := q.db.QueryContext(ctx, listWebhookDeliveries, arg.SubscriptionID, arg.Status, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WebhookDelivery
+	for rows.Next() {
+		var i WebhookDelivery
+		if err := rows.Scan(
+			&i.ID,
+			&i.SubscriptionID,
+			&i.EventID,
+			&i.EventType,
+			&i.PrID,
+			&i.Payload,
+			&i.Status,
+			&i.Attempts,
+			&i.ResponseStatus,
+			&i.LastError,
+			&i.NextAttemptAt,
+			&i.CreatedAt,
+			&i.DeliveredAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWebhookSubscriptions = `-- name: ListWebhookSubscriptions :many
+SELECT id, url, events, secret, created_at
+FROM webhook_subscriptions
+ORDER BY created_at, id
+`
+
+func (q *Queries) ListWebhookSubscriptions(ctx context.Context) ([]WebhookSubscription, error) {
+	rows, err := q.db.QueryContext(ctx, listWebhookSubscriptions)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WebhookSubscription
+	for rows.Next() {
+		var i WebhookSubscription
+		if err := rows.Scan(
+			&i.ID,
+			&i.Url,
+			&i.Events,
+			&i.Secret,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markWebhookAttemptFailed = `-- name: MarkWebhookAttemptFailed :exec
+UPDATE webhook_deliveries
+SET status = ?1,
+    attempts = attempts + 1,
+    response_status = ?2,
+    last_error = ?3,
+    next_attempt_at = ?4
+WHERE id = ?5
+`
+
+type MarkWebhookAttemptFailedParams struct {
+	Status         string
+	ResponseStatus sql.NullInt64
+	LastError      sql.NullString
+	NextAttemptAt  time.Time
+	ID             int64
+}
+
+func (q *Queries) MarkWebhookAttemptFailed(ctx context.Context, arg MarkWebhookAttemptFailedParams) error {
+	_, err := q.db.ExecContext(ctx, markWebhookAttemptFailed,
+		arg.Status,
+		arg.ResponseStatus,
+		arg.LastError,
+		arg.NextAttemptAt,
+		arg.ID,
+	)
+	return err
+}
+
+const markWebhookDelivered = `-- name: MarkWebhookDelivered :exec
+UPDATE webhook_deliveries
+SET status = 'DELIVERED',
+    attempts = attempts + 1,
+    response_status = ?1,
+    last_error = NULL,
+    delivered_at = strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')
+WHERE id = ?2
+`
+
+type MarkWebhookDeliveredParams struct {
+	ResponseStatus sql.NullInt64
+	ID             int64
+}
+
+func (q *Queries) MarkWebhookDelivered(ctx context.Context, arg MarkWebhookDeliveredParams) error {
+	_, err := q.db.ExecContext(ctx, markWebhookDelivered, arg.ResponseStatus, arg.ID)
+	return err
+}
+
+const requeueWebhookDelivery = `-- name: RequeueWebhookDelivery :execrows
+UPDATE webhook_deliveries
+SET status = 'PENDING',
+    attempts = 0,
+    last_error = NULL,
+    next_attempt_at = strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')
+WHERE id = ?
+  AND status = 'DEAD'
+`
+
+func (q *Queries) RequeueWebhookDelivery(ctx context.Context, id int64) (int64, error) {
+	result, err := q.db.ExecContext(ctx, requeueWebhookDelivery, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,99 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	_ "modernc.org/sqlite"
+
+	"github.com/LlirikP/pr_dispenser/internal/database"
+	"github.com/LlirikP/pr_dispenser/internal/database/sqlite/sqlitedb"
+)
+
+type Queries struct {
+	q *sqlitedb.Queries
+}
+
+type Store struct {
+	*Queries
+	db *sql.DB
+}
+
+var _ database.Storage = (*Store)(nil)
+
+func OpenDB(path string) (*sql.DB, error) {
+	dsn := fmt.Sprintf("file:%s?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_time_format=sqlite", path)
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(1)
+
+	return db, nil
+}
+
+func NewStore(db *sql.DB) *Store {
+	return &Store{
+		Queries: &Queries{q: sqlitedb.New(db)},
+		db:      db,
+	}
+}
+
+func (s *Store) ExecTx(ctx context.Context, fn func(database.Querier) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(&Queries{q: s.q.WithTx(tx)}); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("%w (rollback failed: %v)", err, rbErr)
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
+const timeLayout = "2006-01-02 15:04:05.000+00:00"
+
+func utc(t time.Time) time.Time {
+	return t.UTC()
+}
+
+func nullTimeString(t sql.NullTime) sql.NullString {
+	if !t.Valid {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: t.Time.UTC().Format(timeLayout), Valid: true}
+}
+
+func nullInt32(v sql.NullInt64) sql.NullInt32 {
+	return sql.NullInt32{Int32: int32(v.Int64), Valid: v.Valid}
+}
+
+func nullInt64(v sql.NullInt32) sql.NullInt64 {
+	return sql.NullInt64{Int64: int64(v.Int32), Valid: v.Valid}
+}
+
+func encodeList(values []string) (string, error) {
+	if values == nil {
+		values = []string{}
+	}
+
+	raw, err := json.Marshal(values)
+	return string(raw), err
+}
+
+func decodeList(raw string) ([]string, error) {
+	values := []string{}
+	if err := json.Unmarshal([]byte(raw), &values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
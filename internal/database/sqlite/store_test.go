@@ -0,0 +1,32 @@
+package sqlite_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/LlirikP/pr_dispenser/internal/database"
+	"github.com/LlirikP/pr_dispenser/internal/database/sqlite"
+	"github.com/LlirikP/pr_dispenser/internal/database/storagetest"
+	"github.com/LlirikP/pr_dispenser/internal/migrate"
+)
+
+func TestStorage(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) database.Storage {
+		db, err := sqlite.OpenDB(filepath.Join(t.TempDir(), "dispenser.db"))
+		if err != nil {
+			t.Fatalf("open sqlite: %v", err)
+		}
+		t.Cleanup(func() { db.Close() })
+
+		migrator, err := migrate.New("sqlite", db)
+		if err != nil {
+			t.Fatalf("new migrator: %v", err)
+		}
+		if _, err := migrator.Up(context.Background()); err != nil {
+			t.Fatalf("migrate: %v", err)
+		}
+
+		return sqlite.NewStore(db)
+	})
+}
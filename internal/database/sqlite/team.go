@@ -0,0 +1,149 @@
+package sqlite
+
+import (
+	"context"
+
+	"github.com/LlirikP/pr_dispenser/internal/database"
+	"github.com/LlirikP/pr_dispenser/internal/database/sqlite/sqlitedb"
+)
+
+func toTeam(t sqlitedb.Team) database.Team {
+	return database.Team{
+		ID:                t.ID,
+		Teamname:          t.Teamname,
+		ReviewerStrategy:  t.ReviewerStrategy,
+		RrLastReviewerID:  t.RrLastReviewerID,
+		MaxOpenReviews:    int32(t.MaxOpenReviews),
+		ReviewersRequired: int32(t.ReviewersRequired),
+		ApprovalsRequired: int32(t.ApprovalsRequired),
+	}
+}
+
+func (q *Queries) CreateTeam(ctx context.Context, arg database.CreateTeamParams) error {
+	return q.q.CreateTeam(ctx, sqlitedb.CreateTeamParams{
+		ID:                arg.ID,
+		Teamname:          arg.Teamname,
+		ReviewerStrategy:  arg.ReviewerStrategy,
+		MaxOpenReviews:    int64(arg.MaxOpenReviews),
+		ReviewersRequired: int64(arg.ReviewersRequired),
+		ApprovalsRequired: int64(arg.ApprovalsRequired),
+	})
+}
+
+func (q *Queries) GetTeamByName(ctx context.Context, teamname string) (database.Team, error) {
+	team, err := q.q.GetTeamByName(ctx, teamname)
+	if err != nil {
+		return database.Team{}, err
+	}
+	return toTeam(team), nil
+}
+
+func (q *Queries) GetTeamByID(ctx context.Context, id string) (database.Team, error) {
+	team, err := q.q.GetTeamByID(ctx, id)
+	if err != nil {
+		return database.Team{}, err
+	}
+	return toTeam(team), nil
+}
+
+func (q *Queries) GetTeamNameByID(ctx context.Context, id string) (string, error) {
+	return q.q.GetTeamNameByID(ctx, id)
+}
+
+func (q *Queries) SetTeamReviewerStrategy(ctx context.Context, arg database.SetTeamReviewerStrategyParams) error {
+	return q.q.SetTeamReviewerStrategy(ctx, sqlitedb.SetTeamReviewerStrategyParams{
+		ID:               arg.ID,
+		ReviewerStrategy: arg.ReviewerStrategy,
+	})
+}
+
+func (q *Queries) SetTeamMaxOpenReviews(ctx context.Context, arg database.SetTeamMaxOpenReviewsParams) error {
+	return q.q.SetTeamMaxOpenReviews(ctx, sqlitedb.SetTeamMaxOpenReviewsParams{
+		ID:             arg.ID,
+		MaxOpenReviews: int64(arg.MaxOpenReviews),
+	})
+}
+
+func (q *Queries) SetTeamReviewersRequired(ctx context.Context, arg database.SetTeamReviewersRequiredParams) error {
+	return q.q.SetTeamReviewersRequired(ctx, sqlitedb.SetTeamReviewersRequiredParams{
+		ID:                arg.ID,
+		ReviewersRequired: int64(arg.ReviewersRequired),
+	})
+}
+
+func (q *Queries) SetTeamApprovalsRequired(ctx context.Context, arg database.SetTeamApprovalsRequiredParams) error {
+	return q.q.SetTeamApprovalsRequired(ctx, sqlitedb.SetTeamApprovalsRequiredParams{
+		ID:                arg.ID,
+		ApprovalsRequired: int64(arg.ApprovalsRequired),
+	})
+}
+
+func (q *Queries) SetTeamRoundRobinCursor(ctx context.Context, arg database.SetTeamRoundRobinCursorParams) error {
+	return q.q.SetTeamRoundRobinCursor(ctx, sqlitedb.SetTeamRoundRobinCursorParams{
+		ID:               arg.ID,
+		RrLastReviewerID: arg.RrLastReviewerID,
+	})
+}
+
+func (q *Queries) UpsertUser(ctx context.Context, arg database.UpsertUserParams) error {
+	return q.q.UpsertUser(ctx, sqlitedb.UpsertUserParams{
+		ID:             arg.ID,
+		Username:       arg.Username,
+		IsAvailable:    arg.IsAvailable,
+		TeamID:         arg.TeamID,
+		ReviewWeight:   int64(arg.ReviewWeight),
+		MaxOpenReviews: nullInt64(arg.MaxOpenReviews),
+		GithubLogin:    arg.GithubLogin,
+		GitlabUsername: arg.GitlabUsername,
+		Role:           arg.Role,
+	})
+}
+
+func (q *Queries) GetUsersByTeam(ctx context.Context, teamID string) ([]database.GetUsersByTeamRow, error) {
+	rows, err := q.q.GetUsersByTeam(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	users := make([]database.GetUsersByTeamRow, 0, len(rows))
+	for _, row := range rows {
+		users = append(users, database.GetUsersByTeamRow{
+			ID:             row.ID,
+			Username:       row.Username,
+			IsAvailable:    row.IsAvailable,
+			TeamID:         row.TeamID,
+			ReviewWeight:   int32(row.ReviewWeight),
+			MaxOpenReviews: nullInt32(row.MaxOpenReviews),
+			GithubLogin:    row.GithubLogin,
+			GitlabUsername: row.GitlabUsername,
+			Role:           row.Role,
+			OpenReviews:    row.OpenReviews,
+		})
+	}
+	return users, nil
+}
+
+func (q *Queries) GetTeamFallbacks(ctx context.Context, teamID string) ([]database.Team, error) {
+	rows, err := q.q.GetTeamFallbacks(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	teams := make([]database.Team, 0, len(rows))
+	for _, row := range rows {
+		teams = append(teams, toTeam(row))
+	}
+	return teams, nil
+}
+
+func (q *Queries) DeleteTeamFallbacks(ctx context.Context, teamID string) error {
+	return q.q.DeleteTeamFallbacks(ctx, teamID)
+}
+
+func (q *Queries) AddTeamFallback(ctx context.Context, arg database.AddTeamFallbackParams) error {
+	return q.q.AddTeamFallback(ctx, sqlitedb.AddTeamFallbackParams{
+		TeamID:         arg.TeamID,
+		FallbackTeamID: arg.FallbackTeamID,
+		Priority:       int64(arg.Priority),
+	})
+}
@@ -0,0 +1,44 @@
+package sqlite
+
+import (
+	"context"
+
+	"github.com/LlirikP/pr_dispenser/internal/database"
+	"github.com/LlirikP/pr_dispenser/internal/database/sqlite/sqlitedb"
+)
+
+func (q *Queries) CreateAPIToken(ctx context.Context, arg database.CreateAPITokenParams) error {
+	return q.q.CreateAPIToken(ctx, sqlitedb.CreateAPITokenParams(arg))
+}
+
+func (q *Queries) GetAPITokenByHash(ctx context.Context, tokenHash string) (database.ApiToken, error) {
+	token, err := q.q.GetAPITokenByHash(ctx, tokenHash)
+	return database.ApiToken(token), err
+}
+
+func (q *Queries) FindAPITokenByHash(ctx context.Context, tokenHash string) (database.ApiToken, error) {
+	token, err := q.q.FindAPITokenByHash(ctx, tokenHash)
+	return database.ApiToken(token), err
+}
+
+func (q *Queries) GetAPITokenByID(ctx context.Context, id string) (database.ApiToken, error) {
+	token, err := q.q.GetAPITokenByID(ctx, id)
+	return database.ApiToken(token), err
+}
+
+func (q *Queries) ListAPITokens(ctx context.Context) ([]database.ApiToken, error) {
+	rows, err := q.q.ListAPITokens(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := make([]database.ApiToken, 0, len(rows))
+	for _, row := range rows {
+		tokens = append(tokens, database.ApiToken(row))
+	}
+	return tokens, nil
+}
+
+func (q *Queries) RevokeAPIToken(ctx context.Context, id string) (int64, error) {
+	return q.q.RevokeAPIToken(ctx, id)
+}
@@ -0,0 +1,107 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/LlirikP/pr_dispenser/internal/database"
+	"github.com/LlirikP/pr_dispenser/internal/database/sqlite/sqlitedb"
+)
+
+func toUser(u sqlitedb.User, err error) (database.User, error) {
+	if err != nil {
+		return database.User{}, err
+	}
+
+	return database.User{
+		ID:             u.ID,
+		Username:       u.Username,
+		IsAvailable:    u.IsAvailable,
+		TeamID:         u.TeamID,
+		ReviewWeight:   int32(u.ReviewWeight),
+		MaxOpenReviews: nullInt32(u.MaxOpenReviews),
+		GithubLogin:    u.GithubLogin,
+		GitlabUsername: u.GitlabUsername,
+		Role:           u.Role,
+	}, nil
+}
+
+func (q *Queries) GetUserById(ctx context.Context, id string) (database.User, error) {
+	return toUser(q.q.GetUserById(ctx, id))
+}
+
+func (q *Queries) GetUserByGithubLogin(ctx context.Context, githubLogin sql.NullString) (database.User, error) {
+	return toUser(q.q.GetUserByGithubLogin(ctx, githubLogin))
+}
+
+func (q *Queries) GetUserByGitlabUsername(ctx context.Context, gitlabUsername sql.NullString) (database.User, error) {
+	return toUser(q.q.GetUserByGitlabUsername(ctx, gitlabUsername))
+}
+
+func (q *Queries) GetUserOpenReviews(ctx context.Context, userID string) (int64, error) {
+	return q.q.GetUserOpenReviews(ctx, userID)
+}
+
+func (q *Queries) GetUserWeights(ctx context.Context, ids []string) ([]database.GetUserWeightsRow, error) {
+	rows, err := q.q.GetUserWeights(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	weights := make([]database.GetUserWeightsRow, 0, len(rows))
+	for _, row := range rows {
+		weights = append(weights, database.GetUserWeightsRow{
+			ID:           row.ID,
+			ReviewWeight: int32(row.ReviewWeight),
+		})
+	}
+	return weights, nil
+}
+
+func (q *Queries) SetUserAvailability(ctx context.Context, arg database.SetUserAvailabilityParams) error {
+	return q.q.SetUserAvailability(ctx, sqlitedb.SetUserAvailabilityParams{
+		ID:          arg.ID,
+		IsAvailable: arg.IsAvailable,
+	})
+}
+
+func (q *Queries) SetUserMaxOpenReviews(ctx context.Context, arg database.SetUserMaxOpenReviewsParams) error {
+	return q.q.SetUserMaxOpenReviews(ctx, sqlitedb.SetUserMaxOpenReviewsParams{
+		ID:             arg.ID,
+		MaxOpenReviews: nullInt64(arg.MaxOpenReviews),
+	})
+}
+
+func (q *Queries) SetUserRole(ctx context.Context, arg database.SetUserRoleParams) error {
+	return q.q.SetUserRole(ctx, sqlitedb.SetUserRoleParams{
+		ID:   arg.ID,
+		Role: arg.Role,
+	})
+}
+
+func (q *Queries) GetReviewPRs(ctx context.Context, reviewerID string) ([]database.GetReviewPRsRow, error) {
+	rows, err := q.q.GetReviewPRs(ctx, reviewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	prs := make([]database.GetReviewPRsRow, 0, len(rows))
+	for _, row := range rows {
+		prs = append(prs, database.GetReviewPRsRow{
+			PrID:        row.PrID,
+			PrTitle:     row.PrTitle,
+			AuthorID:    row.AuthorID,
+			Status:      row.Status,
+			ReviewState: row.ReviewState,
+			ReviewedAt:  row.ReviewedAt,
+		})
+	}
+	return prs, nil
+}
+
+func (q *Queries) SetUsersAvailability(ctx context.Context, arg database.SetUsersAvailabilityParams) error {
+	return q.q.SetUsersAvailability(ctx, sqlitedb.SetUsersAvailabilityParams{
+		IsAvailable: arg.IsAvailable,
+		Ids:         arg.Ids,
+	})
+}
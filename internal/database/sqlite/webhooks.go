@@ -0,0 +1,193 @@
+package sqlite
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/LlirikP/pr_dispenser/internal/database"
+	"github.com/LlirikP/pr_dispenser/internal/database/sqlite/sqlitedb"
+)
+
+func toSubscriptions(rows []sqlitedb.WebhookSubscription, err error) ([]database.WebhookSubscription, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	subs := make([]database.WebhookSubscription, 0, len(rows))
+	for _, row := range rows {
+		events, err := decodeList(row.Events)
+		if err != nil {
+			return nil, err
+		}
+
+		subs = append(subs, database.WebhookSubscription{
+			ID:        row.ID,
+			Url:       row.Url,
+			Events:    events,
+			Secret:    row.Secret,
+			CreatedAt: row.CreatedAt,
+		})
+	}
+	return subs, nil
+}
+
+func (q *Queries) CreateWebhookSubscription(ctx context.Context, arg database.CreateWebhookSubscriptionParams) error {
+	events, err := encodeList(arg.Events)
+	if err != nil {
+		return err
+	}
+
+	return q.q.CreateWebhookSubscription(ctx, sqlitedb.CreateWebhookSubscriptionParams{
+		ID:     arg.ID,
+		Url:    arg.Url,
+		Events: events,
+		Secret: arg.Secret,
+	})
+}
+
+func (q *Queries) ListWebhookSubscriptions(ctx context.Context) ([]database.WebhookSubscription, error) {
+	return toSubscriptions(q.q.ListWebhookSubscriptions(ctx))
+}
+
+func (q *Queries) GetWebhookSubscriptionByID(ctx context.Context, id string) (database.WebhookSubscription, error) {
+	sub, err := q.q.GetWebhookSubscriptionByID(ctx, id)
+	if err != nil {
+		return database.WebhookSubscription{}, err
+	}
+
+	subs, err := toSubscriptions([]sqlitedb.WebhookSubscription{sub}, nil)
+	if err != nil {
+		return database.WebhookSubscription{}, err
+	}
+	return subs[0], nil
+}
+
+func (q *Queries) DeleteWebhookSubscription(ctx context.Context, id string) (int64, error) {
+	return q.q.DeleteWebhookSubscription(ctx, id)
+}
+
+func (q *Queries) GetSubscriptionsForEvent(ctx context.Context, eventType string) ([]database.WebhookSubscription, error) {
+	return toSubscriptions(q.q.GetSubscriptionsForEvent(ctx, eventType))
+}
+
+func (q *Queries) CreateWebhookDelivery(ctx context.Context, arg database.CreateWebhookDeliveryParams) error {
+	return q.q.CreateWebhookDelivery(ctx, sqlitedb.CreateWebhookDeliveryParams{
+		SubscriptionID: arg.SubscriptionID,
+		EventID:        arg.EventID,
+		EventType:      arg.EventType,
+		PrID:           arg.PrID,
+		Payload:        string(arg.Payload),
+	})
+}
+
+func (q *Queries) ClaimDueWebhookDeliveries(ctx context.Context, arg database.ClaimDueWebhookDeliveriesParams) ([]database.ClaimDueWebhookDeliveriesRow, error) {
+	rows, err := q.q.ClaimDueWebhookDeliveries(ctx, sqlitedb.ClaimDueWebhookDeliveriesParams{
+		LeaseUntil: utc(arg.LeaseUntil),
+		BatchSize:  int64(arg.BatchSize),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	subs := map[string]sqlitedb.WebhookSubscription{}
+	claimed := make([]database.ClaimDueWebhookDeliveriesRow, 0, len(rows))
+
+	for _, row := range rows {
+		sub, ok := subs[row.SubscriptionID]
+		if !ok {
+			sub, err = q.q.GetWebhookSubscriptionByID(ctx, row.SubscriptionID)
+			if err != nil {
+				return nil, err
+			}
+			subs[row.SubscriptionID] = sub
+		}
+
+		claimed = append(claimed, database.ClaimDueWebhookDeliveriesRow{
+			ID:        row.ID,
+			EventID:   row.EventID,
+			EventType: row.EventType,
+			PrID:      row.PrID,
+			Payload:   json.RawMessage(row.Payload),
+			Attempts:  int32(row.Attempts),
+			Url:       sub.Url,
+			Secret:    sub.Secret,
+		})
+	}
+
+	return claimed, nil
+}
+
+func (q *Queries) MarkWebhookDelivered(ctx context.Context, arg database.MarkWebhookDeliveredParams) error {
+	return q.q.MarkWebhookDelivered(ctx, sqlitedb.MarkWebhookDeliveredParams{
+		ID:             arg.ID,
+		ResponseStatus: nullInt64(arg.ResponseStatus),
+	})
+}
+
+func (q *Queries) MarkWebhookAttemptFailed(ctx context.Context, arg database.MarkWebhookAttemptFailedParams) error {
+	return q.q.MarkWebhookAttemptFailed(ctx, sqlitedb.MarkWebhookAttemptFailedParams{
+		ID:             arg.ID,
+		Status:         arg.Status,
+		ResponseStatus: nullInt64(arg.ResponseStatus),
+		LastError:      arg.LastError,
+		NextAttemptAt:  utc(arg.NextAttemptAt),
+	})
+}
+
+func (q *Queries) ListWebhookDeliveries(ctx context.Context, arg database.ListWebhookDeliveriesParams) ([]database.ListWebhookDeliveriesRow, error) {
+	rows, err := q.q.ListWebhookDeliveries(ctx, sqlitedb.ListWebhookDeliveriesParams{
+		SubscriptionID: arg.SubscriptionID,
+		Status:         arg.Status,
+		Limit:          int64(arg.Limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := make([]database.ListWebhookDeliveriesRow, 0, len(rows))
+	for _, row := range rows {
+		deliveries = append(deliveries, database.ListWebhookDeliveriesRow{
+			ID:             row.ID,
+			SubscriptionID: row.SubscriptionID,
+			EventID:        row.EventID,
+			EventType:      row.EventType,
+			PrID:           row.PrID,
+			Payload:        json.RawMessage(row.Payload),
+			Status:         row.Status,
+			Attempts:       int32(row.Attempts),
+			ResponseStatus: nullInt32(row.ResponseStatus),
+			LastError:      row.LastError,
+			NextAttemptAt:  row.NextAttemptAt,
+			CreatedAt:      row.CreatedAt,
+			DeliveredAt:    row.DeliveredAt,
+		})
+	}
+	return deliveries, nil
+}
+
+func (q *Queries) GetWebhookDeliveryByID(ctx context.Context, id int64) (database.WebhookDelivery, error) {
+	row, err := q.q.GetWebhookDeliveryByID(ctx, id)
+	if err != nil {
+		return database.WebhookDelivery{}, err
+	}
+
+	return database.WebhookDelivery{
+		ID:             row.ID,
+		SubscriptionID: row.SubscriptionID,
+		EventID:        row.EventID,
+		EventType:      row.EventType,
+		PrID:           row.PrID,
+		Payload:        json.RawMessage(row.Payload),
+		Status:         row.Status,
+		Attempts:       int32(row.Attempts),
+		ResponseStatus: nullInt32(row.ResponseStatus),
+		LastError:      row.LastError,
+		NextAttemptAt:  row.NextAttemptAt,
+		CreatedAt:      row.CreatedAt,
+		DeliveredAt:    row.DeliveredAt,
+	}, nil
+}
+
+func (q *Queries) RequeueWebhookDelivery(ctx context.Context, id int64) (int64, error) {
+	return q.q.RequeueWebhookDelivery(ctx, id)
+}
@@ -0,0 +1,92 @@
+package storagetest
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/LlirikP/pr_dispenser/internal/database"
+)
+
+var auditCases = []testCase{
+	{"AuditLog", testAuditLog},
+}
+
+func testAuditLog(t *testing.T, s database.Storage) {
+	ctx := context.Background()
+	start := time.Now().Add(-time.Minute)
+
+	insert := func(actor, action, targetID, requestID string) {
+		t.Helper()
+		requireNoError(t, s.InsertAuditEntry(ctx, database.InsertAuditEntryParams{
+			Actor:      actor,
+			Action:     action,
+			TargetType: "user",
+			TargetID:   targetID,
+			Before:     json.RawMessage(`null`),
+			After:      json.RawMessage(`{"id":"` + targetID + `"}`),
+			RequestID:  requestID,
+		}))
+	}
+
+	insert("u1", "user.upserted", "u2", "req-1")
+	insert("u1", "user.active_set", "u2", "req-2")
+	insert("token:abc", "user.upserted", "u3", "req-3")
+
+	list := func(arg database.ListAuditEntriesParams) []database.AuditLog {
+		t.Helper()
+		if arg.MaxRows == 0 {
+			arg.MaxRows = 10
+		}
+		entries, err := s.ListAuditEntries(ctx, arg)
+		requireNoError(t, err)
+		return entries
+	}
+
+	all := list(database.ListAuditEntriesParams{})
+	if len(all) != 3 {
+		t.Fatalf("entries = %+v, want 3", all)
+	}
+	if all[0].RequestID != "req-3" || all[2].RequestID != "req-1" {
+		t.Fatalf("entries are not newest first: %+v", all)
+	}
+	if all[0].ID <= all[1].ID || all[1].ID <= all[2].ID {
+		t.Fatalf("ids are not descending: %d %d %d", all[0].ID, all[1].ID, all[2].ID)
+	}
+	if !jsonEqual(t, all[0].After, `{"id":"u3"}`) || all[0].Actor != "token:abc" || all[0].CreatedAt.IsZero() {
+		t.Fatalf("entry = %+v", all[0])
+	}
+
+	tests := []struct {
+		name string
+		arg  database.ListAuditEntriesParams
+		want []string
+	}{
+		{"actor", database.ListAuditEntriesParams{Actor: sql.NullString{String: "u1", Valid: true}}, []string{"req-2", "req-1"}},
+		{"action", database.ListAuditEntriesParams{Action: sql.NullString{String: "user.upserted", Valid: true}}, []string{"req-3", "req-1"}},
+		{"target", database.ListAuditEntriesParams{
+			TargetType: sql.NullString{String: "user", Valid: true},
+			TargetID:   sql.NullString{String: "u2", Valid: true},
+		}, []string{"req-2", "req-1"}},
+		{"request", database.ListAuditEntriesParams{RequestID: sql.NullString{String: "req-2", Valid: true}}, []string{"req-2"}},
+		{"since", database.ListAuditEntriesParams{Since: sql.NullTime{Time: start, Valid: true}}, []string{"req-3", "req-2", "req-1"}},
+		{"until", database.ListAuditEntriesParams{Until: sql.NullTime{Time: start, Valid: true}}, nil},
+		{"before", database.ListAuditEntriesParams{BeforeID: sql.NullInt64{Int64: all[0].ID, Valid: true}}, []string{"req-2", "req-1"}},
+		{"limit", database.ListAuditEntriesParams{MaxRows: 1}, []string{"req-3"}},
+	}
+
+	for _, tt := range tests {
+		entries := list(tt.arg)
+
+		var got []string
+		for _, e := range entries {
+			got = append(got, e.RequestID)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Fatalf("%s: request ids = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
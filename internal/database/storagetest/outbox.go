@@ -0,0 +1,247 @@
+package storagetest
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/LlirikP/pr_dispenser/internal/database"
+)
+
+var outboxCases = []testCase{
+	{"OutboxClaimOrdering", testOutboxClaimOrdering},
+	{"OutboxStreamSequence", testOutboxStreamSequence},
+}
+
+func mustOutboxEvent(t *testing.T, s database.Querier, eventID, prID string, userIDs ...string) {
+	t.Helper()
+
+	if userIDs == nil {
+		userIDs = []string{}
+	}
+
+	err := s.InsertOutboxEvent(context.Background(), database.InsertOutboxEventParams{
+		EventID:   eventID,
+		EventType: "pr.created",
+		PrID:      prID,
+		UserIds:   userIDs,
+		Payload:   json.RawMessage(`{"event_id":"` + eventID + `"}`),
+	})
+	if err != nil {
+		t.Fatalf("insert outbox event %s: %v", eventID, err)
+	}
+}
+
+func claimOutbox(t *testing.T, s database.Storage) (database.Outbox, bool) {
+	t.Helper()
+
+	var (
+		entry database.Outbox
+		found bool
+	)
+
+	err := s.ExecTx(context.Background(), func(q database.Querier) error {
+		var err error
+		entry, err = q.ClaimNextOutboxEvent(context.Background())
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		found = err == nil
+		return err
+	})
+	requireNoError(t, err)
+
+	return entry, found
+}
+
+func sequenceOutbox(t *testing.T, s database.Storage) int64 {
+	t.Helper()
+
+	var sequenced int64
+	err := s.ExecTx(context.Background(), func(q database.Querier) error {
+		if err := q.LockOutboxSequencer(context.Background()); err != nil {
+			return err
+		}
+
+		var err error
+		sequenced, err = q.SequenceOutboxEvents(context.Background())
+		return err
+	})
+	requireNoError(t, err)
+
+	return sequenced
+}
+
+func testOutboxClaimOrdering(t *testing.T, s database.Storage) {
+	ctx := context.Background()
+
+	if _, ok := claimOutbox(t, s); ok {
+		t.Fatalf("claimed from an empty outbox")
+	}
+
+	mustOutboxEvent(t, s, "e1", "pr-1", "u1", "u2")
+	mustOutboxEvent(t, s, "e2", "pr-1")
+	mustOutboxEvent(t, s, "e3", "pr-2")
+
+	entry, ok := claimOutbox(t, s)
+	if !ok || entry.EventID != "e1" {
+		t.Fatalf("claimed %+v, want e1", entry)
+	}
+	if entry.EventType != "pr.created" || entry.PrID != "pr-1" || entry.Status != "PENDING" || entry.Attempts != 0 {
+		t.Fatalf("entry = %+v", entry)
+	}
+	if !slices.Equal(entry.UserIds, []string{"u1", "u2"}) || !jsonEqual(t, entry.Payload, `{"event_id":"e1"}`) {
+		t.Fatalf("entry = %+v", entry)
+	}
+	if entry.StreamSeq.Valid {
+		t.Fatalf("unsequenced entry has stream_seq %d", entry.StreamSeq.Int64)
+	}
+
+	requireNoError(t, s.MarkOutboxDispatched(ctx, entry.ID))
+
+	e2, ok := claimOutbox(t, s)
+	if !ok || e2.EventID != "e2" {
+		t.Fatalf("claimed %+v, want e2", e2)
+	}
+
+	requireNoError(t, s.MarkOutboxAttemptFailed(ctx, database.MarkOutboxAttemptFailedParams{
+		Status:        "PENDING",
+		LastError:     sql.NullString{String: "boom", Valid: true},
+		NextAttemptAt: time.Now().Add(time.Hour),
+		ID:            e2.ID,
+	}))
+
+	entry, ok = claimOutbox(t, s)
+	if !ok || entry.EventID != "e3" {
+		t.Fatalf("claimed %+v, want e3 while e2 backs off", entry)
+	}
+	requireNoError(t, s.MarkOutboxDispatched(ctx, entry.ID))
+
+	mustOutboxEvent(t, s, "e4", "pr-1")
+
+	if entry, ok := claimOutbox(t, s); ok {
+		t.Fatalf("claimed %+v, want nothing while e2 blocks pr-1", entry)
+	}
+
+	requireNoError(t, s.MarkOutboxAttemptFailed(ctx, database.MarkOutboxAttemptFailedParams{
+		Status:        "DEAD",
+		LastError:     sql.NullString{String: "boom again", Valid: true},
+		NextAttemptAt: time.Now(),
+		ID:            e2.ID,
+	}))
+
+	entry, ok = claimOutbox(t, s)
+	if !ok || entry.EventID != "e4" {
+		t.Fatalf("claimed %+v, want e4 after e2 is dead", entry)
+	}
+}
+
+func streamSeqs(t *testing.T, s database.Storage, arg database.GetStreamEventsAfterParams) ([]int64, []string) {
+	t.Helper()
+
+	if arg.BatchSize == 0 {
+		arg.BatchSize = 100
+	}
+
+	rows, err := s.GetStreamEventsAfter(context.Background(), arg)
+	requireNoError(t, err)
+
+	var (
+		seqs   []int64
+		events []string
+	)
+	for _, row := range rows {
+		payload := struct {
+			EventID string `json:"event_id"`
+		}{}
+		if err := json.Unmarshal(row.Payload, &payload); err != nil {
+			t.Fatalf("decode payload %s: %v", row.Payload, err)
+		}
+		if row.EventType != "pr.created" {
+			t.Fatalf("event type = %q", row.EventType)
+		}
+		seqs = append(seqs, row.Seq)
+		events = append(events, payload.EventID)
+	}
+	return seqs, events
+}
+
+func testOutboxStreamSequence(t *testing.T, s database.Storage) {
+	ctx := context.Background()
+	mustTeam(t, s, "t1", 1)
+	mustTeam(t, s, "t2", 1)
+	mustUser(t, s, "u1", "t1")
+	mustUser(t, s, "u2", "t1")
+	mustUser(t, s, "u3", "t2")
+
+	latest, err := s.GetLatestStreamSeq(ctx)
+	requireNoError(t, err)
+	if latest != 0 {
+		t.Fatalf("latest seq of an empty outbox = %d, want 0", latest)
+	}
+	if n := sequenceOutbox(t, s); n != 0 {
+		t.Fatalf("sequenced %d events in an empty outbox", n)
+	}
+
+	mustOutboxEvent(t, s, "e1", "pr-1", "u1")
+	mustOutboxEvent(t, s, "e2", "pr-2", "u2", "u3")
+	mustOutboxEvent(t, s, "e3", "pr-1", "u3")
+
+	if seqs, _ := streamSeqs(t, s, database.GetStreamEventsAfterParams{}); len(seqs) != 0 {
+		t.Fatalf("unsequenced events are visible in the stream: %v", seqs)
+	}
+
+	if n := sequenceOutbox(t, s); n != 3 {
+		t.Fatalf("sequenced %d events, want 3", n)
+	}
+	if n := sequenceOutbox(t, s); n != 0 {
+		t.Fatalf("sequenced %d events twice", n)
+	}
+
+	seqs, events := streamSeqs(t, s, database.GetStreamEventsAfterParams{})
+	if !slices.Equal(events, []string{"e1", "e2", "e3"}) {
+		t.Fatalf("stream = %v, want insertion order", events)
+	}
+	if !slices.IsSorted(seqs) || seqs[0] <= 0 || seqs[0] == seqs[1] || seqs[1] == seqs[2] {
+		t.Fatalf("stream seqs = %v, want strictly increasing", seqs)
+	}
+
+	latest, err = s.GetLatestStreamSeq(ctx)
+	requireNoError(t, err)
+	if latest != seqs[2] {
+		t.Fatalf("latest seq = %d, want %d", latest, seqs[2])
+	}
+
+	mustOutboxEvent(t, s, "e4", "pr-3", "u1")
+	if n := sequenceOutbox(t, s); n != 1 {
+		t.Fatalf("sequenced %d events, want 1", n)
+	}
+
+	after, events := streamSeqs(t, s, database.GetStreamEventsAfterParams{AfterSeq: latest})
+	if !slices.Equal(events, []string{"e4"}) || after[0] <= latest {
+		t.Fatalf("stream after %d = %v %v, want e4 with a higher seq", latest, after, events)
+	}
+
+	tests := []struct {
+		name string
+		arg  database.GetStreamEventsAfterParams
+		want []string
+	}{
+		{"after", database.GetStreamEventsAfterParams{AfterSeq: seqs[0]}, []string{"e2", "e3", "e4"}},
+		{"batch", database.GetStreamEventsAfterParams{BatchSize: 2}, []string{"e1", "e2"}},
+		{"pr", database.GetStreamEventsAfterParams{PrID: sql.NullString{String: "pr-1", Valid: true}}, []string{"e1", "e3"}},
+		{"user", database.GetStreamEventsAfterParams{UserID: sql.NullString{String: "u3", Valid: true}}, []string{"e2", "e3"}},
+		{"team", database.GetStreamEventsAfterParams{TeamID: sql.NullString{String: "t1", Valid: true}}, []string{"e1", "e2", "e4"}},
+		{"team without members", database.GetStreamEventsAfterParams{TeamID: sql.NullString{String: "missing", Valid: true}}, nil},
+	}
+
+	for _, tt := range tests {
+		if _, got := streamSeqs(t, s, tt.arg); !slices.Equal(got, tt.want) {
+			t.Fatalf("%s: stream = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
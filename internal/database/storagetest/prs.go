@@ -0,0 +1,318 @@
+package storagetest
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"testing"
+
+	"github.com/LlirikP/pr_dispenser/internal/database"
+)
+
+var prCases = []testCase{
+	{"PRLifecycle", testPRLifecycle},
+	{"PRDuplicateCheck", testPRDuplicateCheck},
+	{"Reviewers", testReviewers},
+	{"Workload", testWorkload},
+	{"TeamCandidates", testTeamCandidates},
+	{"ReviewStates", testReviewStates},
+}
+
+func testPRLifecycle(t *testing.T, s database.Storage) {
+	ctx := context.Background()
+	mustTeam(t, s, "t1", 1)
+	mustUser(t, s, "u1", "t1")
+
+	err := s.CreatePR(ctx, database.CreatePRParams{
+		ID:                "pr-1",
+		Title:             "change",
+		AuthorID:          "u1",
+		ReviewersRequired: 2,
+		IsDraft:           true,
+	})
+	requireNoError(t, err)
+
+	pr, err := s.GetPRById(ctx, "pr-1")
+	requireNoError(t, err)
+	if pr.Status != "OPEN" || !pr.IsDraft || pr.ReviewersRequired != 2 || pr.AuthorID != "u1" || pr.Title != "change" {
+		t.Fatalf("pr = %+v", pr)
+	}
+	if pr.CreatedAt.IsZero() || pr.MergedAt.Valid || pr.ClosedAt.Valid || pr.ForceMerged {
+		t.Fatalf("pr = %+v", pr)
+	}
+
+	_, err = s.GetPRById(ctx, "missing")
+	requireNoRows(t, err)
+
+	requireNoError(t, s.MarkPRReady(ctx, "pr-1"))
+	requireNoError(t, s.ClosePR(ctx, "pr-1"))
+
+	pr, err = s.GetPRById(ctx, "pr-1")
+	requireNoError(t, err)
+	if pr.Status != "CLOSED" || !pr.ClosedAt.Valid || pr.IsDraft {
+		t.Fatalf("closed pr = %+v", pr)
+	}
+
+	requireNoError(t, s.ReopenPR(ctx, "pr-1"))
+
+	pr, err = s.GetPRById(ctx, "pr-1")
+	requireNoError(t, err)
+	if pr.Status != "OPEN" || pr.ClosedAt.Valid {
+		t.Fatalf("reopened pr = %+v", pr)
+	}
+
+	requireNoError(t, s.MergePR(ctx, database.MergePRParams{
+		ID:            "pr-1",
+		ForceMerged:   true,
+		ForceMergedBy: sql.NullString{String: "u1", Valid: true},
+	}))
+
+	pr, err = s.GetPRById(ctx, "pr-1")
+	requireNoError(t, err)
+	if pr.Status != "MERGED" || !pr.MergedAt.Valid || !pr.ForceMerged || pr.ForceMergedBy.String != "u1" {
+		t.Fatalf("merged pr = %+v", pr)
+	}
+	if pr.MergedAt.Time.Before(pr.CreatedAt) {
+		t.Fatalf("merged_at %v is before created_at %v", pr.MergedAt.Time, pr.CreatedAt)
+	}
+}
+
+func testPRDuplicateCheck(t *testing.T, s database.Storage) {
+	ctx := context.Background()
+	mustTeam(t, s, "t1", 1)
+	mustUser(t, s, "u1", "t1")
+	mustPR(t, s, "pr-1", "u1")
+
+	id, err := s.CheckDuplicatePR(ctx, database.CheckDuplicatePRParams{AuthorID: "u1", Title: "pr-1 title"})
+	requireNoError(t, err)
+	if id != "pr-1" {
+		t.Fatalf("duplicate = %q, want pr-1", id)
+	}
+
+	_, err = s.CheckDuplicatePR(ctx, database.CheckDuplicatePRParams{AuthorID: "u1", Title: "other"})
+	requireNoRows(t, err)
+
+	requireNoError(t, s.ClosePR(ctx, "pr-1"))
+
+	_, err = s.CheckDuplicatePR(ctx, database.CheckDuplicatePRParams{AuthorID: "u1", Title: "pr-1 title"})
+	requireNoRows(t, err)
+}
+
+func testReviewers(t *testing.T, s database.Storage) {
+	ctx := context.Background()
+	mustTeam(t, s, "t1", 5)
+	mustUser(t, s, "u1", "t1")
+	mustUser(t, s, "u2", "t1")
+	mustUser(t, s, "u3", "t1")
+	mustPR(t, s, "pr-1", "u1", "u2", "u3")
+
+	requireNoError(t, s.AddReviewer(ctx, database.AddReviewerParams{PrID: "pr-1", ReviewerID: "u2"}))
+
+	ids, err := s.GetReviewersByPR(ctx, "pr-1")
+	requireNoError(t, err)
+	slices.Sort(ids)
+	if !slices.Equal(ids, []string{"u2", "u3"}) {
+		t.Fatalf("reviewers = %v, want [u2 u3]", ids)
+	}
+
+	assigned, err := s.IsReviewerAssigned(ctx, database.IsReviewerAssignedParams{PrID: "pr-1", ReviewerID: "u2"})
+	requireNoError(t, err)
+	if !assigned {
+		t.Fatalf("u2 is not assigned")
+	}
+
+	requireNoError(t, s.DeleteReviewer(ctx, database.DeleteReviewerParams{PrID: "pr-1", ReviewerID: "u2"}))
+
+	assigned, err = s.IsReviewerAssigned(ctx, database.IsReviewerAssignedParams{PrID: "pr-1", ReviewerID: "u2"})
+	requireNoError(t, err)
+	if assigned {
+		t.Fatalf("u2 is still assigned")
+	}
+
+	mustPR(t, s, "pr-2", "u2", "u1")
+	mustPR(t, s, "pr-3", "u3", "u1")
+
+	rows, err := s.GetReviewersByPRs(ctx, []string{"pr-1", "pr-2", "missing"})
+	requireNoError(t, err)
+	want := []database.GetReviewersByPRsRow{{PrID: "pr-1", ReviewerID: "u3"}, {PrID: "pr-2", ReviewerID: "u1"}}
+	if !slices.Equal(rows, want) {
+		t.Fatalf("reviewers by prs = %+v, want %+v", rows, want)
+	}
+
+	byID, err := s.GetPRsByIDs(ctx, []string{"pr-3", "pr-1", "missing"})
+	requireNoError(t, err)
+	if len(byID) != 2 || byID[0].ID != "pr-1" || byID[1].ID != "pr-3" || byID[1].AuthorID != "u3" || byID[0].Title != "pr-1 title" {
+		t.Fatalf("prs by ids = %+v", byID)
+	}
+
+	prs, err := s.GetReviewPRs(ctx, "u3")
+	requireNoError(t, err)
+	if len(prs) != 1 || prs[0].PrID != "pr-1" || prs[0].PrTitle != "pr-1 title" || prs[0].AuthorID != "u1" || prs[0].ReviewState != "PENDING" {
+		t.Fatalf("review prs = %+v", prs)
+	}
+}
+
+func testWorkload(t *testing.T, s database.Storage) {
+	ctx := context.Background()
+	mustTeam(t, s, "t1", 2)
+	mustUser(t, s, "u1", "t1")
+	mustUser(t, s, "u2", "t1")
+	mustUser(t, s, "u3", "t1")
+	mustUser(t, s, "u4", "t1")
+
+	mustPR(t, s, "pr-1", "u1", "u2", "u3")
+	mustPR(t, s, "pr-2", "u1", "u2")
+	mustPR(t, s, "pr-3", "u1", "u3")
+	requireNoError(t, s.MergePR(ctx, database.MergePRParams{ID: "pr-3"}))
+
+	counts, err := s.CountOpenReviews(ctx, []string{"u2", "u3", "u4"})
+	requireNoError(t, err)
+
+	got := map[string]int64{}
+	for _, c := range counts {
+		got[c.ReviewerID] = c.OpenReviews
+	}
+	if got["u2"] != 2 || got["u3"] != 1 || got["u4"] != 0 {
+		t.Fatalf("open reviews = %v", got)
+	}
+
+	open, err := s.GetUserOpenReviews(ctx, "u2")
+	requireNoError(t, err)
+	if open != 2 {
+		t.Fatalf("u2 open reviews = %d, want 2", open)
+	}
+
+	rows, err := s.GetOpenReviewsByReviewers(ctx, []string{"u2", "u3"})
+	requireNoError(t, err)
+	want := []database.GetOpenReviewsByReviewersRow{
+		{PrID: "pr-1", ReviewerID: "u2", AuthorID: "u1"},
+		{PrID: "pr-1", ReviewerID: "u3", AuthorID: "u1"},
+		{PrID: "pr-2", ReviewerID: "u2", AuthorID: "u1"},
+	}
+	if !slices.Equal(rows, want) {
+		t.Fatalf("open reviews by reviewers = %+v, want %+v", rows, want)
+	}
+
+	requireNoError(t, s.SetUserAvailability(ctx, database.SetUserAvailabilityParams{ID: "u4", IsAvailable: false}))
+
+	candidates, err := s.GetAvailableTeamMembersExceptAuthor(ctx, database.GetAvailableTeamMembersExceptAuthorParams{TeamID: "t1", ID: "u1"})
+	requireNoError(t, err)
+	if !slices.Equal(candidates, []string{"u3"}) {
+		t.Fatalf("candidates = %v, want [u3]", candidates)
+	}
+
+	requireNoError(t, s.SetUserMaxOpenReviews(ctx, database.SetUserMaxOpenReviewsParams{
+		ID:             "u2",
+		MaxOpenReviews: sql.NullInt32{Int32: 3, Valid: true},
+	}))
+
+	candidates, err = s.GetAvailableTeamMembersExceptAuthor(ctx, database.GetAvailableTeamMembersExceptAuthorParams{TeamID: "t1", ID: "u1"})
+	requireNoError(t, err)
+	slices.Sort(candidates)
+	if !slices.Equal(candidates, []string{"u2", "u3"}) {
+		t.Fatalf("candidates = %v, want [u2 u3]", candidates)
+	}
+
+	requireNoError(t, s.SetTeamMaxOpenReviews(ctx, database.SetTeamMaxOpenReviewsParams{ID: "t1", MaxOpenReviews: 1}))
+
+	ineligible, err := s.GetIneligibleReviewersByPR(ctx, "pr-1")
+	requireNoError(t, err)
+	if len(ineligible) != 0 {
+		t.Fatalf("ineligible = %+v, want none", ineligible)
+	}
+
+	requireNoError(t, s.SetUserMaxOpenReviews(ctx, database.SetUserMaxOpenReviewsParams{ID: "u2"}))
+	requireNoError(t, s.SetUserAvailability(ctx, database.SetUserAvailabilityParams{ID: "u3", IsAvailable: false}))
+
+	ineligible, err = s.GetIneligibleReviewersByPR(ctx, "pr-1")
+	requireNoError(t, err)
+	wantIneligible := []database.GetIneligibleReviewersByPRRow{
+		{ID: "u2", TeamID: "t1"},
+		{ID: "u3", TeamID: "t1"},
+	}
+	if !slices.Equal(ineligible, wantIneligible) {
+		t.Fatalf("ineligible = %+v, want %+v", ineligible, wantIneligible)
+	}
+}
+
+func testTeamCandidates(t *testing.T, s database.Storage) {
+	ctx := context.Background()
+	mustTeam(t, s, "t1", 3)
+	mustTeam(t, s, "t2", 3)
+	mustUser(t, s, "u1", "t1")
+	mustUser(t, s, "u2", "t1")
+	mustUser(t, s, "u3", "t1")
+	mustUser(t, s, "u4", "t2")
+	mustPR(t, s, "pr-1", "u4", "u1", "u2")
+	mustPR(t, s, "pr-2", "u4", "u1")
+	mustPR(t, s, "pr-3", "u4", "u1")
+	requireNoError(t, s.ClosePR(ctx, "pr-3"))
+
+	requireNoError(t, s.SetUserMaxOpenReviews(ctx, database.SetUserMaxOpenReviewsParams{
+		ID:             "u2",
+		MaxOpenReviews: sql.NullInt32{Int32: 7, Valid: true},
+	}))
+	requireNoError(t, s.SetUserAvailability(ctx, database.SetUserAvailabilityParams{ID: "u3", IsAvailable: false}))
+
+	rows, err := s.GetTeamCandidates(ctx, "t1")
+	requireNoError(t, err)
+	want := []database.GetTeamCandidatesRow{
+		{ID: "u1", ReviewWeight: 1, OpenReviews: 2, Capacity: 3},
+		{ID: "u2", ReviewWeight: 1, OpenReviews: 1, Capacity: 7},
+	}
+	if !slices.Equal(rows, want) {
+		t.Fatalf("candidates = %+v, want %+v", rows, want)
+	}
+
+	rows, err = s.GetTeamCandidates(ctx, "missing")
+	requireNoError(t, err)
+	if len(rows) != 0 {
+		t.Fatalf("candidates of a missing team = %+v", rows)
+	}
+}
+
+func testReviewStates(t *testing.T, s database.Storage) {
+	ctx := context.Background()
+	mustTeam(t, s, "t1", 5)
+	mustUser(t, s, "u1", "t1")
+	mustUser(t, s, "u2", "t1")
+	mustUser(t, s, "u3", "t1")
+	mustPR(t, s, "pr-1", "u1", "u2", "u3")
+
+	reviews, err := s.GetReviewsByPR(ctx, "pr-1")
+	requireNoError(t, err)
+	if len(reviews) != 2 {
+		t.Fatalf("reviews = %+v, want 2", reviews)
+	}
+	for _, r := range reviews {
+		if r.State != "PENDING" || r.ReviewedAt.Valid || r.AssignedAt.IsZero() {
+			t.Fatalf("review = %+v", r)
+		}
+	}
+
+	n, err := s.SetReviewState(ctx, database.SetReviewStateParams{PrID: "pr-1", ReviewerID: "u2", State: "APPROVED"})
+	requireNoError(t, err)
+	if n != 1 {
+		t.Fatalf("rows = %d, want 1", n)
+	}
+
+	n, err = s.SetReviewState(ctx, database.SetReviewStateParams{PrID: "pr-1", ReviewerID: "u1", State: "APPROVED"})
+	requireNoError(t, err)
+	if n != 0 {
+		t.Fatalf("rows for unassigned reviewer = %d, want 0", n)
+	}
+
+	reviews, err = s.GetReviewsByPR(ctx, "pr-1")
+	requireNoError(t, err)
+
+	states := map[string]database.GetReviewsByPRRow{}
+	for _, r := range reviews {
+		states[r.ReviewerID] = r
+	}
+	if states["u2"].State != "APPROVED" || !states["u2"].ReviewedAt.Valid {
+		t.Fatalf("u2 review = %+v", states["u2"])
+	}
+	if states["u3"].State != "PENDING" {
+		t.Fatalf("u3 review = %+v", states["u3"])
+	}
+}
@@ -0,0 +1,124 @@
+package storagetest
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/LlirikP/pr_dispenser/internal/database"
+)
+
+type Opener func(t *testing.T) database.Storage
+
+type testCase struct {
+	name string
+	fn   func(t *testing.T, s database.Storage)
+}
+
+func Run(t *testing.T, open Opener) {
+	var cases []testCase
+	cases = append(cases, teamCases...)
+	cases = append(cases, prCases...)
+	cases = append(cases, tokenCases...)
+	cases = append(cases, webhookCases...)
+	cases = append(cases, outboxCases...)
+	cases = append(cases, auditCases...)
+	cases = append(cases, txCases...)
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.fn(t, open(t))
+		})
+	}
+}
+
+func mustTeam(t *testing.T, s database.Querier, id string, maxOpenReviews int32) database.Team {
+	t.Helper()
+
+	err := s.CreateTeam(context.Background(), database.CreateTeamParams{
+		ID:                id,
+		Teamname:          id + "-name",
+		ReviewerStrategy:  "least_loaded",
+		MaxOpenReviews:    maxOpenReviews,
+		ReviewersRequired: 2,
+		ApprovalsRequired: 1,
+	})
+	if err != nil {
+		t.Fatalf("create team %s: %v", id, err)
+	}
+
+	team, err := s.GetTeamByID(context.Background(), id)
+	if err != nil {
+		t.Fatalf("get team %s: %v", id, err)
+	}
+	return team
+}
+
+func mustUser(t *testing.T, s database.Querier, id, teamID string) {
+	t.Helper()
+
+	err := s.UpsertUser(context.Background(), database.UpsertUserParams{
+		ID:           id,
+		Username:     id + "-name",
+		IsAvailable:  true,
+		TeamID:       teamID,
+		ReviewWeight: 1,
+		Role:         "member",
+	})
+	if err != nil {
+		t.Fatalf("upsert user %s: %v", id, err)
+	}
+}
+
+func mustPR(t *testing.T, s database.Querier, id, authorID string, reviewerIDs ...string) {
+	t.Helper()
+
+	ctx := context.Background()
+	err := s.CreatePR(ctx, database.CreatePRParams{
+		ID:                id,
+		Title:             id + " title",
+		AuthorID:          authorID,
+		ReviewersRequired: int32(len(reviewerIDs)),
+	})
+	if err != nil {
+		t.Fatalf("create pr %s: %v", id, err)
+	}
+
+	for _, reviewerID := range reviewerIDs {
+		if err := s.AddReviewer(ctx, database.AddReviewerParams{PrID: id, ReviewerID: reviewerID}); err != nil {
+			t.Fatalf("add reviewer %s to %s: %v", reviewerID, id, err)
+		}
+	}
+}
+
+func requireNoRows(t *testing.T, err error) {
+	t.Helper()
+
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("err = %v, want sql.ErrNoRows", err)
+	}
+}
+
+func requireNoError(t *testing.T, err error) {
+	t.Helper()
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func jsonEqual(t *testing.T, got json.RawMessage, want string) bool {
+	t.Helper()
+
+	var a, b any
+	if err := json.Unmarshal(got, &a); err != nil {
+		t.Fatalf("decode %s: %v", got, err)
+	}
+	if err := json.Unmarshal([]byte(want), &b); err != nil {
+		t.Fatalf("decode %s: %v", want, err)
+	}
+	return reflect.DeepEqual(a, b)
+}
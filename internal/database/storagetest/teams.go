@@ -0,0 +1,234 @@
+package storagetest
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"testing"
+
+	"github.com/LlirikP/pr_dispenser/internal/database"
+)
+
+var teamCases = []testCase{
+	{"TeamLookupAndSettings", testTeamLookupAndSettings},
+	{"TeamNameIsUnique", testTeamNameIsUnique},
+	{"TeamFallbacks", testTeamFallbacks},
+	{"UserUpsertAndLookup", testUserUpsertAndLookup},
+	{"UserSettings", testUserSettings},
+}
+
+func testTeamLookupAndSettings(t *testing.T, s database.Storage) {
+	ctx := context.Background()
+	team := mustTeam(t, s, "t1", 3)
+
+	if team.Teamname != "t1-name" || team.MaxOpenReviews != 3 || team.ReviewersRequired != 2 || team.ApprovalsRequired != 1 {
+		t.Fatalf("team = %+v", team)
+	}
+	if team.RrLastReviewerID.Valid {
+		t.Fatalf("rr cursor = %v, want null", team.RrLastReviewerID)
+	}
+
+	byName, err := s.GetTeamByName(ctx, "t1-name")
+	requireNoError(t, err)
+	if byName != team {
+		t.Fatalf("by name = %+v, want %+v", byName, team)
+	}
+
+	name, err := s.GetTeamNameByID(ctx, "t1")
+	requireNoError(t, err)
+	if name != "t1-name" {
+		t.Fatalf("name = %q", name)
+	}
+
+	_, err = s.GetTeamByName(ctx, "missing")
+	requireNoRows(t, err)
+	_, err = s.GetTeamByID(ctx, "missing")
+	requireNoRows(t, err)
+	_, err = s.GetTeamNameByID(ctx, "missing")
+	requireNoRows(t, err)
+
+	mustUser(t, s, "u1", "t1")
+
+	requireNoError(t, s.SetTeamReviewerStrategy(ctx, database.SetTeamReviewerStrategyParams{ID: "t1", ReviewerStrategy: "round_robin"}))
+	requireNoError(t, s.SetTeamMaxOpenReviews(ctx, database.SetTeamMaxOpenReviewsParams{ID: "t1", MaxOpenReviews: 5}))
+	requireNoError(t, s.SetTeamReviewersRequired(ctx, database.SetTeamReviewersRequiredParams{ID: "t1", ReviewersRequired: 3}))
+	requireNoError(t, s.SetTeamApprovalsRequired(ctx, database.SetTeamApprovalsRequiredParams{ID: "t1", ApprovalsRequired: 2}))
+	requireNoError(t, s.SetTeamRoundRobinCursor(ctx, database.SetTeamRoundRobinCursorParams{
+		ID:               "t1",
+		RrLastReviewerID: sql.NullString{String: "u1", Valid: true},
+	}))
+
+	team, err = s.GetTeamByID(ctx, "t1")
+	requireNoError(t, err)
+
+	want := database.Team{
+		ID:                "t1",
+		Teamname:          "t1-name",
+		ReviewerStrategy:  "round_robin",
+		RrLastReviewerID:  sql.NullString{String: "u1", Valid: true},
+		MaxOpenReviews:    5,
+		ReviewersRequired: 3,
+		ApprovalsRequired: 2,
+	}
+	if team != want {
+		t.Fatalf("team = %+v, want %+v", team, want)
+	}
+}
+
+func testTeamNameIsUnique(t *testing.T, s database.Storage) {
+	mustTeam(t, s, "t1", 1)
+
+	err := s.CreateTeam(context.Background(), database.CreateTeamParams{
+		ID:                "t2",
+		Teamname:          "t1-name",
+		ReviewerStrategy:  "least_loaded",
+		MaxOpenReviews:    1,
+		ReviewersRequired: 2,
+		ApprovalsRequired: 1,
+	})
+	if err == nil {
+		t.Fatalf("duplicate team name was accepted")
+	}
+}
+
+func testTeamFallbacks(t *testing.T, s database.Storage) {
+	ctx := context.Background()
+	mustTeam(t, s, "t1", 1)
+	mustTeam(t, s, "t2", 1)
+	mustTeam(t, s, "t3", 1)
+
+	requireNoError(t, s.AddTeamFallback(ctx, database.AddTeamFallbackParams{TeamID: "t1", FallbackTeamID: "t3", Priority: 1}))
+	requireNoError(t, s.AddTeamFallback(ctx, database.AddTeamFallbackParams{TeamID: "t1", FallbackTeamID: "t2", Priority: 2}))
+
+	fallbacks, err := s.GetTeamFallbacks(ctx, "t1")
+	requireNoError(t, err)
+	if len(fallbacks) != 2 || fallbacks[0].ID != "t3" || fallbacks[1].ID != "t2" {
+		t.Fatalf("fallbacks = %+v, want t3 then t2", fallbacks)
+	}
+
+	requireNoError(t, s.DeleteTeamFallbacks(ctx, "t1"))
+
+	fallbacks, err = s.GetTeamFallbacks(ctx, "t1")
+	requireNoError(t, err)
+	if len(fallbacks) != 0 {
+		t.Fatalf("fallbacks = %+v, want none", fallbacks)
+	}
+}
+
+func testUserUpsertAndLookup(t *testing.T, s database.Storage) {
+	ctx := context.Background()
+	mustTeam(t, s, "t1", 1)
+	mustTeam(t, s, "t2", 1)
+
+	err := s.UpsertUser(ctx, database.UpsertUserParams{
+		ID:             "u1",
+		Username:       "alice",
+		IsAvailable:    true,
+		TeamID:         "t1",
+		ReviewWeight:   2,
+		MaxOpenReviews: sql.NullInt32{Int32: 4, Valid: true},
+		GithubLogin:    sql.NullString{String: "alice-gh", Valid: true},
+		GitlabUsername: sql.NullString{String: "alice-gl", Valid: true},
+		Role:           "team_lead",
+	})
+	requireNoError(t, err)
+
+	err = s.UpsertUser(ctx, database.UpsertUserParams{
+		ID:           "u1",
+		Username:     "alice2",
+		IsAvailable:  false,
+		TeamID:       "t2",
+		ReviewWeight: 3,
+		Role:         "member",
+	})
+	requireNoError(t, err)
+
+	user, err := s.GetUserById(ctx, "u1")
+	requireNoError(t, err)
+
+	want := database.User{
+		ID:             "u1",
+		Username:       "alice2",
+		IsAvailable:    false,
+		TeamID:         "t2",
+		ReviewWeight:   3,
+		GithubLogin:    sql.NullString{String: "alice-gh", Valid: true},
+		GitlabUsername: sql.NullString{String: "alice-gl", Valid: true},
+		Role:           "member",
+	}
+	if user != want {
+		t.Fatalf("user = %+v, want %+v", user, want)
+	}
+
+	byGithub, err := s.GetUserByGithubLogin(ctx, sql.NullString{String: "alice-gh", Valid: true})
+	requireNoError(t, err)
+	if byGithub.ID != "u1" {
+		t.Fatalf("by github login = %+v", byGithub)
+	}
+
+	byGitlab, err := s.GetUserByGitlabUsername(ctx, sql.NullString{String: "alice-gl", Valid: true})
+	requireNoError(t, err)
+	if byGitlab.ID != "u1" {
+		t.Fatalf("by gitlab username = %+v", byGitlab)
+	}
+
+	_, err = s.GetUserById(ctx, "missing")
+	requireNoRows(t, err)
+	_, err = s.GetUserByGithubLogin(ctx, sql.NullString{String: "missing", Valid: true})
+	requireNoRows(t, err)
+	_, err = s.GetUserByGitlabUsername(ctx, sql.NullString{String: "missing", Valid: true})
+	requireNoRows(t, err)
+}
+
+func testUserSettings(t *testing.T, s database.Storage) {
+	ctx := context.Background()
+	mustTeam(t, s, "t1", 1)
+	mustUser(t, s, "u2", "t1")
+	mustUser(t, s, "u1", "t1")
+	mustUser(t, s, "u3", "t1")
+
+	requireNoError(t, s.SetUserMaxOpenReviews(ctx, database.SetUserMaxOpenReviewsParams{
+		ID:             "u1",
+		MaxOpenReviews: sql.NullInt32{Int32: 7, Valid: true},
+	}))
+	requireNoError(t, s.SetUserRole(ctx, database.SetUserRoleParams{ID: "u1", Role: "admin"}))
+	requireNoError(t, s.SetUserAvailability(ctx, database.SetUserAvailabilityParams{ID: "u1", IsAvailable: false}))
+	requireNoError(t, s.SetUsersAvailability(ctx, database.SetUsersAvailabilityParams{IsAvailable: false, Ids: []string{"u2", "u3"}}))
+	requireNoError(t, s.SetUsersAvailability(ctx, database.SetUsersAvailabilityParams{IsAvailable: true, Ids: []string{"u3"}}))
+
+	user, err := s.GetUserById(ctx, "u1")
+	requireNoError(t, err)
+	if user.MaxOpenReviews != (sql.NullInt32{Int32: 7, Valid: true}) || user.Role != "admin" || user.IsAvailable {
+		t.Fatalf("user = %+v", user)
+	}
+
+	members, err := s.GetUsersByTeam(ctx, "t1")
+	requireNoError(t, err)
+
+	var ids []string
+	available := map[string]bool{}
+	for _, m := range members {
+		ids = append(ids, m.ID)
+		available[m.ID] = m.IsAvailable
+		if m.OpenReviews != 0 {
+			t.Fatalf("open reviews of %s = %d, want 0", m.ID, m.OpenReviews)
+		}
+	}
+	if !slices.Equal(ids, []string{"u1", "u2", "u3"}) {
+		t.Fatalf("members = %v, want ordered by username", ids)
+	}
+	if available["u1"] || available["u2"] || !available["u3"] {
+		t.Fatalf("availability = %v", available)
+	}
+
+	weights, err := s.GetUserWeights(ctx, []string{"u1", "u3", "missing"})
+	requireNoError(t, err)
+	if len(weights) != 2 {
+		t.Fatalf("weights = %+v, want 2 rows", weights)
+	}
+	for _, w := range weights {
+		if w.ReviewWeight != 1 {
+			t.Fatalf("weight = %+v, want 1", w)
+		}
+	}
+}
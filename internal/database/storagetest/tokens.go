@@ -0,0 +1,81 @@
+package storagetest
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/LlirikP/pr_dispenser/internal/database"
+)
+
+var tokenCases = []testCase{
+	{"APITokens", testAPITokens},
+}
+
+func testAPITokens(t *testing.T, s database.Storage) {
+	ctx := context.Background()
+	mustTeam(t, s, "t1", 1)
+	mustUser(t, s, "u1", "t1")
+
+	requireNoError(t, s.CreateAPIToken(ctx, database.CreateAPITokenParams{
+		ID:        "tok-1",
+		Name:      "ci",
+		TokenHash: "hash-1",
+		Scope:     "admin",
+	}))
+	requireNoError(t, s.CreateAPIToken(ctx, database.CreateAPITokenParams{
+		ID:        "tok-2",
+		Name:      "alice",
+		TokenHash: "hash-2",
+		Scope:     "user",
+		UserID:    sql.NullString{String: "u1", Valid: true},
+	}))
+
+	token, err := s.GetAPITokenByHash(ctx, "hash-2")
+	requireNoError(t, err)
+	if token.ID != "tok-2" || token.Scope != "user" || token.UserID.String != "u1" || token.RevokedAt.Valid || token.CreatedAt.IsZero() {
+		t.Fatalf("token = %+v", token)
+	}
+
+	_, err = s.GetAPITokenByHash(ctx, "missing")
+	requireNoRows(t, err)
+
+	n, err := s.RevokeAPIToken(ctx, "tok-1")
+	requireNoError(t, err)
+	if n != 1 {
+		t.Fatalf("revoked rows = %d, want 1", n)
+	}
+
+	n, err = s.RevokeAPIToken(ctx, "tok-1")
+	requireNoError(t, err)
+	if n != 0 {
+		t.Fatalf("second revoke rows = %d, want 0", n)
+	}
+
+	_, err = s.GetAPITokenByHash(ctx, "hash-1")
+	requireNoRows(t, err)
+
+	revoked, err := s.FindAPITokenByHash(ctx, "hash-1")
+	requireNoError(t, err)
+	if revoked.ID != "tok-1" || !revoked.RevokedAt.Valid {
+		t.Fatalf("revoked token = %+v", revoked)
+	}
+
+	_, err = s.FindAPITokenByHash(ctx, "missing")
+	requireNoRows(t, err)
+
+	byID, err := s.GetAPITokenByID(ctx, "tok-1")
+	requireNoError(t, err)
+	if byID.ID != "tok-1" || byID.TokenHash != "hash-1" || !byID.RevokedAt.Valid {
+		t.Fatalf("token by id = %+v", byID)
+	}
+
+	_, err = s.GetAPITokenByID(ctx, "missing")
+	requireNoRows(t, err)
+
+	tokens, err := s.ListAPITokens(ctx)
+	requireNoError(t, err)
+	if len(tokens) != 2 {
+		t.Fatalf("tokens = %+v, want 2", tokens)
+	}
+}
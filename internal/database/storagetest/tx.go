@@ -0,0 +1,168 @@
+package storagetest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/LlirikP/pr_dispenser/internal/database"
+)
+
+var txCases = []testCase{
+	{"ExecTxCommits", testExecTxCommits},
+	{"ExecTxRollsBack", testExecTxRollsBack},
+	{"ExecTxRollsBackQueues", testExecTxRollsBackQueues},
+}
+
+func testExecTxCommits(t *testing.T, s database.Storage) {
+	ctx := context.Background()
+
+	err := s.ExecTx(ctx, func(q database.Querier) error {
+		mustTeam(t, q, "t1", 1)
+		mustUser(t, q, "u1", "t1")
+		mustUser(t, q, "u2", "t1")
+		mustPR(t, q, "pr-1", "u1", "u2")
+		return nil
+	})
+	requireNoError(t, err)
+
+	_, err = s.GetPRById(ctx, "pr-1")
+	requireNoError(t, err)
+
+	ids, err := s.GetReviewersByPR(ctx, "pr-1")
+	requireNoError(t, err)
+	if len(ids) != 1 || ids[0] != "u2" {
+		t.Fatalf("reviewers = %v, want [u2]", ids)
+	}
+}
+
+func testExecTxRollsBack(t *testing.T, s database.Storage) {
+	ctx := context.Background()
+	mustTeam(t, s, "t1", 5)
+	mustUser(t, s, "u1", "t1")
+	mustUser(t, s, "u2", "t1")
+	mustUser(t, s, "u3", "t1")
+	mustPR(t, s, "pr-1", "u1", "u2")
+
+	errBoom := errors.New("boom")
+	err := s.ExecTx(ctx, func(q database.Querier) error {
+		mustPR(t, q, "pr-2", "u1", "u2", "u3")
+		requireNoError(t, q.DeleteReviewer(ctx, database.DeleteReviewerParams{PrID: "pr-1", ReviewerID: "u2"}))
+		requireNoError(t, q.AddReviewer(ctx, database.AddReviewerParams{PrID: "pr-1", ReviewerID: "u3"}))
+		requireNoError(t, q.MergePR(ctx, database.MergePRParams{ID: "pr-1"}))
+		return errBoom
+	})
+	if !errors.Is(err, errBoom) {
+		t.Fatalf("err = %v, want %v", err, errBoom)
+	}
+
+	_, err = s.GetPRById(ctx, "pr-2")
+	requireNoRows(t, err)
+
+	ids, err := s.GetReviewersByPR(ctx, "pr-2")
+	requireNoError(t, err)
+	if len(ids) != 0 {
+		t.Fatalf("reviewers of rolled back pr = %v", ids)
+	}
+
+	pr, err := s.GetPRById(ctx, "pr-1")
+	requireNoError(t, err)
+	if pr.Status != "OPEN" {
+		t.Fatalf("status = %s, want OPEN", pr.Status)
+	}
+
+	ids, err = s.GetReviewersByPR(ctx, "pr-1")
+	requireNoError(t, err)
+	if len(ids) != 1 || ids[0] != "u2" {
+		t.Fatalf("reviewers = %v, want [u2]", ids)
+	}
+}
+
+func testExecTxRollsBackQueues(t *testing.T, s database.Storage) {
+	ctx := context.Background()
+	requireNoError(t, s.CreateWebhookSubscription(ctx, database.CreateWebhookSubscriptionParams{
+		ID:     "sub-1",
+		Url:    "https://example.com/hook",
+		Events: []string{},
+		Secret: "secret",
+	}))
+	mustOutboxEvent(t, s, "e1", "pr-1")
+	requireNoError(t, s.CreateWebhookDelivery(ctx, database.CreateWebhookDeliveryParams{
+		SubscriptionID: "sub-1",
+		EventID:        "e1",
+		EventType:      "pr.created",
+		PrID:           "pr-1",
+		Payload:        json.RawMessage(`{"event_id":"e1"}`),
+	}))
+
+	claim := database.ClaimDueWebhookDeliveriesParams{LeaseUntil: time.Now().Add(time.Minute), BatchSize: 10}
+
+	errBoom := errors.New("boom")
+	err := s.ExecTx(ctx, func(q database.Querier) error {
+		entry, err := q.ClaimNextOutboxEvent(ctx)
+		requireNoError(t, err)
+		requireNoError(t, q.MarkOutboxDispatched(ctx, entry.ID))
+		mustOutboxEvent(t, q, "e2", "pr-2")
+		_, err = q.SequenceOutboxEvents(ctx)
+		requireNoError(t, err)
+
+		rows, err := q.ClaimDueWebhookDeliveries(ctx, claim)
+		requireNoError(t, err)
+		if len(rows) != 1 {
+			t.Fatalf("claimed %+v, want e1", rows)
+		}
+		requireNoError(t, q.CreateWebhookDelivery(ctx, database.CreateWebhookDeliveryParams{
+			SubscriptionID: "sub-1",
+			EventID:        "e2",
+			EventType:      "pr.created",
+			PrID:           "pr-2",
+			Payload:        json.RawMessage(`{"event_id":"e2"}`),
+		}))
+		_, err = q.DeleteWebhookSubscription(ctx, "sub-1")
+		requireNoError(t, err)
+
+		requireNoError(t, q.InsertAuditEntry(ctx, database.InsertAuditEntryParams{
+			Actor:      "admin",
+			Action:     "subscription.deleted",
+			TargetType: "webhook_subscription",
+			TargetID:   "sub-1",
+			Before:     json.RawMessage(`{"id":"sub-1"}`),
+			After:      json.RawMessage(`null`),
+		}))
+		return errBoom
+	})
+	if !errors.Is(err, errBoom) {
+		t.Fatalf("err = %v, want %v", err, errBoom)
+	}
+
+	if _, err := s.GetWebhookSubscriptionByID(ctx, "sub-1"); err != nil {
+		t.Fatalf("subscription after rollback: %v", err)
+	}
+
+	entries, err := s.ListAuditEntries(ctx, database.ListAuditEntriesParams{MaxRows: 10})
+	requireNoError(t, err)
+	if len(entries) != 0 {
+		t.Fatalf("audit entries = %+v, want none", entries)
+	}
+
+	latest, err := s.GetLatestStreamSeq(ctx)
+	requireNoError(t, err)
+	if latest != 0 {
+		t.Fatalf("latest seq = %d, want 0", latest)
+	}
+
+	entry, found := claimOutbox(t, s)
+	if !found || entry.EventID != "e1" || entry.Status != "PENDING" || entry.Attempts != 0 {
+		t.Fatalf("outbox head = %+v, want pending e1", entry)
+	}
+
+	// The lease taken in the rolled back transaction is gone as well, so e1
+	// is due straight away.
+	rows, err := s.ClaimDueWebhookDeliveries(ctx, claim)
+	requireNoError(t, err)
+	if len(rows) != 1 || rows[0].EventID != "e1" {
+		t.Fatalf("claimed %+v, want only e1", rows)
+	}
+}
@@ -0,0 +1,217 @@
+package storagetest
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/LlirikP/pr_dispenser/internal/database"
+)
+
+var webhookCases = []testCase{
+	{"WebhookSubscriptions", testWebhookSubscriptions},
+	{"WebhookDeliveries", testWebhookDeliveries},
+}
+
+func subscriptionIDs(subs []database.WebhookSubscription) []string {
+	ids := make([]string, 0, len(subs))
+	for _, sub := range subs {
+		ids = append(ids, sub.ID)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+func testWebhookSubscriptions(t *testing.T, s database.Storage) {
+	ctx := context.Background()
+
+	requireNoError(t, s.CreateWebhookSubscription(ctx, database.CreateWebhookSubscriptionParams{
+		ID:     "sub-all",
+		Url:    "https://example.com/all",
+		Events: []string{},
+		Secret: "secret-all",
+	}))
+	requireNoError(t, s.CreateWebhookSubscription(ctx, database.CreateWebhookSubscriptionParams{
+		ID:     "sub-merged",
+		Url:    "https://example.com/merged",
+		Events: []string{"pr.merged"},
+		Secret: "secret-merged",
+	}))
+
+	subs, err := s.ListWebhookSubscriptions(ctx)
+	requireNoError(t, err)
+	if !slices.Equal(subscriptionIDs(subs), []string{"sub-all", "sub-merged"}) {
+		t.Fatalf("subscriptions = %+v", subs)
+	}
+	for _, sub := range subs {
+		if sub.ID == "sub-merged" && (!slices.Equal(sub.Events, []string{"pr.merged"}) || sub.Secret != "secret-merged" || sub.Url != "https://example.com/merged") {
+			t.Fatalf("subscription = %+v", sub)
+		}
+	}
+
+	subs, err = s.GetSubscriptionsForEvent(ctx, "pr.created")
+	requireNoError(t, err)
+	if !slices.Equal(subscriptionIDs(subs), []string{"sub-all"}) {
+		t.Fatalf("subscriptions for pr.created = %v", subscriptionIDs(subs))
+	}
+
+	subs, err = s.GetSubscriptionsForEvent(ctx, "pr.merged")
+	requireNoError(t, err)
+	if !slices.Equal(subscriptionIDs(subs), []string{"sub-all", "sub-merged"}) {
+		t.Fatalf("subscriptions for pr.merged = %v", subscriptionIDs(subs))
+	}
+
+	sub, err := s.GetWebhookSubscriptionByID(ctx, "sub-merged")
+	requireNoError(t, err)
+	if sub.ID != "sub-merged" || sub.Url != "https://example.com/merged" || !slices.Equal(sub.Events, []string{"pr.merged"}) {
+		t.Fatalf("subscription by id = %+v", sub)
+	}
+
+	n, err := s.DeleteWebhookSubscription(ctx, "sub-merged")
+	requireNoError(t, err)
+	if n != 1 {
+		t.Fatalf("deleted rows = %d, want 1", n)
+	}
+
+	n, err = s.DeleteWebhookSubscription(ctx, "sub-merged")
+	requireNoError(t, err)
+	if n != 0 {
+		t.Fatalf("second delete rows = %d, want 0", n)
+	}
+
+	_, err = s.GetWebhookSubscriptionByID(ctx, "sub-merged")
+	requireNoRows(t, err)
+}
+
+func testWebhookDeliveries(t *testing.T, s database.Storage) {
+	ctx := context.Background()
+
+	requireNoError(t, s.CreateWebhookSubscription(ctx, database.CreateWebhookSubscriptionParams{
+		ID:     "sub-1",
+		Url:    "https://example.com/hook",
+		Events: []string{},
+		Secret: "secret",
+	}))
+
+	deliver := func(eventID, prID string) {
+		t.Helper()
+		requireNoError(t, s.CreateWebhookDelivery(ctx, database.CreateWebhookDeliveryParams{
+			SubscriptionID: "sub-1",
+			EventID:        eventID,
+			EventType:      "pr.created",
+			PrID:           prID,
+			Payload:        json.RawMessage(`{"event_id":"` + eventID + `"}`),
+		}))
+	}
+
+	deliver("e1", "pr-1")
+	deliver("e2", "pr-1")
+	deliver("e3", "pr-2")
+	deliver("e1", "pr-1")
+
+	listed, err := s.ListWebhookDeliveries(ctx, database.ListWebhookDeliveriesParams{Limit: 10})
+	requireNoError(t, err)
+	if len(listed) != 3 {
+		t.Fatalf("deliveries = %+v, want 3", listed)
+	}
+	if listed[0].EventID != "e3" || listed[2].EventID != "e1" {
+		t.Fatalf("deliveries are not newest first: %+v", listed)
+	}
+
+	ids := map[string]int64{}
+	for _, d := range listed {
+		ids[d.EventID] = d.ID
+		if d.Status != "PENDING" || d.Attempts != 0 || d.SubscriptionID != "sub-1" {
+			t.Fatalf("delivery = %+v", d)
+		}
+	}
+
+	claim := func() []string {
+		t.Helper()
+		rows, err := s.ClaimDueWebhookDeliveries(ctx, database.ClaimDueWebhookDeliveriesParams{
+			LeaseUntil: time.Now().Add(time.Minute),
+			BatchSize:  10,
+		})
+		requireNoError(t, err)
+
+		var events []string
+		for _, row := range rows {
+			if row.Url != "https://example.com/hook" || row.Secret != "secret" {
+				t.Fatalf("claimed row = %+v", row)
+			}
+			events = append(events, row.EventID)
+		}
+		slices.Sort(events)
+		return events
+	}
+
+	if got := claim(); !slices.Equal(got, []string{"e1", "e3"}) {
+		t.Fatalf("claimed = %v, want [e1 e3]", got)
+	}
+	if got := claim(); len(got) != 0 {
+		t.Fatalf("leased deliveries were claimed again: %v", got)
+	}
+
+	requireNoError(t, s.MarkWebhookDelivered(ctx, database.MarkWebhookDeliveredParams{
+		ID:             ids["e1"],
+		ResponseStatus: sql.NullInt32{Int32: 200, Valid: true},
+	}))
+	requireNoError(t, s.MarkWebhookAttemptFailed(ctx, database.MarkWebhookAttemptFailedParams{
+		Status:         "DEAD",
+		ResponseStatus: sql.NullInt32{Int32: 500, Valid: true},
+		LastError:      sql.NullString{String: "boom", Valid: true},
+		NextAttemptAt:  time.Now(),
+		ID:             ids["e3"],
+	}))
+
+	if got := claim(); !slices.Equal(got, []string{"e2"}) {
+		t.Fatalf("claimed = %v, want [e2]", got)
+	}
+
+	dead, err := s.ListWebhookDeliveries(ctx, database.ListWebhookDeliveriesParams{
+		Limit:  10,
+		Status: sql.NullString{String: "DEAD", Valid: true},
+	})
+	requireNoError(t, err)
+	if len(dead) != 1 || dead[0].EventID != "e3" || dead[0].Attempts != 1 || dead[0].LastError.String != "boom" || dead[0].ResponseStatus.Int32 != 500 {
+		t.Fatalf("dead deliveries = %+v", dead)
+	}
+
+	delivered, err := s.ListWebhookDeliveries(ctx, database.ListWebhookDeliveriesParams{
+		Limit:          10,
+		SubscriptionID: sql.NullString{String: "sub-1", Valid: true},
+		Status:         sql.NullString{String: "DELIVERED", Valid: true},
+	})
+	requireNoError(t, err)
+	if len(delivered) != 1 || delivered[0].EventID != "e1" || !delivered[0].DeliveredAt.Valid || delivered[0].Attempts != 1 {
+		t.Fatalf("delivered = %+v", delivered)
+	}
+
+	byID, err := s.GetWebhookDeliveryByID(ctx, ids["e3"])
+	requireNoError(t, err)
+	if byID.EventID != "e3" || byID.PrID != "pr-2" || byID.Status != "DEAD" || byID.Attempts != 1 || byID.LastError.String != "boom" || string(byID.Payload) != `{"event_id":"e3"}` {
+		t.Fatalf("delivery by id = %+v", byID)
+	}
+
+	_, err = s.GetWebhookDeliveryByID(ctx, -1)
+	requireNoRows(t, err)
+
+	n, err := s.RequeueWebhookDelivery(ctx, ids["e1"])
+	requireNoError(t, err)
+	if n != 0 {
+		t.Fatalf("requeued a delivered delivery")
+	}
+
+	n, err = s.RequeueWebhookDelivery(ctx, ids["e3"])
+	requireNoError(t, err)
+	if n != 1 {
+		t.Fatalf("requeue rows = %d, want 1", n)
+	}
+
+	if got := claim(); !slices.Equal(got, []string{"e3"}) {
+		t.Fatalf("claimed = %v, want [e3]", got)
+	}
+}
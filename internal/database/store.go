@@ -0,0 +1,41 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+type Storage interface {
+	Querier
+	ExecTx(ctx context.Context, fn func(Querier) error) error
+}
+
+type Store struct {
+	*Queries
+	db *sql.DB
+}
+
+func NewStore(db *sql.DB) *Store {
+	return &Store{
+		Queries: New(db),
+		db:      db,
+	}
+}
+
+func (s *Store) ExecTx(ctx context.Context, fn func(Querier) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(s.WithTx(tx)); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("%w (rollback failed: %v)", err, rbErr)
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
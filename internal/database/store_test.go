@@ -0,0 +1,66 @@
+package database_test
+
+import (
+	"context"
+	"database/sql"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/LlirikP/pr_dispenser/internal/database"
+	"github.com/LlirikP/pr_dispenser/internal/database/storagetest"
+	"github.com/LlirikP/pr_dispenser/internal/migrate"
+	"github.com/google/uuid"
+)
+
+func withSearchPath(dbURL, schema string) string {
+	if u, err := url.Parse(dbURL); err == nil && (u.Scheme == "postgres" || u.Scheme == "postgresql") {
+		q := u.Query()
+		q.Set("search_path", schema)
+		u.RawQuery = q.Encode()
+		return u.String()
+	}
+	return dbURL + " search_path=" + schema
+}
+
+func TestStorage(t *testing.T) {
+	dbURL := os.Getenv("DB_URL")
+	if dbURL == "" {
+		t.Skip("DB_URL is not set")
+	}
+
+	admin, err := sql.Open("postgres", dbURL)
+	if err != nil {
+		t.Fatalf("open postgres: %v", err)
+	}
+	defer admin.Close()
+
+	storagetest.Run(t, func(t *testing.T) database.Storage {
+		ctx := context.Background()
+		schema := "storagetest_" + strings.ReplaceAll(uuid.NewString(), "-", "")
+
+		if _, err := admin.ExecContext(ctx, "CREATE SCHEMA "+schema); err != nil {
+			t.Fatalf("create schema: %v", err)
+		}
+		t.Cleanup(func() {
+			admin.ExecContext(context.Background(), "DROP SCHEMA "+schema+" CASCADE")
+		})
+
+		db, err := sql.Open("postgres", withSearchPath(dbURL, schema))
+		if err != nil {
+			t.Fatalf("open postgres: %v", err)
+		}
+		t.Cleanup(func() { db.Close() })
+
+		migrator, err := migrate.New("postgres", db)
+		if err != nil {
+			t.Fatalf("new migrator: %v", err)
+		}
+		if _, err := migrator.Up(ctx); err != nil {
+			t.Fatalf("migrate: %v", err)
+		}
+
+		return database.NewStore(db)
+	})
+}
@@ -7,25 +7,84 @@ package database
 
 import (
 	"context"
+	"database/sql"
 )
 
+const addTeamFallback = `-- name: AddTeamFallback :exec
+INSERT INTO team_fallbacks (team_id, fallback_team_id, priority)
+VALUES ($1, $2, $3)
+`
+
+type AddTeamFallbackParams struct {
+	TeamID         string
+	FallbackTeamID string
+	Priority       int32
+}
+
+func (q *Queries) AddTeamFallback(ctx context.Context, arg AddTeamFallbackParams) error {
+	_, err := q.db.ExecContext(ctx, addTeamFallback, arg.TeamID, arg.FallbackTeamID, arg.Priority)
+	return err
+}
+
 const createTeam = `-- name: CreateTeam :exec
-INSERT INTO teams (id, teamname)
-VALUES ($1, $2)
+INSERT INTO teams (id, teamname, reviewer_strategy, max_open_reviews, reviewers_required, approvals_required)
+VALUES ($1, $2, $3, $4, $5, $6)
 `
 
 type CreateTeamParams struct {
-	ID       string
-	Teamname string
+	ID                string
+	Teamname          string
+	ReviewerStrategy  string
+	MaxOpenReviews    int32
+	ReviewersRequired int32
+	ApprovalsRequired int32
 }
 
 func (q *Queries) CreateTeam(ctx context.Context, arg CreateTeamParams) error {
-	_, err := q.db.ExecContext(ctx, createTeam, arg.ID, arg.Teamname)
+	_, err := q.db.ExecContext(ctx, createTeam,
+		arg.ID,
+		arg.Teamname,
+		arg.ReviewerStrategy,
+		arg.MaxOpenReviews,
+		arg.ReviewersRequired,
+		arg.ApprovalsRequired,
+	)
 	return err
 }
 
+const deleteTeamFallbacks = `-- name: DeleteTeamFallbacks :exec
+DELETE FROM team_fallbacks
+WHERE team_id = $1
+`
+
+func (q *Queries) DeleteTeamFallbacks(ctx context.Context, teamID string) error {
+	_, err := q.db.ExecContext(ctx, deleteTeamFallbacks, teamID)
+	return err
+}
+
+const getTeamByID = `-- name: GetTeamByID :one
+SELECT id, teamname, reviewer_strategy, rr_last_reviewer_id, max_open_reviews, reviewers_required, approvals_required
+FROM teams
+WHERE id = $1
+`
+
+func (q *Queries) GetTeamByID(ctx context.Context, id string) (Team, error) {
+	row := q.db.QueryRowContext(ctx, getTeamByID, id)
+	var i Team
+	err := row.Scan(
+		&i.ID,
+		&i.Teamname,
+		&i.ReviewerStrategy,
+		&i.RrLastReviewerID,
+		&i.MaxOpenReviews,
+		&i.ReviewersRequired,
+		&i.ApprovalsRequired,
+	)
+	return i, err
+}
+
 const getTeamByName = `-- name: GetTeamByName :one
-SELECT id, teamname
+SELECT id, teamname, reviewer_strategy, rr_last_reviewer_id, max_open_reviews, reviewers_required, approvals_required
 FROM teams
 WHERE teamname = $1
 `
@@ -33,10 +92,57 @@ WHERE teamname = $1
 func (q *Queries) GetTeamByName(ctx context.Context, teamname string) (Team, error) {
 	row := q.db.QueryRowContext(ctx, getTeamByName, teamname)
 	var i Team
-	err := row.Scan(&i.ID, &i.Teamname)
+	err := row.Scan(
+		&i.ID,
+		&i.Teamname,
+		&i.ReviewerStrategy,
+		&i.RrLastReviewerID,
+		&i.MaxOpenReviews,
+		&i.ReviewersRequired,
+		&i.ApprovalsRequired,
+	)
 	return i, err
 }
 
+const getTeamFallbacks = `-- name: GetTeamFallbacks :many
+SELECT t.id, t.teamname, t.reviewer_strategy, t.rr_last_reviewer_id, t.max_open_reviews, t.reviewers_required, t.approvals_required
+FROM team_fallbacks f
+JOIN teams t ON t.id = f.fallback_team_id
+WHERE f.team_id = $1
+ORDER BY f.priority
+`
+
+func (q *Queries) GetTeamFallbacks(ctx context.Context, teamID string) ([]Team, error) {
+	rows, err := q.db.QueryContext(ctx, getTeamFallbacks, teamID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Team
+	for rows.Next() {
+		var i Team
+		if err := rows.Scan(
+			&i.ID,
+			&i.Teamname,
+			&i.ReviewerStrategy,
+			&i.RrLastReviewerID,
+			&i.MaxOpenReviews,
+			&i.ReviewersRequired,
+			&i.ApprovalsRequired,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getTeamNameByID = `-- name: GetTeamNameByID :one
 SELECT teamname
 FROM teams
@@ -51,26 +157,46 @@ func (q *Queries) GetTeamNameByID(ctx context.Context, id string) (string, error
 }
 
 const getUsersByTeam = `-- name: GetUsersByTeam :many
-SELECT id, username, is_active, team_id
-FROM users
-WHERE team_id = $1
-ORDER BY username
+SELECT u.id, u.username, u.is_available, u.team_id, u.review_weight, u.max_open_reviews, u.github_login, u.gitlab_username, u.role, w.open_reviews
+FROM users u
+JOIN user_workload w ON w.user_id = u.id
+WHERE u.team_id = $1
+ORDER BY u.username
 `
 
-func (q *Queries) GetUsersByTeam(ctx context.Context, teamID string) ([]User, error) {
+type GetUsersByTeamRow struct {
+	ID             string
+	Username       string
+	IsAvailable    bool
+	TeamID         string
+	ReviewWeight   int32
+	MaxOpenReviews sql.NullInt32
+	GithubLogin    sql.NullString
+	GitlabUsername sql.NullString
+	Role           string
+	OpenReviews    int64
+}
+
+func (q *Queries) GetUsersByTeam(ctx context.Context, teamID string) ([]GetUsersByTeamRow, error) {
 	rows, err := q.db.QueryContext(ctx, getUsersByTeam, teamID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []User
+	var items []GetUsersByTeamRow
 	for rows.Next() {
-		var i User
+		var i GetUsersByTeamRow
 		if err := rows.Scan(
 			&i.ID,
 			&i.Username,
-			&i.IsActive,
+			&i.IsAvailable,
 			&i.TeamID,
+			&i.ReviewWeight,
+			&i.MaxOpenReviews,
+			&i.GithubLogin,
+			&i.GitlabUsername,
+			&i.Role,
+			&i.OpenReviews,
 		); err != nil {
 			return nil, err
 		}
@@ -85,28 +211,123 @@ func (q *Queries) GetUsersByTeam(ctx context.Context, teamID string) ([]User, er
 	return items, nil
 }
 
+const setTeamApprovalsRequired = `-- name: SetTeamApprovalsRequired :exec
+UPDATE teams
+SET approvals_required = $2
+WHERE id = $1
+`
+
+type SetTeamApprovalsRequiredParams struct {
+	ID                string
+	ApprovalsRequired int32
+}
+
+func (q *Queries) SetTeamApprovalsRequired(ctx context.Context, arg SetTeamApprovalsRequiredParams) error {
+	_, err := q.db.ExecContext(ctx, setTeamApprovalsRequired, arg.ID, arg.ApprovalsRequired)
+	return err
+}
+
+const setTeamMaxOpenReviews = `-- name: SetTeamMaxOpenReviews :exec
+UPDATE teams
+SET max_open_reviews = $2
+WHERE id = $1
+`
+
+type SetTeamMaxOpenReviewsParams struct {
+	ID             string
+	MaxOpenReviews int32
+}
+
+func (q *Queries) SetTeamMaxOpenReviews(ctx context.Context, arg SetTeamMaxOpenReviewsParams) error {
+	_, err := q.db.ExecContext(ctx, setTeamMaxOpenReviews, arg.ID, arg.MaxOpenReviews)
+	return err
+}
+
+const setTeamReviewerStrategy = `-- name: SetTeamReviewerStrategy :exec
+UPDATE teams
+SET reviewer_strategy = $2
+WHERE id = $1
+`
+
+type SetTeamReviewerStrategyParams struct {
+	ID               string
+	ReviewerStrategy string
+}
+
+func (q *Queries) SetTeamReviewerStrategy(ctx context.Context, arg SetTeamReviewerStrategyParams) error {
+	_, err := q.db.ExecContext(ctx, setTeamReviewerStrategy, arg.ID, arg.ReviewerStrategy)
+	return err
+}
+
+const setTeamReviewersRequired = `-- name: SetTeamReviewersRequired :exec
+UPDATE teams
+SET reviewers_required = $2
+WHERE id = $1
+`
+
+type SetTeamReviewersRequiredParams struct {
+	ID                string
+	ReviewersRequired int32
+}
+
+func (q *Queries) SetTeamReviewersRequired(ctx context.Context, arg SetTeamReviewersRequiredParams) error {
+	_, err := q.db.ExecContext(ctx, setTeamReviewersRequired, arg.ID, arg.ReviewersRequired)
+	return err
+}
+
+const setTeamRoundRobinCursor = `-- name: SetTeamRoundRobinCursor :exec
+UPDATE teams
+SET rr_last_reviewer_id = $2
+WHERE id = $1
+`
+
+type SetTeamRoundRobinCursorParams struct {
+	ID               string
+	RrLastReviewerID sql.NullString
+}
+
+func (q *Queries) SetTeamRoundRobinCursor(ctx context.Context, arg SetTeamRoundRobinCursorParams) error {
+	_, err := q.db.ExecContext(ctx, setTeamRoundRobinCursor, arg.ID, arg.RrLastReviewerID)
+	return err
+}
+
 const upsertUser = `-- name: UpsertUser :exec
-INSERT INTO users (id, username, is_active, team_id)
-VALUES ($1, $2, $3, $4)
+INSERT INTO users (id, username, is_available, team_id, review_weight, max_open_reviews, github_login, gitlab_username, role)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 ON CONFLICT (id) DO UPDATE
 SET username = EXCLUDED.username,
-    is_active = EXCLUDED.is_active,
-    team_id = EXCLUDED.team_id
+    is_available = EXCLUDED.is_available,
+    team_id = EXCLUDED.team_id,
+    review_weight = EXCLUDED.review_weight,
+    max_open_reviews = EXCLUDED.max_open_reviews,
+    github_login = COALESCE(EXCLUDED.github_login, users.github_login),
+    gitlab_username = COALESCE(EXCLUDED.gitlab_username, users.gitlab_username),
+    role = EXCLUDED.role
 `
 
 type UpsertUserParams struct {
-	ID       string
-	Username string
-	IsActive bool
-	TeamID   string
+	ID             string
+	Username       string
+	IsAvailable    bool
+	TeamID         string
+	ReviewWeight   int32
+	MaxOpenReviews sql.NullInt32
+	GithubLogin    sql.NullString
+	GitlabUsername sql.NullString
+	Role           string
 }
 
 func (q *Queries) UpsertUser(ctx context.Context, arg UpsertUserParams) error {
 	_, err := q.db.ExecContext(ctx, upsertUser,
 		arg.ID,
 		arg.Username,
-		arg.IsActive,
+		arg.IsAvailable,
 		arg.TeamID,
+		arg.ReviewWeight,
+		arg.MaxOpenReviews,
+		arg.GithubLogin,
+		arg.GitlabUsername,
+		arg.Role,
 	)
 	return err
 }
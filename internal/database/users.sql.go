@@ -7,6 +7,9 @@ package database
 
 import (
 	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
 )
 
 const getReviewPRs = `-- name: GetReviewPRs :many
@@ -14,7 +17,9 @@ SELECT
     prs.id AS pr_id,
     prs.title AS pr_title,
     prs.author_id,
-    prs.status
+    prs.status,
+    r.state AS review_state,
+    r.reviewed_at
 FROM pr_reviewers r
 JOIN prs ON prs.id = r.pr_id
 WHERE r.reviewer_id = $1
@@ -22,10 +27,12 @@ ORDER BY prs.id
 `
 
 type GetReviewPRsRow struct {
-	PrID     string
-	PrTitle  string
-	AuthorID string
-	Status   string
+	PrID        string
+	PrTitle     string
+	AuthorID    string
+	Status      string
+	ReviewState string
+	ReviewedAt  sql.NullTime
 }
 
 func (q *Queries) GetReviewPRs(ctx context.Context, reviewerID string) ([]GetReviewPRsRow, error) {
@@ -42,6 +49,8 @@ func (q *Queries) GetReviewPRs(ctx context.Context, reviewerID string) ([]GetRev
 			&i.PrTitle,
 			&i.AuthorID,
 			&i.Status,
+			&i.ReviewState,
+			&i.ReviewedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -56,8 +65,54 @@ func (q *Queries) GetReviewPRs(ctx context.Context, reviewerID string) ([]GetRev
 	return items, nil
 }
 
+const getUserByGithubLogin = `-- name: GetUserByGithubLogin :one
+SELECT id, username, is_available, team_id, review_weight, max_open_reviews, github_login, gitlab_username, role
+FROM users
+WHERE github_login = $1
+`
+
+func (q *Queries) GetUserByGithubLogin(ctx context.Context, githubLogin sql.NullString) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByGithubLogin, githubLogin)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.IsAvailable,
+		&i.TeamID,
+		&i.ReviewWeight,
+		&i.MaxOpenReviews,
+		&i.GithubLogin,
+		&i.GitlabUsername,
+		&i.Role,
+	)
+	return i, err
+}
+
+const getUserByGitlabUsername = `-- name: GetUserByGitlabUsername :one
+SELECT id, username, is_available, team_id, review_weight, max_open_reviews, github_login, gitlab_username, role
+FROM users
+WHERE gitlab_username = $1
+`
+
+func (q *Queries) GetUserByGitlabUsername(ctx context.Context, gitlabUsername sql.NullString) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByGitlabUsername, gitlabUsername)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.IsAvailable,
+		&i.TeamID,
+		&i.ReviewWeight,
+		&i.MaxOpenReviews,
+		&i.GithubLogin,
+		&i.GitlabUsername,
+		&i.Role,
+	)
+	return i, err
+}
+
 const getUserById = `-- name: GetUserById :one
-SELECT id, username, is_active, team_id
+SELECT id, username, is_available, team_id, review_weight, max_open_reviews, github_login, gitlab_username, role
 FROM users
 WHERE id = $1
 `
@@ -68,24 +123,124 @@ func (q *Queries) GetUserById(ctx context.Context, id string) (User, error) {
 	err := row.Scan(
 		&i.ID,
 		&i.Username,
-		&i.IsActive,
+		&i.IsAvailable,
 		&i.TeamID,
+		&i.ReviewWeight,
+		&i.MaxOpenReviews,
+		&i.GithubLogin,
+		&i.GitlabUsername,
+		&i.Role,
 	)
 	return i, err
 }
 
-const setUserIsActive = `-- name: SetUserIsActive :exec
+const getUserOpenReviews = `-- name: GetUserOpenReviews :one
+SELECT open_reviews
+FROM user_workload
+WHERE user_id = $1
+`
+
+func (q *Queries) GetUserOpenReviews(ctx context.Context, userID string) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getUserOpenReviews, userID)
+	var open_reviews int64
+	err := row.Scan(&open_reviews)
+	return open_reviews, err
+}
+
+const getUserWeights = `-- name: GetUserWeights :many
+SELECT id, review_weight
+FROM users
+WHERE id = ANY($1::text[])
+`
+
+type GetUserWeightsRow struct {
+	ID           string
+	ReviewWeight int32
+}
+
+func (q *Queries) GetUserWeights(ctx context.Context, ids []string) ([]GetUserWeightsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getUserWeights, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetUserWeightsRow
+	for rows.Next() {
+		var i GetUserWeightsRow
+		if err := rows.Scan(&i.ID, &i.ReviewWeight); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setUserAvailability = `-- name: SetUserAvailability :exec
 UPDATE users
-SET is_active = $2
+SET is_available = $2
 WHERE id = $1
 `
 
-type SetUserIsActiveParams struct {
-	ID       string
-	IsActive bool
+type SetUserAvailabilityParams struct {
+	ID          string
+	IsAvailable bool
+}
+
+func (q *Queries) SetUserAvailability(ctx context.Context, arg SetUserAvailabilityParams) error {
+	_, err := q.db.ExecContext(ctx, setUserAvailability, arg.ID, arg.IsAvailable)
+	return err
+}
+
+const setUserMaxOpenReviews = `-- name: SetUserMaxOpenReviews :exec
+UPDATE users
+SET max_open_reviews = $2
+WHERE id = $1
+`
+
+type SetUserMaxOpenReviewsParams struct {
+	ID             string
+	MaxOpenReviews sql.NullInt32
+}
+
+func (q *Queries) SetUserMaxOpenReviews(ctx context.Context, arg SetUserMaxOpenReviewsParams) error {
+	_, err := q.db.ExecContext(ctx, setUserMaxOpenReviews, arg.ID, arg.MaxOpenReviews)
+	return err
+}
+
+const setUserRole = `-- name: SetUserRole :exec
+UPDATE users
+SET role = $2
+WHERE id = $1
+`
+
+type SetUserRoleParams struct {
+	ID   string
+	Role string
+}
+
+func (q *Queries) SetUserRole(ctx context.Context, arg SetUserRoleParams) error {
+	_, err := q.db.ExecContext(ctx, setUserRole, arg.ID, arg.Role)
+	return err
+}
+
+const setUsersAvailability = `-- name: SetUsersAvailability :exec
+UPDATE users
+SET is_available = $1
+WHERE id = ANY($2::text[])
+`
+
+type SetUsersAvailabilityParams struct {
+	IsAvailable bool
+	Ids         []string
 }
 
-func (q *Queries) SetUserIsActive(ctx context.Context, arg SetUserIsActiveParams) error {
-	_, err := q.db.ExecContext(ctx, setUserIsActive, arg.ID, arg.IsActive)
+func (q *Queries) SetUsersAvailability(ctx context.Context, arg SetUsersAvailabilityParams) error {
+	_, err := q.db.ExecContext(ctx, setUsersAvailability, arg.IsAvailable, pq.Array(arg.Ids))
 	return err
 }
@@ -0,0 +1,400 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: webhooks.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const claimDueWebhookDeliveries = `-- name: ClaimDueWebhookDeliveries :many
+UPDATE webhook_deliveries d
+SET next_attempt_at = $1
+FROM webhook_subscriptions s
+WHERE s.id = d.subscription_id
+  AND d.id IN (
+      SELECT w.id
+      FROM webhook_deliveries w
+      WHERE w.status = 'PENDING'
+        AND w.next_attempt_at <= NOW()
+        AND NOT EXISTS (
+            SELECT 1
+            FROM webhook_deliveries prev
+            WHERE prev.subscription_id = w.subscription_id
+              AND prev.pr_id = w.pr_id
+              AND prev.status = 'PENDING'
+              AND prev.id < w.id
+        )
+      ORDER BY w.id
+      LIMIT $2
+      FOR UPDATE SKIP LOCKED
+  )
+RETURNING d.id, d.event_id, d.event_type, d.pr_id, d.payload, d.attempts, s.url, s.secret
+`
+
+type ClaimDueWebhookDeliveriesParams struct {
+	LeaseUntil time.Time
+	BatchSize  int32
+}
+
+type ClaimDueWebhookDeliveriesRow struct {
+	ID        int64
+	EventID   string
+	EventType string
+	PrID      string
+	Payload   json.RawMessage
+	Attempts  int32
+	Url       string
+	Secret    string
+}
+
+func (q *Queries) ClaimDueWebhookDeliveries(ctx context.Context, arg ClaimDueWebhookDeliveriesParams) ([]ClaimDueWebhookDeliveriesRow, error) {
+	rows, err := q.db.QueryContext(ctx, claimDueWebhookDeliveries, arg.LeaseUntil, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ClaimDueWebhookDeliveriesRow
+	for rows.Next() {
+		var i ClaimDueWebhookDeliveriesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.EventID,
+			&i.EventType,
+			&i.PrID,
+			&i.Payload,
+			&i.Attempts,
+			&i.Url,
+			&i.Secret,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const createWebhookDelivery = `-- name: CreateWebhookDelivery :exec
+INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, pr_id, payload)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (subscription_id, event_id) DO NOTHING
+`
+
+type CreateWebhookDeliveryParams struct {
+	SubscriptionID string
+	EventID        string
+	EventType      string
+	PrID           string
+	Payload        json.RawMessage
+}
+
+func (q *Queries) CreateWebhookDelivery(ctx context.Context, arg CreateWebhookDeliveryParams) error {
+	_, err := q.db.ExecContext(ctx, createWebhookDelivery,
+		arg.SubscriptionID,
+		arg.EventID,
+		arg.EventType,
+		arg.PrID,
+		arg.Payload,
+	)
+	return err
+}
+
+const createWebhookSubscription = `-- name: CreateWebhookSubscription :exec
+INSERT INTO webhook_subscriptions (id, url, events, secret)
+VALUES ($1, $2, $3, $4)
+`
+
+type CreateWebhookSubscriptionParams struct {
+	ID     string
+	Url    string
+	Events []string
+	Secret string
+}
+
+func (q *Queries) CreateWebhookSubscription(ctx context.Context, arg CreateWebhookSubscriptionParams) error {
+	_, err := q.db.ExecContext(ctx, createWebhookSubscription,
+		arg.ID,
+		arg.Url,
+		pq.Array(arg.Events),
+		arg.Secret,
+	)
+	return err
+}
+
+const deleteWebhookSubscription = `-- name: DeleteWebhookSubscription :execrows
+DELETE FROM webhook_subscriptions
+WHERE id = $1
+`
+
+func (q *Queries) DeleteWebhookSubscription(ctx context.Context, id string) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteWebhookSubscription, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getSubscriptionsForEvent = `-- name: GetSubscriptionsForEvent :many
+SELECT id, url, events, secret, created_at
+FROM webhook_subscriptions
+WHERE cardinality(events) = 0
+   OR $1::text = ANY(events)
+`
+
+func (q *Queries) GetSubscriptionsForEvent(ctx context.Context, eventType string) ([]WebhookSubscription, error) {
+	rows, err := q.db.QueryContext(ctx, getSubscriptionsForEvent, eventType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WebhookSubscription
+	for rows.Next() {
+		var i WebhookSubscription
+		if err := rows.Scan(
+			&i.ID,
+			&i.Url,
+			pq.Array(&i.Events),
+			&i.Secret,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getWebhookDeliveryByID = `-- name: GetWebhookDeliveryByID :one
+SELECT id, subscription_id, event_id, event_type, payload, status, attempts, response_status, last_error, next_attempt_at, created_at, delivered_at, pr_id
+FROM webhook_deliveries
+WHERE id = $1
+`
+
+func (q *Queries) GetWebhookDeliveryByID(ctx context.Context, id int64) (WebhookDelivery, error) {
+	row := q.db.QueryRowContext(ctx, getWebhookDeliveryByID, id)
+	var i WebhookDelivery
+	err := row.Scan(
+		&i.ID,
+		&i.SubscriptionID,
+		&i.EventID,
+		&i.EventType,
+		&i.Payload,
+		&i.Status,
+		&i.Attempts,
+		&i.ResponseStatus,
+		&i.LastError,
+		&i.NextAttemptAt,
+		&i.CreatedAt,
+		&i.DeliveredAt,
+		&i.PrID,
+	)
+	return i, err
+}
+
+const getWebhookSubscriptionByID = `-- name: GetWebhookSubscriptionByID :one
+SELECT id, url, events, secret, created_at
+FROM webhook_subscriptions
+WHERE id = $1
+`
+
+func (q *Queries) GetWebhookSubscriptionByID(ctx context.Context, id string) (WebhookSubscription, error) {
+	row := q.db.QueryRowContext(ctx, getWebhookSubscriptionByID, id)
+	var i WebhookSubscription
+	err := row.Scan(
+		&i.ID,
+		&i.Url,
+		pq.Array(&i.Events),
+		&i.Secret,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listWebhookDeliveries = `-- name: ListWebhookDeliveries :many
+SELECT id, subscription_id, event_id, event_type, pr_id, payload, status, attempts, response_status, last_error, next_attempt_at, created_at, delivered_at
+FROM webhook_deliveries
+WHERE ($2::text IS NULL OR subscription_id = $2)
+  AND ($3::text IS NULL OR status = $3)
+ORDER BY id DESC
+LIMIT $1
+`
+
+type ListWebhookDeliveriesParams struct {
+	Limit          int32
+	SubscriptionID sql.NullString
+	Status         sql.NullString
+}
+
+type ListWebhookDeliveriesRow struct {
+	ID             int64
+	SubscriptionID string
+	EventID        string
+	EventType      string
+	PrID           string
+	Payload        json.RawMessage
+	Status         string
+	Attempts       int32
+	ResponseStatus sql.NullInt32
+	LastError      sql.NullString
+	NextAttemptAt  time.Time
+	CreatedAt      time.Time
+	DeliveredAt    sql.NullTime
+}
+
+func (q *Queries) ListWebhookDeliveries(ctx context.Context, arg ListWebhookDeliveriesParams) ([]ListWebhookDeliveriesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listWebhookDeliveries, arg.Limit, arg.SubscriptionID, arg.Status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListWebhookDeliveriesRow
+	for rows.Next() {
+		var i ListWebhookDeliveriesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.SubscriptionID,
+			&i.EventID,
+			&i.EventType,
+			&i.PrID,
+			&i.Payload,
+			&i.Status,
+			&i.Attempts,
+			&i.ResponseStatus,
+			&i.LastError,
+			&i.NextAttemptAt,
+			&i.CreatedAt,
+			&i.DeliveredAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWebhookSubscriptions = `-- name: ListWebhookSubscriptions :many
+SELECT id, url, events, secret, created_at
+FROM webhook_subscriptions
+ORDER BY created_at, id
+`
+
+func (q *Queries) ListWebhookSubscriptions(ctx context.Context) ([]WebhookSubscription, error) {
+	rows, err := q.db.QueryContext(ctx, listWebhookSubscriptions)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WebhookSubscription
+	for rows.Next() {
+		var i WebhookSubscription
+		if err := rows.Scan(
+			&i.ID,
+			&i.Url,
+			pq.Array(&i.Events),
+			&i.Secret,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markWebhookAttemptFailed = `-- name: MarkWebhookAttemptFailed :exec
+UPDATE webhook_deliveries
+SET status = $1,
+    attempts = attempts + 1,
+    response_status = $2,
+    last_error = $3,
+    next_attempt_at = $4
+WHERE id = $5
+`
+
+type MarkWebhookAttemptFailedParams struct {
+	Status         string
+	ResponseStatus sql.NullInt32
+	LastError      sql.NullString
+	NextAttemptAt  time.Time
+	ID             int64
+}
+
+func (q *Queries) MarkWebhookAttemptFailed(ctx context.Context, arg MarkWebhookAttemptFailedParams) error {
+	_, err := q.db.ExecContext(ctx, markWebhookAttemptFailed,
+		arg.Status,
+		arg.ResponseStatus,
+		arg.LastError,
+		arg.NextAttemptAt,
+		arg.ID,
+	)
+	return err
+}
+
+const markWebhookDelivered = `-- name: MarkWebhookDelivered :exec
+UPDATE webhook_deliveries
+SET status = 'DELIVERED',
+    attempts = attempts + 1,
+    response_status = $2,
+    last_error = NULL,
+    delivered_at = NOW()
+WHERE id = $1
+`
+
+type MarkWebhookDeliveredParams struct {
+	ID             int64
+	ResponseStatus sql.NullInt32
+}
+
+func (q *Queries) MarkWebhookDelivered(ctx context.Context, arg MarkWebhookDeliveredParams) error {
+	_, err := q.db.ExecContext(ctx, markWebhookDelivered, arg.ID, arg.ResponseStatus)
+	return err
+}
+
+const requeueWebhookDelivery = `-- name: RequeueWebhookDelivery :execrows
+UPDATE webhook_deliveries
+SET status = 'PENDING',
+    attempts = 0,
+    last_error = NULL,
+    next_attempt_at = NOW()
+WHERE id = $1
+  AND status = 'DEAD'
+`
+
+func (q *Queries) RequeueWebhookDelivery(ctx context.Context, id int64) (int64, error) {
+	result, err := q.db.ExecContext(ctx, requeueWebhookDelivery, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,338 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/LlirikP/pr_dispenser/internal/database"
+	"github.com/LlirikP/pr_dispenser/internal/events"
+	"github.com/LlirikP/pr_dispenser/internal/reviewers"
+)
+
+type Picked struct {
+	ID       string
+	TeamID   string
+	Fallback bool
+}
+
+func selectReviewers(ctx context.Context, q database.Querier, team database.Team, candidates []string, count int) ([]string, error) {
+	selector, err := reviewers.ForTeam(team)
+	if err != nil {
+		return nil, err
+	}
+
+	return selector.Select(ctx, q, team, candidates, count)
+}
+
+func PickReviewers(ctx context.Context, q database.Querier, team database.Team, authorID string, exclude []string, count int) ([]Picked, error) {
+	skip := make(map[string]bool, len(exclude)+1)
+	skip[authorID] = true
+	for _, id := range exclude {
+		skip[id] = true
+	}
+
+	picked := make([]Picked, 0, count)
+	if count <= 0 {
+		return picked, nil
+	}
+
+	fallbacks, err := q.GetTeamFallbacks(ctx, team.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	pools := append([]database.Team{team}, fallbacks...)
+
+	for i, pool := range pools {
+		if len(picked) >= count {
+			break
+		}
+
+		members, err := q.GetAvailableTeamMembersExceptAuthor(ctx, database.GetAvailableTeamMembersExceptAuthorParams{
+			TeamID: pool.ID,
+			ID:     authorID,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		candidates := make([]string, 0, len(members))
+		for _, id := range members {
+			if !skip[id] {
+				candidates = append(candidates, id)
+			}
+		}
+
+		if len(candidates) == 0 {
+			continue
+		}
+
+		chosen, err := selectReviewers(ctx, q, pool, candidates, count-len(picked))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, id := range chosen {
+			skip[id] = true
+			picked = append(picked, Picked{
+				ID:       id,
+				TeamID:   pool.ID,
+				Fallback: i > 0,
+			})
+		}
+	}
+
+	return picked, nil
+}
+
+func replaceReviewer(ctx context.Context, q database.Querier, rec *events.Recorder, prID, authorID, oldReviewerID string, team database.Team) (Picked, error) {
+	current, err := q.GetReviewersByPR(ctx, prID)
+	if err != nil {
+		return Picked{}, err
+	}
+
+	picked, err := PickReviewers(ctx, q, team, authorID, current, 1)
+	if err != nil {
+		return Picked{}, err
+	}
+
+	if len(picked) == 0 {
+		return Picked{}, ErrNoCandidate
+	}
+
+	err = q.DeleteReviewer(ctx, database.DeleteReviewerParams{
+		PrID:       prID,
+		ReviewerID: oldReviewerID,
+	})
+	if err != nil {
+		return Picked{}, err
+	}
+
+	err = q.AddReviewer(ctx, database.AddReviewerParams{
+		PrID:       prID,
+		ReviewerID: picked[0].ID,
+	})
+	if err != nil {
+		return Picked{}, err
+	}
+
+	recordReassignment(rec, prID, authorID, oldReviewerID, picked[0])
+
+	return picked[0], nil
+}
+
+func assignInitialReviewers(ctx context.Context, q database.Querier, prID, authorID string, team database.Team, required int32, allowShortfall bool) ([]string, []string, error) {
+	picked, err := PickReviewers(ctx, q, team, authorID, nil, int(required))
+	if err != nil {
+		return nil, nil, storageError("failed to select reviewers", err)
+	}
+
+	if len(picked) < int(required) && !allowShortfall {
+		return nil, nil, newError(
+			KindConflict,
+			"NOT_ENOUGH_REVIEWERS",
+			fmt.Sprintf("team %s and its fallbacks have %d eligible reviewers, %d required", team.Teamname, len(picked), required),
+		)
+	}
+
+	assigned := make([]string, 0, len(picked))
+	fallback := []string{}
+
+	for _, reviewer := range picked {
+		err = q.AddReviewer(ctx, database.AddReviewerParams{
+			PrID:       prID,
+			ReviewerID: reviewer.ID,
+		})
+
+		if err != nil {
+			return nil, nil, storageError("failed to assign reviewer", err)
+		}
+
+		if reviewer.Fallback {
+			fallback = append(fallback, reviewer.ID)
+		}
+
+		assigned = append(assigned, reviewer.ID)
+	}
+
+	return assigned, fallback, nil
+}
+
+// candidatePool is one team's available members, loaded once per bulk
+// reassignment and kept current as reviewers are handed out.
+type candidatePool struct {
+	team    database.Team
+	loaded  bool
+	members []database.GetTeamCandidatesRow
+	load    map[string]int64
+	moved   bool
+}
+
+func (p *candidatePool) fill(ctx context.Context, q database.Querier) error {
+	if p.loaded {
+		return nil
+	}
+
+	members, err := q.GetTeamCandidates(ctx, p.team.ID)
+	if err != nil {
+		return err
+	}
+
+	p.members = members
+	p.load = make(map[string]int64, len(members))
+	for _, m := range members {
+		p.load[m.ID] = m.OpenReviews
+	}
+	p.loaded = true
+
+	return nil
+}
+
+func (p *candidatePool) candidates(skip map[string]bool) []string {
+	ids := make([]string, 0, len(p.members))
+	for _, m := range p.members {
+		if !skip[m.ID] && p.load[m.ID] < m.Capacity {
+			ids = append(ids, m.ID)
+		}
+	}
+	return ids
+}
+
+// poolQuerier answers the selectors' workload and weight lookups from the
+// pool and holds the round-robin cursor until the reassignment is done.
+type poolQuerier struct {
+	database.Querier
+	pool *candidatePool
+}
+
+func (q poolQuerier) CountOpenReviews(_ context.Context, reviewerIDs []string) ([]database.CountOpenReviewsRow, error) {
+	rows := make([]database.CountOpenReviewsRow, 0, len(reviewerIDs))
+	for _, id := range reviewerIDs {
+		rows = append(rows, database.CountOpenReviewsRow{ReviewerID: id, OpenReviews: q.pool.load[id]})
+	}
+	return rows, nil
+}
+
+func (q poolQuerier) GetUserWeights(_ context.Context, ids []string) ([]database.GetUserWeightsRow, error) {
+	rows := make([]database.GetUserWeightsRow, 0, len(ids))
+	for _, m := range q.pool.members {
+		if slices.Contains(ids, m.ID) {
+			rows = append(rows, database.GetUserWeightsRow{ID: m.ID, ReviewWeight: m.ReviewWeight})
+		}
+	}
+	return rows, nil
+}
+
+func (q poolQuerier) SetTeamRoundRobinCursor(_ context.Context, arg database.SetTeamRoundRobinCursorParams) error {
+	q.pool.team.RrLastReviewerID = arg.RrLastReviewerID
+	q.pool.moved = true
+	return nil
+}
+
+// bulkReassigner replaces reviewers across many PRs of one team. Candidates,
+// workload and current reviewers are read once up front instead of per review.
+type bulkReassigner struct {
+	q       database.Querier
+	rec     *events.Recorder
+	pools   []*candidatePool
+	current map[string][]string
+}
+
+func newBulkReassigner(ctx context.Context, q database.Querier, rec *events.Recorder, team database.Team, prIDs []string) (*bulkReassigner, error) {
+	fallbacks, err := q.GetTeamFallbacks(ctx, team.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	pools := make([]*candidatePool, 0, len(fallbacks)+1)
+	for _, t := range append([]database.Team{team}, fallbacks...) {
+		pools = append(pools, &candidatePool{team: t})
+	}
+
+	rows, err := q.GetReviewersByPRs(ctx, prIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	current := make(map[string][]string, len(prIDs))
+	for _, row := range rows {
+		current[row.PrID] = append(current[row.PrID], row.ReviewerID)
+	}
+
+	return &bulkReassigner{q: q, rec: rec, pools: pools, current: current}, nil
+}
+
+func (b *bulkReassigner) replace(ctx context.Context, prID, authorID, oldReviewerID string) (Picked, error) {
+	skip := make(map[string]bool, len(b.current[prID])+1)
+	skip[authorID] = true
+	for _, id := range b.current[prID] {
+		skip[id] = true
+	}
+
+	for i, pool := range b.pools {
+		if err := pool.fill(ctx, b.q); err != nil {
+			return Picked{}, err
+		}
+
+		candidates := pool.candidates(skip)
+		if len(candidates) == 0 {
+			continue
+		}
+
+		selector, err := reviewers.ForTeam(pool.team)
+		if err != nil {
+			return Picked{}, err
+		}
+
+		chosen, err := selector.Select(ctx, poolQuerier{Querier: b.q, pool: pool}, pool.team, candidates, 1)
+		if err != nil {
+			return Picked{}, err
+		}
+		if len(chosen) == 0 {
+			continue
+		}
+
+		picked := Picked{ID: chosen[0], TeamID: pool.team.ID, Fallback: i > 0}
+
+		err = b.q.DeleteReviewer(ctx, database.DeleteReviewerParams{PrID: prID, ReviewerID: oldReviewerID})
+		if err != nil {
+			return Picked{}, err
+		}
+
+		err = b.q.AddReviewer(ctx, database.AddReviewerParams{PrID: prID, ReviewerID: picked.ID})
+		if err != nil {
+			return Picked{}, err
+		}
+
+		pool.load[picked.ID]++
+		b.current[prID] = append(slices.DeleteFunc(b.current[prID], func(id string) bool {
+			return id == oldReviewerID
+		}), picked.ID)
+
+		recordReassignment(b.rec, prID, authorID, oldReviewerID, picked)
+
+		return picked, nil
+	}
+
+	return Picked{}, ErrNoCandidate
+}
+
+// flush stores the round-robin cursors advanced during the reassignment.
+func (b *bulkReassigner) flush(ctx context.Context) error {
+	for _, pool := range b.pools {
+		if !pool.moved {
+			continue
+		}
+
+		err := b.q.SetTeamRoundRobinCursor(ctx, database.SetTeamRoundRobinCursorParams{
+			ID:               pool.team.ID,
+			RrLastReviewerID: pool.team.RrLastReviewerID,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
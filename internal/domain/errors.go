@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindInvalid
+	KindNotFound
+	KindConflict
+	KindForbidden
+)
+
+var ErrNoCandidate = errors.New("no replacement candidate")
+
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	Err     error
+}
+
+func newError(kind Kind, code, msg string) *Error {
+	return &Error{Kind: kind, Code: code, Message: msg}
+}
+
+func storageError(msg string, err error) *Error {
+	return &Error{Kind: KindInternal, Code: "DB_ERROR", Message: msg, Err: err}
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Err)
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/LlirikP/pr_dispenser/internal/database"
+	"github.com/LlirikP/pr_dispenser/internal/events"
+)
+
+func nullTimePtr(v sql.NullTime) *time.Time {
+	if !v.Valid {
+		return nil
+	}
+	return &v.Time
+}
+
+func prParticipants(ctx context.Context, q database.Querier, pr database.Pr) ([]string, error) {
+	reviewers, err := q.GetReviewersByPR(ctx, pr.ID)
+	if err != nil {
+		return nil, fmt.Errorf("load reviewers: %w", err)
+	}
+
+	return append([]string{pr.AuthorID}, reviewers...), nil
+}
+
+func recordAssignments(rec *events.Recorder, prID, authorID string, assigned, fallback []string) {
+	fromFallback := make(map[string]bool, len(fallback))
+	for _, id := range fallback {
+		fromFallback[id] = true
+	}
+
+	for _, id := range assigned {
+		rec.Record(events.ReviewerAssigned, prID, []string{authorID, id}, events.ReviewerAssignedData{
+			ReviewerID:   id,
+			FromFallback: fromFallback[id],
+		})
+	}
+}
+
+func recordReassignment(rec *events.Recorder, prID, authorID, oldReviewerID string, replacement Picked) {
+	rec.Record(events.ReviewerReassigned, prID, []string{authorID, oldReviewerID, replacement.ID}, events.ReviewerReassignedData{
+		OldReviewerID: oldReviewerID,
+		NewReviewerID: replacement.ID,
+		FromFallback:  replacement.Fallback,
+	})
+}
@@ -0,0 +1,407 @@
+package domain
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/LlirikP/pr_dispenser/internal/database"
+	"github.com/LlirikP/pr_dispenser/internal/events"
+)
+
+type CreatePRParams struct {
+	PrID           string
+	Title          string
+	AuthorID       string
+	ReviewersCount *int32
+	Draft          bool
+	AllowShortfall bool
+}
+
+type MergePRParams struct {
+	PrID  string
+	Force bool
+	// MergedUpstream records a merge that already happened on the git
+	// host: approvals are not checked, but the PR is not force merged.
+	MergedUpstream bool
+	ActorID        string
+}
+
+type ReopenPRParams struct {
+	PrID               string
+	ReassignIneligible bool
+}
+
+type Reassignment struct {
+	PrID         string `json:"pull_request_id"`
+	OldUserID    string `json:"old_user_id"`
+	NewUserID    string `json:"new_user_id"`
+	FromFallback bool   `json:"from_fallback"`
+}
+
+type FailedReassignment struct {
+	PrID      string `json:"pull_request_id"`
+	OldUserID string `json:"old_user_id"`
+	Reason    string `json:"reason"`
+}
+
+type CreatedPR struct {
+	Required  int32
+	Assigned  []string
+	Fallback  []string
+	Shortfall int32
+}
+
+func CreatePR(ctx context.Context, q database.Querier, rec *events.Recorder, params CreatePRParams) (CreatedPR, error) {
+	created := CreatedPR{
+		Assigned: []string{},
+		Fallback: []string{},
+	}
+
+	author, err := q.GetUserById(ctx, params.AuthorID)
+	if err != nil {
+		log.Printf("error finding user, %v", err)
+		return created, newError(KindNotFound, "USER_NOT_FOUND", "author not found")
+	}
+
+	_, err = q.CheckDuplicatePR(ctx, database.CheckDuplicatePRParams{
+		AuthorID: params.AuthorID,
+		Title:    params.Title,
+	})
+
+	if err == nil {
+		return created, newError(KindConflict, "PR_EXISTS", "PR already exists")
+	}
+
+	if !errors.Is(err, sql.ErrNoRows) {
+		return created, storageError("failed to check duplicate PR", err)
+	}
+
+	team, err := q.GetTeamByID(ctx, author.TeamID)
+	if err != nil {
+		return created, storageError("failed to load author's team", err)
+	}
+
+	created.Required = team.ReviewersRequired
+	if params.ReviewersCount != nil {
+		if *params.ReviewersCount < team.ApprovalsRequired {
+			return created, newError(KindInvalid, "BAD_REQUEST", fmt.Sprintf("reviewers_count must be at least the team's approvals_required (%d)", team.ApprovalsRequired))
+		}
+		created.Required = *params.ReviewersCount
+	}
+
+	err = q.CreatePR(ctx, database.CreatePRParams{
+		ID:                params.PrID,
+		Title:             params.Title,
+		AuthorID:          params.AuthorID,
+		ReviewersRequired: created.Required,
+		IsDraft:           params.Draft,
+	})
+
+	if err != nil {
+		return created, storageError("failed to create PR", err)
+	}
+
+	if !params.Draft {
+		created.Assigned, created.Fallback, err = assignInitialReviewers(ctx, q, params.PrID, author.ID, team, created.Required, params.AllowShortfall)
+		if err != nil {
+			return created, err
+		}
+
+		created.Shortfall = created.Required - int32(len(created.Assigned))
+		if created.Shortfall > 0 {
+			log.Printf("PR %s created with %d of %d reviewers, team %s has no more eligible reviewers", params.PrID, len(created.Assigned), created.Required, team.Teamname)
+		}
+	}
+
+	rec.Record(events.PRCreated, params.PrID, append([]string{params.AuthorID}, created.Assigned...), events.PRCreatedData{
+		Title:     params.Title,
+		AuthorID:  params.AuthorID,
+		Draft:     params.Draft,
+		Reviewers: created.Assigned,
+	})
+	recordAssignments(rec, params.PrID, params.AuthorID, created.Assigned, created.Fallback)
+
+	return created, nil
+}
+
+func MarkPRReady(ctx context.Context, q database.Querier, rec *events.Recorder, prID string, allowShortfall bool) (database.Pr, []string, error) {
+	fallback := []string{}
+
+	pr, err := q.GetPRById(ctx, prID)
+	if err != nil {
+		return pr, nil, newError(KindNotFound, "PR_NOT_FOUND", "unknown PR")
+	}
+
+	if pr.Status != "OPEN" {
+		return pr, nil, newError(KindConflict, "PR_"+pr.Status, "only open PRs can be marked ready")
+	}
+
+	if !pr.IsDraft {
+		return pr, fallback, nil
+	}
+
+	author, err := q.GetUserById(ctx, pr.AuthorID)
+	if err != nil {
+		return pr, nil, storageError("failed to load PR author", err)
+	}
+
+	team, err := q.GetTeamByID(ctx, author.TeamID)
+	if err != nil {
+		return pr, nil, storageError("failed to load author's team", err)
+	}
+
+	err = q.MarkPRReady(ctx, pr.ID)
+	if err != nil {
+		return pr, nil, storageError("failed to mark PR ready", err)
+	}
+
+	assigned, fallback, err := assignInitialReviewers(ctx, q, pr.ID, author.ID, team, pr.ReviewersRequired, allowShortfall)
+	if err != nil {
+		return pr, nil, err
+	}
+
+	if len(assigned) < int(pr.ReviewersRequired) {
+		log.Printf("PR %s marked ready with %d of %d reviewers, team %s has no more eligible reviewers", pr.ID, len(assigned), pr.ReviewersRequired, team.Teamname)
+	}
+
+	recordAssignments(rec, pr.ID, pr.AuthorID, assigned, fallback)
+
+	pr.IsDraft = false
+
+	return pr, fallback, nil
+}
+
+func MergePR(ctx context.Context, q database.Querier, rec *events.Recorder, params MergePRParams) (database.Pr, error) {
+	pr, err := q.GetPRById(ctx, params.PrID)
+	if err != nil {
+		log.Printf("error finding pr, %v", err)
+		return pr, newError(KindNotFound, "PR_NOT_FOUND", "unknown PR")
+	}
+
+	if pr.Status == "MERGED" {
+		return pr, nil
+	}
+
+	if pr.Status == "CLOSED" {
+		return pr, newError(KindConflict, "PR_CLOSED", "cannot merge closed PR, reopen it first")
+	}
+
+	if pr.IsDraft {
+		return pr, newError(KindConflict, "PR_DRAFT", "cannot merge draft PR")
+	}
+
+	if !params.Force && !params.MergedUpstream {
+		if err := CheckApprovals(ctx, q, pr); err != nil {
+			return pr, err
+		}
+	}
+
+	err = q.MergePR(ctx, database.MergePRParams{
+		ID:            params.PrID,
+		ForceMerged:   params.Force,
+		ForceMergedBy: sql.NullString{String: params.ActorID, Valid: params.Force},
+	})
+	if err != nil {
+		return pr, storageError("failed to merge PR", err)
+	}
+
+	if params.Force {
+		log.Printf("PR %s force merged by %s", params.PrID, params.ActorID)
+	} else if params.MergedUpstream {
+		log.Printf("PR %s merged upstream by %s", params.PrID, params.ActorID)
+	}
+
+	pr, err = q.GetPRById(ctx, params.PrID)
+	if err != nil {
+		return pr, storageError("failed to reload PR", err)
+	}
+
+	participants, err := prParticipants(ctx, q, pr)
+	if err != nil {
+		return pr, storageError("failed to load PR participants", err)
+	}
+
+	rec.Record(events.PRMerged, pr.ID, participants, events.PRMergedData{
+		AuthorID:      pr.AuthorID,
+		MergedAt:      nullTimePtr(pr.MergedAt),
+		ForceMerged:   pr.ForceMerged,
+		ForceMergedBy: pr.ForceMergedBy.String,
+	})
+
+	return pr, nil
+}
+
+func ClosePR(ctx context.Context, q database.Querier, rec *events.Recorder, prID string) (database.Pr, error) {
+	pr, err := q.GetPRById(ctx, prID)
+	if err != nil {
+		return pr, newError(KindNotFound, "PR_NOT_FOUND", "unknown PR")
+	}
+
+	if pr.Status == "MERGED" {
+		return pr, newError(KindConflict, "PR_MERGED", "cannot close merged PR")
+	}
+
+	if pr.Status == "CLOSED" {
+		return pr, nil
+	}
+
+	err = q.ClosePR(ctx, prID)
+	if err != nil {
+		return pr, storageError("failed to close PR", err)
+	}
+
+	pr, err = q.GetPRById(ctx, prID)
+	if err != nil {
+		return pr, storageError("failed to reload PR", err)
+	}
+
+	participants, err := prParticipants(ctx, q, pr)
+	if err != nil {
+		return pr, storageError("failed to load PR participants", err)
+	}
+
+	rec.Record(events.PRClosed, pr.ID, participants, events.PRStatusData{
+		AuthorID: pr.AuthorID,
+		Status:   pr.Status,
+	})
+
+	return pr, nil
+}
+
+type ReopenedPR struct {
+	PR         database.Pr
+	Reassigned []Reassignment
+	Failed     []FailedReassignment
+}
+
+func ReopenPR(ctx context.Context, q database.Querier, rec *events.Recorder, params ReopenPRParams) (ReopenedPR, error) {
+	reopened := ReopenedPR{
+		Reassigned: []Reassignment{},
+		Failed:     []FailedReassignment{},
+	}
+
+	pr, err := q.GetPRById(ctx, params.PrID)
+	if err != nil {
+		return reopened, newError(KindNotFound, "PR_NOT_FOUND", "unknown PR")
+	}
+
+	if pr.Status == "MERGED" {
+		return reopened, newError(KindConflict, "PR_MERGED", "cannot reopen merged PR")
+	}
+
+	if pr.Status == "CLOSED" {
+		err = q.ReopenPR(ctx, params.PrID)
+		if err != nil {
+			return reopened, storageError("failed to reopen PR", err)
+		}
+
+		pr, err = q.GetPRById(ctx, params.PrID)
+		if err != nil {
+			return reopened, storageError("failed to reload PR", err)
+		}
+
+		participants, err := prParticipants(ctx, q, pr)
+		if err != nil {
+			return reopened, storageError("failed to load PR participants", err)
+		}
+
+		rec.Record(events.PRReopened, pr.ID, participants, events.PRStatusData{
+			AuthorID: pr.AuthorID,
+			Status:   pr.Status,
+		})
+	}
+
+	reopened.PR = pr
+
+	if !params.ReassignIneligible {
+		return reopened, nil
+	}
+
+	ineligible, err := q.GetIneligibleReviewersByPR(ctx, params.PrID)
+	if err != nil {
+		return reopened, storageError("failed to check reviewers", err)
+	}
+
+	for _, old := range ineligible {
+		team, err := q.GetTeamByID(ctx, old.TeamID)
+		if err != nil {
+			return reopened, storageError("failed to load reviewer's team", err)
+		}
+
+		replacement, err := replaceReviewer(ctx, q, rec, pr.ID, pr.AuthorID, old.ID, team)
+		if errors.Is(err, ErrNoCandidate) {
+			reopened.Failed = append(reopened.Failed, FailedReassignment{
+				PrID:      pr.ID,
+				OldUserID: old.ID,
+				Reason:    "NO_CANDIDATE",
+			})
+			continue
+		}
+
+		if err != nil {
+			return reopened, storageError("failed to reassign reviewer", err)
+		}
+
+		reopened.Reassigned = append(reopened.Reassigned, Reassignment{
+			PrID:         pr.ID,
+			OldUserID:    old.ID,
+			NewUserID:    replacement.ID,
+			FromFallback: replacement.Fallback,
+		})
+	}
+
+	return reopened, nil
+}
+
+func ReassignReviewer(ctx context.Context, q database.Querier, rec *events.Recorder, prID, reviewerID string) (database.Pr, Picked, error) {
+	pr, err := q.GetPRById(ctx, prID)
+	if err != nil {
+		return pr, Picked{}, newError(KindNotFound, "PR_NOT_FOUND", "unknown PR")
+	}
+
+	if pr.Status == "MERGED" {
+		return pr, Picked{}, newError(KindConflict, "PR_MERGED", "cannot reassign on merged PR")
+	}
+
+	if pr.Status == "CLOSED" {
+		return pr, Picked{}, newError(KindConflict, "PR_CLOSED", "cannot reassign on closed PR")
+	}
+
+	reviewer, err := q.GetUserById(ctx, reviewerID)
+	if err != nil {
+		log.Printf("error finding user, %v", err)
+		return pr, Picked{}, newError(KindNotFound, "USER_NOT_FOUND", "user not found")
+	}
+
+	assigned, err := q.IsReviewerAssigned(ctx, database.IsReviewerAssignedParams{
+		PrID:       prID,
+		ReviewerID: reviewerID,
+	})
+
+	if err != nil {
+		return pr, Picked{}, storageError("failed to check reviewer assignment", err)
+	}
+
+	if !assigned {
+		return pr, Picked{}, newError(KindConflict, "NOT_ASSIGNED", "reviewer is not assigned to this PR")
+	}
+
+	team, err := q.GetTeamByID(ctx, reviewer.TeamID)
+	if err != nil {
+		return pr, Picked{}, storageError("failed to load reviewer's team", err)
+	}
+
+	replacement, err := replaceReviewer(ctx, q, rec, pr.ID, pr.AuthorID, reviewer.ID, team)
+	if errors.Is(err, ErrNoCandidate) {
+		return pr, Picked{}, newError(KindConflict, "NO_CANDIDATE", "no active replacement candidate in team or its fallbacks")
+	}
+
+	if err != nil {
+		return pr, Picked{}, storageError("failed to reassign reviewer", err)
+	}
+
+	return pr, replacement, nil
+}
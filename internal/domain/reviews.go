@@ -0,0 +1,89 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/LlirikP/pr_dispenser/internal/database"
+)
+
+const (
+	ReviewPending          = "PENDING"
+	ReviewApproved         = "APPROVED"
+	ReviewChangesRequested = "CHANGES_REQUESTED"
+	ReviewCommented        = "COMMENTED"
+)
+
+type SubmitReviewParams struct {
+	PrID       string
+	ReviewerID string
+	State      string
+}
+
+func CheckApprovals(ctx context.Context, q database.Querier, pr database.Pr) error {
+	author, err := q.GetUserById(ctx, pr.AuthorID)
+	if err != nil {
+		return storageError("failed to load PR author", err)
+	}
+
+	team, err := q.GetTeamByID(ctx, author.TeamID)
+	if err != nil {
+		return storageError("failed to load author's team", err)
+	}
+
+	reviews, err := q.GetReviewsByPR(ctx, pr.ID)
+	if err != nil {
+		return storageError("failed to load reviews", err)
+	}
+
+	approved := 0
+	for _, review := range reviews {
+		switch review.State {
+		case ReviewChangesRequested:
+			return newError(KindConflict, "CHANGES_REQUESTED", fmt.Sprintf("%s requested changes", review.ReviewerID))
+		case ReviewApproved:
+			approved++
+		}
+	}
+
+	required := int(team.ApprovalsRequired)
+	if len(reviews) < required {
+		return newError(KindConflict, "NOT_ENOUGH_REVIEWERS", fmt.Sprintf("PR has %d reviewers but %d approvals are required", len(reviews), required))
+	}
+
+	if approved < required {
+		return newError(KindConflict, "NOT_APPROVED", fmt.Sprintf("PR has %d of %d required approvals", approved, required))
+	}
+
+	return nil
+}
+
+func SubmitReview(ctx context.Context, q database.Querier, params SubmitReviewParams) (database.Pr, error) {
+	pr, err := q.GetPRById(ctx, params.PrID)
+	if err != nil {
+		return pr, newError(KindNotFound, "PR_NOT_FOUND", "unknown PR")
+	}
+
+	if pr.Status == "MERGED" {
+		return pr, newError(KindConflict, "PR_MERGED", "cannot review merged PR")
+	}
+
+	if pr.Status == "CLOSED" {
+		return pr, newError(KindConflict, "PR_CLOSED", "cannot review closed PR")
+	}
+
+	updated, err := q.SetReviewState(ctx, database.SetReviewStateParams{
+		PrID:       params.PrID,
+		ReviewerID: params.ReviewerID,
+		State:      params.State,
+	})
+	if err != nil {
+		return pr, storageError("failed to save review", err)
+	}
+
+	if updated == 0 {
+		return pr, newError(KindConflict, "NOT_ASSIGNED", "reviewer is not assigned to this PR")
+	}
+
+	return pr, nil
+}
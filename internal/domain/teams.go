@@ -0,0 +1,150 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/LlirikP/pr_dispenser/internal/database"
+	"github.com/LlirikP/pr_dispenser/internal/events"
+)
+
+type DeactivationReport struct {
+	Deactivated []string
+	Reassigned  []Reassignment
+	Failed      []FailedReassignment
+}
+
+func SetTeamFallbacks(ctx context.Context, q database.Querier, teamName string, fallbackTeams []string) error {
+	team, err := q.GetTeamByName(ctx, teamName)
+	if err != nil {
+		return newError(KindNotFound, "NOT_FOUND", "team not found")
+	}
+
+	err = q.DeleteTeamFallbacks(ctx, team.ID)
+	if err != nil {
+		return storageError("failed to clear fallback teams", err)
+	}
+
+	seen := make(map[string]bool, len(fallbackTeams))
+
+	for i, name := range fallbackTeams {
+		if name == team.Teamname || seen[name] {
+			return newError(KindInvalid, "BAD_REQUEST", fmt.Sprintf("invalid fallback team %s", name))
+		}
+		seen[name] = true
+
+		fallback, err := q.GetTeamByName(ctx, name)
+		if err != nil {
+			return newError(KindNotFound, "NOT_FOUND", fmt.Sprintf("fallback team %s not found", name))
+		}
+
+		err = q.AddTeamFallback(ctx, database.AddTeamFallbackParams{
+			TeamID:         team.ID,
+			FallbackTeamID: fallback.ID,
+			Priority:       int32(i),
+		})
+		if err != nil {
+			return storageError("failed to add fallback team", err)
+		}
+	}
+
+	return nil
+}
+
+func DeactivateTeam(ctx context.Context, q database.Querier, rec *events.Recorder, teamName string, userIDs []string) (DeactivationReport, error) {
+	report := DeactivationReport{
+		Deactivated: []string{},
+		Reassigned:  []Reassignment{},
+		Failed:      []FailedReassignment{},
+	}
+
+	team, err := q.GetTeamByName(ctx, teamName)
+	if err != nil {
+		return report, newError(KindNotFound, "NOT_FOUND", "team not found")
+	}
+
+	members, err := q.GetUsersByTeam(ctx, team.ID)
+	if err != nil {
+		return report, storageError("could not get team users", err)
+	}
+
+	inTeam := make(map[string]bool, len(members))
+	for _, m := range members {
+		inTeam[m.ID] = true
+	}
+
+	targets := userIDs
+	if len(targets) == 0 {
+		targets = make([]string, 0, len(members))
+		for _, m := range members {
+			targets = append(targets, m.ID)
+		}
+	}
+
+	for _, id := range targets {
+		if !inTeam[id] {
+			return report, newError(KindInvalid, "BAD_REQUEST", fmt.Sprintf("user %s is not a member of %s", id, team.Teamname))
+		}
+	}
+
+	err = q.SetUsersAvailability(ctx, database.SetUsersAvailabilityParams{
+		IsAvailable: false,
+		Ids:         targets,
+	})
+	if err != nil {
+		return report, storageError("failed to deactivate users", err)
+	}
+
+	report.Deactivated = targets
+
+	open, err := q.GetOpenReviewsByReviewers(ctx, targets)
+	if err != nil {
+		return report, storageError("failed to load open reviews", err)
+	}
+
+	if len(open) == 0 {
+		return report, nil
+	}
+
+	prIDs := make([]string, 0, len(open))
+	for _, review := range open {
+		if len(prIDs) == 0 || prIDs[len(prIDs)-1] != review.PrID {
+			prIDs = append(prIDs, review.PrID)
+		}
+	}
+
+	reassigner, err := newBulkReassigner(ctx, q, rec, team, prIDs)
+	if err != nil {
+		return report, storageError("failed to load reviewer candidates", err)
+	}
+
+	for _, review := range open {
+		replacement, err := reassigner.replace(ctx, review.PrID, review.AuthorID, review.ReviewerID)
+		if errors.Is(err, ErrNoCandidate) {
+			report.Failed = append(report.Failed, FailedReassignment{
+				PrID:      review.PrID,
+				OldUserID: review.ReviewerID,
+				Reason:    "NO_CANDIDATE",
+			})
+			continue
+		}
+
+		if err != nil {
+			return report, storageError("failed to reassign reviewer", err)
+		}
+
+		report.Reassigned = append(report.Reassigned, Reassignment{
+			PrID:         review.PrID,
+			OldUserID:    review.ReviewerID,
+			NewUserID:    replacement.ID,
+			FromFallback: replacement.Fallback,
+		})
+	}
+
+	if err := reassigner.flush(ctx); err != nil {
+		return report, storageError("failed to save round-robin cursor", err)
+	}
+
+	return report, nil
+}
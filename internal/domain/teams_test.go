@@ -0,0 +1,286 @@
+package domain_test
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/LlirikP/pr_dispenser/internal/database"
+	"github.com/LlirikP/pr_dispenser/internal/database/memory"
+	"github.com/LlirikP/pr_dispenser/internal/domain"
+	"github.com/LlirikP/pr_dispenser/internal/events"
+)
+
+// countingQuerier counts the read queries a reassignment can issue, so tests
+// can tell per-team lookups from per-review ones.
+type countingQuerier struct {
+	database.Querier
+
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+func newCountingQuerier(q database.Querier) *countingQuerier {
+	return &countingQuerier{Querier: q, calls: map[string]int{}}
+}
+
+func (c *countingQuerier) count(name string) {
+	c.mu.Lock()
+	c.calls[name]++
+	c.mu.Unlock()
+}
+
+func (c *countingQuerier) GetReviewersByPR(ctx context.Context, prID string) ([]string, error) {
+	c.count("GetReviewersByPR")
+	return c.Querier.GetReviewersByPR(ctx, prID)
+}
+
+func (c *countingQuerier) GetReviewersByPRs(ctx context.Context, prIDs []string) ([]database.GetReviewersByPRsRow, error) {
+	c.count("GetReviewersByPRs")
+	return c.Querier.GetReviewersByPRs(ctx, prIDs)
+}
+
+func (c *countingQuerier) GetTeamFallbacks(ctx context.Context, teamID string) ([]database.Team, error) {
+	c.count("GetTeamFallbacks")
+	return c.Querier.GetTeamFallbacks(ctx, teamID)
+}
+
+func (c *countingQuerier) GetAvailableTeamMembersExceptAuthor(ctx context.Context, arg database.GetAvailableTeamMembersExceptAuthorParams) ([]string, error) {
+	c.count("GetAvailableTeamMembersExceptAuthor")
+	return c.Querier.GetAvailableTeamMembersExceptAuthor(ctx, arg)
+}
+
+func (c *countingQuerier) GetTeamCandidates(ctx context.Context, teamID string) ([]database.GetTeamCandidatesRow, error) {
+	c.count("GetTeamCandidates")
+	return c.Querier.GetTeamCandidates(ctx, teamID)
+}
+
+func (c *countingQuerier) CountOpenReviews(ctx context.Context, reviewerIDs []string) ([]database.CountOpenReviewsRow, error) {
+	c.count("CountOpenReviews")
+	return c.Querier.CountOpenReviews(ctx, reviewerIDs)
+}
+
+func (c *countingQuerier) GetUserWeights(ctx context.Context, ids []string) ([]database.GetUserWeightsRow, error) {
+	c.count("GetUserWeights")
+	return c.Querier.GetUserWeights(ctx, ids)
+}
+
+func (c *countingQuerier) SetTeamRoundRobinCursor(ctx context.Context, arg database.SetTeamRoundRobinCursorParams) error {
+	c.count("SetTeamRoundRobinCursor")
+	return c.Querier.SetTeamRoundRobinCursor(ctx, arg)
+}
+
+func seedTeam(tb testing.TB, q database.Querier, id string, capacity int32, strategy string, members int) []string {
+	tb.Helper()
+
+	ctx := context.Background()
+	err := q.CreateTeam(ctx, database.CreateTeamParams{
+		ID:                id,
+		Teamname:          id,
+		ReviewerStrategy:  strategy,
+		MaxOpenReviews:    capacity,
+		ReviewersRequired: 2,
+		ApprovalsRequired: 1,
+	})
+	if err != nil {
+		tb.Fatalf("create team: %v", err)
+	}
+
+	ids := make([]string, 0, members)
+	for i := 1; i <= members; i++ {
+		userID := fmt.Sprintf("%s-u%02d", id, i)
+		err := q.UpsertUser(ctx, database.UpsertUserParams{
+			ID:           userID,
+			Username:     userID,
+			IsAvailable:  true,
+			TeamID:       id,
+			ReviewWeight: 1,
+			Role:         "member",
+		})
+		if err != nil {
+			tb.Fatalf("upsert user: %v", err)
+		}
+		ids = append(ids, userID)
+	}
+
+	return ids
+}
+
+// seedPRs opens n PRs, each authored by one of authors and reviewed by
+// reviewers.
+func seedPRs(tb testing.TB, q database.Querier, n int, authors, reviewers []string) {
+	tb.Helper()
+
+	ctx := context.Background()
+	for i := 0; i < n; i++ {
+		prID := fmt.Sprintf("pr-%04d", i)
+		err := q.CreatePR(ctx, database.CreatePRParams{
+			ID:                prID,
+			Title:             prID,
+			AuthorID:          authors[i%len(authors)],
+			ReviewersRequired: int32(len(reviewers)),
+		})
+		if err != nil {
+			tb.Fatalf("create pr: %v", err)
+		}
+
+		for _, reviewerID := range reviewers {
+			if err := q.AddReviewer(ctx, database.AddReviewerParams{PrID: prID, ReviewerID: reviewerID}); err != nil {
+				tb.Fatalf("add reviewer: %v", err)
+			}
+		}
+	}
+}
+
+func TestDeactivateTeamBatchesLookups(t *testing.T) {
+	const prs = 300
+
+	for _, strategy := range []string{"least_loaded", "round_robin", "random", "weighted"} {
+		t.Run(strategy, func(t *testing.T) {
+			ctx := context.Background()
+			store := memory.New()
+			members := seedTeam(t, store, "backend", 1000, strategy, 10)
+			leaving, staying := members[:2], members[2:]
+			seedPRs(t, store, prs, staying, leaving)
+
+			q := newCountingQuerier(store)
+			report, err := domain.DeactivateTeam(ctx, q, &events.Recorder{}, "backend", leaving)
+			if err != nil {
+				t.Fatalf("deactivate: %v", err)
+			}
+
+			if len(report.Reassigned) != 2*prs || len(report.Failed) != 0 {
+				t.Fatalf("reassigned %d, failed %d, want %d and 0", len(report.Reassigned), len(report.Failed), 2*prs)
+			}
+
+			want := map[string]int{
+				"GetTeamFallbacks":  1,
+				"GetReviewersByPRs": 1,
+				"GetTeamCandidates": 1,
+			}
+			if strategy == "round_robin" {
+				want["SetTeamRoundRobinCursor"] = 1
+			}
+			if !maps.Equal(q.calls, want) {
+				t.Fatalf("queries = %v, want %v", q.calls, want)
+			}
+
+			load := map[string]int{}
+			for i := 0; i < prs; i++ {
+				prID := fmt.Sprintf("pr-%04d", i)
+				reviewers, err := store.GetReviewersByPR(ctx, prID)
+				if err != nil {
+					t.Fatalf("get reviewers: %v", err)
+				}
+
+				author := staying[i%len(staying)]
+				if len(reviewers) != 2 || reviewers[0] == reviewers[1] || slices.Contains(reviewers, author) {
+					t.Fatalf("%s reviewers = %v, author %s", prID, reviewers, author)
+				}
+				for _, id := range reviewers {
+					if slices.Contains(leaving, id) {
+						t.Fatalf("%s is still reviewed by deactivated %s", prID, id)
+					}
+					load[id]++
+				}
+			}
+
+			if strategy == "least_loaded" || strategy == "round_robin" {
+				lo, hi := 2*prs, 0
+				for _, id := range staying {
+					lo, hi = min(lo, load[id]), max(hi, load[id])
+				}
+				if hi-lo > 2 {
+					t.Fatalf("load = %v, want an even spread", load)
+				}
+			}
+		})
+	}
+}
+
+func TestDeactivateTeamRespectsCapacityAcrossReviews(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New()
+	members := seedTeam(t, store, "backend", 3, "least_loaded", 3)
+	fallback := seedTeam(t, store, "platform", 4, "least_loaded", 1)
+	if err := store.AddTeamFallback(ctx, database.AddTeamFallbackParams{TeamID: "backend", FallbackTeamID: "platform"}); err != nil {
+		t.Fatalf("add fallback: %v", err)
+	}
+
+	// Ten PRs by the fallback member: the two staying members take three
+	// each, the fallback cannot review its own PRs and four go unassigned.
+	seedPRs(t, store, 10, fallback, members[:1])
+
+	report, err := domain.DeactivateTeam(ctx, store, &events.Recorder{}, "backend", members[:1])
+	if err != nil {
+		t.Fatalf("deactivate: %v", err)
+	}
+
+	if len(report.Reassigned) != 6 || len(report.Failed) != 4 {
+		t.Fatalf("reassigned %d, failed %d, want 6 and 4", len(report.Reassigned), len(report.Failed))
+	}
+
+	rows, err := store.CountOpenReviews(ctx, members[1:])
+	if err != nil {
+		t.Fatalf("count open reviews: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("open reviews = %+v, want both staying members", rows)
+	}
+	for _, row := range rows {
+		if row.OpenReviews != 3 {
+			t.Fatalf("%s has %d open reviews, want 3", row.ReviewerID, row.OpenReviews)
+		}
+	}
+}
+
+func TestDeactivateTeamUsesFallbackWhenTeamIsFull(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New()
+	members := seedTeam(t, store, "backend", 2, "least_loaded", 2)
+	fallback := seedTeam(t, store, "platform", 10, "least_loaded", 2)
+	if err := store.AddTeamFallback(ctx, database.AddTeamFallbackParams{TeamID: "backend", FallbackTeamID: "platform"}); err != nil {
+		t.Fatalf("add fallback: %v", err)
+	}
+
+	seedPRs(t, store, 6, []string{"platform-u02"}, members[:1])
+
+	report, err := domain.DeactivateTeam(ctx, store, &events.Recorder{}, "backend", members[:1])
+	if err != nil {
+		t.Fatalf("deactivate: %v", err)
+	}
+
+	var fromFallback []string
+	for _, r := range report.Reassigned {
+		if r.FromFallback {
+			fromFallback = append(fromFallback, r.NewUserID)
+		}
+	}
+	if len(report.Reassigned) != 6 || len(fromFallback) != 4 || len(report.Failed) != 0 {
+		t.Fatalf("report = %+v, want 2 in team and 4 from the fallback", report)
+	}
+	for _, id := range fromFallback {
+		if id != fallback[0] {
+			t.Fatalf("fallback reviewer = %s, want %s", id, fallback[0])
+		}
+	}
+}
+
+func BenchmarkDeactivateTeam(b *testing.B) {
+	ctx := context.Background()
+
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		store := memory.New()
+		members := seedTeam(b, store, "backend", 1000, "least_loaded", 20)
+		seedPRs(b, store, 500, members[4:], members[:4])
+		b.StartTimer()
+
+		if _, err := domain.DeactivateTeam(ctx, store, &events.Recorder{}, "backend", members[:4]); err != nil {
+			b.Fatalf("deactivate: %v", err)
+		}
+	}
+}
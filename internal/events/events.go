@@ -0,0 +1,92 @@
+package events
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	PRCreated          = "pr.created"
+	PRMerged           = "pr.merged"
+	PRClosed           = "pr.closed"
+	PRReopened         = "pr.reopened"
+	ReviewerAssigned   = "reviewer.assigned"
+	ReviewerReassigned = "reviewer.reassigned"
+)
+
+var Types = []string{
+	PRCreated,
+	PRMerged,
+	PRClosed,
+	PRReopened,
+	ReviewerAssigned,
+	ReviewerReassigned,
+}
+
+func IsValidType(eventType string) bool {
+	for _, t := range Types {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+type Event struct {
+	ID         string    `json:"id"`
+	Type       string    `json:"type"`
+	PrID       string    `json:"pull_request_id"`
+	UserIDs    []string  `json:"user_ids"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Data       any       `json:"data"`
+}
+
+type Recorder struct {
+	events []Event
+}
+
+func (r *Recorder) Record(eventType, prID string, userIDs []string, data any) {
+	r.events = append(r.events, Event{
+		ID:         uuid.NewString(),
+		Type:       eventType,
+		PrID:       prID,
+		UserIDs:    userIDs,
+		OccurredAt: time.Now().UTC(),
+		Data:       data,
+	})
+}
+
+func (r *Recorder) Events() []Event {
+	return r.events
+}
+
+type PRCreatedData struct {
+	Title     string   `json:"pull_request_name"`
+	AuthorID  string   `json:"author_id"`
+	Draft     bool     `json:"draft"`
+	Reviewers []string `json:"assigned_reviewers"`
+}
+
+type ReviewerAssignedData struct {
+	ReviewerID   string `json:"reviewer_id"`
+	FromFallback bool   `json:"from_fallback"`
+}
+
+type ReviewerReassignedData struct {
+	OldReviewerID string `json:"old_user_id"`
+	NewReviewerID string `json:"new_user_id"`
+	FromFallback  bool   `json:"from_fallback"`
+}
+
+type PRMergedData struct {
+	AuthorID      string     `json:"author_id"`
+	MergedAt      *time.Time `json:"mergedAt"`
+	ForceMerged   bool       `json:"force_merged"`
+	ForceMergedBy string     `json:"force_merged_by,omitempty"`
+}
+
+type PRStatusData struct {
+	AuthorID string `json:"author_id"`
+	Status   string `json:"status"`
+}
@@ -0,0 +1,346 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/LlirikP/pr_dispenser/internal/audit"
+	"github.com/LlirikP/pr_dispenser/internal/auth"
+	"github.com/LlirikP/pr_dispenser/internal/database"
+)
+
+const (
+	defaultAuditLimit = 50
+	maxAuditLimit     = 500
+)
+
+type userSnapshot struct {
+	UserID         string  `json:"user_id"`
+	Username       string  `json:"username"`
+	TeamID         string  `json:"team_id"`
+	IsActive       bool    `json:"is_active"`
+	Role           string  `json:"role"`
+	ReviewWeight   int32   `json:"review_weight"`
+	MaxOpenReviews *int32  `json:"max_open_reviews"`
+	GithubLogin    *string `json:"github_login"`
+	GitlabUsername *string `json:"gitlab_username"`
+}
+
+type teamSnapshot struct {
+	TeamID            string `json:"team_id"`
+	TeamName          string `json:"team_name"`
+	ReviewerStrategy  string `json:"reviewer_strategy"`
+	MaxOpenReviews    int32  `json:"max_open_reviews"`
+	ReviewersRequired int32  `json:"reviewers_required"`
+	ApprovalsRequired int32  `json:"approvals_required"`
+}
+
+type teamFallbacksSnapshot struct {
+	TeamID        string   `json:"team_id"`
+	TeamName      string   `json:"team_name"`
+	FallbackTeams []string `json:"fallback_teams"`
+}
+
+type prSnapshot struct {
+	PrID              string     `json:"pull_request_id"`
+	Title             string     `json:"pull_request_name"`
+	AuthorID          string     `json:"author_id"`
+	Status            string     `json:"status"`
+	Draft             bool       `json:"draft"`
+	AssignedReviewers []string   `json:"assigned_reviewers"`
+	ForceMerged       bool       `json:"force_merged"`
+	ForceMergedBy     *string    `json:"force_merged_by"`
+	MergedAt          *time.Time `json:"merged_at"`
+}
+
+type auditEntryResponse struct {
+	ID         int64           `json:"id"`
+	Actor      string          `json:"actor"`
+	Action     string          `json:"action"`
+	TargetType string          `json:"target_type"`
+	TargetID   string          `json:"target_id"`
+	Before     json.RawMessage `json:"before"`
+	After      json.RawMessage `json:"after"`
+	RequestID  string          `json:"request_id"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+func toUserSnapshot(u database.User) *userSnapshot {
+	return &userSnapshot{
+		UserID:         u.ID,
+		Username:       u.Username,
+		TeamID:         u.TeamID,
+		IsActive:       u.IsAvailable,
+		Role:           u.Role,
+		ReviewWeight:   u.ReviewWeight,
+		MaxOpenReviews: nullInt32Ptr(u.MaxOpenReviews),
+		GithubLogin:    nullStringPtr(u.GithubLogin),
+		GitlabUsername: nullStringPtr(u.GitlabUsername),
+	}
+}
+
+func toTeamSnapshot(t database.Team) *teamSnapshot {
+	return &teamSnapshot{
+		TeamID:            t.ID,
+		TeamName:          t.Teamname,
+		ReviewerStrategy:  t.ReviewerStrategy,
+		MaxOpenReviews:    t.MaxOpenReviews,
+		ReviewersRequired: t.ReviewersRequired,
+		ApprovalsRequired: t.ApprovalsRequired,
+	}
+}
+
+func loadTeamFallbacksSnapshot(ctx context.Context, q database.Querier, team database.Team) (*teamFallbacksSnapshot, error) {
+	fallbacks, err := q.GetTeamFallbacks(ctx, team.ID)
+	if err != nil {
+		return nil, dbError("failed to load fallback teams", err)
+	}
+
+	names := make([]string, 0, len(fallbacks))
+	for _, f := range fallbacks {
+		names = append(names, f.Teamname)
+	}
+
+	return &teamFallbacksSnapshot{
+		TeamID:        team.ID,
+		TeamName:      team.Teamname,
+		FallbackTeams: names,
+	}, nil
+}
+
+func loadPRSnapshot(ctx context.Context, q database.Querier, prID string) (*prSnapshot, error) {
+	pr, err := q.GetPRById(ctx, prID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, dbError("failed to load pull request", err)
+	}
+
+	reviewers, err := q.GetReviewersByPR(ctx, prID)
+	if err != nil {
+		return nil, dbError("failed to load reviewers", err)
+	}
+
+	return toPRSnapshot(pr, reviewers), nil
+}
+
+func toPRSnapshot(pr database.Pr, reviewers []string) *prSnapshot {
+	return &prSnapshot{
+		PrID:              pr.ID,
+		Title:             pr.Title,
+		AuthorID:          pr.AuthorID,
+		Status:            pr.Status,
+		Draft:             pr.IsDraft,
+		AssignedReviewers: reviewers,
+		ForceMerged:       pr.ForceMerged,
+		ForceMergedBy:     nullStringPtr(pr.ForceMergedBy),
+		MergedAt:          nullTimePtr(pr.MergedAt),
+	}
+}
+
+func auditActor(ctx context.Context) string {
+	principal, ok := auth.FromContext(ctx)
+	switch {
+	case !ok:
+		return ""
+	case principal.UserID != "":
+		return principal.UserID
+	default:
+		return "token:" + principal.TokenID
+	}
+}
+
+func writeAudit(ctx context.Context, q database.Querier, actor string, entry audit.Entry) error {
+	if err := audit.Write(ctx, q, actor, entry); err != nil {
+		return dbError("failed to record audit entry", err)
+	}
+	return nil
+}
+
+func recordUserAudit(ctx context.Context, q database.Querier, action, userID string, before *userSnapshot) error {
+	after, err := q.GetUserById(ctx, userID)
+	if err != nil {
+		return dbError("failed to load user", err)
+	}
+
+	return writeAudit(ctx, q, auditActor(ctx), audit.Entry{
+		Action:     action,
+		TargetType: audit.TargetUser,
+		TargetID:   userID,
+		Before:     before,
+		After:      toUserSnapshot(after),
+	})
+}
+
+func recordTeamAudit(ctx context.Context, q database.Querier, action, teamID string, before *teamSnapshot) error {
+	after, err := q.GetTeamByID(ctx, teamID)
+	if err != nil {
+		return dbError("failed to load team", err)
+	}
+
+	return writeAudit(ctx, q, auditActor(ctx), audit.Entry{
+		Action:     action,
+		TargetType: audit.TargetTeam,
+		TargetID:   teamID,
+		Before:     before,
+		After:      toTeamSnapshot(after),
+	})
+}
+
+func recordTokenAudit(ctx context.Context, q database.Querier, action, tokenID string, before *tokenResponse) error {
+	after, err := q.GetAPITokenByID(ctx, tokenID)
+	if err != nil {
+		return dbError("failed to load token", err)
+	}
+	snapshot := toTokenResponse(after)
+
+	return writeAudit(ctx, q, auditActor(ctx), audit.Entry{
+		Action:     action,
+		TargetType: audit.TargetToken,
+		TargetID:   tokenID,
+		Before:     before,
+		After:      &snapshot,
+	})
+}
+
+func recordPRAudit(ctx context.Context, q database.Querier, actor, action, prID string, before *prSnapshot) error {
+	after, err := loadPRSnapshot(ctx, q, prID)
+	if err != nil {
+		return err
+	}
+
+	return writeAudit(ctx, q, actor, audit.Entry{
+		Action:     action,
+		TargetType: audit.TargetPR,
+		TargetID:   prID,
+		Before:     before,
+		After:      after,
+	})
+}
+
+func requestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get("X-Request-Id")
+		if id == "" {
+			id = uuid.NewString()
+		}
+
+		ctx := audit.WithRequestID(r.Context(), id)
+		w.Header().Set("X-Request-Id", audit.RequestID(ctx))
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func parseAuditTime(raw string) (sql.NullTime, error) {
+	if raw == "" {
+		return sql.NullTime{}, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return sql.NullTime{}, err
+	}
+	return sql.NullTime{Time: t, Valid: true}, nil
+}
+
+func (s *Server) ListAuditHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	query := r.URL.Query()
+
+	limit := int32(defaultAuditLimit)
+	if raw := query.Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 || n > maxAuditLimit {
+			RespondWithError(w, "BAD_REQUEST", "limit must be between 1 and 500", http.StatusBadRequest)
+			return
+		}
+		limit = int32(n)
+	}
+
+	var beforeID sql.NullInt64
+	if raw := query.Get("before"); raw != "" {
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || n <= 0 {
+			RespondWithError(w, "BAD_REQUEST", "before must be a positive entry id", http.StatusBadRequest)
+			return
+		}
+		beforeID = sql.NullInt64{Int64: n, Valid: true}
+	}
+
+	action := query.Get("action")
+	if action != "" && !audit.IsValidAction(action) {
+		RespondWithError(w, "BAD_REQUEST", "unknown action", http.StatusBadRequest)
+		return
+	}
+
+	since, err := parseAuditTime(query.Get("since"))
+	if err != nil {
+		RespondWithError(w, "BAD_REQUEST", "since must be an RFC 3339 timestamp", http.StatusBadRequest)
+		return
+	}
+
+	until, err := parseAuditTime(query.Get("until"))
+	if err != nil {
+		RespondWithError(w, "BAD_REQUEST", "until must be an RFC 3339 timestamp", http.StatusBadRequest)
+		return
+	}
+
+	actor := query.Get("actor")
+	targetType := query.Get("target_type")
+	targetID := query.Get("target_id")
+	reqID := query.Get("request_id")
+
+	entries, err := s.store.ListAuditEntries(ctx, database.ListAuditEntriesParams{
+		Actor:      stringPtrNull(&actor),
+		Action:     stringPtrNull(&action),
+		TargetType: stringPtrNull(&targetType),
+		TargetID:   stringPtrNull(&targetID),
+		RequestID:  stringPtrNull(&reqID),
+		Since:      since,
+		Until:      until,
+		BeforeID:   beforeID,
+		MaxRows:    limit,
+	})
+	if err != nil {
+		log.Printf("error listing audit entries, %v", err)
+		RespondWithError(w, "DB_ERROR", "failed to load audit entries", http.StatusInternalServerError)
+		return
+	}
+
+	resp := make([]auditEntryResponse, 0, len(entries))
+	for _, e := range entries {
+		resp = append(resp, auditEntryResponse{
+			ID:         e.ID,
+			Actor:      e.Actor,
+			Action:     e.Action,
+			TargetType: e.TargetType,
+			TargetID:   e.TargetID,
+			Before:     e.Before,
+			After:      e.After,
+			RequestID:  e.RequestID,
+			CreatedAt:  e.CreatedAt,
+		})
+	}
+
+	var next *int64
+	if len(entries) == int(limit) {
+		next = &entries[len(entries)-1].ID
+	}
+
+	RespondWithJSON(w, http.StatusOK, map[string]any{
+		"entries":     resp,
+		"next_before": next,
+	})
+}
@@ -0,0 +1,520 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/LlirikP/pr_dispenser/internal/audit"
+	"github.com/LlirikP/pr_dispenser/internal/config"
+	"github.com/LlirikP/pr_dispenser/internal/database"
+)
+
+// auditEntry returns the only audit entry recorded for action, with its
+// before and after snapshots decoded.
+func (e *testEnv) auditEntry(action string) (database.AuditLog, map[string]any, map[string]any) {
+	e.t.Helper()
+
+	entries, err := e.store.ListAuditEntries(context.Background(), database.ListAuditEntriesParams{
+		Action:  sql.NullString{String: action, Valid: true},
+		MaxRows: 10,
+	})
+	if err != nil {
+		e.t.Fatalf("list audit entries: %v", err)
+	}
+	if len(entries) != 1 {
+		e.t.Fatalf("%s entries = %d, want 1", action, len(entries))
+	}
+
+	var before, after map[string]any
+	if err := json.Unmarshal(entries[0].Before, &before); err != nil {
+		e.t.Fatalf("decode before %s: %v", entries[0].Before, err)
+	}
+	if err := json.Unmarshal(entries[0].After, &after); err != nil {
+		e.t.Fatalf("decode after %s: %v", entries[0].After, err)
+	}
+	return entries[0], before, after
+}
+
+func (e *testEnv) teamID(name string) string {
+	e.t.Helper()
+
+	team, err := e.store.GetTeamByName(context.Background(), name)
+	if err != nil {
+		e.t.Fatalf("get team %s: %v", name, err)
+	}
+	return team.ID
+}
+
+func newAuditEnv(t *testing.T) *testEnv {
+	t.Helper()
+
+	env := newTestEnv(t, config.Config{})
+	env.addTeam("backend", 2,
+		testMember{UserID: "u1", Username: "alice", IsActive: true},
+		testMember{UserID: "u2", Username: "bob", IsActive: true},
+	)
+	env.addTeam("platform", 1,
+		testMember{UserID: "u3", Username: "carol", IsActive: true},
+	)
+	return env
+}
+
+func TestTeamSettingsAreAudited(t *testing.T) {
+	tests := []struct {
+		path   string
+		body   map[string]any
+		action string
+		field  string
+		before any
+		after  any
+	}{
+		{"/team/setStrategy", map[string]any{"reviewer_strategy": "round_robin"}, audit.TeamStrategySet, "reviewer_strategy", "least_loaded", "round_robin"},
+		{"/team/setCapacity", map[string]any{"max_open_reviews": 7}, audit.TeamCapacitySet, "max_open_reviews", float64(1), float64(7)},
+		{"/team/setReviewersRequired", map[string]any{"reviewers_required": 1}, audit.TeamReviewersRequiredSet, "reviewers_required", float64(2), float64(1)},
+		{"/team/setApprovalsRequired", map[string]any{"approvals_required": 2}, audit.TeamApprovalsRequiredSet, "approvals_required", float64(1), float64(2)},
+		{"/team/setFallbacks", map[string]any{"fallback_teams": []string{"platform"}}, audit.TeamFallbacksSet, "fallback_teams", []any{}, []any{"platform"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.action, func(t *testing.T) {
+			env := newAuditEnv(t)
+
+			tt.body["team_name"] = "backend"
+			if rec := env.do(http.MethodPost, tt.path, env.admin, tt.body); rec.Code != http.StatusOK {
+				t.Fatalf("status %d, body %s", rec.Code, rec.Body)
+			}
+
+			entry, before, after := env.auditEntry(tt.action)
+			if entry.TargetType != audit.TargetTeam || entry.TargetID != env.teamID("backend") || !strings.HasPrefix(entry.Actor, "token:") {
+				t.Fatalf("entry = %+v", entry)
+			}
+			if !reflect.DeepEqual(before[tt.field], tt.before) || !reflect.DeepEqual(after[tt.field], tt.after) {
+				t.Fatalf("%s: before %v, after %v, want %v and %v", tt.field, before[tt.field], after[tt.field], tt.before, tt.after)
+			}
+		})
+	}
+}
+
+func TestUserCapacityIsAudited(t *testing.T) {
+	env := newAuditEnv(t)
+
+	rec := env.do(http.MethodPost, "/users/setCapacity", env.admin, map[string]any{"user_id": "u2", "max_open_reviews": 3})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d, body %s", rec.Code, rec.Body)
+	}
+
+	entry, before, after := env.auditEntry(audit.UserCapacitySet)
+	if entry.TargetType != audit.TargetUser || entry.TargetID != "u2" {
+		t.Fatalf("entry = %+v", entry)
+	}
+	if before["max_open_reviews"] != nil || after["max_open_reviews"] != float64(3) {
+		t.Fatalf("max_open_reviews: before %v, after %v", before["max_open_reviews"], after["max_open_reviews"])
+	}
+}
+
+func TestTokenLifecycleIsAudited(t *testing.T) {
+	env := newAuditEnv(t)
+
+	rec := env.do(http.MethodPost, "/tokens/create", env.admin, map[string]any{"name": "ci", "scope": "user", "user_id": "u1"})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: status %d, body %s", rec.Code, rec.Body)
+	}
+
+	created := struct {
+		Token tokenResponse `json:"token"`
+		Value string        `json:"value"`
+	}{}
+	decodeBody(t, rec, &created)
+
+	entry, _, after := env.auditEntry(audit.TokenCreated)
+	if entry.TargetType != audit.TargetToken || entry.TargetID != created.Token.TokenID || string(entry.Before) != "null" {
+		t.Fatalf("created entry = %+v", entry)
+	}
+	if after["scope"] != "user" || after["user_id"] != "u1" || after["revoked_at"] != nil {
+		t.Fatalf("created snapshot = %v", after)
+	}
+	if strings.Contains(string(entry.After), created.Value) || strings.Contains(string(entry.After), "hash") {
+		t.Fatalf("audit entry leaks the token: %s", entry.After)
+	}
+
+	rec = env.do(http.MethodPost, "/tokens/revoke", env.admin, map[string]any{"token_id": created.Token.TokenID})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("revoke: status %d, body %s", rec.Code, rec.Body)
+	}
+
+	entry, before, after := env.auditEntry(audit.TokenRevoked)
+	if entry.TargetID != created.Token.TokenID || before["revoked_at"] != nil || after["revoked_at"] == nil {
+		t.Fatalf("revoked entry = %+v", entry)
+	}
+
+	rec = env.do(http.MethodPost, "/tokens/revoke", env.admin, map[string]any{"token_id": created.Token.TokenID})
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("second revoke: status %d, body %s", rec.Code, rec.Body)
+	}
+	if rec = env.do(http.MethodPost, "/tokens/revoke", env.admin, map[string]any{"token_id": "missing"}); rec.Code != http.StatusNotFound {
+		t.Fatalf("revoke missing: status %d, body %s", rec.Code, rec.Body)
+	}
+	env.auditEntry(audit.TokenRevoked)
+}
+
+func TestSettingsRollBackWhenAuditFails(t *testing.T) {
+	env, store := newFailingEnv(t)
+	ctx := context.Background()
+
+	env.addTeam("platform", 1, testMember{UserID: "u5", Username: "erin", IsActive: true})
+	userToken := env.token("user", "u1")
+	tokens, err := env.store.ListAPITokens(ctx)
+	if err != nil {
+		t.Fatalf("list tokens: %v", err)
+	}
+	var userTokenID string
+	for _, token := range tokens {
+		if token.UserID.String == "u1" {
+			userTokenID = token.ID
+		}
+	}
+
+	store.failOn["InsertAuditEntry"] = true
+
+	requests := []struct {
+		path string
+		body map[string]any
+	}{
+		{"/team/setStrategy", map[string]any{"team_name": "backend", "reviewer_strategy": "random"}},
+		{"/team/setCapacity", map[string]any{"team_name": "backend", "max_open_reviews": 9}},
+		{"/team/setReviewersRequired", map[string]any{"team_name": "backend", "reviewers_required": 1}},
+		{"/team/setApprovalsRequired", map[string]any{"team_name": "backend", "approvals_required": 2}},
+		{"/team/setFallbacks", map[string]any{"team_name": "backend", "fallback_teams": []string{"platform"}}},
+		{"/users/setCapacity", map[string]any{"user_id": "u2", "max_open_reviews": 1}},
+		{"/tokens/create", map[string]any{"name": "ci", "scope": "admin"}},
+		{"/tokens/revoke", map[string]any{"token_id": userTokenID}},
+	}
+
+	for _, req := range requests {
+		if rec := env.do(http.MethodPost, req.path, env.admin, req.body); rec.Code != http.StatusInternalServerError {
+			t.Fatalf("%s: status %d, want 500, body %s", req.path, rec.Code, rec.Body)
+		}
+	}
+
+	team, err := env.store.GetTeamByName(ctx, "backend")
+	if err != nil {
+		t.Fatalf("get team: %v", err)
+	}
+	if team.ReviewerStrategy != "least_loaded" || team.MaxOpenReviews != 1 || team.ReviewersRequired != 2 || team.ApprovalsRequired != 1 {
+		t.Fatalf("team changed without an audit entry: %+v", team)
+	}
+
+	fallbacks, err := env.store.GetTeamFallbacks(ctx, team.ID)
+	if err != nil {
+		t.Fatalf("get fallbacks: %v", err)
+	}
+	if len(fallbacks) != 0 {
+		t.Fatalf("fallbacks changed without an audit entry: %+v", fallbacks)
+	}
+
+	user, err := env.store.GetUserById(ctx, "u2")
+	if err != nil {
+		t.Fatalf("get user: %v", err)
+	}
+	if user.MaxOpenReviews.Valid {
+		t.Fatalf("user capacity changed without an audit entry")
+	}
+
+	after, err := env.store.ListAPITokens(ctx)
+	if err != nil {
+		t.Fatalf("list tokens: %v", err)
+	}
+	if len(after) != len(tokens) {
+		t.Fatalf("token created without an audit entry")
+	}
+	if rec := env.do(http.MethodGet, "/users/getReview?user_id=u1", userToken, nil); rec.Code != http.StatusOK {
+		t.Fatalf("token revoked without an audit entry: status %d", rec.Code)
+	}
+}
+
+func TestDeactivationIsAudited(t *testing.T) {
+	env := newTestEnv(t, config.Config{})
+	env.addTeam("backend", 1,
+		testMember{UserID: "u1", Username: "alice", IsActive: true},
+		testMember{UserID: "u2", Username: "bob", IsActive: true},
+		testMember{UserID: "u3", Username: "carol", IsActive: true},
+	)
+	env.setTeamCapacity("backend", 5)
+
+	old := env.createPR("pr-1", "u1")[0]
+	reviewedByOld := 1
+	if slices.Contains(env.createPR("pr-2", "u1"), old) {
+		reviewedByOld++
+	}
+
+	rec := env.do(http.MethodPost, "/team/deactivate", env.admin, map[string]any{"team_name": "backend", "user_ids": []string{old}})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d, body %s", rec.Code, rec.Body)
+	}
+
+	entry, before, after := env.auditEntry(audit.UserActiveSet)
+	if entry.TargetID != old || before["is_active"] != true || after["is_active"] != false || after["username"] != before["username"] {
+		t.Fatalf("user entry = %+v, before %v, after %v", entry, before, after)
+	}
+
+	entries, err := env.store.ListAuditEntries(context.Background(), database.ListAuditEntriesParams{
+		Action:  sql.NullString{String: audit.PRReassigned, Valid: true},
+		MaxRows: 10,
+	})
+	if err != nil {
+		t.Fatalf("list audit entries: %v", err)
+	}
+
+	for _, entry := range entries {
+		var before, after prSnapshot
+		if err := json.Unmarshal(entry.Before, &before); err != nil {
+			t.Fatalf("decode before: %v", err)
+		}
+		if err := json.Unmarshal(entry.After, &after); err != nil {
+			t.Fatalf("decode after: %v", err)
+		}
+
+		if !reflect.DeepEqual(before.AssignedReviewers, []string{old}) {
+			t.Fatalf("%s before reviewers = %v, want [%s]", entry.TargetID, before.AssignedReviewers, old)
+		}
+		if got := env.reviewers(entry.TargetID); !reflect.DeepEqual(after.AssignedReviewers, got) {
+			t.Fatalf("%s after reviewers = %v, stored %v", entry.TargetID, after.AssignedReviewers, got)
+		}
+		if after.Title != before.Title || after.Status != "OPEN" {
+			t.Fatalf("%s after = %+v", entry.TargetID, after)
+		}
+	}
+
+	if len(entries) != reviewedByOld {
+		t.Fatalf("pr.reassigned entries = %d, want %d", len(entries), reviewedByOld)
+	}
+}
+
+func TestPRLifecycleIsAudited(t *testing.T) {
+	env := newTestEnv(t, config.Config{})
+	env.addTeam("backend", 1,
+		testMember{UserID: "u1", Username: "alice", IsActive: true},
+		testMember{UserID: "u2", Username: "bob", IsActive: true},
+		testMember{UserID: "u3", Username: "carol", IsActive: true},
+	)
+	env.setTeamCapacity("backend", 5)
+
+	reviewer := env.createPR("pr-1", "u1")[0]
+
+	steps := []struct {
+		path string
+		body map[string]any
+	}{
+		{"/pullRequest/review", map[string]any{"pull_request_id": "pr-1", "reviewer_id": reviewer, "state": "APPROVED"}},
+		{"/pullRequest/close", map[string]any{"pull_request_id": "pr-1"}},
+		{"/pullRequest/close", map[string]any{"pull_request_id": "pr-1"}},
+		{"/users/setIsActive", map[string]any{"user_id": reviewer, "is_active": false}},
+		{"/pullRequest/reopen", map[string]any{"pull_request_id": "pr-1", "reassign_ineligible": true}},
+		{"/pullRequest/merge", map[string]any{"pull_request_id": "pr-1", "force": true}},
+		{"/pullRequest/merge", map[string]any{"pull_request_id": "pr-1"}},
+	}
+	for _, step := range steps {
+		if rec := env.do(http.MethodPost, step.path, env.admin, step.body); rec.Code != http.StatusOK {
+			t.Fatalf("%s: status %d, body %s", step.path, rec.Code, rec.Body)
+		}
+	}
+
+	entry, before, after := env.auditEntry(audit.PRReviewSubmitted)
+	if entry.TargetType != audit.TargetPR || entry.TargetID != "pr-1" || before["state"] != "PENDING" || after["state"] != "APPROVED" || after["reviewer_id"] != reviewer || after["reviewed_at"] == nil {
+		t.Fatalf("review entry = %+v, before %v, after %v", entry, before, after)
+	}
+
+	_, before, after = env.auditEntry(audit.PRClosed)
+	if before["status"] != "OPEN" || after["status"] != "CLOSED" {
+		t.Fatalf("close: before %v, after %v", before, after)
+	}
+
+	_, before, after = env.auditEntry(audit.PRReopened)
+	if before["status"] != "CLOSED" || after["status"] != "OPEN" || !reflect.DeepEqual(after["assigned_reviewers"], []any{reviewer}) {
+		t.Fatalf("reopen: before %v, after %v", before, after)
+	}
+	reopened := after
+
+	_, before, after = env.auditEntry(audit.PRReassigned)
+	replacement := env.reviewers("pr-1")
+	if !reflect.DeepEqual(before, reopened) || len(replacement) != 1 || replacement[0] == reviewer || !reflect.DeepEqual(after["assigned_reviewers"], []any{replacement[0]}) {
+		t.Fatalf("reassign on reopen: before %v, after %v, stored %v", before, after, replacement)
+	}
+
+	_, before, after = env.auditEntry(audit.PRMerged)
+	if before["status"] != "OPEN" || after["status"] != "MERGED" || after["force_merged"] != true {
+		t.Fatalf("merge: before %v, after %v", before, after)
+	}
+}
+
+func TestReadyIsAudited(t *testing.T) {
+	env := newAuditEnv(t)
+
+	rec := env.do(http.MethodPost, "/pullRequest/create", env.admin, map[string]any{
+		"pull_request_id":   "pr-1",
+		"pull_request_name": "draft",
+		"author_id":         "u1",
+		"reviewers_count":   1,
+		"draft":             true,
+	})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: status %d, body %s", rec.Code, rec.Body)
+	}
+
+	for i := 0; i < 2; i++ {
+		if rec := env.do(http.MethodPost, "/pullRequest/ready", env.admin, map[string]any{"pull_request_id": "pr-1"}); rec.Code != http.StatusOK {
+			t.Fatalf("ready: status %d, body %s", rec.Code, rec.Body)
+		}
+	}
+
+	_, before, after := env.auditEntry(audit.PRReady)
+	if before["draft"] != true || after["draft"] != false || !reflect.DeepEqual(before["assigned_reviewers"], []any{}) || !reflect.DeepEqual(after["assigned_reviewers"], []any{"u2"}) {
+		t.Fatalf("ready: before %v, after %v", before, after)
+	}
+}
+
+func TestSubscriptionsAreAudited(t *testing.T) {
+	env := newAuditEnv(t)
+	ctx := context.Background()
+	stubLookup(t, map[string][]string{"example.com": {"93.184.215.14"}})
+
+	rec := env.do(http.MethodPost, "/subscriptions/add", env.admin, map[string]any{
+		"url":    "https://example.com/hook",
+		"events": []string{"pr.created"},
+		"secret": "0123456789abcdef",
+	})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: status %d, body %s", rec.Code, rec.Body)
+	}
+
+	created := struct {
+		Subscription subscriptionResponse `json:"subscription"`
+	}{}
+	decodeBody(t, rec, &created)
+	subID := created.Subscription.SubscriptionID
+
+	entry, _, after := env.auditEntry(audit.SubscriptionCreated)
+	if entry.TargetType != audit.TargetSubscription || entry.TargetID != subID || string(entry.Before) != "null" || after["url"] != "https://example.com/hook" {
+		t.Fatalf("created entry = %+v, after %v", entry, after)
+	}
+	if strings.Contains(string(entry.After), "0123456789abcdef") {
+		t.Fatalf("audit entry leaks the secret: %s", entry.After)
+	}
+
+	err := env.store.CreateWebhookDelivery(ctx, database.CreateWebhookDeliveryParams{
+		SubscriptionID: subID,
+		EventID:        "e1",
+		EventType:      "pr.created",
+		PrID:           "pr-1",
+		Payload:        json.RawMessage(`{}`),
+	})
+	if err != nil {
+		t.Fatalf("create delivery: %v", err)
+	}
+	deliveries, err := env.store.ListWebhookDeliveries(ctx, database.ListWebhookDeliveriesParams{Limit: 1})
+	if err != nil {
+		t.Fatalf("list deliveries: %v", err)
+	}
+	deliveryID := deliveries[0].ID
+
+	retry := map[string]any{"delivery_id": deliveryID}
+	if rec := env.do(http.MethodPost, "/subscriptions/deliveries/retry", env.admin, retry); rec.Code != http.StatusConflict {
+		t.Fatalf("retry pending: status %d, body %s", rec.Code, rec.Body)
+	}
+
+	err = env.store.MarkWebhookAttemptFailed(ctx, database.MarkWebhookAttemptFailedParams{
+		Status:        "DEAD",
+		LastError:     sql.NullString{String: "boom", Valid: true},
+		NextAttemptAt: time.Now(),
+		ID:            deliveryID,
+	})
+	if err != nil {
+		t.Fatalf("mark dead: %v", err)
+	}
+
+	if rec := env.do(http.MethodPost, "/subscriptions/deliveries/retry", env.admin, retry); rec.Code != http.StatusOK {
+		t.Fatalf("retry: status %d, body %s", rec.Code, rec.Body)
+	}
+
+	entry, before, after := env.auditEntry(audit.DeliveryRetried)
+	if entry.TargetType != audit.TargetDelivery || entry.TargetID != strconv.FormatInt(deliveryID, 10) || before["status"] != "DEAD" || after["status"] != "PENDING" {
+		t.Fatalf("retry entry = %+v, before %v, after %v", entry, before, after)
+	}
+
+	if rec := env.do(http.MethodPost, "/subscriptions/delete", env.admin, map[string]any{"subscription_id": subID}); rec.Code != http.StatusOK {
+		t.Fatalf("delete: status %d, body %s", rec.Code, rec.Body)
+	}
+	if rec := env.do(http.MethodPost, "/subscriptions/delete", env.admin, map[string]any{"subscription_id": subID}); rec.Code != http.StatusNotFound {
+		t.Fatalf("delete again: status %d, body %s", rec.Code, rec.Body)
+	}
+
+	entry, before, _ = env.auditEntry(audit.SubscriptionDeleted)
+	if entry.TargetID != subID || string(entry.After) != "null" || !reflect.DeepEqual(before["events"], []any{"pr.created"}) {
+		t.Fatalf("deleted entry = %+v, before %v", entry, before)
+	}
+}
+
+func TestWebhookActionsAreAudited(t *testing.T) {
+	t.Run("github", func(t *testing.T) {
+		env := newGithubEnv(t,
+			testMember{UserID: "u1", Username: "alice", IsActive: true, GithubLogin: ptr("alice-gh")},
+			testMember{UserID: "u2", Username: "bob", IsActive: true, GithubLogin: ptr("bob-gh")},
+			testMember{UserID: "u3", Username: "carol", IsActive: true},
+			testMember{UserID: "u4", Username: "dave", IsActive: true},
+		)
+
+		for _, fixture := range []string{
+			"pull_request_opened_draft.json",
+			"pull_request_ready_for_review.json",
+			"pull_request_opened.json",
+			"pull_request_closed.json",
+			"pull_request_reopened.json",
+			"pull_request_merged.json",
+			"pull_request_merged.json",
+		} {
+			env.githubFixture(fixture)
+		}
+
+		for _, action := range []string{audit.PRReady, audit.PRClosed, audit.PRReopened, audit.PRMerged} {
+			entry, _, _ := env.auditEntry(action)
+			if !strings.HasPrefix(entry.Actor, "github:") || entry.TargetType != audit.TargetPR {
+				t.Fatalf("%s entry = %+v", action, entry)
+			}
+		}
+	})
+
+	t.Run("gitlab", func(t *testing.T) {
+		env := newGitlabEnv(t)
+		const prID = "gitlab:acme/billing!7"
+
+		env.gitlabFixture("merge_request_open.json")
+		env.gitlabFixture("merge_request_approved.json")
+
+		entry, before, after := env.auditEntry(audit.PRReviewSubmitted)
+		if entry.Actor != "gitlab:bob-gl" || entry.TargetID != prID || before["state"] != "PENDING" || after["state"] != "APPROVED" {
+			t.Fatalf("approval entry = %+v, before %v, after %v", entry, before, after)
+		}
+
+		for _, fixture := range []string{
+			"merge_request_close.json",
+			"merge_request_reopen.json",
+			"merge_request_merge.json",
+		} {
+			env.gitlabFixture(fixture)
+		}
+
+		for _, action := range []string{audit.PRClosed, audit.PRReopened, audit.PRMerged} {
+			if entry, _, _ := env.auditEntry(action); !strings.HasPrefix(entry.Actor, "gitlab:") {
+				t.Fatalf("%s entry = %+v", action, entry)
+			}
+		}
+	})
+}
@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/LlirikP/pr_dispenser/internal/auth"
+	"github.com/LlirikP/pr_dispenser/internal/policy"
+)
+
+const maxPolicyBodyBytes = 1 << 20
+
+func (s *Server) tokenPrincipal(ctx context.Context, token string) (auth.Principal, error) {
+	row, err := s.store.GetAPITokenByHash(ctx, auth.HashToken(token))
+	if errors.Is(err, sql.ErrNoRows) {
+		return auth.Principal{}, newAPIError("UNAUTHORIZED", "invalid or revoked token", http.StatusUnauthorized)
+	}
+	if err != nil {
+		return auth.Principal{}, dbError("failed to verify token", err)
+	}
+
+	principal := auth.Principal{
+		TokenID: row.ID,
+		Scope:   auth.Scope(row.Scope),
+		UserID:  row.UserID.String,
+	}
+	if !row.UserID.Valid {
+		return principal, nil
+	}
+
+	// A bound token never grants more than the user's current role, so a
+	// demotion takes effect without revoking the token.
+	user, err := s.store.GetUserById(ctx, principal.UserID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return auth.Principal{}, newAPIError("UNAUTHORIZED", "token user no longer exists", http.StatusUnauthorized)
+	}
+	if err != nil {
+		return auth.Principal{}, dbError("failed to load user", err)
+	}
+
+	if roleScope := policy.ScopeForRole(user.Role); !roleScope.Allows(principal.Scope) {
+		principal.Scope = roleScope
+	}
+	return principal, nil
+}
+
+func (s *Server) jwtPrincipal(ctx context.Context, token string) (auth.Principal, error) {
+	principal, err := s.verifier.Verify(ctx, token)
+	if errors.Is(err, auth.ErrInvalidToken) {
+		return principal, newAPIError("UNAUTHORIZED", err.Error(), http.StatusUnauthorized)
+	}
+	if err != nil {
+		log.Printf("error verifying jwt, %v", err)
+		return principal, newAPIError("IDP_UNAVAILABLE", "could not load identity provider keys", http.StatusServiceUnavailable)
+	}
+
+	user, err := s.store.GetUserById(ctx, principal.UserID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return principal, newAPIError("UNAUTHORIZED", "unknown user "+principal.UserID, http.StatusUnauthorized)
+	}
+	if err != nil {
+		return principal, dbError("failed to load user", err)
+	}
+
+	principal.Scope = policy.ScopeForRole(user.Role)
+	return principal, nil
+}
+
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			RespondWithError(w, "UNAUTHORIZED", "missing bearer token", http.StatusUnauthorized)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		var (
+			principal auth.Principal
+			err       error
+		)
+
+		if s.verifier != nil && auth.IsJWT(token) {
+			principal, err = s.jwtPrincipal(ctx, token)
+		} else {
+			principal, err = s.tokenPrincipal(ctx, token)
+		}
+
+		if err != nil {
+			respondWithAPIError(w, err)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), principal)))
+	})
+}
+
+func requireScope(scopes ...auth.Scope) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := auth.FromContext(r.Context())
+			if !ok {
+				RespondWithError(w, "UNAUTHORIZED", "missing bearer token", http.StatusUnauthorized)
+				return
+			}
+
+			for _, scope := range scopes {
+				if principal.Scope.Allows(scope) {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			RespondWithError(w, "FORBIDDEN", "token scope does not allow this operation", http.StatusForbidden)
+		})
+	}
+}
+
+func (s *Server) enforce(rule policy.Rule) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := auth.FromContext(r.Context())
+			if !ok {
+				RespondWithError(w, "UNAUTHORIZED", "missing bearer token", http.StatusUnauthorized)
+				return
+			}
+
+			if principal.UserID == "" {
+				if principal.Scope.Allows(auth.ScopeAdmin) {
+					next.ServeHTTP(w, r)
+					return
+				}
+				RespondWithError(w, "FORBIDDEN", "this operation requires a token bound to a user", http.StatusForbidden)
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+			defer cancel()
+
+			user, err := s.store.GetUserById(ctx, principal.UserID)
+			if errors.Is(err, sql.ErrNoRows) {
+				RespondWithError(w, "FORBIDDEN", "token user no longer exists", http.StatusForbidden)
+				return
+			}
+			if err != nil {
+				respondWithAPIError(w, dbError("failed to load user", err))
+				return
+			}
+
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxPolicyBodyBytes))
+			if err != nil {
+				RespondWithError(w, "BAD_REQUEST", "could not read request body", http.StatusBadRequest)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			target := policy.Target{}
+			if err := json.NewDecoder(bytes.NewReader(body)).Decode(&target); err != nil {
+				RespondWithError(w, "BAD_JSON", "invalid json", http.StatusBadRequest)
+				log.Printf("error parsing json, %v", err)
+				return
+			}
+
+			actor := policy.Actor{
+				UserID: user.ID,
+				Role:   user.Role,
+				TeamID: user.TeamID,
+			}
+
+			if err := rule(ctx, s.store, actor, target); err != nil {
+				respondWithAPIError(w, err)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,236 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/LlirikP/pr_dispenser/internal/auth"
+	"github.com/LlirikP/pr_dispenser/internal/auth/authtest"
+	"github.com/LlirikP/pr_dispenser/internal/config"
+)
+
+const testAudience = "pr-dispenser"
+
+func newJWTEnv(t *testing.T) (*testEnv, *authtest.Issuer) {
+	t.Helper()
+
+	issuer, err := authtest.NewIssuer()
+	if err != nil {
+		t.Fatalf("new issuer: %v", err)
+	}
+	t.Cleanup(issuer.Close)
+
+	env := newTestEnv(t, config.Config{
+		OIDCIssuer:   issuer.URL(),
+		OIDCAudience: testAudience,
+	})
+	env.addTeam("backend", 1,
+		testMember{UserID: "u1", Username: "alice", IsActive: true},
+		testMember{UserID: "u2", Username: "bob", IsActive: true, Role: "team_lead"},
+	)
+	return env, issuer
+}
+
+func TestAuthenticateJWT(t *testing.T) {
+	env, issuer := newJWTEnv(t)
+
+	sign := func(alg, subject, audience string, ttl time.Duration) string {
+		token, err := issuer.Sign(alg, issuer.Claims(subject, audience, ttl))
+		if err != nil {
+			t.Fatalf("sign: %v", err)
+		}
+		return token
+	}
+
+	tests := []struct {
+		name   string
+		token  string
+		status int
+	}{
+		{"rs256", sign("RS256", "u1", testAudience, time.Hour), http.StatusOK},
+		{"es256", sign("ES256", "u1", testAudience, time.Hour), http.StatusOK},
+		{"wrong audience", sign("RS256", "u1", "other", time.Hour), http.StatusUnauthorized},
+		{"expired", sign("RS256", "u1", testAudience, -time.Hour), http.StatusUnauthorized},
+		{"unknown user", sign("RS256", "ghost", testAudience, time.Hour), http.StatusUnauthorized},
+		{"garbage", "a.b.c", http.StatusUnauthorized},
+		{"missing", "", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := env.do(http.MethodGet, "/team/get?team_name=backend", tt.token, nil)
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d, body %s", rec.Code, tt.status, rec.Body)
+			}
+		})
+	}
+}
+
+func TestAuthenticateJWTScopeFollowsRole(t *testing.T) {
+	env, issuer := newJWTEnv(t)
+
+	member, err := issuer.Token("u1", testAudience)
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	lead, err := issuer.Token("u2", testAudience)
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+
+	body := map[string]any{"team_name": "backend", "max_open_reviews": 3}
+
+	if rec := env.do(http.MethodPost, "/team/setCapacity", member, body); rec.Code != http.StatusForbidden {
+		t.Fatalf("member: status = %d, want 403, body %s", rec.Code, rec.Body)
+	}
+	if rec := env.do(http.MethodPost, "/team/setCapacity", lead, body); rec.Code != http.StatusOK {
+		t.Fatalf("team lead: status = %d, want 200, body %s", rec.Code, rec.Body)
+	}
+}
+
+func TestAuthenticateAPITokenAlongsideJWT(t *testing.T) {
+	env, _ := newJWTEnv(t)
+
+	if rec := env.do(http.MethodGet, "/team/get?team_name=backend", env.admin, nil); rec.Code != http.StatusOK {
+		t.Fatalf("api token: status = %d, want 200, body %s", rec.Code, rec.Body)
+	}
+	if rec := env.do(http.MethodGet, "/team/get?team_name=backend", "prd_unknown", nil); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("unknown api token: status = %d, want 401, body %s", rec.Code, rec.Body)
+	}
+}
+
+// newPolicyEnv seeds two teams and a PR by u1 reviewed inside backend, and
+// returns a token for every kind of caller the policy distinguishes.
+func newPolicyEnv(t *testing.T) (*testEnv, map[string]string) {
+	t.Helper()
+
+	env := newTestEnv(t, config.Config{})
+	env.addTeam("backend", 1,
+		testMember{UserID: "u1", Username: "alice", IsActive: true},
+		testMember{UserID: "u2", Username: "bob", IsActive: true, Role: "team_lead"},
+		testMember{UserID: "u6", Username: "frank", IsActive: true},
+	)
+	env.addTeam("platform", 1,
+		testMember{UserID: "u3", Username: "carol", IsActive: true},
+		testMember{UserID: "u4", Username: "dave", IsActive: true, Role: "team_lead"},
+		testMember{UserID: "u5", Username: "erin", IsActive: true, Role: "admin"},
+	)
+	env.createPR("pr-1", "u1")
+
+	tokens := map[string]string{
+		"author":       env.token(auth.ScopeUser, "u1"),
+		"member":       env.token(auth.ScopeUser, "u6"),
+		"lead":         env.token(auth.ScopeTeamAdmin, "u2"),
+		"other lead":   env.token(auth.ScopeTeamAdmin, "u4"),
+		"admin":        env.token(auth.ScopeAdmin, "u5"),
+		"unbound":      env.admin,
+		"unbound user": env.token(auth.ScopeUser, ""),
+	}
+	return env, tokens
+}
+
+func (e *testEnv) doRaw(path, token, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	r.Header.Set("Authorization", "Bearer "+token)
+	return e.request(r)
+}
+
+func TestEnforceRoles(t *testing.T) {
+	tests := []struct {
+		path    string
+		body    map[string]any
+		allowed []string
+	}{
+		{"/team/setStrategy", map[string]any{"team_name": "backend", "reviewer_strategy": "random"}, []string{"lead", "admin", "unbound"}},
+		{"/team/setCapacity", map[string]any{"team_name": "backend", "max_open_reviews": 3}, []string{"lead", "admin", "unbound"}},
+		{"/team/setReviewersRequired", map[string]any{"team_name": "backend", "reviewers_required": 2}, []string{"lead", "admin", "unbound"}},
+		{"/team/setApprovalsRequired", map[string]any{"team_name": "backend", "approvals_required": 1}, []string{"lead", "admin", "unbound"}},
+		{"/team/setFallbacks", map[string]any{"team_name": "backend", "fallback_teams": []string{}}, []string{"lead", "admin", "unbound"}},
+		{"/users/setCapacity", map[string]any{"user_id": "u6", "max_open_reviews": 2}, []string{"lead", "admin", "unbound"}},
+		{"/users/setIsActive", map[string]any{"user_id": "u6", "is_active": true}, []string{"member", "lead", "admin", "unbound"}},
+		{"/pullRequest/reassign", map[string]any{"pull_request_id": "pr-1", "old_user_id": "u2"}, []string{"lead", "admin", "unbound"}},
+		{"/pullRequest/create", map[string]any{"pull_request_id": "pr-2", "pull_request_name": "pr-2", "author_id": "u1"}, []string{"author", "lead", "admin", "unbound"}},
+		{"/pullRequest/ready", map[string]any{"pull_request_id": "pr-1"}, []string{"author", "lead", "admin", "unbound"}},
+		{"/pullRequest/merge", map[string]any{"pull_request_id": "pr-1"}, []string{"author", "lead", "admin", "unbound"}},
+		{"/pullRequest/close", map[string]any{"pull_request_id": "pr-1"}, []string{"author", "lead", "admin", "unbound"}},
+		{"/pullRequest/reopen", map[string]any{"pull_request_id": "pr-1"}, []string{"author", "lead", "admin", "unbound"}},
+		{"/pullRequest/review", map[string]any{"pull_request_id": "pr-1", "reviewer_id": "u6", "state": "COMMENTED"}, []string{"member", "admin", "unbound"}},
+	}
+
+	callers := []string{"author", "member", "lead", "other lead", "admin", "unbound", "unbound user"}
+
+	for _, tt := range tests {
+		for _, caller := range callers {
+			t.Run(tt.path+"/"+caller, func(t *testing.T) {
+				env, tokens := newPolicyEnv(t)
+
+				rec := env.do(http.MethodPost, tt.path, tokens[caller], tt.body)
+				forbidden := rec.Code == http.StatusForbidden && errorCode(t, rec) == "FORBIDDEN"
+				if want := !slices.Contains(tt.allowed, caller); forbidden != want {
+					t.Fatalf("forbidden = %v, want %v: status %d, body %s", forbidden, want, rec.Code, rec.Body)
+				}
+			})
+		}
+	}
+}
+
+func TestEnforceRejectsBadBodies(t *testing.T) {
+	env, tokens := newPolicyEnv(t)
+
+	tests := []struct {
+		name   string
+		token  string
+		body   string
+		status int
+	}{
+		{"malformed", tokens["author"], `{"pull_request_id":`, http.StatusBadRequest},
+		{"empty", tokens["author"], ``, http.StatusBadRequest},
+		{"mistyped target field", tokens["member"], `{"pull_request_id":"pr-1","user_id":1}`, http.StatusBadRequest},
+		{"mistyped pr id", tokens["member"], `{"pull_request_id":1}`, http.StatusBadRequest},
+		{"trailing garbage", tokens["member"], `{"pull_request_id":"pr-1"} junk`, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := env.doRaw("/pullRequest/close", tt.token, tt.body)
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d, body %s", rec.Code, tt.status, rec.Body)
+			}
+		})
+	}
+
+	if env.pr("pr-1").Status != "OPEN" {
+		t.Fatalf("pr was closed by a rejected request: %+v", env.pr("pr-1"))
+	}
+}
+
+func TestBoundTokenScopeFollowsRole(t *testing.T) {
+	env, tokens := newPolicyEnv(t)
+
+	if rec := env.do(http.MethodGet, "/tokens/list", tokens["admin"], nil); rec.Code != http.StatusOK {
+		t.Fatalf("admin: status = %d, want 200, body %s", rec.Code, rec.Body)
+	}
+
+	for _, demotion := range []struct{ userID, role string }{{"u5", "member"}, {"u2", "member"}} {
+		rec := env.do(http.MethodPost, "/users/setRole", env.admin, map[string]any{"user_id": demotion.userID, "role": demotion.role})
+		if rec.Code != http.StatusOK {
+			t.Fatalf("set role: status %d, body %s", rec.Code, rec.Body)
+		}
+	}
+
+	if rec := env.do(http.MethodGet, "/tokens/list", tokens["admin"], nil); rec.Code != http.StatusForbidden {
+		t.Fatalf("demoted admin: status = %d, want 403, body %s", rec.Code, rec.Body)
+	}
+
+	body := map[string]any{"team_name": "backend", "max_open_reviews": 3}
+	if rec := env.do(http.MethodPost, "/team/setCapacity", tokens["lead"], body); rec.Code != http.StatusForbidden {
+		t.Fatalf("demoted lead: status = %d, want 403, body %s", rec.Code, rec.Body)
+	}
+	if rec := env.do(http.MethodGet, "/team/get?team_name=backend", tokens["lead"], nil); rec.Code != http.StatusOK {
+		t.Fatalf("demoted lead keeps user scope: status = %d, body %s", rec.Code, rec.Body)
+	}
+}
@@ -4,18 +4,26 @@ import (
 	"context"
 	"encoding/json"
 	"log"
-	"math/rand/v2"
 	"net/http"
 	"time"
 
-	"github.com/LlirikP/pr_dispenser/internal/config"
+	"github.com/LlirikP/pr_dispenser/internal/audit"
+	"github.com/LlirikP/pr_dispenser/internal/auth"
 	"github.com/LlirikP/pr_dispenser/internal/database"
+	"github.com/LlirikP/pr_dispenser/internal/domain"
+	"github.com/LlirikP/pr_dispenser/internal/events"
 )
 
 type createPRRequest struct {
-	PrID     string `json:"pull_request_id"`
-	Title    string `json:"pull_request_name"`
-	AuthorID string `json:"author_id"`
+	PrID           string `json:"pull_request_id"`
+	Title          string `json:"pull_request_name"`
+	AuthorID       string `json:"author_id"`
+	ReviewersCount *int32 `json:"reviewers_count"`
+	Draft          bool   `json:"draft"`
+}
+
+type readyPRRequest struct {
+	PrID string `json:"pull_request_id"`
 }
 
 type assignReviewerRequest struct {
@@ -23,11 +31,21 @@ type assignReviewerRequest struct {
 	ReviewerID string `json:"old_user_id"`
 }
 
-type mergePRRequest struct {
+type closePRRequest struct {
 	PrID string `json:"pull_request_id"`
 }
 
-func CreatePRHandler(w http.ResponseWriter, r *http.Request) {
+type reopenPRRequest struct {
+	PrID               string `json:"pull_request_id"`
+	ReassignIneligible bool   `json:"reassign_ineligible"`
+}
+
+type mergePRRequest struct {
+	PrID  string `json:"pull_request_id"`
+	Force bool   `json:"force"`
+}
+
+func (s *Server) CreatePRHandler(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
 	defer cancel()
 
@@ -39,336 +57,483 @@ func CreatePRHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	author, err := config.ApiCfg.DB.GetUserById(ctx, params.AuthorID)
-	if err != nil {
-		RespondWithError(w, "USER_NOT_FOUND", "author not found", http.StatusNotFound)
-		log.Printf("error finding user, %v", err)
+	if params.ReviewersCount != nil && *params.ReviewersCount < 0 {
+		RespondWithError(w, "BAD_REQUEST", "reviewers_count must not be negative", http.StatusBadRequest)
 		return
 	}
 
-	_, err = config.ApiCfg.DB.CheckDuplicatePR(ctx, database.CheckDuplicatePRParams{
-		AuthorID: params.AuthorID,
-		Title:    params.Title,
-	})
+	var (
+		created domain.CreatedPR
+		reviews []reviewResponse
+	)
 
-	if err == nil {
-		RespondWithError(w, "PR_EXISTS", "PR already exists", http.StatusConflict)
-		log.Printf("error creating pr due to duplicating, %v", err)
-		return
-	}
+	err := s.execTx(ctx, func(q database.Querier, rec *events.Recorder) error {
+		var err error
 
-	err = config.ApiCfg.DB.CreatePR(ctx, database.CreatePRParams{
-		ID:       params.PrID,
-		Title:    params.Title,
-		AuthorID: params.AuthorID,
-	})
+		created, err = domain.CreatePR(ctx, q, rec, domain.CreatePRParams{
+			PrID:           params.PrID,
+			Title:          params.Title,
+			AuthorID:       params.AuthorID,
+			ReviewersCount: params.ReviewersCount,
+			Draft:          params.Draft,
+		})
+		if err != nil {
+			return err
+		}
 
-	if err != nil {
-		RespondWithError(w, "DB_ERROR", "failed to create PR", http.StatusInternalServerError)
-		log.Printf("error creating pr, %v", err)
-		return
-	}
+		if err := recordPRAudit(ctx, q, auditActor(ctx), audit.PRCreated, params.PrID, nil); err != nil {
+			return err
+		}
+
+		reviews, err = loadReviews(ctx, q, params.PrID)
+		if err != nil {
+			return dbError("failed to load reviews", err)
+		}
 
-	teammates, err := config.ApiCfg.DB.GetActiveTeamMembersExceptAuthor(ctx, database.GetActiveTeamMembersExceptAuthorParams{
-		TeamID: author.TeamID,
-		ID:     author.ID,
+		return nil
 	})
 
 	if err != nil {
-		RespondWithError(w, "DB_ERROR", "failed to get team members", http.StatusInternalServerError)
-		log.Printf("error getting teammates: %v", err)
+		respondWithAPIError(w, err)
 		return
 	}
 
-	rand.Shuffle(len(teammates), func(i, j int) {
-		teammates[i], teammates[j] = teammates[j], teammates[i]
-	})
+	resp := struct {
+		PRID              string           `json:"pull_request_id"`
+		Title             string           `json:"pull_request_name"`
+		AuthorID          string           `json:"author_id"`
+		Status            string           `json:"status"`
+		Draft             bool             `json:"draft"`
+		AssignedReviewers []string         `json:"assigned_reviewers"`
+		FallbackReviewers []string         `json:"fallback_reviewers"`
+		ReviewersRequired int32            `json:"reviewers_required"`
+		Reviews           []reviewResponse `json:"reviews"`
+	}{
+		PRID:              params.PrID,
+		Title:             params.Title,
+		AuthorID:          params.AuthorID,
+		Status:            "OPEN",
+		Draft:             params.Draft,
+		AssignedReviewers: created.Assigned,
+		FallbackReviewers: created.Fallback,
+		ReviewersRequired: created.Required,
+		Reviews:           reviews,
+	}
+
+	RespondWithJSON(w, http.StatusCreated, resp)
+}
 
-	count := 2
-	if len(teammates) < 2 {
-		count = len(teammates)
+func (s *Server) AssignReviewerHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	params := assignReviewerRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		RespondWithError(w, "BAD_JSON", "invalid json", http.StatusBadRequest)
+		return
 	}
 
-	assigned := make([]string, 0, count)
+	var (
+		pr            database.Pr
+		reviewers     []string
+		newReviewerID string
+		fromFallback  bool
+		reviews       []reviewResponse
+	)
 
-	for i := 0; i < count; i++ {
-		reviewerID := teammates[i]
+	err := s.execTx(ctx, func(q database.Querier, rec *events.Recorder) error {
+		before, err := loadPRSnapshot(ctx, q, params.PrID)
+		if err != nil {
+			return err
+		}
 
-		err = config.ApiCfg.DB.AddReviewer(ctx, database.AddReviewerParams{
-			PrID:       params.PrID,
-			ReviewerID: reviewerID,
-		})
+		var replacement domain.Picked
 
+		pr, replacement, err = domain.ReassignReviewer(ctx, q, rec, params.PrID, params.ReviewerID)
 		if err != nil {
-			RespondWithError(w, "DB_ERROR", "failed to assign reviewer", http.StatusInternalServerError)
-			log.Printf("error assigning reviewers, %v", err)
-			return
+			return err
 		}
 
-		err = config.ApiCfg.DB.SetUserIsActive(ctx, database.SetUserIsActiveParams{
-			ID:       reviewerID,
-			IsActive: false,
-		})
+		if err := recordPRAudit(ctx, q, auditActor(ctx), audit.PRReassigned, params.PrID, before); err != nil {
+			return err
+		}
+
+		newReviewerID = replacement.ID
+		fromFallback = replacement.Fallback
 
+		reviewers, err = q.GetReviewersByPR(ctx, params.PrID)
 		if err != nil {
-			RespondWithError(w, "DB_ERROR", "failed to update reviewer status", http.StatusInternalServerError)
-			log.Printf("error updating reviewer status, %v", err)
-			return
+			return dbError("failed to load reviewers", err)
 		}
 
-		assigned = append(assigned, reviewerID)
+		reviews, err = loadReviews(ctx, q, params.PrID)
+		if err != nil {
+			return dbError("failed to load reviews", err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		respondWithAPIError(w, err)
+		return
 	}
 
 	resp := struct {
-		PRID              string   `json:"pull_request_id"`
-		Title             string   `json:"pull_request_name"`
-		AuthorID          string   `json:"author_id"`
-		Status            string   `json:"status"`
-		AssignedReviewers []string `json:"assigned_reviewers"`
-	}{
-		PRID:              params.PrID,
-		Title:             params.Title,
-		AuthorID:          params.AuthorID,
-		Status:            "OPEN",
-		AssignedReviewers: assigned,
-	}
+		PR struct {
+			ID                string           `json:"pull_request_id"`
+			Title             string           `json:"pull_request_name"`
+			AuthorID          string           `json:"author_id"`
+			Status            string           `json:"status"`
+			AssignedReviewers []string         `json:"assigned_reviewers"`
+			Reviews           []reviewResponse `json:"reviews"`
+		} `json:"pr"`
+		ReplacedBy   string `json:"replaced_by"`
+		FromFallback bool   `json:"from_fallback"`
+	}{}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	err = json.NewEncoder(w).Encode(resp)
+	resp.PR.ID = pr.ID
+	resp.PR.Title = pr.Title
+	resp.PR.AuthorID = pr.AuthorID
+	resp.PR.Status = pr.Status
+	resp.PR.AssignedReviewers = reviewers
+	resp.PR.Reviews = reviews
+	resp.ReplacedBy = newReviewerID
+	resp.FromFallback = fromFallback
 
-	if err != nil {
-		log.Printf("error encoding response: %v", err)
-	}
+	RespondWithJSON(w, http.StatusOK, resp)
 }
 
-func AssignReviewerHandler(w http.ResponseWriter, r *http.Request) {
+func (s *Server) MergePRHandler(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
 	defer cancel()
 
-	params := assignReviewerRequest{}
+	params := mergePRRequest{}
 	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
 		RespondWithError(w, "BAD_JSON", "invalid json", http.StatusBadRequest)
+		log.Printf("error parsing json, %v", err)
 		return
 	}
 
-	pr, err := config.ApiCfg.DB.GetPRById(ctx, params.PrID)
-	if err != nil {
-		RespondWithError(w, "PR_NOT_FOUND", "unknown PR", http.StatusNotFound)
+	principal, _ := auth.FromContext(r.Context())
+	if params.Force && !principal.Scope.Allows(auth.ScopeAdmin) {
+		RespondWithError(w, "FORBIDDEN", "force merge requires admin scope", http.StatusForbidden)
 		return
 	}
 
-	if pr.Status == "MERGED" {
-		RespondWithError(w, "PR_MERGED", "cannot reassign on merged PR", http.StatusConflict)
-		return
-	}
+	actor := auditActor(r.Context())
 
-	reviewer, err := config.ApiCfg.DB.GetUserById(ctx, params.ReviewerID)
-	if err != nil {
-		RespondWithError(w, "USER_NOT_FOUND", "user not found", http.StatusNotFound)
-		log.Printf("error finding user, %v", err)
-		return
-	}
+	var (
+		pr        database.Pr
+		reviewers []string
+		reviews   []reviewResponse
+	)
+
+	err := s.execTx(ctx, func(q database.Querier, rec *events.Recorder) error {
+		var err error
+
+		pr, err = mergePR(ctx, q, rec, domain.MergePRParams{
+			PrID:    params.PrID,
+			Force:   params.Force,
+			ActorID: actor,
+		})
+		if err != nil {
+			return err
+		}
+
+		reviewers, err = q.GetReviewersByPR(ctx, params.PrID)
+		if err != nil {
+			return dbError("failed to load reviewers", err)
+		}
 
-	assigned, err := config.ApiCfg.DB.IsReviewerAssigned(ctx, database.IsReviewerAssignedParams{
-		PrID:       params.PrID,
-		ReviewerID: params.ReviewerID,
+		reviews, err = loadReviews(ctx, q, params.PrID)
+		if err != nil {
+			return dbError("failed to load reviews", err)
+		}
+
+		return nil
 	})
 
 	if err != nil {
-		RespondWithError(w, "DB_ERROR", "failed to check reviewer assignment", http.StatusInternalServerError)
+		respondWithAPIError(w, err)
 		return
 	}
 
-	if !assigned {
-		RespondWithError(w, "NOT_ASSIGNED", "reviewer is not assigned to this PR", http.StatusConflict)
-		return
+	resp := map[string]any{
+		"pr": map[string]any{
+			"pull_request_id":    pr.ID,
+			"pull_request_name":  pr.Title,
+			"author_id":          pr.AuthorID,
+			"status":             pr.Status,
+			"assigned_reviewers": reviewers,
+			"reviews":            reviews,
+			"force_merged":       pr.ForceMerged,
+			"mergedAt":           pr.MergedAt,
+		},
 	}
 
-	teammates, err := config.ApiCfg.DB.GetActiveTeamMembersExceptAuthor(
-		ctx,
-		database.GetActiveTeamMembersExceptAuthorParams{
-			TeamID: reviewer.TeamID,
-			ID:     reviewer.ID,
-		},
-	)
+	RespondWithJSON(w, http.StatusOK, resp)
+}
 
-	if err != nil {
-		RespondWithError(w, "DB_ERROR", "failed to load team members", http.StatusInternalServerError)
+func (s *Server) ClosePRHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	params := closePRRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		RespondWithError(w, "BAD_JSON", "invalid json", http.StatusBadRequest)
+		log.Printf("error parsing json, %v", err)
 		return
 	}
 
-	newReviewerID := ""
+	var (
+		pr        database.Pr
+		reviewers []string
+	)
 
-	for _, candidate := range teammates {
-		cid := candidate
+	err := s.execTx(ctx, func(q database.Querier, rec *events.Recorder) error {
+		var err error
 
-		if cid == reviewer.ID {
-			continue
+		pr, err = closePR(ctx, q, rec, auditActor(ctx), params.PrID)
+		if err != nil {
+			return err
 		}
 
-		alreadyAssigned, err := config.ApiCfg.DB.IsReviewerAssigned(ctx, database.IsReviewerAssignedParams{
-			PrID:       params.PrID,
-			ReviewerID: cid,
-		})
+		reviewers, err = q.GetReviewersByPR(ctx, params.PrID)
 		if err != nil {
-			RespondWithError(w, "DB_ERROR", "failed to check candidate", http.StatusInternalServerError)
-			return
+			return dbError("failed to load reviewers", err)
 		}
 
-		if alreadyAssigned {
-			continue
-		}
+		return nil
+	})
 
-		newReviewerID = cid
-		break
+	if err != nil {
+		respondWithAPIError(w, err)
+		return
 	}
 
-	if newReviewerID == "" {
-		RespondWithError(w, "NO_CANDIDATE", "no active replacement candidate in team", http.StatusConflict)
-		return
+	resp := map[string]any{
+		"pr": map[string]any{
+			"pull_request_id":    pr.ID,
+			"pull_request_name":  pr.Title,
+			"author_id":          pr.AuthorID,
+			"status":             pr.Status,
+			"assigned_reviewers": reviewers,
+			"closedAt":           nullTimePtr(pr.ClosedAt),
+		},
 	}
 
-	err = config.ApiCfg.DB.DeleteReviewer(ctx, database.DeleteReviewerParams{
-		PrID:       params.PrID,
-		ReviewerID: params.ReviewerID,
-	})
-	if err != nil {
-		RespondWithError(w, "DB_ERROR", "failed to remove old reviewer", http.StatusInternalServerError)
+	RespondWithJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) ReopenPRHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	params := reopenPRRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		RespondWithError(w, "BAD_JSON", "invalid json", http.StatusBadRequest)
+		log.Printf("error parsing json, %v", err)
 		return
 	}
 
-	err = config.ApiCfg.DB.AddReviewer(ctx, database.AddReviewerParams{
-		PrID:       params.PrID,
-		ReviewerID: newReviewerID,
+	var (
+		reopened  domain.ReopenedPR
+		reviewers []string
+	)
+
+	err := s.execTx(ctx, func(q database.Querier, rec *events.Recorder) error {
+		var err error
+
+		reopened, err = reopenPR(ctx, q, rec, auditActor(ctx), domain.ReopenPRParams{
+			PrID:               params.PrID,
+			ReassignIneligible: params.ReassignIneligible,
+		})
+		if err != nil {
+			return err
+		}
+
+		reviewers, err = q.GetReviewersByPR(ctx, params.PrID)
+		if err != nil {
+			return dbError("failed to load reviewers", err)
+		}
+
+		return nil
 	})
-	if err != nil {
-		RespondWithError(w, "DB_ERROR", "failed to assign new reviewer", http.StatusInternalServerError)
-		return
-	}
 
-	reviewers, err := config.ApiCfg.DB.GetReviewersByPR(ctx, params.PrID)
 	if err != nil {
-		RespondWithError(w, "DB_ERROR", "failed to load reviewers", http.StatusInternalServerError)
+		respondWithAPIError(w, err)
 		return
 	}
 
-	resp := struct {
-		PR struct {
-			ID                string   `json:"pull_request_id"`
-			Title             string   `json:"pull_request_name"`
-			AuthorID          string   `json:"author_id"`
-			Status            string   `json:"status"`
-			AssignedReviewers []string `json:"assigned_reviewers"`
-		} `json:"pr"`
-		ReplacedBy string `json:"replaced_by"`
-	}{}
+	pr := reopened.PR
 
-	resp.PR.ID = pr.ID
-	resp.PR.Title = pr.Title
-	resp.PR.AuthorID = pr.AuthorID
-	resp.PR.Status = pr.Status
-	resp.PR.AssignedReviewers = reviewers
-	resp.ReplacedBy = newReviewerID
+	resp := map[string]any{
+		"pr": map[string]any{
+			"pull_request_id":    pr.ID,
+			"pull_request_name":  pr.Title,
+			"author_id":          pr.AuthorID,
+			"status":             pr.Status,
+			"assigned_reviewers": reviewers,
+		},
+		"reassigned": reopened.Reassigned,
+		"failed":     reopened.Failed,
+	}
 
 	RespondWithJSON(w, http.StatusOK, resp)
-	w.WriteHeader(http.StatusOK)
 }
 
-func MergePRHandler(w http.ResponseWriter, r *http.Request) {
+func (s *Server) ReadyPRHandler(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
 	defer cancel()
 
-	params := mergePRRequest{}
+	params := readyPRRequest{}
 	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
 		RespondWithError(w, "BAD_JSON", "invalid json", http.StatusBadRequest)
 		log.Printf("error parsing json, %v", err)
 		return
 	}
 
-	pr, err := config.ApiCfg.DB.GetPRById(ctx, params.PrID)
-	if err != nil {
-		RespondWithError(w, "PR_NOT_FOUND", "unknown PR", http.StatusNotFound)
-		log.Printf("error finding pr, %v", err)
-		return
-	}
+	var (
+		pr        database.Pr
+		reviewers []string
+		fallback  []string
+		reviews   []reviewResponse
+	)
 
-	if pr.Status == "MERGED" {
-		reviewers, err := config.ApiCfg.DB.GetReviewersByPR(ctx, params.PrID)
+	err := s.execTx(ctx, func(q database.Querier, rec *events.Recorder) error {
+		var err error
+
+		pr, fallback, err = markPRReady(ctx, q, rec, auditActor(ctx), params.PrID, false)
 		if err != nil {
-			RespondWithError(w, "DB_ERROR", "failed to load reviewers", http.StatusInternalServerError)
-			log.Printf("error loading reviewers, %v", err)
-			return
+			return err
 		}
 
-		resp := map[string]any{
-			"pr": map[string]any{
-				"pull_request_id":    pr.ID,
-				"pull_request_name":  pr.Title,
-				"author_id":          pr.AuthorID,
-				"status":             pr.Status,
-				"assigned_reviewers": reviewers,
-				"mergedAt":           pr.MergedAt,
-			},
+		reviewers, err = q.GetReviewersByPR(ctx, params.PrID)
+		if err != nil {
+			return dbError("failed to load reviewers", err)
 		}
 
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		err = json.NewEncoder(w).Encode(resp)
+		reviews, err = loadReviews(ctx, q, params.PrID)
 		if err != nil {
-			log.Printf("error encoding response: %v", err)
+			return dbError("failed to load reviews", err)
 		}
+
+		return nil
+	})
+
+	if err != nil {
+		respondWithAPIError(w, err)
 		return
 	}
 
-	err = config.ApiCfg.DB.MergePR(ctx, params.PrID)
+	resp := map[string]any{
+		"pr": map[string]any{
+			"pull_request_id":    pr.ID,
+			"pull_request_name":  pr.Title,
+			"author_id":          pr.AuthorID,
+			"status":             pr.Status,
+			"draft":              pr.IsDraft,
+			"assigned_reviewers": reviewers,
+			"fallback_reviewers": fallback,
+			"reviews":            reviews,
+		},
+	}
+
+	RespondWithJSON(w, http.StatusOK, resp)
+}
+
+// The helpers below wrap the domain lifecycle calls with their audit entries,
+// so the HTTP handlers and the GitHub/GitLab webhooks record the same actions.
+// No entry is written when the call did not change the PR.
+
+func mergePR(ctx context.Context, q database.Querier, rec *events.Recorder, params domain.MergePRParams) (database.Pr, error) {
+	before, err := loadPRSnapshot(ctx, q, params.PrID)
 	if err != nil {
-		RespondWithError(w, "DB_ERROR", "failed to merge PR", http.StatusInternalServerError)
-		log.Printf("error merging pr, %v", err)
-		return
+		return database.Pr{}, err
 	}
 
-	reviewers, err := config.ApiCfg.DB.GetReviewersByPR(ctx, params.PrID)
+	pr, err := domain.MergePR(ctx, q, rec, params)
 	if err != nil {
-		RespondWithError(w, "DB_ERROR", "failed to load reviewers", http.StatusInternalServerError)
-		log.Printf("error loading reviewers, %v", err)
-		return
+		return pr, err
 	}
 
-	for _, reviewerID := range reviewers {
-		err = config.ApiCfg.DB.SetUserIsActive(ctx, database.SetUserIsActiveParams{
-			ID:       reviewerID,
-			IsActive: true,
-		})
+	if before.Status == "MERGED" {
+		return pr, nil
+	}
 
-		if err != nil {
-			RespondWithError(w, "DB_ERROR", "failed to change reviewer's status", http.StatusInternalServerError)
-			log.Printf("error changind status, %v", err)
-			return
-		}
+	return pr, recordPRAudit(ctx, q, params.ActorID, audit.PRMerged, params.PrID, before)
+}
+
+func closePR(ctx context.Context, q database.Querier, rec *events.Recorder, actor, prID string) (database.Pr, error) {
+	before, err := loadPRSnapshot(ctx, q, prID)
+	if err != nil {
+		return database.Pr{}, err
 	}
 
-	updatedPR, err := config.ApiCfg.DB.GetPRById(ctx, params.PrID)
+	pr, err := domain.ClosePR(ctx, q, rec, prID)
 	if err != nil {
-		RespondWithError(w, "DB_ERROR", "failed to reload PR", http.StatusInternalServerError)
-		return
+		return pr, err
 	}
 
-	resp := map[string]any{
-		"pr": map[string]any{
-			"pull_request_id":    updatedPR.ID,
-			"pull_request_name":  updatedPR.Title,
-			"author_id":          updatedPR.AuthorID,
-			"status":             updatedPR.Status,
-			"assigned_reviewers": reviewers,
-			"mergedAt":           updatedPR.MergedAt,
-		},
+	if before.Status == "CLOSED" {
+		return pr, nil
+	}
+
+	return pr, recordPRAudit(ctx, q, actor, audit.PRClosed, prID, before)
+}
+
+func markPRReady(ctx context.Context, q database.Querier, rec *events.Recorder, actor, prID string, allowShortfall bool) (database.Pr, []string, error) {
+	before, err := loadPRSnapshot(ctx, q, prID)
+	if err != nil {
+		return database.Pr{}, nil, err
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	err = json.NewEncoder(w).Encode(resp)
+	pr, fallback, err := domain.MarkPRReady(ctx, q, rec, prID, allowShortfall)
 	if err != nil {
-		log.Printf("error encoding response: %v", err)
+		return pr, fallback, err
+	}
+
+	if !before.Draft {
+		return pr, fallback, nil
 	}
+
+	return pr, fallback, recordPRAudit(ctx, q, actor, audit.PRReady, prID, before)
+}
+
+// reopenPR writes pr.reopened for the status change and a separate
+// pr.reassigned entry for the reviewers ReassignIneligible replaced.
+func reopenPR(ctx context.Context, q database.Querier, rec *events.Recorder, actor string, params domain.ReopenPRParams) (domain.ReopenedPR, error) {
+	before, err := loadPRSnapshot(ctx, q, params.PrID)
+	if err != nil {
+		return domain.ReopenedPR{}, err
+	}
+
+	reopened, err := domain.ReopenPR(ctx, q, rec, params)
+	if err != nil {
+		return reopened, err
+	}
+
+	// Reopening leaves the reviewers alone, so the state between the two
+	// entries is the reopened PR with the reviewers it had before.
+	reopenedSnapshot := toPRSnapshot(reopened.PR, before.AssignedReviewers)
+
+	if before.Status == "CLOSED" {
+		err := writeAudit(ctx, q, actor, audit.Entry{
+			Action:     audit.PRReopened,
+			TargetType: audit.TargetPR,
+			TargetID:   params.PrID,
+			Before:     before,
+			After:      reopenedSnapshot,
+		})
+		if err != nil {
+			return reopened, err
+		}
+	}
+
+	if len(reopened.Reassigned) == 0 {
+		return reopened, nil
+	}
+
+	return reopened, recordPRAudit(ctx, q, actor, audit.PRReassigned, params.PrID, reopenedSnapshot)
 }
@@ -0,0 +1,242 @@
+package handlers
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/LlirikP/pr_dispenser/internal/config"
+)
+
+type prStateResponse struct {
+	PR struct {
+		PrID              string     `json:"pull_request_id"`
+		Status            string     `json:"status"`
+		Draft             bool       `json:"draft"`
+		AssignedReviewers []string   `json:"assigned_reviewers"`
+		ClosedAt          *time.Time `json:"closedAt"`
+	} `json:"pr"`
+	Reassigned []struct {
+		OldUserID string `json:"old_user_id"`
+		NewUserID string `json:"new_user_id"`
+	} `json:"reassigned"`
+	Failed []struct {
+		OldUserID string `json:"old_user_id"`
+		Reason    string `json:"reason"`
+	} `json:"failed"`
+}
+
+func newLifecycleEnv(t *testing.T) *testEnv {
+	t.Helper()
+
+	env := newTestEnv(t, config.Config{})
+	env.addTeam("backend", 1,
+		testMember{UserID: "u1", Username: "alice", IsActive: true},
+		testMember{UserID: "u2", Username: "bob", IsActive: true},
+		testMember{UserID: "u3", Username: "carol", IsActive: true},
+	)
+	env.setTeamCapacity("backend", 5)
+	return env
+}
+
+func (e *testEnv) prAction(path, prID string, extra map[string]any) (int, prStateResponse) {
+	e.t.Helper()
+
+	body := map[string]any{"pull_request_id": prID}
+	for k, v := range extra {
+		body[k] = v
+	}
+
+	resp := prStateResponse{}
+	rec := e.do(http.MethodPost, path, e.admin, body)
+	if rec.Code == http.StatusOK {
+		decodeBody(e.t, rec, &resp)
+	}
+	return rec.Code, resp
+}
+
+func TestClosePR(t *testing.T) {
+	env := newLifecycleEnv(t)
+	reviewers := env.createPR("pr-1", "u1")
+
+	code, resp := env.prAction("/pullRequest/close", "pr-1", nil)
+	if code != http.StatusOK {
+		t.Fatalf("close: status %d", code)
+	}
+	if resp.PR.Status != "CLOSED" || resp.PR.ClosedAt == nil || !slices.Equal(resp.PR.AssignedReviewers, reviewers) {
+		t.Fatalf("closed pr = %+v, want CLOSED with closedAt and reviewers %v", resp.PR, reviewers)
+	}
+	closedAt := *resp.PR.ClosedAt
+
+	code, resp = env.prAction("/pullRequest/close", "pr-1", nil)
+	if code != http.StatusOK || resp.PR.ClosedAt == nil || !resp.PR.ClosedAt.Equal(closedAt) {
+		t.Fatalf("close again: status %d, pr %+v, want closedAt %v kept", code, resp.PR, closedAt)
+	}
+
+	if rec := env.do(http.MethodPost, "/pullRequest/review", env.admin, map[string]any{"pull_request_id": "pr-1", "reviewer_id": reviewers[0], "state": "APPROVED"}); rec.Code != http.StatusConflict {
+		t.Fatalf("review of closed pr: status %d, want 409", rec.Code)
+	}
+	if code, _ := env.prAction("/pullRequest/merge", "pr-1", map[string]any{"force": true}); code != http.StatusConflict {
+		t.Fatalf("merge of closed pr: status %d, want 409", code)
+	}
+
+	env.createPR("pr-2", "u1")
+	if code, _ := env.prAction("/pullRequest/merge", "pr-2", map[string]any{"force": true}); code != http.StatusOK {
+		t.Fatalf("merge: status %d", code)
+	}
+	if code, _ := env.prAction("/pullRequest/close", "pr-2", nil); code != http.StatusConflict {
+		t.Fatalf("close of merged pr: status %d, want 409", code)
+	}
+
+	if code, _ := env.prAction("/pullRequest/close", "ghost", nil); code != http.StatusNotFound {
+		t.Fatalf("close of unknown pr: status %d, want 404", code)
+	}
+}
+
+func TestReopenPR(t *testing.T) {
+	env := newLifecycleEnv(t)
+	reviewer := env.createPR("pr-1", "u1")[0]
+
+	if code, _ := env.prAction("/pullRequest/close", "pr-1", nil); code != http.StatusOK {
+		t.Fatalf("close: status %d", code)
+	}
+
+	code, resp := env.prAction("/pullRequest/reopen", "pr-1", nil)
+	if code != http.StatusOK {
+		t.Fatalf("reopen: status %d", code)
+	}
+	if resp.PR.Status != "OPEN" || !slices.Equal(resp.PR.AssignedReviewers, []string{reviewer}) || len(resp.Reassigned) != 0 {
+		t.Fatalf("reopened pr = %+v, want OPEN with reviewer %s kept", resp, reviewer)
+	}
+	if pr := env.pr("pr-1"); pr.ClosedAt.Valid {
+		t.Fatalf("reopened pr still has closed_at %v", pr.ClosedAt.Time)
+	}
+
+	if code, _ := env.prAction("/pullRequest/merge", "pr-1", map[string]any{"force": true}); code != http.StatusOK {
+		t.Fatalf("merge: status %d", code)
+	}
+	if code, _ := env.prAction("/pullRequest/reopen", "pr-1", nil); code != http.StatusConflict {
+		t.Fatalf("reopen of merged pr: status %d, want 409", code)
+	}
+}
+
+func TestReopenPRReassignsIneligibleReviewers(t *testing.T) {
+	env := newLifecycleEnv(t)
+	reviewer := env.createPR("pr-1", "u1")[0]
+
+	if code, _ := env.prAction("/pullRequest/close", "pr-1", nil); code != http.StatusOK {
+		t.Fatalf("close: status %d", code)
+	}
+	if rec := env.do(http.MethodPost, "/users/setIsActive", env.admin, map[string]any{"user_id": reviewer, "is_active": false}); rec.Code != http.StatusOK {
+		t.Fatalf("deactivate: status %d, body %s", rec.Code, rec.Body)
+	}
+
+	code, resp := env.prAction("/pullRequest/reopen", "pr-1", map[string]any{"reassign_ineligible": true})
+	if code != http.StatusOK {
+		t.Fatalf("reopen: status %d", code)
+	}
+
+	if len(resp.Reassigned) != 1 || resp.Reassigned[0].OldUserID != reviewer || len(resp.Failed) != 0 {
+		t.Fatalf("reopen report = %+v, want %s reassigned", resp, reviewer)
+	}
+	replacement := resp.Reassigned[0].NewUserID
+	if replacement == reviewer || replacement == "u1" {
+		t.Fatalf("replacement = %s", replacement)
+	}
+	if !slices.Equal(resp.PR.AssignedReviewers, []string{replacement}) || !slices.Equal(env.reviewers("pr-1"), []string{replacement}) {
+		t.Fatalf("reviewers = %v, stored %v, want [%s]", resp.PR.AssignedReviewers, env.reviewers("pr-1"), replacement)
+	}
+
+	// With the only other teammate gone too, the reviewer stays and the
+	// reopen reports why.
+	if code, _ := env.prAction("/pullRequest/close", "pr-1", nil); code != http.StatusOK {
+		t.Fatalf("close: status %d", code)
+	}
+	if rec := env.do(http.MethodPost, "/users/setIsActive", env.admin, map[string]any{"user_id": replacement, "is_active": false}); rec.Code != http.StatusOK {
+		t.Fatalf("deactivate: status %d, body %s", rec.Code, rec.Body)
+	}
+
+	code, resp = env.prAction("/pullRequest/reopen", "pr-1", map[string]any{"reassign_ineligible": true})
+	if code != http.StatusOK {
+		t.Fatalf("reopen: status %d", code)
+	}
+	if len(resp.Reassigned) != 0 || len(resp.Failed) != 1 || resp.Failed[0].OldUserID != replacement || resp.Failed[0].Reason != "NO_CANDIDATE" {
+		t.Fatalf("reopen report = %+v, want %s failed with NO_CANDIDATE", resp, replacement)
+	}
+	if !slices.Equal(env.reviewers("pr-1"), []string{replacement}) {
+		t.Fatalf("reviewers = %v, want [%s] kept", env.reviewers("pr-1"), replacement)
+	}
+}
+
+func TestReadyPR(t *testing.T) {
+	env := newLifecycleEnv(t)
+
+	rec := env.do(http.MethodPost, "/pullRequest/create", env.admin, map[string]any{
+		"pull_request_id":   "pr-1",
+		"pull_request_name": "draft",
+		"author_id":         "u1",
+		"draft":             true,
+	})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: status %d, body %s", rec.Code, rec.Body)
+	}
+	if reviewers := env.reviewers("pr-1"); len(reviewers) != 0 {
+		t.Fatalf("draft has reviewers %v", reviewers)
+	}
+
+	code, resp := env.prAction("/pullRequest/ready", "pr-1", nil)
+	if code != http.StatusOK {
+		t.Fatalf("ready: status %d", code)
+	}
+	if resp.PR.Draft || len(resp.PR.AssignedReviewers) != 1 || resp.PR.AssignedReviewers[0] == "u1" {
+		t.Fatalf("ready pr = %+v, want one reviewer other than the author", resp.PR)
+	}
+	if env.pr("pr-1").IsDraft {
+		t.Fatalf("pr is still a draft")
+	}
+	assigned := resp.PR.AssignedReviewers
+
+	code, resp = env.prAction("/pullRequest/ready", "pr-1", nil)
+	if code != http.StatusOK || !slices.Equal(resp.PR.AssignedReviewers, assigned) {
+		t.Fatalf("ready again: status %d, reviewers %v, want %v kept", code, resp.PR.AssignedReviewers, assigned)
+	}
+
+	if code, _ := env.prAction("/pullRequest/close", "pr-1", nil); code != http.StatusOK {
+		t.Fatalf("close: status %d", code)
+	}
+	if code, _ := env.prAction("/pullRequest/ready", "pr-1", nil); code != http.StatusConflict {
+		t.Fatalf("ready of closed pr: status %d, want 409", code)
+	}
+}
+
+func TestReadyPRRejectsShortfall(t *testing.T) {
+	env := newTestEnv(t, config.Config{})
+	env.addTeam("backend", 2,
+		testMember{UserID: "u1", Username: "alice", IsActive: true},
+		testMember{UserID: "u2", Username: "bob", IsActive: true},
+		testMember{UserID: "u3", Username: "carol", IsActive: true},
+	)
+	env.setTeamCapacity("backend", 5)
+
+	rec := env.do(http.MethodPost, "/pullRequest/create", env.admin, map[string]any{
+		"pull_request_id":   "pr-1",
+		"pull_request_name": "draft",
+		"author_id":         "u1",
+		"draft":             true,
+	})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: status %d, body %s", rec.Code, rec.Body)
+	}
+	if rec := env.do(http.MethodPost, "/users/setIsActive", env.admin, map[string]any{"user_id": "u3", "is_active": false}); rec.Code != http.StatusOK {
+		t.Fatalf("deactivate: status %d, body %s", rec.Code, rec.Body)
+	}
+
+	rec = env.do(http.MethodPost, "/pullRequest/ready", env.admin, map[string]any{"pull_request_id": "pr-1"})
+	if code := errorCode(t, rec); code != "NOT_ENOUGH_REVIEWERS" {
+		t.Fatalf("ready with one of two reviewers available: status %d, code %q", rec.Code, code)
+	}
+	if pr := env.pr("pr-1"); !pr.IsDraft || len(env.reviewers("pr-1")) != 0 {
+		t.Fatalf("failed ready changed the pr: draft %v, reviewers %v", pr.IsDraft, env.reviewers("pr-1"))
+	}
+}
@@ -2,10 +2,40 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
+
+	"github.com/LlirikP/pr_dispenser/internal/domain"
 )
 
+type apiError struct {
+	code   string
+	msg    string
+	status int
+	err    error
+}
+
+func newAPIError(code, msg string, status int) *apiError {
+	return &apiError{code: code, msg: msg, status: status}
+}
+
+func dbError(msg string, err error) *apiError {
+	return &apiError{code: "DB_ERROR", msg: msg, status: http.StatusInternalServerError, err: err}
+}
+
+func (e *apiError) Error() string {
+	if e.err != nil {
+		return fmt.Sprintf("%s: %v", e.msg, e.err)
+	}
+	return e.msg
+}
+
+func (e *apiError) Unwrap() error {
+	return e.err
+}
+
 func RespondWithError(w http.ResponseWriter, code, msg string, status int) {
 	w.WriteHeader(status)
 	err := json.NewEncoder(w).Encode(map[string]any{
@@ -20,6 +50,43 @@ func RespondWithError(w http.ResponseWriter, code, msg string, status int) {
 	}
 }
 
+func fromDomainError(err *domain.Error) *apiError {
+	status := http.StatusInternalServerError
+	switch err.Kind {
+	case domain.KindInvalid:
+		status = http.StatusBadRequest
+	case domain.KindNotFound:
+		status = http.StatusNotFound
+	case domain.KindConflict:
+		status = http.StatusConflict
+	case domain.KindForbidden:
+		status = http.StatusForbidden
+	}
+
+	return &apiError{code: err.Code, msg: err.Message, status: status, err: err.Err}
+}
+
+func respondWithAPIError(w http.ResponseWriter, err error) {
+	var (
+		apiErr    *apiError
+		domainErr *domain.Error
+	)
+
+	switch {
+	case errors.As(err, &apiErr):
+	case errors.As(err, &domainErr):
+		apiErr = fromDomainError(domainErr)
+	default:
+		apiErr = dbError("internal error", err)
+	}
+
+	if apiErr.status >= http.StatusInternalServerError {
+		log.Printf("error: %v", err)
+	}
+
+	RespondWithError(w, apiErr.code, apiErr.msg, apiErr.status)
+}
+
 func RespondWithJSON(w http.ResponseWriter, status int, payload interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
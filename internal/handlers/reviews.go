@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/LlirikP/pr_dispenser/internal/audit"
+	"github.com/LlirikP/pr_dispenser/internal/auth"
+	"github.com/LlirikP/pr_dispenser/internal/database"
+	"github.com/LlirikP/pr_dispenser/internal/domain"
+)
+
+type submitReviewRequest struct {
+	PrID       string `json:"pull_request_id"`
+	ReviewerID string `json:"reviewer_id"`
+	State      string `json:"state"`
+}
+
+type reviewSnapshot struct {
+	PrID       string     `json:"pull_request_id"`
+	ReviewerID string     `json:"reviewer_id"`
+	State      string     `json:"state"`
+	ReviewedAt *time.Time `json:"reviewed_at"`
+}
+
+type reviewResponse struct {
+	ReviewerID string     `json:"reviewer_id"`
+	State      string     `json:"state"`
+	AssignedAt time.Time  `json:"assigned_at"`
+	ReviewedAt *time.Time `json:"reviewed_at"`
+}
+
+func nullTimePtr(v sql.NullTime) *time.Time {
+	if !v.Valid {
+		return nil
+	}
+	return &v.Time
+}
+
+func loadReviews(ctx context.Context, q database.Querier, prID string) ([]reviewResponse, error) {
+	rows, err := q.GetReviewsByPR(ctx, prID)
+	if err != nil {
+		return nil, err
+	}
+
+	reviews := make([]reviewResponse, 0, len(rows))
+	for _, row := range rows {
+		reviews = append(reviews, reviewResponse{
+			ReviewerID: row.ReviewerID,
+			State:      row.State,
+			AssignedAt: row.AssignedAt,
+			ReviewedAt: nullTimePtr(row.ReviewedAt),
+		})
+	}
+
+	return reviews, nil
+}
+
+func loadReviewSnapshot(ctx context.Context, q database.Querier, prID, reviewerID string) (*reviewSnapshot, error) {
+	reviews, err := loadReviews(ctx, q, prID)
+	if err != nil {
+		return nil, dbError("failed to load reviews", err)
+	}
+
+	for _, r := range reviews {
+		if r.ReviewerID == reviewerID {
+			return &reviewSnapshot{
+				PrID:       prID,
+				ReviewerID: reviewerID,
+				State:      r.State,
+				ReviewedAt: r.ReviewedAt,
+			}, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// submitReview saves the review and records the reviewer's state before and
+// after it, for the review endpoint and the GitLab approval events alike.
+func submitReview(ctx context.Context, q database.Querier, actor string, params domain.SubmitReviewParams) (database.Pr, error) {
+	before, err := loadReviewSnapshot(ctx, q, params.PrID, params.ReviewerID)
+	if err != nil {
+		return database.Pr{}, err
+	}
+
+	pr, err := domain.SubmitReview(ctx, q, params)
+	if err != nil {
+		return pr, err
+	}
+
+	after, err := loadReviewSnapshot(ctx, q, params.PrID, params.ReviewerID)
+	if err != nil {
+		return pr, err
+	}
+
+	return pr, writeAudit(ctx, q, actor, audit.Entry{
+		Action:     audit.PRReviewSubmitted,
+		TargetType: audit.TargetPR,
+		TargetID:   params.PrID,
+		Before:     before,
+		After:      after,
+	})
+}
+
+func (s *Server) SubmitReviewHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	params := submitReviewRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		RespondWithError(w, "BAD_JSON", "invalid json", http.StatusBadRequest)
+		log.Printf("error parsing json, %v", err)
+		return
+	}
+
+	switch params.State {
+	case domain.ReviewApproved, domain.ReviewChangesRequested, domain.ReviewCommented:
+	default:
+		RespondWithError(w, "BAD_REQUEST", "state must be APPROVED, CHANGES_REQUESTED or COMMENTED", http.StatusBadRequest)
+		return
+	}
+
+	if principal, ok := auth.FromContext(r.Context()); ok && params.ReviewerID == "" {
+		params.ReviewerID = principal.UserID
+	}
+
+	var (
+		pr      database.Pr
+		reviews []reviewResponse
+	)
+
+	err := s.store.ExecTx(ctx, func(q database.Querier) error {
+		var err error
+
+		pr, err = submitReview(ctx, q, auditActor(ctx), domain.SubmitReviewParams{
+			PrID:       params.PrID,
+			ReviewerID: params.ReviewerID,
+			State:      params.State,
+		})
+		if err != nil {
+			return err
+		}
+
+		reviews, err = loadReviews(ctx, q, params.PrID)
+		if err != nil {
+			return dbError("failed to load reviews", err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		respondWithAPIError(w, err)
+		return
+	}
+
+	resp := map[string]any{
+		"pr": map[string]any{
+			"pull_request_id":   pr.ID,
+			"pull_request_name": pr.Title,
+			"author_id":         pr.AuthorID,
+			"status":            pr.Status,
+			"reviews":           reviews,
+		},
+	}
+
+	RespondWithJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/LlirikP/pr_dispenser/internal/config"
+)
+
+func newReviewEnv(t *testing.T, approvalsRequired int32) *testEnv {
+	t.Helper()
+
+	env := newTestEnv(t, config.Config{})
+	env.addTeam("backend", 2,
+		testMember{UserID: "u1", Username: "alice", IsActive: true},
+		testMember{UserID: "u2", Username: "bob", IsActive: true},
+		testMember{UserID: "u3", Username: "carol", IsActive: true},
+	)
+	env.setTeamCapacity("backend", 5)
+
+	rec := env.do(http.MethodPost, "/team/setApprovalsRequired", env.admin, map[string]any{
+		"team_name":          "backend",
+		"approvals_required": approvalsRequired,
+	})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("set approvals: status %d, body %s", rec.Code, rec.Body)
+	}
+	return env
+}
+
+func (e *testEnv) review(token, prID, reviewerID, state string) *httptest.ResponseRecorder {
+	e.t.Helper()
+
+	return e.do(http.MethodPost, "/pullRequest/review", token, map[string]any{
+		"pull_request_id": prID,
+		"reviewer_id":     reviewerID,
+		"state":           state,
+	})
+}
+
+func (e *testEnv) merge(prID string) *httptest.ResponseRecorder {
+	e.t.Helper()
+
+	return e.do(http.MethodPost, "/pullRequest/merge", e.admin, map[string]any{"pull_request_id": prID})
+}
+
+func TestSubmitReview(t *testing.T) {
+	env := newReviewEnv(t, 1)
+	reviewers := env.createPR("pr-1", "u1")
+
+	rec := env.review(env.token("user", reviewers[0]), "pr-1", "", "COMMENTED")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("review: status %d, body %s", rec.Code, rec.Body)
+	}
+
+	resp := struct {
+		PR struct {
+			Status  string           `json:"status"`
+			Reviews []reviewResponse `json:"reviews"`
+		} `json:"pr"`
+	}{}
+	decodeBody(t, rec, &resp)
+
+	if resp.PR.Status != "OPEN" || len(resp.PR.Reviews) != 2 {
+		t.Fatalf("pr = %+v, want OPEN with two reviews", resp.PR)
+	}
+	for _, r := range resp.PR.Reviews {
+		want := "PENDING"
+		if r.ReviewerID == reviewers[0] {
+			want = "COMMENTED"
+		}
+		if r.State != want || (r.ReviewedAt != nil) != (want != "PENDING") {
+			t.Fatalf("review of %s = %+v, want %s", r.ReviewerID, r, want)
+		}
+	}
+	if state := env.reviewState("pr-1", reviewers[0]); state != "COMMENTED" {
+		t.Fatalf("stored state = %s, want COMMENTED", state)
+	}
+
+	tests := []struct {
+		name       string
+		reviewerID string
+		state      string
+		status     int
+		code       string
+	}{
+		{"unknown state", reviewers[1], "LGTM", http.StatusBadRequest, "BAD_REQUEST"},
+		{"pending state", reviewers[1], "PENDING", http.StatusBadRequest, "BAD_REQUEST"},
+		{"author", "u1", "APPROVED", http.StatusConflict, "NOT_ASSIGNED"},
+	}
+
+	for _, tt := range tests {
+		rec := env.review(env.admin, "pr-1", tt.reviewerID, tt.state)
+		if rec.Code != tt.status || errorCode(t, rec) != tt.code {
+			t.Fatalf("%s: status %d, body %s, want %d %s", tt.name, rec.Code, rec.Body, tt.status, tt.code)
+		}
+	}
+
+	if rec := env.review(env.admin, "ghost", reviewers[1], "APPROVED"); rec.Code != http.StatusNotFound {
+		t.Fatalf("unknown pr: status %d, want 404", rec.Code)
+	}
+}
+
+func TestChangesRequestedBlocksMerge(t *testing.T) {
+	env := newReviewEnv(t, 1)
+	reviewers := env.createPR("pr-1", "u1")
+
+	if rec := env.review(env.admin, "pr-1", reviewers[0], "APPROVED"); rec.Code != http.StatusOK {
+		t.Fatalf("approve: status %d, body %s", rec.Code, rec.Body)
+	}
+	if rec := env.review(env.admin, "pr-1", reviewers[1], "CHANGES_REQUESTED"); rec.Code != http.StatusOK {
+		t.Fatalf("request changes: status %d, body %s", rec.Code, rec.Body)
+	}
+
+	rec := env.merge("pr-1")
+	if rec.Code != http.StatusConflict || errorCode(t, rec) != "CHANGES_REQUESTED" {
+		t.Fatalf("merge: status %d, body %s, want 409 CHANGES_REQUESTED", rec.Code, rec.Body)
+	}
+	if pr := env.pr("pr-1"); pr.Status != "OPEN" {
+		t.Fatalf("pr status = %s, want OPEN", pr.Status)
+	}
+
+	if rec := env.review(env.admin, "pr-1", reviewers[1], "APPROVED"); rec.Code != http.StatusOK {
+		t.Fatalf("approve: status %d, body %s", rec.Code, rec.Body)
+	}
+	if rec := env.merge("pr-1"); rec.Code != http.StatusOK {
+		t.Fatalf("merge after approval: status %d, body %s", rec.Code, rec.Body)
+	}
+	if pr := env.pr("pr-1"); pr.Status != "MERGED" || pr.ForceMerged {
+		t.Fatalf("pr = %+v, want MERGED without force", pr)
+	}
+}
+
+func TestMergeRequiresApprovals(t *testing.T) {
+	env := newReviewEnv(t, 2)
+	reviewers := env.createPR("pr-1", "u1")
+
+	rec := env.merge("pr-1")
+	if rec.Code != http.StatusConflict || errorCode(t, rec) != "NOT_APPROVED" {
+		t.Fatalf("merge without reviews: status %d, body %s", rec.Code, rec.Body)
+	}
+
+	if rec := env.review(env.admin, "pr-1", reviewers[0], "APPROVED"); rec.Code != http.StatusOK {
+		t.Fatalf("approve: status %d, body %s", rec.Code, rec.Body)
+	}
+	if rec := env.review(env.admin, "pr-1", reviewers[1], "COMMENTED"); rec.Code != http.StatusOK {
+		t.Fatalf("comment: status %d, body %s", rec.Code, rec.Body)
+	}
+
+	rec = env.merge("pr-1")
+	if rec.Code != http.StatusConflict || errorCode(t, rec) != "NOT_APPROVED" {
+		t.Fatalf("merge with one of two approvals: status %d, body %s", rec.Code, rec.Body)
+	}
+
+	if rec := env.review(env.admin, "pr-1", reviewers[1], "APPROVED"); rec.Code != http.StatusOK {
+		t.Fatalf("approve: status %d, body %s", rec.Code, rec.Body)
+	}
+	if rec := env.merge("pr-1"); rec.Code != http.StatusOK {
+		t.Fatalf("merge with two approvals: status %d, body %s", rec.Code, rec.Body)
+	}
+
+	if rec := env.review(env.admin, "pr-1", reviewers[0], "CHANGES_REQUESTED"); rec.Code != http.StatusConflict || errorCode(t, rec) != "PR_MERGED" {
+		t.Fatalf("review of merged pr: status %d, body %s", rec.Code, rec.Body)
+	}
+}
+
+func TestMergeRequiresEnoughReviewers(t *testing.T) {
+	env := newReviewEnv(t, 1)
+
+	rec := env.do(http.MethodPost, "/pullRequest/create", env.admin, map[string]any{
+		"pull_request_id":   "pr-1",
+		"pull_request_name": "one reviewer",
+		"author_id":         "u1",
+		"reviewers_count":   1,
+	})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: status %d, body %s", rec.Code, rec.Body)
+	}
+
+	reviewer := env.reviewers("pr-1")[0]
+	if rec := env.review(env.admin, "pr-1", reviewer, "APPROVED"); rec.Code != http.StatusOK {
+		t.Fatalf("approve: status %d, body %s", rec.Code, rec.Body)
+	}
+
+	// Raising the bar after the PR was opened leaves it with fewer reviewers
+	// than approvals, which no review can fix.
+	rec = env.do(http.MethodPost, "/team/setApprovalsRequired", env.admin, map[string]any{"team_name": "backend", "approvals_required": 2})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("set approvals: status %d, body %s", rec.Code, rec.Body)
+	}
+
+	rec = env.merge("pr-1")
+	if rec.Code != http.StatusConflict || errorCode(t, rec) != "NOT_ENOUGH_REVIEWERS" {
+		t.Fatalf("merge with one reviewer: status %d, body %s", rec.Code, rec.Body)
+	}
+}
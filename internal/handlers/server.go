@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"github.com/go-chi/chi/v5"
+
+	"github.com/LlirikP/pr_dispenser/internal/auth"
+	"github.com/LlirikP/pr_dispenser/internal/config"
+	"github.com/LlirikP/pr_dispenser/internal/database"
+	"github.com/LlirikP/pr_dispenser/internal/policy"
+)
+
+type Server struct {
+	store    database.Storage
+	cfg      config.Config
+	verifier *auth.Verifier
+}
+
+func NewServer(store database.Storage, cfg config.Config) *Server {
+	s := &Server{
+		store: store,
+		cfg:   cfg,
+	}
+
+	if cfg.OIDCIssuer != "" {
+		s.verifier = auth.NewVerifier(auth.VerifierConfig{
+			Issuer:    cfg.OIDCIssuer,
+			Audience:  cfg.OIDCAudience,
+			JWKSURL:   cfg.OIDCJWKSURL,
+			UserClaim: cfg.OIDCUserClaim,
+		})
+	}
+
+	return s
+}
+
+func (s *Server) Routes() chi.Router {
+	v1router := chi.NewRouter()
+	v1router.Use(requestID)
+
+	v1router.Post("/webhooks/github", s.GithubWebhookHandler)
+	v1router.Post("/webhooks/gitlab", s.GitlabWebhookHandler)
+
+	v1router.Group(func(r chi.Router) {
+		r.Use(s.authenticate)
+
+		r.Group(func(r chi.Router) {
+			r.Use(requireScope(auth.ScopeAdmin))
+
+			r.Post("/team/add", s.CreateTeamHandler)
+			r.Post("/team/deactivate", s.DeactivateTeamHandler)
+
+			r.Post("/tokens/create", s.CreateTokenHandler)
+			r.Get("/tokens/list", s.ListTokensHandler)
+			r.Post("/tokens/revoke", s.RevokeTokenHandler)
+
+			r.Post("/users/setRole", s.SetUserRoleHandler)
+
+			r.Get("/audit", s.ListAuditHandler)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(requireScope(auth.ScopeTeamAdmin))
+
+			r.With(s.enforce(policy.ManageTeam)).Post("/team/setStrategy", s.SetTeamStrategyHandler)
+			r.With(s.enforce(policy.ManageTeam)).Post("/team/setCapacity", s.SetTeamCapacityHandler)
+			r.With(s.enforce(policy.ManageTeam)).Post("/team/setReviewersRequired", s.SetTeamReviewersRequiredHandler)
+			r.With(s.enforce(policy.ManageTeam)).Post("/team/setApprovalsRequired", s.SetTeamApprovalsRequiredHandler)
+			r.With(s.enforce(policy.ManageTeam)).Post("/team/setFallbacks", s.SetTeamFallbacksHandler)
+
+			r.With(s.enforce(policy.ManageUser)).Post("/users/setCapacity", s.SetUserCapacityHandler)
+
+			r.With(s.enforce(policy.ReassignReviewer)).Post("/pullRequest/reassign", s.AssignReviewerHandler)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(requireScope(auth.ScopeUser))
+
+			r.Get("/team/get", s.GetTeamHandler)
+			r.Get("/users/getReview", s.ReviewListHandler)
+			r.With(s.enforce(policy.SetUserActive)).Post("/users/setIsActive", s.SetUserActiveHandler)
+
+			r.With(s.enforce(policy.CreatePR)).Post("/pullRequest/create", s.CreatePRHandler)
+			r.With(s.enforce(policy.ManagePR)).Post("/pullRequest/ready", s.ReadyPRHandler)
+			r.With(s.enforce(policy.ManagePR)).Post("/pullRequest/merge", s.MergePRHandler)
+			r.With(s.enforce(policy.ManagePR)).Post("/pullRequest/close", s.ClosePRHandler)
+			r.With(s.enforce(policy.ManagePR)).Post("/pullRequest/reopen", s.ReopenPRHandler)
+			r.With(s.enforce(policy.SubmitReview)).Post("/pullRequest/review", s.SubmitReviewHandler)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(requireScope(auth.ScopeWebhook))
+
+			r.Post("/subscriptions/add", s.CreateSubscriptionHandler)
+			r.Get("/subscriptions/list", s.ListSubscriptionsHandler)
+			r.Post("/subscriptions/delete", s.DeleteSubscriptionHandler)
+			r.Get("/subscriptions/deliveries", s.ListDeliveriesHandler)
+			r.Post("/subscriptions/deliveries/retry", s.RetryDeliveryHandler)
+		})
+
+		r.With(requireScope(auth.ScopeUser, auth.ScopeWebhook)).Get("/events/stream", s.EventStreamHandler)
+	})
+
+	return v1router
+}
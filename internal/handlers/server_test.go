@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/LlirikP/pr_dispenser/internal/auth"
+	"github.com/LlirikP/pr_dispenser/internal/config"
+	"github.com/LlirikP/pr_dispenser/internal/database"
+	"github.com/LlirikP/pr_dispenser/internal/database/memory"
+	"github.com/google/uuid"
+)
+
+type testEnv struct {
+	t       *testing.T
+	store   database.Storage
+	handler http.Handler
+	admin   string
+}
+
+type testMember struct {
+	UserID         string  `json:"user_id"`
+	Username       string  `json:"username"`
+	IsActive       bool    `json:"is_active"`
+	GithubLogin    *string `json:"github_login,omitempty"`
+	GitlabUsername *string `json:"gitlab_username,omitempty"`
+	Role           string  `json:"role,omitempty"`
+}
+
+func newTestEnv(t *testing.T, cfg config.Config) *testEnv {
+	t.Helper()
+
+	return newTestEnvWithStore(t, memory.New(), cfg)
+}
+
+func newTestEnvWithStore(t *testing.T, store database.Storage, cfg config.Config) *testEnv {
+	t.Helper()
+
+	env := &testEnv{
+		t:       t,
+		store:   store,
+		handler: NewServer(store, cfg).Routes(),
+	}
+	env.admin = env.token(auth.ScopeAdmin, "")
+	return env
+}
+
+func (e *testEnv) token(scope auth.Scope, userID string) string {
+	e.t.Helper()
+
+	token, err := auth.GenerateToken()
+	if err != nil {
+		e.t.Fatalf("generate token: %v", err)
+	}
+
+	err = e.store.CreateAPIToken(context.Background(), database.CreateAPITokenParams{
+		ID:        uuid.NewString(),
+		Name:      "test",
+		TokenHash: auth.HashToken(token),
+		Scope:     string(scope),
+		UserID:    sql.NullString{String: userID, Valid: userID != ""},
+	})
+	if err != nil {
+		e.t.Fatalf("create token: %v", err)
+	}
+	return token
+}
+
+func (e *testEnv) request(r *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	e.handler.ServeHTTP(rec, r)
+	return rec
+}
+
+func (e *testEnv) do(method, path, token string, body any) *httptest.ResponseRecorder {
+	e.t.Helper()
+
+	var reader io.Reader
+	if body != nil {
+		raw, err := json.Marshal(body)
+		if err != nil {
+			e.t.Fatalf("marshal body: %v", err)
+		}
+		reader = bytes.NewReader(raw)
+	}
+
+	r := httptest.NewRequest(method, path, reader)
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+	return e.request(r)
+}
+
+func (e *testEnv) addTeam(name string, reviewersRequired int32, members ...testMember) {
+	e.t.Helper()
+
+	rec := e.do(http.MethodPost, "/team/add", e.admin, map[string]any{
+		"team_name":          name,
+		"reviewers_required": reviewersRequired,
+		"members":            members,
+	})
+	if rec.Code != http.StatusCreated {
+		e.t.Fatalf("add team %s: status %d, body %s", name, rec.Code, rec.Body)
+	}
+}
+
+func (e *testEnv) setTeamCapacity(name string, maxOpenReviews int32) {
+	e.t.Helper()
+
+	rec := e.do(http.MethodPost, "/team/setCapacity", e.admin, map[string]any{
+		"team_name":        name,
+		"max_open_reviews": maxOpenReviews,
+	})
+	if rec.Code != http.StatusOK {
+		e.t.Fatalf("set capacity of %s: status %d, body %s", name, rec.Code, rec.Body)
+	}
+}
+
+func (e *testEnv) reviewers(prID string) []string {
+	e.t.Helper()
+
+	ids, err := e.store.GetReviewersByPR(context.Background(), prID)
+	if err != nil {
+		e.t.Fatalf("get reviewers: %v", err)
+	}
+	return ids
+}
+
+func (e *testEnv) pr(prID string) database.Pr {
+	e.t.Helper()
+
+	pr, err := e.store.GetPRById(context.Background(), prID)
+	if err != nil {
+		e.t.Fatalf("get pr %s: %v", prID, err)
+	}
+	return pr
+}
+
+func decodeBody(t *testing.T, rec *httptest.ResponseRecorder, dst any) {
+	t.Helper()
+
+	if err := json.Unmarshal(rec.Body.Bytes(), dst); err != nil {
+		t.Fatalf("decode body %q: %v", rec.Body, err)
+	}
+}
+
+func errorCode(t *testing.T, rec *httptest.ResponseRecorder) string {
+	t.Helper()
+
+	body := struct {
+		Error struct {
+			Code string `json:"code"`
+		} `json:"error"`
+	}{}
+	decodeBody(t, rec, &body)
+	return body.Error.Code
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/LlirikP/pr_dispenser/internal/database"
+)
+
+const (
+	streamPollInterval = time.Second
+	streamHeartbeat    = 15 * time.Second
+	streamBatchSize    = 100
+	streamRetryMillis  = 3000
+)
+
+func (s *Server) EventStreamHandler(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		RespondWithError(w, "STREAMING_UNSUPPORTED", "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	query := r.URL.Query()
+	filter := database.GetStreamEventsAfterParams{
+		BatchSize: streamBatchSize,
+	}
+
+	if prID := query.Get("pull_request_id"); prID != "" {
+		filter.PrID = stringPtrNull(&prID)
+	}
+
+	if userID := query.Get("user_id"); userID != "" {
+		filter.UserID = stringPtrNull(&userID)
+	}
+
+	if teamName := query.Get("team_name"); teamName != "" {
+		ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+		team, err := s.store.GetTeamByName(ctx, teamName)
+		cancel()
+		if err != nil {
+			RespondWithError(w, "NOT_FOUND", "team not found", http.StatusNotFound)
+			return
+		}
+		filter.TeamID = stringPtrNull(&team.ID)
+	}
+
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = query.Get("last_event_id")
+	}
+
+	if lastEventID != "" {
+		seq, err := strconv.ParseInt(lastEventID, 10, 64)
+		if err != nil || seq < 0 {
+			RespondWithError(w, "BAD_REQUEST", "invalid Last-Event-ID", http.StatusBadRequest)
+			return
+		}
+		filter.AfterSeq = seq
+	} else {
+		ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+		latest, err := s.store.GetLatestStreamSeq(ctx)
+		cancel()
+		if err != nil {
+			log.Printf("error loading stream position, %v", err)
+			RespondWithError(w, "DB_ERROR", "failed to load stream position", http.StatusInternalServerError)
+			return
+		}
+		filter.AfterSeq = latest
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	fmt.Fprintf(w, "retry: %d\n\n", streamRetryMillis)
+	flusher.Flush()
+
+	poll := time.NewTicker(streamPollInterval)
+	defer poll.Stop()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+		batch, err := s.store.GetStreamEventsAfter(ctx, filter)
+		cancel()
+		if err != nil {
+			if r.Context().Err() != nil {
+				return
+			}
+			log.Printf("error reading event stream, %v", err)
+		}
+
+		for _, ev := range batch {
+			fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", ev.Seq, ev.EventType, ev.Payload)
+			filter.AfterSeq = ev.Seq
+		}
+
+		if len(batch) > 0 {
+			flusher.Flush()
+			heartbeat.Reset(streamHeartbeat)
+		}
+
+		if len(batch) == streamBatchSize {
+			continue
+		}
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": keepalive\n\n")
+			flusher.Flush()
+		case <-poll.C:
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/LlirikP/pr_dispenser/internal/config"
+	"github.com/LlirikP/pr_dispenser/internal/outbox"
+)
+
+type streamEvent struct {
+	ID      int64
+	Type    string
+	PrID    string
+	UserIDs []string
+}
+
+// openStream connects to /events/stream over a real connection and parses
+// the server-sent events into a channel, closed when the stream ends.
+func (e *testEnv) openStream(query, lastEventID string) (int, <-chan streamEvent) {
+	e.t.Helper()
+
+	srv := httptest.NewServer(e.handler)
+	ctx, cancel := context.WithCancel(context.Background())
+	e.t.Cleanup(func() {
+		cancel()
+		srv.Close()
+	})
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/events/stream?"+query, nil)
+	if err != nil {
+		e.t.Fatalf("new request: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+e.admin)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		e.t.Fatalf("open stream: %v", err)
+	}
+
+	ch := make(chan streamEvent, 100)
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		close(ch)
+		return resp.StatusCode, ch
+	}
+
+	go func() {
+		defer close(ch)
+		defer resp.Body.Close()
+
+		var ev streamEvent
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case strings.HasPrefix(line, "id: "):
+				ev.ID, _ = strconv.ParseInt(strings.TrimPrefix(line, "id: "), 10, 64)
+			case strings.HasPrefix(line, "event: "):
+				ev.Type = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				payload := struct {
+					PrID    string   `json:"pull_request_id"`
+					UserIDs []string `json:"user_ids"`
+				}{}
+				_ = json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &payload)
+				ev.PrID, ev.UserIDs = payload.PrID, payload.UserIDs
+			case line == "" && ev.ID != 0:
+				ch <- ev
+				ev = streamEvent{}
+			}
+		}
+	}()
+
+	return resp.StatusCode, ch
+}
+
+// collect reads events until the stream stays quiet for idle.
+func collect(ch <-chan streamEvent, idle time.Duration) []streamEvent {
+	var got []streamEvent
+	for {
+		select {
+		case ev, ok := <-ch:
+			if !ok {
+				return got
+			}
+			got = append(got, ev)
+		case <-time.After(idle):
+			return got
+		}
+	}
+}
+
+// sequence assigns stream positions to committed events, as the outbox relay
+// does in the service.
+func (e *testEnv) sequence() {
+	e.t.Helper()
+
+	if _, err := outbox.NewRelay(e.store).Sequence(context.Background()); err != nil {
+		e.t.Fatalf("sequence: %v", err)
+	}
+}
+
+func newStreamEnv(t *testing.T) *testEnv {
+	t.Helper()
+
+	env := newTestEnv(t, config.Config{})
+	env.addTeam("backend", 1,
+		testMember{UserID: "u1", Username: "alice", IsActive: true},
+		testMember{UserID: "u2", Username: "bob", IsActive: true},
+	)
+	env.addTeam("platform", 1,
+		testMember{UserID: "u3", Username: "carol", IsActive: true},
+		testMember{UserID: "u4", Username: "dave", IsActive: true},
+	)
+
+	env.createPR("pr-backend", "u1")
+	env.createPR("pr-platform", "u3")
+	env.sequence()
+	return env
+}
+
+func TestEventStreamFilters(t *testing.T) {
+	env := newStreamEnv(t)
+
+	tests := []struct {
+		name  string
+		query string
+		prs   []string
+		user  string
+	}{
+		{"all", "", []string{"pr-backend", "pr-platform"}, ""},
+		{"team", "team_name=platform", []string{"pr-platform"}, ""},
+		{"user", "user_id=u2", []string{"pr-backend"}, "u2"},
+		{"pull request", "pull_request_id=pr-backend", []string{"pr-backend"}, ""},
+		{"team and pull request", "team_name=platform&pull_request_id=pr-backend", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, ch := env.openStream(tt.query, "0")
+			if code != http.StatusOK {
+				t.Fatalf("status %d", code)
+			}
+
+			got := collect(ch, 300*time.Millisecond)
+
+			var prs []string
+			for _, ev := range got {
+				if !slices.Contains(prs, ev.PrID) {
+					prs = append(prs, ev.PrID)
+				}
+				if tt.user != "" && !slices.Contains(ev.UserIDs, tt.user) {
+					t.Fatalf("event %+v does not involve %s", ev, tt.user)
+				}
+			}
+			if !slices.Equal(prs, tt.prs) {
+				t.Fatalf("events for %v, want %v: %+v", prs, tt.prs, got)
+			}
+		})
+	}
+}
+
+func TestEventStreamResumesFromLastEventID(t *testing.T) {
+	env := newStreamEnv(t)
+
+	_, ch := env.openStream("", "0")
+	all := collect(ch, 300*time.Millisecond)
+	if len(all) < 4 {
+		t.Fatalf("events = %+v, want the creation and assignment of both PRs", all)
+	}
+	for i, ev := range all {
+		if ev.ID != int64(i+1) {
+			t.Fatalf("event %d has id %d, want %d", i, ev.ID, i+1)
+		}
+	}
+
+	resumeAt := all[1].ID
+	_, ch = env.openStream("", strconv.FormatInt(resumeAt, 10))
+	if rest := collect(ch, 300*time.Millisecond); !reflect.DeepEqual(rest, all[2:]) {
+		t.Fatalf("resumed after %d with %+v, want %+v", resumeAt, rest, all[2:])
+	}
+
+	// Without Last-Event-ID the stream starts at the newest event and only
+	// sends what comes after it.
+	_, live := env.openStream("", "")
+	if early := collect(live, 300*time.Millisecond); len(early) != 0 {
+		t.Fatalf("new stream replayed %+v", early)
+	}
+
+	env.createPR("pr-late", "u2")
+	env.sequence()
+
+	got := collect(live, 2*streamPollInterval)
+	if len(got) == 0 || got[0].ID != all[len(all)-1].ID+1 {
+		t.Fatalf("live events = %+v, want to continue after %d", got, all[len(all)-1].ID)
+	}
+	for _, ev := range got {
+		if ev.PrID != "pr-late" {
+			t.Fatalf("live event %+v, want only pr-late", ev)
+		}
+	}
+}
+
+func TestEventStreamRejectsBadRequests(t *testing.T) {
+	env := newStreamEnv(t)
+
+	tests := []struct {
+		name        string
+		query       string
+		lastEventID string
+		status      int
+	}{
+		{"unknown team", "team_name=ghost", "", http.StatusNotFound},
+		{"malformed id", "", "abc", http.StatusBadRequest},
+		{"negative id", "", "-1", http.StatusBadRequest},
+		{"malformed query id", "last_event_id=abc", "", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		if code, _ := env.openStream(tt.query, tt.lastEventID); code != tt.status {
+			t.Fatalf("%s: status %d, want %d", tt.name, code, tt.status)
+		}
+	}
+}
@@ -0,0 +1,380 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/LlirikP/pr_dispenser/internal/audit"
+	"github.com/LlirikP/pr_dispenser/internal/database"
+	"github.com/LlirikP/pr_dispenser/internal/events"
+	"github.com/LlirikP/pr_dispenser/internal/webhooks"
+	"github.com/google/uuid"
+)
+
+type createSubscriptionRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	Secret string   `json:"secret"`
+}
+
+type deleteSubscriptionRequest struct {
+	SubscriptionID string `json:"subscription_id"`
+}
+
+type retryDeliveryRequest struct {
+	DeliveryID int64 `json:"delivery_id"`
+}
+
+type subscriptionResponse struct {
+	SubscriptionID string    `json:"subscription_id"`
+	URL            string    `json:"url"`
+	Events         []string  `json:"events"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type deliveryResponse struct {
+	DeliveryID     int64           `json:"delivery_id"`
+	SubscriptionID string          `json:"subscription_id"`
+	EventID        string          `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	PrID           string          `json:"pull_request_id"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int32           `json:"attempts"`
+	ResponseStatus *int32          `json:"response_status"`
+	LastError      *string         `json:"last_error"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at"`
+	CreatedAt      time.Time       `json:"created_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at"`
+}
+
+type deliverySnapshot struct {
+	DeliveryID     int64   `json:"delivery_id"`
+	SubscriptionID string  `json:"subscription_id"`
+	EventID        string  `json:"event_id"`
+	Status         string  `json:"status"`
+	Attempts       int32   `json:"attempts"`
+	LastError      *string `json:"last_error"`
+}
+
+const (
+	minWebhookSecretLength = 16
+	defaultDeliveryLimit   = 50
+	maxDeliveryLimit       = 500
+)
+
+func toSubscriptionResponse(sub database.WebhookSubscription) subscriptionResponse {
+	return subscriptionResponse{
+		SubscriptionID: sub.ID,
+		URL:            sub.Url,
+		Events:         sub.Events,
+		CreatedAt:      sub.CreatedAt,
+	}
+}
+
+// lookupIPAddr resolves subscription hosts. Tests replace it to stay off the
+// network.
+var lookupIPAddr = net.DefaultResolver.LookupIPAddr
+
+func isInternalIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast()
+}
+
+// checkSubscriptionHost keeps subscriptions from pointing the dispatcher at
+// the service's own network: every address the host resolves to must be
+// public.
+func checkSubscriptionHost(ctx context.Context, host string) error {
+	if ip := net.ParseIP(host); ip != nil {
+		if isInternalIP(ip) {
+			return errors.New("url must not point to a loopback, link-local or private address")
+		}
+		return nil
+	}
+
+	addrs, err := lookupIPAddr(ctx, host)
+	if err != nil || len(addrs) == 0 {
+		return errors.New("url host could not be resolved")
+	}
+
+	for _, addr := range addrs {
+		if isInternalIP(addr.IP) {
+			return errors.New("url must not point to a loopback, link-local or private address")
+		}
+	}
+	return nil
+}
+
+func toDeliverySnapshot(d database.WebhookDelivery) *deliverySnapshot {
+	return &deliverySnapshot{
+		DeliveryID:     d.ID,
+		SubscriptionID: d.SubscriptionID,
+		EventID:        d.EventID,
+		Status:         d.Status,
+		Attempts:       d.Attempts,
+		LastError:      nullStringPtr(d.LastError),
+	}
+}
+
+func (s *Server) CreateSubscriptionHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	params := createSubscriptionRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		RespondWithError(w, "BAD_JSON", "invalid json", http.StatusBadRequest)
+		return
+	}
+
+	target, err := url.Parse(params.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		RespondWithError(w, "BAD_REQUEST", "url must be an absolute http(s) URL", http.StatusBadRequest)
+		return
+	}
+
+	if err := checkSubscriptionHost(ctx, target.Hostname()); err != nil {
+		RespondWithError(w, "BAD_REQUEST", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if len(params.Secret) < minWebhookSecretLength {
+		RespondWithError(w, "BAD_REQUEST", "secret must be at least 16 characters", http.StatusBadRequest)
+		return
+	}
+
+	if params.Events == nil {
+		params.Events = []string{}
+	}
+
+	for _, eventType := range params.Events {
+		if !events.IsValidType(eventType) {
+			RespondWithError(w, "BAD_REQUEST", "unknown event "+eventType, http.StatusBadRequest)
+			return
+		}
+	}
+
+	sub := database.WebhookSubscription{
+		ID:        uuid.NewString(),
+		Url:       params.URL,
+		Events:    params.Events,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	err = s.store.ExecTx(ctx, func(q database.Querier) error {
+		err := q.CreateWebhookSubscription(ctx, database.CreateWebhookSubscriptionParams{
+			ID:     sub.ID,
+			Url:    sub.Url,
+			Events: sub.Events,
+			Secret: params.Secret,
+		})
+		if err != nil {
+			return dbError("failed to create subscription", err)
+		}
+
+		after, err := q.GetWebhookSubscriptionByID(ctx, sub.ID)
+		if err != nil {
+			return dbError("failed to load subscription", err)
+		}
+		snapshot := toSubscriptionResponse(after)
+		sub.CreatedAt = after.CreatedAt
+
+		return writeAudit(ctx, q, auditActor(ctx), audit.Entry{
+			Action:     audit.SubscriptionCreated,
+			TargetType: audit.TargetSubscription,
+			TargetID:   sub.ID,
+			After:      &snapshot,
+		})
+	})
+	if err != nil {
+		respondWithAPIError(w, err)
+		return
+	}
+
+	RespondWithJSON(w, http.StatusCreated, map[string]any{
+		"subscription": toSubscriptionResponse(sub),
+	})
+}
+
+func (s *Server) ListSubscriptionsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	subs, err := s.store.ListWebhookSubscriptions(ctx)
+	if err != nil {
+		log.Printf("error listing webhook subscriptions, %v", err)
+		RespondWithError(w, "DB_ERROR", "failed to load subscriptions", http.StatusInternalServerError)
+		return
+	}
+
+	resp := make([]subscriptionResponse, 0, len(subs))
+	for _, sub := range subs {
+		resp = append(resp, toSubscriptionResponse(sub))
+	}
+
+	RespondWithJSON(w, http.StatusOK, map[string]any{
+		"subscriptions": resp,
+	})
+}
+
+func (s *Server) DeleteSubscriptionHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	params := deleteSubscriptionRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		RespondWithError(w, "BAD_JSON", "invalid json", http.StatusBadRequest)
+		return
+	}
+
+	err := s.store.ExecTx(ctx, func(q database.Querier) error {
+		before, err := q.GetWebhookSubscriptionByID(ctx, params.SubscriptionID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return newAPIError("NOT_FOUND", "subscription not found", http.StatusNotFound)
+		}
+		if err != nil {
+			return dbError("failed to load subscription", err)
+		}
+		snapshot := toSubscriptionResponse(before)
+
+		if _, err := q.DeleteWebhookSubscription(ctx, params.SubscriptionID); err != nil {
+			return dbError("failed to delete subscription", err)
+		}
+
+		return writeAudit(ctx, q, auditActor(ctx), audit.Entry{
+			Action:     audit.SubscriptionDeleted,
+			TargetType: audit.TargetSubscription,
+			TargetID:   params.SubscriptionID,
+			Before:     &snapshot,
+		})
+	})
+	if err != nil {
+		respondWithAPIError(w, err)
+		return
+	}
+
+	RespondWithJSON(w, http.StatusOK, map[string]any{
+		"subscription_id": params.SubscriptionID,
+		"deleted":         true,
+	})
+}
+
+func (s *Server) ListDeliveriesHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	query := r.URL.Query()
+
+	limit := int32(defaultDeliveryLimit)
+	if raw := query.Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 || n > maxDeliveryLimit {
+			RespondWithError(w, "BAD_REQUEST", "limit must be between 1 and 500", http.StatusBadRequest)
+			return
+		}
+		limit = int32(n)
+	}
+
+	subscriptionID := query.Get("subscription_id")
+	status := query.Get("status")
+	if status != "" && status != webhooks.StatusPending && status != webhooks.StatusDelivered && status != webhooks.StatusDead {
+		RespondWithError(w, "BAD_REQUEST", "unknown status", http.StatusBadRequest)
+		return
+	}
+
+	deliveries, err := s.store.ListWebhookDeliveries(ctx, database.ListWebhookDeliveriesParams{
+		Limit:          limit,
+		SubscriptionID: stringPtrNull(&subscriptionID),
+		Status:         stringPtrNull(&status),
+	})
+	if err != nil {
+		log.Printf("error listing webhook deliveries, %v", err)
+		RespondWithError(w, "DB_ERROR", "failed to load deliveries", http.StatusInternalServerError)
+		return
+	}
+
+	resp := make([]deliveryResponse, 0, len(deliveries))
+	for _, d := range deliveries {
+		item := deliveryResponse{
+			DeliveryID:     d.ID,
+			SubscriptionID: d.SubscriptionID,
+			EventID:        d.EventID,
+			EventType:      d.EventType,
+			PrID:           d.PrID,
+			Payload:        d.Payload,
+			Status:         d.Status,
+			Attempts:       d.Attempts,
+			ResponseStatus: nullInt32Ptr(d.ResponseStatus),
+			LastError:      nullStringPtr(d.LastError),
+			CreatedAt:      d.CreatedAt,
+			DeliveredAt:    nullTimePtr(d.DeliveredAt),
+		}
+
+		if d.Status == webhooks.StatusPending {
+			nextAttemptAt := d.NextAttemptAt
+			item.NextAttemptAt = &nextAttemptAt
+		}
+
+		resp = append(resp, item)
+	}
+
+	RespondWithJSON(w, http.StatusOK, map[string]any{
+		"deliveries": resp,
+	})
+}
+
+func (s *Server) RetryDeliveryHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	params := retryDeliveryRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		RespondWithError(w, "BAD_JSON", "invalid json", http.StatusBadRequest)
+		return
+	}
+
+	err := s.store.ExecTx(ctx, func(q database.Querier) error {
+		before, err := q.GetWebhookDeliveryByID(ctx, params.DeliveryID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return dbError("failed to load delivery", err)
+		}
+
+		requeued, err := q.RequeueWebhookDelivery(ctx, params.DeliveryID)
+		if err != nil {
+			return dbError("failed to requeue delivery", err)
+		}
+
+		if requeued == 0 {
+			return newAPIError("NOT_DEAD", "delivery not found or not dead-lettered", http.StatusConflict)
+		}
+
+		after, err := q.GetWebhookDeliveryByID(ctx, params.DeliveryID)
+		if err != nil {
+			return dbError("failed to load delivery", err)
+		}
+
+		return writeAudit(ctx, q, auditActor(ctx), audit.Entry{
+			Action:     audit.DeliveryRetried,
+			TargetType: audit.TargetDelivery,
+			TargetID:   strconv.FormatInt(params.DeliveryID, 10),
+			Before:     toDeliverySnapshot(before),
+			After:      toDeliverySnapshot(after),
+		})
+	})
+	if err != nil {
+		respondWithAPIError(w, err)
+		return
+	}
+
+	RespondWithJSON(w, http.StatusOK, map[string]any{
+		"delivery_id": params.DeliveryID,
+		"status":      webhooks.StatusPending,
+	})
+}
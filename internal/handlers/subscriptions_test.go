@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+)
+
+// stubLookup resolves hosts from the given table instead of DNS for the rest
+// of the test.
+func stubLookup(t *testing.T, hosts map[string][]string) {
+	t.Helper()
+
+	prev := lookupIPAddr
+	lookupIPAddr = func(_ context.Context, host string) ([]net.IPAddr, error) {
+		ips, ok := hosts[host]
+		if !ok {
+			return nil, fmt.Errorf("no such host %s", host)
+		}
+
+		addrs := make([]net.IPAddr, 0, len(ips))
+		for _, ip := range ips {
+			addrs = append(addrs, net.IPAddr{IP: net.ParseIP(ip)})
+		}
+		return addrs, nil
+	}
+	t.Cleanup(func() { lookupIPAddr = prev })
+}
+
+func TestCreateSubscriptionRejectsInternalTargets(t *testing.T) {
+	env := newAuditEnv(t)
+	stubLookup(t, map[string][]string{
+		"example.com":       {"93.184.215.14", "2606:2800:21f:cb07:6820:80da:af6b:8b2c"},
+		"localhost":         {"127.0.0.1", "::1"},
+		"intranet.example":  {"10.20.30.40"},
+		"mixed.example":     {"93.184.215.14", "192.168.0.10"},
+		"metadata.internal": {"169.254.169.254"},
+	})
+
+	tests := []struct {
+		url    string
+		status int
+	}{
+		{"https://example.com/hook", http.StatusCreated},
+		{"http://93.184.215.14:8080/hook", http.StatusCreated},
+		{"http://127.0.0.1/hook", http.StatusBadRequest},
+		{"http://localhost:9000/hook", http.StatusBadRequest},
+		{"http://[::1]/hook", http.StatusBadRequest},
+		{"http://0.0.0.0/hook", http.StatusBadRequest},
+		{"http://10.0.0.5/hook", http.StatusBadRequest},
+		{"http://172.16.3.4/hook", http.StatusBadRequest},
+		{"http://192.168.1.1/hook", http.StatusBadRequest},
+		{"http://[fd00::1]/hook", http.StatusBadRequest},
+		{"http://169.254.169.254/latest/meta-data", http.StatusBadRequest},
+		{"http://[fe80::1]/hook", http.StatusBadRequest},
+		{"http://[::ffff:127.0.0.1]/hook", http.StatusBadRequest},
+		{"https://intranet.example/hook", http.StatusBadRequest},
+		{"https://mixed.example/hook", http.StatusBadRequest},
+		{"http://metadata.internal/", http.StatusBadRequest},
+		{"https://unknown.example/hook", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		rec := env.do(http.MethodPost, "/subscriptions/add", env.admin, map[string]any{
+			"url":    tt.url,
+			"secret": "0123456789abcdef",
+		})
+		if rec.Code != tt.status {
+			t.Fatalf("%s: status %d, want %d, body %s", tt.url, rec.Code, tt.status, rec.Body)
+		}
+	}
+
+	subs, err := env.store.ListWebhookSubscriptions(context.Background())
+	if err != nil {
+		t.Fatalf("list subscriptions: %v", err)
+	}
+	if len(subs) != 2 {
+		t.Fatalf("subscriptions = %+v, want only the two public ones", subs)
+	}
+}
@@ -2,38 +2,146 @@ package handlers
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"slices"
 	"time"
 
-	"github.com/LlirikP/pr_dispenser/internal/config"
+	"github.com/LlirikP/pr_dispenser/internal/audit"
 	"github.com/LlirikP/pr_dispenser/internal/database"
+	"github.com/LlirikP/pr_dispenser/internal/domain"
+	"github.com/LlirikP/pr_dispenser/internal/events"
+	"github.com/LlirikP/pr_dispenser/internal/policy"
+	"github.com/LlirikP/pr_dispenser/internal/reviewers"
 	"github.com/google/uuid"
 )
 
 type createTeamRequest struct {
-	TeamName string `json:"team_name"`
-	Members  []struct {
-		UserID   string `json:"user_id"`
-		Username string `json:"username"`
-		IsActive bool   `json:"is_active"`
+	TeamName          string `json:"team_name"`
+	ReviewerStrategy  string `json:"reviewer_strategy"`
+	MaxOpenReviews    *int32 `json:"max_open_reviews"`
+	ReviewersRequired *int32 `json:"reviewers_required"`
+	ApprovalsRequired *int32 `json:"approvals_required"`
+	Members           []struct {
+		UserID         string  `json:"user_id"`
+		Username       string  `json:"username"`
+		IsActive       bool    `json:"is_active"`
+		ReviewWeight   *int32  `json:"review_weight"`
+		MaxOpenReviews *int32  `json:"max_open_reviews"`
+		GithubLogin    *string `json:"github_login"`
+		GitlabUsername *string `json:"gitlab_username"`
+		Role           *string `json:"role"`
 	} `json:"members"`
 }
 
+type setTeamStrategyRequest struct {
+	TeamName         string `json:"team_name"`
+	ReviewerStrategy string `json:"reviewer_strategy"`
+}
+
+type setTeamCapacityRequest struct {
+	TeamName       string `json:"team_name"`
+	MaxOpenReviews int32  `json:"max_open_reviews"`
+}
+
+type setTeamReviewersRequiredRequest struct {
+	TeamName          string `json:"team_name"`
+	ReviewersRequired int32  `json:"reviewers_required"`
+}
+
+type setTeamApprovalsRequiredRequest struct {
+	TeamName          string `json:"team_name"`
+	ApprovalsRequired int32  `json:"approvals_required"`
+}
+
+type setTeamFallbacksRequest struct {
+	TeamName      string   `json:"team_name"`
+	FallbackTeams []string `json:"fallback_teams"`
+}
+
+type deactivateTeamRequest struct {
+	TeamName string   `json:"team_name"`
+	UserIDs  []string `json:"user_ids"`
+}
+
+type deactivateTeamResponse struct {
+	TeamName    string                      `json:"team_name"`
+	Deactivated []string                    `json:"deactivated"`
+	Reassigned  []domain.Reassignment       `json:"reassigned"`
+	Failed      []domain.FailedReassignment `json:"failed"`
+}
+
 type teamMemberResponse struct {
-	UserID   string `json:"user_id"`
-	Username string `json:"username"`
-	IsActive bool   `json:"is_active"`
+	UserID         string  `json:"user_id"`
+	Username       string  `json:"username"`
+	IsActive       bool    `json:"is_active"`
+	ReviewWeight   int32   `json:"review_weight"`
+	MaxOpenReviews *int32  `json:"max_open_reviews"`
+	GithubLogin    *string `json:"github_login"`
+	GitlabUsername *string `json:"gitlab_username"`
+	Role           string  `json:"role"`
+	OpenReviews    int64   `json:"open_reviews"`
 }
 
 type teamResponse struct {
-	TeamName string               `json:"team_name"`
-	Members  []teamMemberResponse `json:"members"`
+	TeamName          string               `json:"team_name"`
+	ReviewerStrategy  string               `json:"reviewer_strategy"`
+	MaxOpenReviews    int32                `json:"max_open_reviews"`
+	ReviewersRequired int32                `json:"reviewers_required"`
+	ApprovalsRequired int32                `json:"approvals_required"`
+	FallbackTeams     []string             `json:"fallback_teams"`
+	Members           []teamMemberResponse `json:"members"`
+}
+
+const bulkOperationTimeout = 30 * time.Second
+
+func nullInt32Ptr(v sql.NullInt32) *int32 {
+	if !v.Valid {
+		return nil
+	}
+	return &v.Int32
+}
+
+func nullStringPtr(v sql.NullString) *string {
+	if !v.Valid {
+		return nil
+	}
+	return &v.String
+}
+
+func stringPtrNull(v *string) sql.NullString {
+	if v == nil || *v == "" {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: *v, Valid: true}
+}
+
+func int32PtrNull(v *int32) sql.NullInt32 {
+	if v == nil {
+		return sql.NullInt32{}
+	}
+	return sql.NullInt32{Int32: *v, Valid: true}
 }
 
-func CreateTeamHandler(w http.ResponseWriter, r *http.Request) {
+func memberRole(role *string, current *database.User) (string, error) {
+	switch {
+	case role != nil:
+		if !policy.IsValidRole(*role) {
+			return "", newAPIError("BAD_REQUEST", "role must be admin, team_lead or member", http.StatusBadRequest)
+		}
+		return *role, nil
+	case current != nil:
+		return current.Role, nil
+	default:
+		return policy.DefaultRole, nil
+	}
+}
+
+func (s *Server) CreateTeamHandler(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
 	defer cancel()
 
@@ -50,65 +158,193 @@ func CreateTeamHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	_, err := config.ApiCfg.DB.GetTeamByName(ctx, params.TeamName)
-	if err == nil {
-		RespondWithError(w, "TEAM_EXISTS", fmt.Sprintf("%s already exists", params.TeamName), http.StatusBadRequest)
-		log.Printf("team already exists, %v", err)
+	if params.ReviewerStrategy == "" {
+		params.ReviewerStrategy = reviewers.DefaultStrategy
+	}
+
+	if !reviewers.IsValidStrategy(params.ReviewerStrategy) {
+		RespondWithError(w, "BAD_REQUEST", "unknown reviewer_strategy", http.StatusBadRequest)
 		return
 	}
 
-	teamID := uuid.NewString()
+	maxOpenReviews := int32(1)
+	if params.MaxOpenReviews != nil {
+		maxOpenReviews = *params.MaxOpenReviews
+	}
 
-	err = config.ApiCfg.DB.CreateTeam(ctx, database.CreateTeamParams{
-		ID:       teamID,
-		Teamname: params.TeamName,
-	})
+	if maxOpenReviews <= 0 {
+		RespondWithError(w, "BAD_REQUEST", "max_open_reviews must be positive", http.StatusBadRequest)
+		return
+	}
 
-	if err != nil {
-		http.Error(w, "could not create a team", http.StatusInternalServerError)
-		log.Printf("error creating a team, %v", err)
+	reviewersRequired := int32(2)
+	if params.ReviewersRequired != nil {
+		reviewersRequired = *params.ReviewersRequired
+	}
+
+	if reviewersRequired < 0 {
+		RespondWithError(w, "BAD_REQUEST", "reviewers_required must not be negative", http.StatusBadRequest)
 		return
 	}
 
-	membersResp := make([]teamMemberResponse, 0, len(params.Members))
+	approvalsRequired := min(1, reviewersRequired)
+	if params.ApprovalsRequired != nil {
+		approvalsRequired = *params.ApprovalsRequired
+	}
 
-	for _, m := range params.Members {
-		if m.UserID == "" {
-			RespondWithError(w, "BAD_REQUEST", "invalid user id", http.StatusBadRequest)
-			return
-		}
+	if approvalsRequired < 0 {
+		RespondWithError(w, "BAD_REQUEST", "approvals_required must not be negative", http.StatusBadRequest)
+		return
+	}
+
+	if approvalsRequired > reviewersRequired {
+		RespondWithError(w, "BAD_REQUEST", "approvals_required must not exceed reviewers_required", http.StatusBadRequest)
+		return
+	}
+
+	_, err := s.store.GetTeamByName(ctx, params.TeamName)
+	if err == nil {
+		RespondWithError(w, "TEAM_EXISTS", fmt.Sprintf("%s already exists", params.TeamName), http.StatusBadRequest)
+		log.Printf("team already exists, %v", err)
+		return
+	}
+
+	teamID := uuid.NewString()
+	membersResp := make([]teamMemberResponse, 0, len(params.Members))
+	actor := auditActor(ctx)
 
-		err := config.ApiCfg.DB.UpsertUser(ctx, database.UpsertUserParams{
-			ID:       m.UserID,
-			Username: m.Username,
-			IsActive: m.IsActive,
-			TeamID:   teamID,
+	err = s.store.ExecTx(ctx, func(q database.Querier) error {
+		err := q.CreateTeam(ctx, database.CreateTeamParams{
+			ID:                teamID,
+			Teamname:          params.TeamName,
+			ReviewerStrategy:  params.ReviewerStrategy,
+			MaxOpenReviews:    maxOpenReviews,
+			ReviewersRequired: reviewersRequired,
+			ApprovalsRequired: approvalsRequired,
 		})
+		if err != nil {
+			return dbError("could not create a team", err)
+		}
 
+		team, err := q.GetTeamByID(ctx, teamID)
 		if err != nil {
-			RespondWithError(w, "DB_ERROR", "failed to upsert user", http.StatusInternalServerError)
-			log.Printf("error upserting user, %v", err)
-			return
+			return dbError("could not load the team", err)
 		}
 
-		membersResp = append(membersResp, teamMemberResponse{
-			UserID:   m.UserID,
-			Username: m.Username,
-			IsActive: m.IsActive,
+		err = writeAudit(ctx, q, actor, audit.Entry{
+			Action:     audit.TeamCreated,
+			TargetType: audit.TargetTeam,
+			TargetID:   teamID,
+			After:      toTeamSnapshot(team),
 		})
+		if err != nil {
+			return err
+		}
+
+		for _, m := range params.Members {
+			if m.UserID == "" {
+				return newAPIError("BAD_REQUEST", "invalid user id", http.StatusBadRequest)
+			}
+
+			weight := int32(1)
+			if m.ReviewWeight != nil {
+				weight = *m.ReviewWeight
+			}
+
+			if weight < 0 {
+				return newAPIError("BAD_REQUEST", "review_weight must not be negative", http.StatusBadRequest)
+			}
+
+			if m.MaxOpenReviews != nil && *m.MaxOpenReviews <= 0 {
+				return newAPIError("BAD_REQUEST", "max_open_reviews must be positive", http.StatusBadRequest)
+			}
+
+			var (
+				current *database.User
+				before  *userSnapshot
+			)
+
+			existing, err := q.GetUserById(ctx, m.UserID)
+			switch {
+			case err == nil:
+				current = &existing
+				before = toUserSnapshot(existing)
+			case !errors.Is(err, sql.ErrNoRows):
+				return dbError("failed to load user", err)
+			}
+
+			role, err := memberRole(m.Role, current)
+			if err != nil {
+				return err
+			}
+
+			err = q.UpsertUser(ctx, database.UpsertUserParams{
+				ID:             m.UserID,
+				Username:       m.Username,
+				IsAvailable:    m.IsActive,
+				TeamID:         teamID,
+				ReviewWeight:   weight,
+				MaxOpenReviews: int32PtrNull(m.MaxOpenReviews),
+				GithubLogin:    stringPtrNull(m.GithubLogin),
+				GitlabUsername: stringPtrNull(m.GitlabUsername),
+				Role:           role,
+			})
+			if err != nil {
+				return dbError("failed to upsert user", err)
+			}
+
+			user, err := q.GetUserById(ctx, m.UserID)
+			if err != nil {
+				return dbError("failed to load user", err)
+			}
+
+			err = writeAudit(ctx, q, actor, audit.Entry{
+				Action:     audit.UserUpserted,
+				TargetType: audit.TargetUser,
+				TargetID:   m.UserID,
+				Before:     before,
+				After:      toUserSnapshot(user),
+			})
+			if err != nil {
+				return err
+			}
+
+			membersResp = append(membersResp, teamMemberResponse{
+				UserID:         m.UserID,
+				Username:       m.Username,
+				IsActive:       m.IsActive,
+				ReviewWeight:   weight,
+				MaxOpenReviews: m.MaxOpenReviews,
+				GithubLogin:    m.GithubLogin,
+				GitlabUsername: m.GitlabUsername,
+				Role:           role,
+			})
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		respondWithAPIError(w, err)
+		return
 	}
 
 	resp := map[string]any{
 		"team": teamResponse{
-			TeamName: params.TeamName,
-			Members:  membersResp,
+			TeamName:          params.TeamName,
+			ReviewerStrategy:  params.ReviewerStrategy,
+			MaxOpenReviews:    maxOpenReviews,
+			ReviewersRequired: reviewersRequired,
+			ApprovalsRequired: approvalsRequired,
+			FallbackTeams:     []string{},
+			Members:           membersResp,
 		},
 	}
 
 	RespondWithJSON(w, http.StatusCreated, resp)
 }
 
-func GetTeamHandler(w http.ResponseWriter, r *http.Request) {
+func (s *Server) GetTeamHandler(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
 	defer cancel()
 
@@ -118,32 +354,515 @@ func GetTeamHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	team, err := config.ApiCfg.DB.GetTeamByName(ctx, teamName)
+	team, err := s.store.GetTeamByName(ctx, teamName)
 	if err != nil {
 		RespondWithError(w, "NOT_FOUND", "team not found", http.StatusNotFound)
 		log.Printf("team not found: %v", err)
 		return
 	}
 
-	users, err := config.ApiCfg.DB.GetUsersByTeam(ctx, team.ID)
+	users, err := s.store.GetUsersByTeam(ctx, team.ID)
 	if err != nil {
 		RespondWithError(w, "DB_ERROR", "could not get team users", http.StatusInternalServerError)
 		log.Printf("error fetching users for team: %v", err)
 		return
 	}
 
+	fallbacks, err := s.store.GetTeamFallbacks(ctx, team.ID)
+	if err != nil {
+		RespondWithError(w, "DB_ERROR", "could not get fallback teams", http.StatusInternalServerError)
+		log.Printf("error fetching fallback teams: %v", err)
+		return
+	}
+
 	resp := teamResponse{
-		TeamName: team.Teamname,
-		Members:  make([]teamMemberResponse, 0, len(users)),
+		TeamName:          team.Teamname,
+		FallbackTeams:     make([]string, 0, len(fallbacks)),
+		ReviewerStrategy:  team.ReviewerStrategy,
+		MaxOpenReviews:    team.MaxOpenReviews,
+		ReviewersRequired: team.ReviewersRequired,
+		ApprovalsRequired: team.ApprovalsRequired,
+		Members:           make([]teamMemberResponse, 0, len(users)),
+	}
+
+	for _, f := range fallbacks {
+		resp.FallbackTeams = append(resp.FallbackTeams, f.Teamname)
 	}
 
 	for _, u := range users {
 		resp.Members = append(resp.Members, teamMemberResponse{
-			UserID:   u.ID,
-			Username: u.Username,
-			IsActive: u.IsActive,
+			UserID:         u.ID,
+			Username:       u.Username,
+			IsActive:       u.IsAvailable,
+			ReviewWeight:   u.ReviewWeight,
+			MaxOpenReviews: nullInt32Ptr(u.MaxOpenReviews),
+			GithubLogin:    nullStringPtr(u.GithubLogin),
+			GitlabUsername: nullStringPtr(u.GitlabUsername),
+			Role:           u.Role,
+			OpenReviews:    u.OpenReviews,
 		})
 	}
 
 	RespondWithJSON(w, http.StatusOK, resp)
 }
+
+func (s *Server) SetTeamStrategyHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	params := setTeamStrategyRequest{}
+
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		RespondWithError(w, "BAD_JSON", "invalid json", http.StatusBadRequest)
+		log.Printf("error parsing json, %v", err)
+		return
+	}
+
+	if !reviewers.IsValidStrategy(params.ReviewerStrategy) {
+		RespondWithError(w, "BAD_REQUEST", "unknown reviewer_strategy", http.StatusBadRequest)
+		return
+	}
+
+	team, err := s.store.GetTeamByName(ctx, params.TeamName)
+	if err != nil {
+		RespondWithError(w, "NOT_FOUND", "team not found", http.StatusNotFound)
+		log.Printf("team not found: %v", err)
+		return
+	}
+
+	err = s.store.ExecTx(ctx, func(q database.Querier) error {
+		err := q.SetTeamReviewerStrategy(ctx, database.SetTeamReviewerStrategyParams{
+			ID:               team.ID,
+			ReviewerStrategy: params.ReviewerStrategy,
+		})
+		if err != nil {
+			return dbError("failed to update team", err)
+		}
+
+		return recordTeamAudit(ctx, q, audit.TeamStrategySet, team.ID, toTeamSnapshot(team))
+	})
+
+	if err != nil {
+		respondWithAPIError(w, err)
+		return
+	}
+
+	resp := map[string]any{
+		"team_name":         team.Teamname,
+		"reviewer_strategy": params.ReviewerStrategy,
+	}
+
+	RespondWithJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) SetTeamCapacityHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	params := setTeamCapacityRequest{}
+
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		RespondWithError(w, "BAD_JSON", "invalid json", http.StatusBadRequest)
+		log.Printf("error parsing json, %v", err)
+		return
+	}
+
+	if params.MaxOpenReviews <= 0 {
+		RespondWithError(w, "BAD_REQUEST", "max_open_reviews must be positive", http.StatusBadRequest)
+		return
+	}
+
+	team, err := s.store.GetTeamByName(ctx, params.TeamName)
+	if err != nil {
+		RespondWithError(w, "NOT_FOUND", "team not found", http.StatusNotFound)
+		log.Printf("team not found: %v", err)
+		return
+	}
+
+	err = s.store.ExecTx(ctx, func(q database.Querier) error {
+		err := q.SetTeamMaxOpenReviews(ctx, database.SetTeamMaxOpenReviewsParams{
+			ID:             team.ID,
+			MaxOpenReviews: params.MaxOpenReviews,
+		})
+		if err != nil {
+			return dbError("failed to update team", err)
+		}
+
+		return recordTeamAudit(ctx, q, audit.TeamCapacitySet, team.ID, toTeamSnapshot(team))
+	})
+
+	if err != nil {
+		respondWithAPIError(w, err)
+		return
+	}
+
+	resp := map[string]any{
+		"team_name":        team.Teamname,
+		"max_open_reviews": params.MaxOpenReviews,
+	}
+
+	RespondWithJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) SetTeamReviewersRequiredHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	params := setTeamReviewersRequiredRequest{}
+
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		RespondWithError(w, "BAD_JSON", "invalid json", http.StatusBadRequest)
+		log.Printf("error parsing json, %v", err)
+		return
+	}
+
+	if params.ReviewersRequired < 0 {
+		RespondWithError(w, "BAD_REQUEST", "reviewers_required must not be negative", http.StatusBadRequest)
+		return
+	}
+
+	team, err := s.store.GetTeamByName(ctx, params.TeamName)
+	if err != nil {
+		RespondWithError(w, "NOT_FOUND", "team not found", http.StatusNotFound)
+		log.Printf("team not found: %v", err)
+		return
+	}
+
+	err = s.store.ExecTx(ctx, func(q database.Querier) error {
+		current, err := q.GetTeamByID(ctx, team.ID)
+		if err != nil {
+			return dbError("failed to load team", err)
+		}
+
+		if params.ReviewersRequired < current.ApprovalsRequired {
+			return newAPIError("BAD_REQUEST", fmt.Sprintf("reviewers_required must be at least approvals_required (%d)", current.ApprovalsRequired), http.StatusBadRequest)
+		}
+
+		err = q.SetTeamReviewersRequired(ctx, database.SetTeamReviewersRequiredParams{
+			ID:                team.ID,
+			ReviewersRequired: params.ReviewersRequired,
+		})
+		if err != nil {
+			return dbError("failed to update team", err)
+		}
+
+		return recordTeamAudit(ctx, q, audit.TeamReviewersRequiredSet, team.ID, toTeamSnapshot(current))
+	})
+
+	if err != nil {
+		respondWithAPIError(w, err)
+		return
+	}
+
+	resp := map[string]any{
+		"team_name":          team.Teamname,
+		"reviewers_required": params.ReviewersRequired,
+	}
+
+	RespondWithJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) SetTeamFallbacksHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	params := setTeamFallbacksRequest{}
+
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		RespondWithError(w, "BAD_JSON", "invalid json", http.StatusBadRequest)
+		log.Printf("error parsing json, %v", err)
+		return
+	}
+
+	err := s.store.ExecTx(ctx, func(q database.Querier) error {
+		team, err := q.GetTeamByName(ctx, params.TeamName)
+		if err != nil {
+			return domain.SetTeamFallbacks(ctx, q, params.TeamName, params.FallbackTeams)
+		}
+
+		before, err := loadTeamFallbacksSnapshot(ctx, q, team)
+		if err != nil {
+			return err
+		}
+
+		if err := domain.SetTeamFallbacks(ctx, q, params.TeamName, params.FallbackTeams); err != nil {
+			return err
+		}
+
+		after, err := loadTeamFallbacksSnapshot(ctx, q, team)
+		if err != nil {
+			return err
+		}
+
+		return writeAudit(ctx, q, auditActor(ctx), audit.Entry{
+			Action:     audit.TeamFallbacksSet,
+			TargetType: audit.TargetTeam,
+			TargetID:   team.ID,
+			Before:     before,
+			After:      after,
+		})
+	})
+
+	if err != nil {
+		respondWithAPIError(w, err)
+		return
+	}
+
+	resp := map[string]any{
+		"team_name":      params.TeamName,
+		"fallback_teams": params.FallbackTeams,
+	}
+
+	RespondWithJSON(w, http.StatusOK, resp)
+}
+
+// loadDeactivationSnapshots takes the before snapshots of the users a
+// deactivation targets and of the open PRs they review, with a fixed number of
+// queries however many reviews are involved.
+func loadDeactivationSnapshots(ctx context.Context, q database.Querier, teamName string, userIDs []string) (map[string]*userSnapshot, map[string]*prSnapshot, error) {
+	users := map[string]*userSnapshot{}
+	prs := map[string]*prSnapshot{}
+
+	team, err := q.GetTeamByName(ctx, teamName)
+	if err != nil {
+		return users, prs, nil
+	}
+
+	members, err := q.GetUsersByTeam(ctx, team.ID)
+	if err != nil {
+		return nil, nil, dbError("could not get team users", err)
+	}
+
+	ids := make([]string, 0, len(members))
+	for _, m := range members {
+		if len(userIDs) > 0 && !slices.Contains(userIDs, m.ID) {
+			continue
+		}
+
+		ids = append(ids, m.ID)
+		users[m.ID] = toUserSnapshot(database.User{
+			ID:             m.ID,
+			Username:       m.Username,
+			IsAvailable:    m.IsAvailable,
+			TeamID:         m.TeamID,
+			ReviewWeight:   m.ReviewWeight,
+			MaxOpenReviews: m.MaxOpenReviews,
+			GithubLogin:    m.GithubLogin,
+			GitlabUsername: m.GitlabUsername,
+			Role:           m.Role,
+		})
+	}
+
+	open, err := q.GetOpenReviewsByReviewers(ctx, ids)
+	if err != nil {
+		return nil, nil, dbError("failed to load open reviews", err)
+	}
+	if len(open) == 0 {
+		return users, prs, nil
+	}
+
+	prIDs := make([]string, 0, len(open))
+	for _, review := range open {
+		if len(prIDs) == 0 || prIDs[len(prIDs)-1] != review.PrID {
+			prIDs = append(prIDs, review.PrID)
+		}
+	}
+
+	rows, err := q.GetPRsByIDs(ctx, prIDs)
+	if err != nil {
+		return nil, nil, dbError("failed to load pull requests", err)
+	}
+
+	reviewers, err := q.GetReviewersByPRs(ctx, prIDs)
+	if err != nil {
+		return nil, nil, dbError("failed to load reviewers", err)
+	}
+
+	assigned := make(map[string][]string, len(prIDs))
+	for _, r := range reviewers {
+		assigned[r.PrID] = append(assigned[r.PrID], r.ReviewerID)
+	}
+
+	for _, pr := range rows {
+		prs[pr.ID] = toPRSnapshot(pr, assigned[pr.ID])
+	}
+
+	return users, prs, nil
+}
+
+// deactivationAudit builds the after snapshots from the report: deactivated
+// users lose availability and each reassigned reviewer is swapped in place.
+func deactivationAudit(report domain.DeactivationReport, users map[string]*userSnapshot, prs map[string]*prSnapshot) ([]audit.Entry, error) {
+	entries := make([]audit.Entry, 0, len(report.Deactivated)+len(report.Reassigned))
+
+	for _, id := range report.Deactivated {
+		before, ok := users[id]
+		if !ok {
+			return nil, dbError("failed to record audit entry", fmt.Errorf("no snapshot of user %s", id))
+		}
+
+		after := *before
+		after.IsActive = false
+
+		entries = append(entries, audit.Entry{
+			Action:     audit.UserActiveSet,
+			TargetType: audit.TargetUser,
+			TargetID:   id,
+			Before:     before,
+			After:      &after,
+		})
+	}
+
+	after := make(map[string]*prSnapshot, len(prs))
+	var order []string
+	for _, ra := range report.Reassigned {
+		before, ok := prs[ra.PrID]
+		if !ok {
+			return nil, dbError("failed to record audit entry", fmt.Errorf("no snapshot of pull request %s", ra.PrID))
+		}
+
+		snapshot, ok := after[ra.PrID]
+		if !ok {
+			copied := *before
+			copied.AssignedReviewers = slices.Clone(before.AssignedReviewers)
+			snapshot = &copied
+			after[ra.PrID] = snapshot
+			order = append(order, ra.PrID)
+		}
+
+		if i := slices.Index(snapshot.AssignedReviewers, ra.OldUserID); i >= 0 {
+			snapshot.AssignedReviewers[i] = ra.NewUserID
+		}
+	}
+
+	for _, prID := range order {
+		entries = append(entries, audit.Entry{
+			Action:     audit.PRReassigned,
+			TargetType: audit.TargetPR,
+			TargetID:   prID,
+			Before:     prs[prID],
+			After:      after[prID],
+		})
+	}
+
+	return entries, nil
+}
+
+func (s *Server) DeactivateTeamHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), bulkOperationTimeout)
+	defer cancel()
+
+	params := deactivateTeamRequest{}
+
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		RespondWithError(w, "BAD_JSON", "invalid json", http.StatusBadRequest)
+		log.Printf("error parsing json, %v", err)
+		return
+	}
+
+	var report domain.DeactivationReport
+
+	err := s.execTx(ctx, func(q database.Querier, rec *events.Recorder) error {
+		users, prs, err := loadDeactivationSnapshots(ctx, q, params.TeamName, params.UserIDs)
+		if err != nil {
+			return err
+		}
+
+		report, err = domain.DeactivateTeam(ctx, q, rec, params.TeamName, params.UserIDs)
+		if err != nil {
+			return err
+		}
+
+		entries, err := deactivationAudit(report, users, prs)
+		if err != nil {
+			return err
+		}
+
+		actor := auditActor(ctx)
+		for _, entry := range entries {
+			if err := writeAudit(ctx, q, actor, entry); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		RespondWithError(w, "TIMEOUT", "deactivation did not finish in time, nothing was changed", http.StatusGatewayTimeout)
+		log.Printf("team deactivation timed out: %v", err)
+		return
+	}
+
+	if err != nil {
+		respondWithAPIError(w, err)
+		return
+	}
+
+	resp := deactivateTeamResponse{
+		TeamName:    params.TeamName,
+		Deactivated: report.Deactivated,
+		Reassigned:  report.Reassigned,
+		Failed:      report.Failed,
+	}
+
+	RespondWithJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) SetTeamApprovalsRequiredHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	params := setTeamApprovalsRequiredRequest{}
+
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		RespondWithError(w, "BAD_JSON", "invalid json", http.StatusBadRequest)
+		log.Printf("error parsing json, %v", err)
+		return
+	}
+
+	if params.ApprovalsRequired < 0 {
+		RespondWithError(w, "BAD_REQUEST", "approvals_required must not be negative", http.StatusBadRequest)
+		return
+	}
+
+	team, err := s.store.GetTeamByName(ctx, params.TeamName)
+	if err != nil {
+		RespondWithError(w, "NOT_FOUND", "team not found", http.StatusNotFound)
+		log.Printf("team not found: %v", err)
+		return
+	}
+
+	err = s.store.ExecTx(ctx, func(q database.Querier) error {
+		current, err := q.GetTeamByID(ctx, team.ID)
+		if err != nil {
+			return dbError("failed to load team", err)
+		}
+
+		if params.ApprovalsRequired > current.ReviewersRequired {
+			return newAPIError("BAD_REQUEST", fmt.Sprintf("approvals_required must not exceed reviewers_required (%d)", current.ReviewersRequired), http.StatusBadRequest)
+		}
+
+		err = q.SetTeamApprovalsRequired(ctx, database.SetTeamApprovalsRequiredParams{
+			ID:                team.ID,
+			ApprovalsRequired: params.ApprovalsRequired,
+		})
+		if err != nil {
+			return dbError("failed to update team", err)
+		}
+
+		return recordTeamAudit(ctx, q, audit.TeamApprovalsRequiredSet, team.ID, toTeamSnapshot(current))
+	})
+
+	if err != nil {
+		respondWithAPIError(w, err)
+		return
+	}
+
+	resp := map[string]any{
+		"team_name":          team.Teamname,
+		"approvals_required": params.ApprovalsRequired,
+	}
+
+	RespondWithJSON(w, http.StatusOK, resp)
+}
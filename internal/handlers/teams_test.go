@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/LlirikP/pr_dispenser/internal/config"
+)
+
+func TestApprovalsCannotExceedReviewers(t *testing.T) {
+	env := newTestEnv(t, config.Config{})
+	env.addTeam("backend", 2, testMember{UserID: "u1", Username: "alice", IsActive: true})
+
+	tests := []struct {
+		name   string
+		path   string
+		body   map[string]any
+		status int
+	}{
+		{"create with more approvals", "/team/add", map[string]any{"team_name": "platform", "reviewers_required": 1, "approvals_required": 2}, http.StatusBadRequest},
+		{"create without reviewers", "/team/add", map[string]any{"team_name": "docs", "reviewers_required": 0}, http.StatusCreated},
+		{"approvals above reviewers", "/team/setApprovalsRequired", map[string]any{"team_name": "backend", "approvals_required": 3}, http.StatusBadRequest},
+		{"approvals equal to reviewers", "/team/setApprovalsRequired", map[string]any{"team_name": "backend", "approvals_required": 2}, http.StatusOK},
+		{"reviewers below approvals", "/team/setReviewersRequired", map[string]any{"team_name": "backend", "reviewers_required": 1}, http.StatusBadRequest},
+		{"reviewers above approvals", "/team/setReviewersRequired", map[string]any{"team_name": "backend", "reviewers_required": 3}, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		if rec := env.do(http.MethodPost, tt.path, env.admin, tt.body); rec.Code != tt.status {
+			t.Fatalf("%s: status = %d, want %d, body %s", tt.name, rec.Code, tt.status, rec.Body)
+		}
+	}
+
+	team, err := env.store.GetTeamByName(context.Background(), "backend")
+	if err != nil {
+		t.Fatalf("get team: %v", err)
+	}
+	if team.ReviewersRequired != 3 || team.ApprovalsRequired != 2 {
+		t.Fatalf("team = %+v, want 3 reviewers and 2 approvals", team)
+	}
+
+	docs, err := env.store.GetTeamByName(context.Background(), "docs")
+	if err != nil {
+		t.Fatalf("get team: %v", err)
+	}
+	if docs.ApprovalsRequired != 0 {
+		t.Fatalf("docs approvals_required = %d, want 0", docs.ApprovalsRequired)
+	}
+	if _, err := env.store.GetTeamByName(context.Background(), "platform"); err == nil {
+		t.Fatalf("team with more approvals than reviewers was created")
+	}
+}
@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/LlirikP/pr_dispenser/internal/audit"
+	"github.com/LlirikP/pr_dispenser/internal/auth"
+	"github.com/LlirikP/pr_dispenser/internal/database"
+	"github.com/LlirikP/pr_dispenser/internal/policy"
+	"github.com/google/uuid"
+)
+
+type createTokenRequest struct {
+	Name   string  `json:"name"`
+	Scope  string  `json:"scope"`
+	UserID *string `json:"user_id"`
+}
+
+type revokeTokenRequest struct {
+	TokenID string `json:"token_id"`
+}
+
+type tokenResponse struct {
+	TokenID   string     `json:"token_id"`
+	Name      string     `json:"name"`
+	Scope     string     `json:"scope"`
+	UserID    *string    `json:"user_id"`
+	CreatedAt time.Time  `json:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at"`
+}
+
+func toTokenResponse(token database.ApiToken) tokenResponse {
+	return tokenResponse{
+		TokenID:   token.ID,
+		Name:      token.Name,
+		Scope:     token.Scope,
+		UserID:    nullStringPtr(token.UserID),
+		CreatedAt: token.CreatedAt,
+		RevokedAt: nullTimePtr(token.RevokedAt),
+	}
+}
+
+func (s *Server) CreateTokenHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	params := createTokenRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		RespondWithError(w, "BAD_JSON", "invalid json", http.StatusBadRequest)
+		return
+	}
+
+	params.Name = strings.TrimSpace(params.Name)
+	if params.Name == "" {
+		RespondWithError(w, "BAD_REQUEST", "name is required", http.StatusBadRequest)
+		return
+	}
+
+	if !auth.IsValidScope(params.Scope) {
+		RespondWithError(w, "BAD_REQUEST", "scope must be admin, team-admin, user or webhook", http.StatusBadRequest)
+		return
+	}
+
+	userID := stringPtrNull(params.UserID)
+	if auth.Scope(params.Scope) == auth.ScopeTeamAdmin && !userID.Valid {
+		RespondWithError(w, "BAD_REQUEST", "team-admin tokens must be bound to a user", http.StatusBadRequest)
+		return
+	}
+
+	if userID.Valid {
+		user, err := s.store.GetUserById(ctx, userID.String)
+		if errors.Is(err, sql.ErrNoRows) {
+			RespondWithError(w, "NOT_FOUND", "user not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			log.Printf("error loading user, %v", err)
+			RespondWithError(w, "DB_ERROR", "failed to load user", http.StatusInternalServerError)
+			return
+		}
+
+		if !policy.ScopeForRole(user.Role).Allows(auth.Scope(params.Scope)) {
+			RespondWithError(w, "BAD_REQUEST", "scope "+params.Scope+" exceeds the role of user "+user.ID, http.StatusBadRequest)
+			return
+		}
+	}
+
+	value, err := auth.GenerateToken()
+	if err != nil {
+		log.Printf("error generating api token, %v", err)
+		RespondWithError(w, "INTERNAL", "failed to generate token", http.StatusInternalServerError)
+		return
+	}
+
+	token := database.ApiToken{
+		ID:        uuid.NewString(),
+		Name:      params.Name,
+		TokenHash: auth.HashToken(value),
+		Scope:     params.Scope,
+		UserID:    userID,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	err = s.store.ExecTx(ctx, func(q database.Querier) error {
+		err := q.CreateAPIToken(ctx, database.CreateAPITokenParams{
+			ID:        token.ID,
+			Name:      token.Name,
+			TokenHash: token.TokenHash,
+			Scope:     token.Scope,
+			UserID:    token.UserID,
+		})
+		if err != nil {
+			return dbError("failed to create token", err)
+		}
+
+		return recordTokenAudit(ctx, q, audit.TokenCreated, token.ID, nil)
+	})
+	if err != nil {
+		respondWithAPIError(w, err)
+		return
+	}
+
+	RespondWithJSON(w, http.StatusCreated, map[string]any{
+		"token": toTokenResponse(token),
+		"value": value,
+	})
+}
+
+func (s *Server) ListTokensHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	tokens, err := s.store.ListAPITokens(ctx)
+	if err != nil {
+		log.Printf("error listing api tokens, %v", err)
+		RespondWithError(w, "DB_ERROR", "failed to load tokens", http.StatusInternalServerError)
+		return
+	}
+
+	resp := make([]tokenResponse, 0, len(tokens))
+	for _, token := range tokens {
+		resp = append(resp, toTokenResponse(token))
+	}
+
+	RespondWithJSON(w, http.StatusOK, map[string]any{
+		"tokens": resp,
+	})
+}
+
+func (s *Server) RevokeTokenHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	params := revokeTokenRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		RespondWithError(w, "BAD_JSON", "invalid json", http.StatusBadRequest)
+		return
+	}
+
+	err := s.store.ExecTx(ctx, func(q database.Querier) error {
+		token, err := q.GetAPITokenByID(ctx, params.TokenID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return newAPIError("NOT_FOUND", "token not found or already revoked", http.StatusNotFound)
+		}
+		if err != nil {
+			return dbError("failed to load token", err)
+		}
+
+		revoked, err := q.RevokeAPIToken(ctx, params.TokenID)
+		if err != nil {
+			return dbError("failed to revoke token", err)
+		}
+
+		if revoked == 0 {
+			return newAPIError("NOT_FOUND", "token not found or already revoked", http.StatusNotFound)
+		}
+
+		before := toTokenResponse(token)
+		return recordTokenAudit(ctx, q, audit.TokenRevoked, token.ID, &before)
+	})
+	if err != nil {
+		respondWithAPIError(w, err)
+		return
+	}
+
+	RespondWithJSON(w, http.StatusOK, map[string]any{
+		"token_id": params.TokenID,
+		"revoked":  true,
+	})
+}
@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/LlirikP/pr_dispenser/internal/database"
+	"github.com/LlirikP/pr_dispenser/internal/events"
+	"github.com/LlirikP/pr_dispenser/internal/outbox"
+)
+
+func (s *Server) execTx(ctx context.Context, fn func(q database.Querier, rec *events.Recorder) error) error {
+	return s.store.ExecTx(ctx, func(q database.Querier) error {
+		rec := &events.Recorder{}
+
+		if err := fn(q, rec); err != nil {
+			return err
+		}
+
+		if err := outbox.Write(ctx, q, rec.Events()); err != nil {
+			return dbError("failed to record events", err)
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/LlirikP/pr_dispenser/internal/config"
+	"github.com/LlirikP/pr_dispenser/internal/database"
+	"github.com/LlirikP/pr_dispenser/internal/database/memory"
+)
+
+var errInjected = errors.New("injected failure")
+
+type failingStorage struct {
+	database.Storage
+	failOn map[string]bool
+}
+
+func (s *failingStorage) ExecTx(ctx context.Context, fn func(database.Querier) error) error {
+	return s.Storage.ExecTx(ctx, func(q database.Querier) error {
+		return fn(failingQuerier{Querier: q, failOn: s.failOn})
+	})
+}
+
+type failingQuerier struct {
+	database.Querier
+	failOn map[string]bool
+}
+
+func (q failingQuerier) AddReviewer(ctx context.Context, arg database.AddReviewerParams) error {
+	if q.failOn["AddReviewer"] {
+		return errInjected
+	}
+	return q.Querier.AddReviewer(ctx, arg)
+}
+
+func (q failingQuerier) InsertOutboxEvent(ctx context.Context, arg database.InsertOutboxEventParams) error {
+	if q.failOn["InsertOutboxEvent"] {
+		return errInjected
+	}
+	return q.Querier.InsertOutboxEvent(ctx, arg)
+}
+
+func (q failingQuerier) InsertAuditEntry(ctx context.Context, arg database.InsertAuditEntryParams) error {
+	if q.failOn["InsertAuditEntry"] {
+		return errInjected
+	}
+	return q.Querier.InsertAuditEntry(ctx, arg)
+}
+
+func newFailingEnv(t *testing.T) (*testEnv, *failingStorage) {
+	t.Helper()
+
+	store := &failingStorage{Storage: memory.New(), failOn: map[string]bool{}}
+	env := newTestEnvWithStore(t, store, config.Config{})
+	env.addTeam("backend", 2,
+		testMember{UserID: "u1", Username: "alice", IsActive: true},
+		testMember{UserID: "u2", Username: "bob", IsActive: true},
+		testMember{UserID: "u3", Username: "carol", IsActive: true},
+		testMember{UserID: "u4", Username: "dave", IsActive: true},
+	)
+	return env, store
+}
+
+func (e *testEnv) createPR(prID, authorID string) []string {
+	e.t.Helper()
+
+	rec := e.do(http.MethodPost, "/pullRequest/create", e.admin, map[string]any{
+		"pull_request_id":   prID,
+		"pull_request_name": "change " + prID,
+		"author_id":         authorID,
+	})
+	if rec.Code != http.StatusCreated {
+		e.t.Fatalf("create pr %s: status %d, body %s", prID, rec.Code, rec.Body)
+	}
+	return e.reviewers(prID)
+}
+
+func TestCreatePRRollsBackOnFailure(t *testing.T) {
+	for _, method := range []string{"AddReviewer", "InsertOutboxEvent"} {
+		t.Run(method, func(t *testing.T) {
+			env, store := newFailingEnv(t)
+			store.failOn[method] = true
+
+			rec := env.do(http.MethodPost, "/pullRequest/create", env.admin, map[string]any{
+				"pull_request_id":   "pr-1",
+				"pull_request_name": "change",
+				"author_id":         "u1",
+			})
+			if rec.Code != http.StatusInternalServerError {
+				t.Fatalf("status = %d, want 500, body %s", rec.Code, rec.Body)
+			}
+
+			if _, err := env.store.GetPRById(context.Background(), "pr-1"); err == nil {
+				t.Fatalf("pr row survived a failed create")
+			}
+			if ids := env.reviewers("pr-1"); len(ids) != 0 {
+				t.Fatalf("reviewer rows survived a failed create: %v", ids)
+			}
+		})
+	}
+}
+
+func TestReassignRollsBackOnFailure(t *testing.T) {
+	for _, method := range []string{"AddReviewer", "InsertOutboxEvent"} {
+		t.Run(method, func(t *testing.T) {
+			env, store := newFailingEnv(t)
+			before := env.createPR("pr-1", "u1")
+			if len(before) != 2 {
+				t.Fatalf("reviewers = %v, want 2", before)
+			}
+
+			store.failOn[method] = true
+
+			rec := env.do(http.MethodPost, "/pullRequest/reassign", env.admin, map[string]any{
+				"pull_request_id": "pr-1",
+				"old_user_id":     before[0],
+			})
+			if rec.Code != http.StatusInternalServerError {
+				t.Fatalf("status = %d, want 500, body %s", rec.Code, rec.Body)
+			}
+
+			after := env.reviewers("pr-1")
+			slices.Sort(before)
+			slices.Sort(after)
+			if !slices.Equal(before, after) {
+				t.Fatalf("reviewers = %v, want unchanged %v", after, before)
+			}
+		})
+	}
+}
+
+func TestMergeRollsBackOnFailure(t *testing.T) {
+	env, store := newFailingEnv(t)
+	before := env.createPR("pr-1", "u1")
+
+	store.failOn["InsertOutboxEvent"] = true
+
+	rec := env.do(http.MethodPost, "/pullRequest/merge", env.admin, map[string]any{
+		"pull_request_id": "pr-1",
+		"force":           true,
+	})
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want 500, body %s", rec.Code, rec.Body)
+	}
+
+	pr := env.pr("pr-1")
+	if pr.Status != "OPEN" || pr.MergedAt.Valid {
+		t.Fatalf("pr = %s merged_at %v, want OPEN and not merged", pr.Status, pr.MergedAt)
+	}
+
+	after := env.reviewers("pr-1")
+	slices.Sort(before)
+	slices.Sort(after)
+	if !slices.Equal(before, after) {
+		t.Fatalf("reviewers = %v, want unchanged %v", after, before)
+	}
+}
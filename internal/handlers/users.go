@@ -7,8 +7,9 @@ import (
 	"net/http"
 	"time"
 
-	"github.com/LlirikP/pr_dispenser/internal/config"
+	"github.com/LlirikP/pr_dispenser/internal/audit"
 	"github.com/LlirikP/pr_dispenser/internal/database"
+	"github.com/LlirikP/pr_dispenser/internal/policy"
 )
 
 type setUserActiveRequest struct {
@@ -16,18 +17,30 @@ type setUserActiveRequest struct {
 	IsActive bool   `json:"is_active"`
 }
 
+type setUserRoleRequest struct {
+	UserID string `json:"user_id"`
+	Role   string `json:"role"`
+}
+
+type setUserCapacityRequest struct {
+	UserID         string `json:"user_id"`
+	MaxOpenReviews *int32 `json:"max_open_reviews"`
+}
+
 type reviewListResponse struct {
 	Items []reviewListItem `json:"pull_requests"`
 }
 
 type reviewListItem struct {
-	PrID     string `json:"pull_request_id"`
-	Title    string `json:"pull_request_name"`
-	AuthorID string `json:"author_id"`
-	Status   string `json:"status"`
+	PrID        string     `json:"pull_request_id"`
+	Title       string     `json:"pull_request_name"`
+	AuthorID    string     `json:"author_id"`
+	Status      string     `json:"status"`
+	ReviewState string     `json:"review_state"`
+	ReviewedAt  *time.Time `json:"reviewed_at"`
 }
 
-func SetUserActiveHandler(w http.ResponseWriter, r *http.Request) {
+func (s *Server) SetUserActiveHandler(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
 	defer cancel()
 
@@ -44,42 +57,173 @@ func SetUserActiveHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, err := config.ApiCfg.DB.GetUserById(ctx, params.UserID)
+	user, err := s.store.GetUserById(ctx, params.UserID)
 	if err != nil {
 		RespondWithError(w, "USER_NOT_FOUND", "unknown user", http.StatusNotFound)
 		log.Printf("error finding user: %v", err)
 		return
 	}
 
-	err = config.ApiCfg.DB.SetUserIsActive(ctx, database.SetUserIsActiveParams{
-		ID:       params.UserID,
-		IsActive: params.IsActive,
+	err = s.store.ExecTx(ctx, func(q database.Querier) error {
+		err := q.SetUserAvailability(ctx, database.SetUserAvailabilityParams{
+			ID:          params.UserID,
+			IsAvailable: params.IsActive,
+		})
+		if err != nil {
+			return dbError("failed to update user", err)
+		}
+
+		return recordUserAudit(ctx, q, audit.UserActiveSet, user.ID, toUserSnapshot(user))
 	})
 
 	if err != nil {
-		RespondWithError(w, "DB_ERROR", "failed to update user", http.StatusInternalServerError)
-		log.Printf("error updating user: %v", err)
+		respondWithAPIError(w, err)
 		return
 	}
 
-	teamName, err := config.ApiCfg.DB.GetTeamNameByID(ctx, user.TeamID)
+	teamName, err := s.store.GetTeamNameByID(ctx, user.TeamID)
 	if err != nil {
 		RespondWithError(w, "DB_ERROR", "failed to load team name", http.StatusInternalServerError)
 		return
 	}
+
+	openReviews, err := s.store.GetUserOpenReviews(ctx, user.ID)
+	if err != nil {
+		RespondWithError(w, "DB_ERROR", "failed to load workload", http.StatusInternalServerError)
+		log.Printf("error loading workload: %v", err)
+		return
+	}
+
+	resp := map[string]any{
+		"user": map[string]any{
+			"user_id":          user.ID,
+			"username":         user.Username,
+			"team_name":        teamName,
+			"is_active":        params.IsActive,
+			"role":             user.Role,
+			"max_open_reviews": nullInt32Ptr(user.MaxOpenReviews),
+			"open_reviews":     openReviews,
+		},
+	}
+
+	RespondWithJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) SetUserCapacityHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	params := setUserCapacityRequest{}
+
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		RespondWithError(w, "BAD_JSON", "invalid json", http.StatusBadRequest)
+		log.Printf("decode err: %v", err)
+		return
+	}
+
+	if params.UserID == "" {
+		RespondWithError(w, "BAD_REQUEST", "user_id required", http.StatusBadRequest)
+		return
+	}
+
+	if params.MaxOpenReviews != nil && *params.MaxOpenReviews <= 0 {
+		RespondWithError(w, "BAD_REQUEST", "max_open_reviews must be positive", http.StatusBadRequest)
+		return
+	}
+
+	user, err := s.store.GetUserById(ctx, params.UserID)
+	if err != nil {
+		RespondWithError(w, "USER_NOT_FOUND", "unknown user", http.StatusNotFound)
+		log.Printf("error finding user: %v", err)
+		return
+	}
+
+	err = s.store.ExecTx(ctx, func(q database.Querier) error {
+		err := q.SetUserMaxOpenReviews(ctx, database.SetUserMaxOpenReviewsParams{
+			ID:             user.ID,
+			MaxOpenReviews: int32PtrNull(params.MaxOpenReviews),
+		})
+		if err != nil {
+			return dbError("failed to update user", err)
+		}
+
+		return recordUserAudit(ctx, q, audit.UserCapacitySet, user.ID, toUserSnapshot(user))
+	})
+
+	if err != nil {
+		respondWithAPIError(w, err)
+		return
+	}
+
+	resp := map[string]any{
+		"user": map[string]any{
+			"user_id":          user.ID,
+			"username":         user.Username,
+			"max_open_reviews": params.MaxOpenReviews,
+		},
+	}
+
+	RespondWithJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) SetUserRoleHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	params := setUserRoleRequest{}
+
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		RespondWithError(w, "BAD_JSON", "invalid json", http.StatusBadRequest)
+		log.Printf("decode err: %v", err)
+		return
+	}
+
+	if params.UserID == "" {
+		RespondWithError(w, "BAD_REQUEST", "user_id required", http.StatusBadRequest)
+		return
+	}
+
+	if !policy.IsValidRole(params.Role) {
+		RespondWithError(w, "BAD_REQUEST", "role must be admin, team_lead or member", http.StatusBadRequest)
+		return
+	}
+
+	user, err := s.store.GetUserById(ctx, params.UserID)
+	if err != nil {
+		RespondWithError(w, "USER_NOT_FOUND", "unknown user", http.StatusNotFound)
+		log.Printf("error finding user: %v", err)
+		return
+	}
+
+	err = s.store.ExecTx(ctx, func(q database.Querier) error {
+		err := q.SetUserRole(ctx, database.SetUserRoleParams{
+			ID:   user.ID,
+			Role: params.Role,
+		})
+		if err != nil {
+			return dbError("failed to update user", err)
+		}
+
+		return recordUserAudit(ctx, q, audit.UserRoleSet, user.ID, toUserSnapshot(user))
+	})
+
+	if err != nil {
+		respondWithAPIError(w, err)
+		return
+	}
+
 	resp := map[string]any{
 		"user": map[string]any{
-			"user_id":   user.ID,
-			"username":  user.Username,
-			"team_name": teamName,
-			"is_active": params.IsActive,
+			"user_id":  user.ID,
+			"username": user.Username,
+			"role":     params.Role,
 		},
 	}
 
 	RespondWithJSON(w, http.StatusOK, resp)
 }
 
-func ReviewListHandler(w http.ResponseWriter, r *http.Request) {
+func (s *Server) ReviewListHandler(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
 	defer cancel()
 
@@ -89,13 +233,13 @@ func ReviewListHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	_, err := config.ApiCfg.DB.GetUserById(ctx, userID)
+	_, err := s.store.GetUserById(ctx, userID)
 	if err != nil {
 		RespondWithError(w, "USER_NOT_FOUND", "unknown user", http.StatusNotFound)
 		return
 	}
 
-	reviews, err := config.ApiCfg.DB.GetReviewPRs(ctx, userID)
+	reviews, err := s.store.GetReviewPRs(ctx, userID)
 	if err != nil {
 		RespondWithError(w, "DB_ERROR", "failed to load reviews", http.StatusInternalServerError)
 		log.Printf("error loading reviews, %v", err)
@@ -106,10 +250,12 @@ func ReviewListHandler(w http.ResponseWriter, r *http.Request) {
 
 	for _, r := range reviews {
 		resp.Items = append(resp.Items, reviewListItem{
-			PrID:     r.PrID,
-			Title:    r.PrTitle,
-			AuthorID: r.AuthorID,
-			Status:   r.Status,
+			PrID:        r.PrID,
+			Title:       r.PrTitle,
+			AuthorID:    r.AuthorID,
+			Status:      r.Status,
+			ReviewState: r.ReviewState,
+			ReviewedAt:  nullTimePtr(r.ReviewedAt),
 		})
 	}
 
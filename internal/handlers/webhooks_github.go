@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/LlirikP/pr_dispenser/internal/audit"
+	"github.com/LlirikP/pr_dispenser/internal/database"
+	"github.com/LlirikP/pr_dispenser/internal/domain"
+	"github.com/LlirikP/pr_dispenser/internal/events"
+)
+
+const maxWebhookBodySize = 5 << 20
+
+type githubPullRequestEvent struct {
+	Action      string `json:"action"`
+	Number      int    `json:"number"`
+	PullRequest struct {
+		Title  string `json:"title"`
+		Draft  bool   `json:"draft"`
+		Merged bool   `json:"merged"`
+		User   struct {
+			Login string `json:"login"`
+		} `json:"user"`
+	} `json:"pull_request"`
+	Repository struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+	Sender struct {
+		Login string `json:"login"`
+	} `json:"sender"`
+}
+
+type webhookResult struct {
+	Result    string
+	Reviewers []string
+	Shortfall int32
+}
+
+func verifyGithubSignature(secret string, body []byte, header string) bool {
+	signature, ok := strings.CutPrefix(header, "sha256=")
+	if !ok {
+		return false
+	}
+
+	got, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	return hmac.Equal(got, mac.Sum(nil))
+}
+
+func githubPRID(repo string, number int) string {
+	return fmt.Sprintf("github:%s#%d", repo, number)
+}
+
+func githubUser(ctx context.Context, q database.Querier, login string) (database.User, error) {
+	user, err := q.GetUserByGithubLogin(ctx, sql.NullString{String: login, Valid: login != ""})
+	if err != nil {
+		return user, newAPIError("USER_NOT_FOUND", fmt.Sprintf("no user mapped to github login %q", login), http.StatusUnprocessableEntity)
+	}
+	return user, nil
+}
+
+func applyGithubEvent(ctx context.Context, q database.Querier, rec *events.Recorder, event githubPullRequestEvent, prID string) (webhookResult, error) {
+	actor := "github:" + event.Sender.Login
+
+	switch event.Action {
+	case "opened":
+		if _, err := q.GetPRById(ctx, prID); err == nil {
+			return webhookResult{Result: "duplicate"}, nil
+		}
+
+		author, err := githubUser(ctx, q, event.PullRequest.User.Login)
+		if err != nil {
+			return webhookResult{}, err
+		}
+
+		created, err := domain.CreatePR(ctx, q, rec, domain.CreatePRParams{
+			PrID:           prID,
+			Title:          event.PullRequest.Title,
+			AuthorID:       author.ID,
+			Draft:          event.PullRequest.Draft,
+			AllowShortfall: true,
+		})
+		if err != nil {
+			return webhookResult{}, err
+		}
+
+		result := webhookResult{Result: "created", Reviewers: created.Assigned, Shortfall: created.Shortfall}
+		return result, recordPRAudit(ctx, q, actor, audit.PRCreated, prID, nil)
+
+	case "closed":
+		if event.PullRequest.Merged {
+			_, err := mergePR(ctx, q, rec, domain.MergePRParams{
+				PrID:           prID,
+				MergedUpstream: true,
+				ActorID:        actor,
+			})
+			return webhookResult{Result: "merged"}, err
+		}
+
+		_, err := closePR(ctx, q, rec, actor, prID)
+		return webhookResult{Result: "closed"}, err
+
+	case "reopened":
+		_, err := reopenPR(ctx, q, rec, actor, domain.ReopenPRParams{
+			PrID:               prID,
+			ReassignIneligible: true,
+		})
+		return webhookResult{Result: "reopened"}, err
+
+	case "ready_for_review":
+		_, _, err := markPRReady(ctx, q, rec, actor, prID, true)
+		return webhookResult{Result: "ready"}, err
+
+	default:
+		return webhookResult{Result: "ignored"}, nil
+	}
+}
+
+func (s *Server) GithubWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	secret := s.cfg.GithubWebhookSecret
+	if secret == "" {
+		RespondWithError(w, "WEBHOOK_DISABLED", "github webhook secret is not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBodySize))
+	if err != nil {
+		RespondWithError(w, "BAD_REQUEST", "could not read body", http.StatusBadRequest)
+		log.Printf("error reading webhook body, %v", err)
+		return
+	}
+
+	if !verifyGithubSignature(secret, body, r.Header.Get("X-Hub-Signature-256")) {
+		RespondWithError(w, "INVALID_SIGNATURE", "signature mismatch", http.StatusUnauthorized)
+		return
+	}
+
+	eventType := r.Header.Get("X-GitHub-Event")
+	switch eventType {
+	case "ping":
+		RespondWithJSON(w, http.StatusOK, map[string]any{"status": "pong"})
+		return
+	case "pull_request":
+	default:
+		RespondWithJSON(w, http.StatusAccepted, map[string]any{"status": "ignored", "event": eventType})
+		return
+	}
+
+	event := githubPullRequestEvent{}
+	if err := json.Unmarshal(body, &event); err != nil {
+		RespondWithError(w, "BAD_JSON", "invalid json", http.StatusBadRequest)
+		log.Printf("error parsing github payload, %v", err)
+		return
+	}
+
+	prID := githubPRID(event.Repository.FullName, event.Number)
+
+	var result webhookResult
+	err = s.execTx(ctx, func(q database.Querier, rec *events.Recorder) error {
+		var err error
+		result, err = applyGithubEvent(ctx, q, rec, event, prID)
+		return err
+	})
+
+	if err != nil {
+		respondWithAPIError(w, err)
+		return
+	}
+
+	resp := map[string]any{
+		"pull_request_id": prID,
+		"action":          event.Action,
+		"result":          result.Result,
+	}
+	if result.Reviewers != nil {
+		resp["reviewers"] = result.Reviewers
+	}
+	if result.Shortfall > 0 {
+		resp["shortfall"] = result.Shortfall
+	}
+
+	RespondWithJSON(w, http.StatusOK, resp)
+}
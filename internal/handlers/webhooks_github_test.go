@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/LlirikP/pr_dispenser/internal/config"
+)
+
+const githubSecret = "github-test-secret"
+
+func loadFixture(t *testing.T, provider, name string) []byte {
+	t.Helper()
+
+	body, err := os.ReadFile(filepath.Join("testdata", provider, name))
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	return body
+}
+
+func signGithub(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (e *testEnv) github(event string, body []byte, signature string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, "/webhooks/github", bytes.NewReader(body))
+	r.Header.Set("X-GitHub-Event", event)
+	if signature != "" {
+		r.Header.Set("X-Hub-Signature-256", signature)
+	}
+	return e.request(r)
+}
+
+type webhookResponse struct {
+	PrID      string   `json:"pull_request_id"`
+	Action    string   `json:"action"`
+	Result    string   `json:"result"`
+	Reviewers []string `json:"reviewers"`
+	Shortfall int32    `json:"shortfall"`
+}
+
+func (e *testEnv) githubFixture(name string) webhookResponse {
+	e.t.Helper()
+
+	body := loadFixture(e.t, "github", name)
+	rec := e.github("pull_request", body, signGithub(githubSecret, body))
+	if rec.Code != http.StatusOK {
+		e.t.Fatalf("%s: status %d, body %s", name, rec.Code, rec.Body)
+	}
+
+	resp := webhookResponse{}
+	decodeBody(e.t, rec, &resp)
+	return resp
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func newGithubEnv(t *testing.T, members ...testMember) *testEnv {
+	t.Helper()
+
+	env := newTestEnv(t, config.Config{GithubWebhookSecret: githubSecret})
+	env.addTeam("backend", 2, members...)
+	env.setTeamCapacity("backend", 5)
+	return env
+}
+
+func TestGithubWebhookLifecycle(t *testing.T) {
+	env := newGithubEnv(t,
+		testMember{UserID: "u1", Username: "alice", IsActive: true, GithubLogin: ptr("alice-gh")},
+		testMember{UserID: "u2", Username: "bob", IsActive: true, GithubLogin: ptr("bob-gh")},
+		testMember{UserID: "u3", Username: "carol", IsActive: true},
+		testMember{UserID: "u4", Username: "dave", IsActive: true},
+	)
+	const prID = "github:acme/api#42"
+
+	resp := env.githubFixture("pull_request_opened.json")
+	if resp.PrID != prID || resp.Result != "created" || len(resp.Reviewers) != 2 || resp.Shortfall != 0 {
+		t.Fatalf("opened: %+v", resp)
+	}
+	if pr := env.pr(prID); pr.AuthorID != "u1" || pr.Title != "Add rate limiting" || pr.Status != "OPEN" {
+		t.Fatalf("opened pr = %+v", pr)
+	}
+	if slices.Contains(resp.Reviewers, "u1") {
+		t.Fatalf("author was assigned as reviewer: %v", resp.Reviewers)
+	}
+
+	if resp := env.githubFixture("pull_request_opened.json"); resp.Result != "duplicate" {
+		t.Fatalf("redelivered opened: %+v", resp)
+	}
+
+	if resp := env.githubFixture("pull_request_closed.json"); resp.Result != "closed" {
+		t.Fatalf("closed: %+v", resp)
+	}
+	if pr := env.pr(prID); pr.Status != "CLOSED" {
+		t.Fatalf("closed pr status = %s", pr.Status)
+	}
+
+	if resp := env.githubFixture("pull_request_reopened.json"); resp.Result != "reopened" {
+		t.Fatalf("reopened: %+v", resp)
+	}
+	if pr := env.pr(prID); pr.Status != "OPEN" {
+		t.Fatalf("reopened pr status = %s", pr.Status)
+	}
+
+	if resp := env.githubFixture("pull_request_merged.json"); resp.Result != "merged" {
+		t.Fatalf("merged: %+v", resp)
+	}
+	pr := env.pr(prID)
+	if pr.Status != "MERGED" || pr.ForceMerged || pr.ForceMergedBy.Valid {
+		t.Fatalf("merged pr = %+v", pr)
+	}
+}
+
+func TestGithubWebhookReadyForReview(t *testing.T) {
+	env := newGithubEnv(t,
+		testMember{UserID: "u1", Username: "alice", IsActive: true, GithubLogin: ptr("alice-gh")},
+		testMember{UserID: "u2", Username: "bob", IsActive: true},
+		testMember{UserID: "u3", Username: "carol", IsActive: true},
+	)
+	const prID = "github:acme/api#43"
+
+	resp := env.githubFixture("pull_request_opened_draft.json")
+	if resp.Result != "created" || len(resp.Reviewers) != 0 {
+		t.Fatalf("opened draft: %+v", resp)
+	}
+	if pr := env.pr(prID); !pr.IsDraft {
+		t.Fatalf("pr is not a draft")
+	}
+
+	if resp := env.githubFixture("pull_request_ready_for_review.json"); resp.Result != "ready" {
+		t.Fatalf("ready_for_review: %+v", resp)
+	}
+	if pr := env.pr(prID); pr.IsDraft {
+		t.Fatalf("pr is still a draft")
+	}
+
+	reviewers := env.reviewers(prID)
+	slices.Sort(reviewers)
+	if !slices.Equal(reviewers, []string{"u2", "u3"}) {
+		t.Fatalf("reviewers = %v, want [u2 u3]", reviewers)
+	}
+}
+
+func TestGithubWebhookOpenedWithShortfall(t *testing.T) {
+	env := newGithubEnv(t,
+		testMember{UserID: "u1", Username: "alice", IsActive: true, GithubLogin: ptr("alice-gh")},
+		testMember{UserID: "u2", Username: "bob", IsActive: true},
+	)
+
+	resp := env.githubFixture("pull_request_opened.json")
+	if resp.Result != "created" || resp.Shortfall != 1 || !slices.Equal(resp.Reviewers, []string{"u2"}) {
+		t.Fatalf("opened: %+v, want one reviewer and a shortfall of 1", resp)
+	}
+	if reviewers := env.reviewers(resp.PrID); !slices.Equal(reviewers, []string{"u2"}) {
+		t.Fatalf("reviewers = %v, want [u2]", reviewers)
+	}
+}
+
+func TestGithubWebhookReadyWithShortfall(t *testing.T) {
+	env := newGithubEnv(t,
+		testMember{UserID: "u1", Username: "alice", IsActive: true, GithubLogin: ptr("alice-gh")},
+		testMember{UserID: "u2", Username: "bob", IsActive: true},
+	)
+	const prID = "github:acme/api#43"
+
+	env.githubFixture("pull_request_opened_draft.json")
+	if resp := env.githubFixture("pull_request_ready_for_review.json"); resp.Result != "ready" {
+		t.Fatalf("ready_for_review: %+v", resp)
+	}
+	if pr := env.pr(prID); pr.IsDraft {
+		t.Fatalf("pr is still a draft")
+	}
+	if reviewers := env.reviewers(prID); !slices.Equal(reviewers, []string{"u2"}) {
+		t.Fatalf("reviewers = %v, want [u2]", reviewers)
+	}
+}
+
+func TestGithubWebhookRejectsBadSignature(t *testing.T) {
+	env := newGithubEnv(t,
+		testMember{UserID: "u1", Username: "alice", IsActive: true, GithubLogin: ptr("alice-gh")},
+		testMember{UserID: "u2", Username: "bob", IsActive: true},
+		testMember{UserID: "u3", Username: "carol", IsActive: true},
+	)
+	body := loadFixture(t, "github", "pull_request_opened.json")
+	tampered := bytes.Replace(body, []byte("Add rate limiting"), []byte("Add rate limitinG"), 1)
+
+	tests := []struct {
+		name      string
+		body      []byte
+		signature string
+	}{
+		{"missing", body, ""},
+		{"wrong secret", body, signGithub("other-secret", body)},
+		{"tampered body", tampered, signGithub(githubSecret, body)},
+		{"not hex", body, "sha256=zz"},
+		{"wrong prefix", body, "sha1=" + signGithub(githubSecret, body)[len("sha256="):]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := env.github("pull_request", tt.body, tt.signature)
+			if rec.Code != http.StatusUnauthorized {
+				t.Fatalf("status = %d, want 401, body %s", rec.Code, rec.Body)
+			}
+			if code := errorCode(t, rec); code != "INVALID_SIGNATURE" {
+				t.Fatalf("code = %s, want INVALID_SIGNATURE", code)
+			}
+		})
+	}
+
+	if reviewers := env.reviewers("github:acme/api#42"); len(reviewers) != 0 {
+		t.Fatalf("rejected webhook assigned reviewers: %v", reviewers)
+	}
+}
+
+func TestGithubWebhookIgnoresOtherEvents(t *testing.T) {
+	env := newGithubEnv(t)
+
+	body := []byte(`{"zen":"Keep it logically awesome."}`)
+	if rec := env.github("ping", body, signGithub(githubSecret, body)); rec.Code != http.StatusOK {
+		t.Fatalf("ping: status = %d, body %s", rec.Code, rec.Body)
+	}
+	if rec := env.github("push", body, signGithub(githubSecret, body)); rec.Code != http.StatusAccepted {
+		t.Fatalf("push: status = %d, body %s", rec.Code, rec.Body)
+	}
+}
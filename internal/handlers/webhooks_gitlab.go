@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/LlirikP/pr_dispenser/internal/audit"
+	"github.com/LlirikP/pr_dispenser/internal/database"
+	"github.com/LlirikP/pr_dispenser/internal/domain"
+	"github.com/LlirikP/pr_dispenser/internal/events"
+)
+
+type gitlabDraftChange struct {
+	Previous bool `json:"previous"`
+	Current  bool `json:"current"`
+}
+
+type gitlabMergeRequestEvent struct {
+	ObjectKind string `json:"object_kind"`
+	User       struct {
+		Username string `json:"username"`
+	} `json:"user"`
+	Project struct {
+		PathWithNamespace string `json:"path_with_namespace"`
+	} `json:"project"`
+	ObjectAttributes struct {
+		IID            int    `json:"iid"`
+		Title          string `json:"title"`
+		Action         string `json:"action"`
+		Draft          bool   `json:"draft"`
+		WorkInProgress bool   `json:"work_in_progress"`
+	} `json:"object_attributes"`
+	Changes struct {
+		Draft          *gitlabDraftChange `json:"draft"`
+		WorkInProgress *gitlabDraftChange `json:"work_in_progress"`
+	} `json:"changes"`
+}
+
+func (e gitlabMergeRequestEvent) becameReady() bool {
+	for _, change := range []*gitlabDraftChange{e.Changes.Draft, e.Changes.WorkInProgress} {
+		if change != nil && change.Previous && !change.Current {
+			return true
+		}
+	}
+	return false
+}
+
+func gitlabPRID(project string, iid int) string {
+	return fmt.Sprintf("gitlab:%s!%d", project, iid)
+}
+
+func gitlabUser(ctx context.Context, q database.Querier, username string) (database.User, error) {
+	user, err := q.GetUserByGitlabUsername(ctx, sql.NullString{String: username, Valid: username != ""})
+	if err != nil {
+		return user, newAPIError("USER_NOT_FOUND", fmt.Sprintf("no user mapped to gitlab username %q", username), http.StatusUnprocessableEntity)
+	}
+	return user, nil
+}
+
+func applyGitlabEvent(ctx context.Context, q database.Querier, rec *events.Recorder, event gitlabMergeRequestEvent, prID string) (webhookResult, error) {
+	attrs := event.ObjectAttributes
+	actor := "gitlab:" + event.User.Username
+
+	switch attrs.Action {
+	case "open":
+		if _, err := q.GetPRById(ctx, prID); err == nil {
+			return webhookResult{Result: "duplicate"}, nil
+		}
+
+		author, err := gitlabUser(ctx, q, event.User.Username)
+		if err != nil {
+			return webhookResult{}, err
+		}
+
+		created, err := domain.CreatePR(ctx, q, rec, domain.CreatePRParams{
+			PrID:           prID,
+			Title:          attrs.Title,
+			AuthorID:       author.ID,
+			Draft:          attrs.Draft || attrs.WorkInProgress,
+			AllowShortfall: true,
+		})
+		if err != nil {
+			return webhookResult{}, err
+		}
+
+		result := webhookResult{Result: "created", Reviewers: created.Assigned, Shortfall: created.Shortfall}
+		return result, recordPRAudit(ctx, q, actor, audit.PRCreated, prID, nil)
+
+	case "update":
+		if !event.becameReady() {
+			return webhookResult{Result: "ignored"}, nil
+		}
+
+		_, _, err := markPRReady(ctx, q, rec, actor, prID, true)
+		return webhookResult{Result: "ready"}, err
+
+	case "merge":
+		_, err := mergePR(ctx, q, rec, domain.MergePRParams{
+			PrID:           prID,
+			MergedUpstream: true,
+			ActorID:        actor,
+		})
+		return webhookResult{Result: "merged"}, err
+
+	case "close":
+		_, err := closePR(ctx, q, rec, actor, prID)
+		return webhookResult{Result: "closed"}, err
+
+	case "reopen":
+		_, err := reopenPR(ctx, q, rec, actor, domain.ReopenPRParams{
+			PrID:               prID,
+			ReassignIneligible: true,
+		})
+		return webhookResult{Result: "reopened"}, err
+
+	case "approved", "approval", "unapproved", "unapproval":
+		reviewer, err := gitlabUser(ctx, q, event.User.Username)
+		if err != nil {
+			return webhookResult{}, err
+		}
+
+		state := domain.ReviewApproved
+		if attrs.Action == "unapproved" || attrs.Action == "unapproval" {
+			state = domain.ReviewPending
+		}
+
+		_, err = submitReview(ctx, q, actor, domain.SubmitReviewParams{
+			PrID:       prID,
+			ReviewerID: reviewer.ID,
+			State:      state,
+		})
+		return webhookResult{Result: "review_" + state}, err
+
+	default:
+		return webhookResult{Result: "ignored"}, nil
+	}
+}
+
+func (s *Server) GitlabWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	token := s.cfg.GitlabWebhookToken
+	if token == "" {
+		RespondWithError(w, "WEBHOOK_DISABLED", "gitlab webhook token is not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	if subtle.ConstantTimeCompare([]byte(r.Header.Get("X-Gitlab-Token")), []byte(token)) != 1 {
+		RespondWithError(w, "INVALID_TOKEN", "token mismatch", http.StatusUnauthorized)
+		return
+	}
+
+	eventType := r.Header.Get("X-Gitlab-Event")
+	if eventType != "Merge Request Hook" {
+		RespondWithJSON(w, http.StatusAccepted, map[string]any{"status": "ignored", "event": eventType})
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBodySize))
+	if err != nil {
+		RespondWithError(w, "BAD_REQUEST", "could not read body", http.StatusBadRequest)
+		log.Printf("error reading webhook body, %v", err)
+		return
+	}
+
+	event := gitlabMergeRequestEvent{}
+	if err := json.Unmarshal(body, &event); err != nil {
+		RespondWithError(w, "BAD_JSON", "invalid json", http.StatusBadRequest)
+		log.Printf("error parsing gitlab payload, %v", err)
+		return
+	}
+
+	prID := gitlabPRID(event.Project.PathWithNamespace, event.ObjectAttributes.IID)
+
+	var result webhookResult
+	err = s.execTx(ctx, func(q database.Querier, rec *events.Recorder) error {
+		var err error
+		result, err = applyGitlabEvent(ctx, q, rec, event, prID)
+		return err
+	})
+
+	if err != nil {
+		respondWithAPIError(w, err)
+		return
+	}
+
+	resp := map[string]any{
+		"pull_request_id": prID,
+		"action":          event.ObjectAttributes.Action,
+		"result":          result.Result,
+	}
+	if result.Reviewers != nil {
+		resp["reviewers"] = result.Reviewers
+	}
+	if result.Shortfall > 0 {
+		resp["shortfall"] = result.Shortfall
+	}
+
+	RespondWithJSON(w, http.StatusOK, resp)
+}
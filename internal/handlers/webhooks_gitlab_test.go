@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/LlirikP/pr_dispenser/internal/config"
+)
+
+const gitlabToken = "gitlab-test-token"
+
+func (e *testEnv) gitlab(event string, body []byte, token string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, "/webhooks/gitlab", bytes.NewReader(body))
+	r.Header.Set("X-Gitlab-Event", event)
+	if token != "" {
+		r.Header.Set("X-Gitlab-Token", token)
+	}
+	return e.request(r)
+}
+
+func (e *testEnv) gitlabFixture(name string) webhookResponse {
+	e.t.Helper()
+
+	rec := e.gitlab("Merge Request Hook", loadFixture(e.t, "gitlab", name), gitlabToken)
+	if rec.Code != http.StatusOK {
+		e.t.Fatalf("%s: status %d, body %s", name, rec.Code, rec.Body)
+	}
+
+	resp := webhookResponse{}
+	decodeBody(e.t, rec, &resp)
+	return resp
+}
+
+func newGitlabEnv(t *testing.T) *testEnv {
+	t.Helper()
+
+	env := newTestEnv(t, config.Config{GitlabWebhookToken: gitlabToken})
+	env.addTeam("billing", 2,
+		testMember{UserID: "u1", Username: "alice", IsActive: true, GitlabUsername: ptr("alice-gl")},
+		testMember{UserID: "u2", Username: "bob", IsActive: true, GitlabUsername: ptr("bob-gl")},
+		testMember{UserID: "u3", Username: "carol", IsActive: true, GitlabUsername: ptr("carol-gl")},
+	)
+	env.setTeamCapacity("billing", 5)
+	return env
+}
+
+func (e *testEnv) reviewState(prID, reviewerID string) string {
+	e.t.Helper()
+
+	reviews, err := e.store.GetReviewsByPR(context.Background(), prID)
+	if err != nil {
+		e.t.Fatalf("get reviews: %v", err)
+	}
+	for _, r := range reviews {
+		if r.ReviewerID == reviewerID {
+			return r.State
+		}
+	}
+	e.t.Fatalf("%s is not a reviewer of %s", reviewerID, prID)
+	return ""
+}
+
+func TestGitlabWebhookLifecycle(t *testing.T) {
+	env := newGitlabEnv(t)
+	const prID = "gitlab:acme/billing!7"
+
+	resp := env.gitlabFixture("merge_request_open.json")
+	if resp.PrID != prID || resp.Result != "created" || len(resp.Reviewers) != 2 {
+		t.Fatalf("open: %+v", resp)
+	}
+	if pr := env.pr(prID); pr.AuthorID != "u1" || pr.Status != "OPEN" || pr.IsDraft {
+		t.Fatalf("opened pr = %+v", pr)
+	}
+
+	if resp := env.gitlabFixture("merge_request_open.json"); resp.Result != "duplicate" {
+		t.Fatalf("redelivered open: %+v", resp)
+	}
+
+	if resp := env.gitlabFixture("merge_request_approved.json"); resp.Result != "review_APPROVED" {
+		t.Fatalf("approved: %+v", resp)
+	}
+	if state := env.reviewState(prID, "u2"); state != "APPROVED" {
+		t.Fatalf("bob's review = %s, want APPROVED", state)
+	}
+
+	if resp := env.gitlabFixture("merge_request_unapproved.json"); resp.Result != "review_PENDING" {
+		t.Fatalf("unapproved: %+v", resp)
+	}
+	if state := env.reviewState(prID, "u2"); state != "PENDING" {
+		t.Fatalf("bob's review = %s, want PENDING", state)
+	}
+
+	if resp := env.gitlabFixture("merge_request_close.json"); resp.Result != "closed" {
+		t.Fatalf("close: %+v", resp)
+	}
+	if pr := env.pr(prID); pr.Status != "CLOSED" {
+		t.Fatalf("closed pr status = %s", pr.Status)
+	}
+
+	if resp := env.gitlabFixture("merge_request_reopen.json"); resp.Result != "reopened" {
+		t.Fatalf("reopen: %+v", resp)
+	}
+	if pr := env.pr(prID); pr.Status != "OPEN" {
+		t.Fatalf("reopened pr status = %s", pr.Status)
+	}
+
+	if resp := env.gitlabFixture("merge_request_merge.json"); resp.Result != "merged" {
+		t.Fatalf("merge: %+v", resp)
+	}
+	pr := env.pr(prID)
+	if pr.Status != "MERGED" || pr.ForceMerged || pr.ForceMergedBy.Valid {
+		t.Fatalf("merged pr = %+v", pr)
+	}
+}
+
+func TestGitlabWebhookDraftBecomesReady(t *testing.T) {
+	env := newGitlabEnv(t)
+	const prID = "gitlab:acme/billing!8"
+
+	resp := env.gitlabFixture("merge_request_open_draft.json")
+	if resp.Result != "created" || len(resp.Reviewers) != 0 {
+		t.Fatalf("open draft: %+v", resp)
+	}
+
+	if resp := env.gitlabFixture("merge_request_update_title.json"); resp.Result != "ignored" {
+		t.Fatalf("title update: %+v", resp)
+	}
+	if pr := env.pr(prID); !pr.IsDraft {
+		t.Fatalf("title update marked the pr ready")
+	}
+
+	if resp := env.gitlabFixture("merge_request_update_ready.json"); resp.Result != "ready" {
+		t.Fatalf("ready update: %+v", resp)
+	}
+	if pr := env.pr(prID); pr.IsDraft {
+		t.Fatalf("pr is still a draft")
+	}
+
+	reviewers := env.reviewers(prID)
+	slices.Sort(reviewers)
+	if !slices.Equal(reviewers, []string{"u2", "u3"}) {
+		t.Fatalf("reviewers = %v, want [u2 u3]", reviewers)
+	}
+}
+
+func TestGitlabWebhookDraftBecomesReadyWithShortfall(t *testing.T) {
+	env := newGitlabEnv(t)
+	const prID = "gitlab:acme/billing!8"
+
+	env.gitlabFixture("merge_request_open_draft.json")
+	if rec := env.do(http.MethodPost, "/users/setIsActive", env.admin, map[string]any{"user_id": "u3", "is_active": false}); rec.Code != http.StatusOK {
+		t.Fatalf("deactivate u3: status %d, body %s", rec.Code, rec.Body)
+	}
+
+	if resp := env.gitlabFixture("merge_request_update_ready.json"); resp.Result != "ready" {
+		t.Fatalf("ready update: %+v", resp)
+	}
+	if pr := env.pr(prID); pr.IsDraft {
+		t.Fatalf("pr is still a draft")
+	}
+	if reviewers := env.reviewers(prID); !slices.Equal(reviewers, []string{"u2"}) {
+		t.Fatalf("reviewers = %v, want [u2]", reviewers)
+	}
+}
+
+func TestGitlabWebhookRejectsWrongToken(t *testing.T) {
+	env := newGitlabEnv(t)
+	body := loadFixture(t, "gitlab", "merge_request_open.json")
+
+	for _, token := range []string{"", "wrong-token", gitlabToken + "x"} {
+		rec := env.gitlab("Merge Request Hook", body, token)
+		if rec.Code != http.StatusUnauthorized {
+			t.Fatalf("token %q: status = %d, want 401, body %s", token, rec.Code, rec.Body)
+		}
+		if code := errorCode(t, rec); code != "INVALID_TOKEN" {
+			t.Fatalf("token %q: code = %s, want INVALID_TOKEN", token, code)
+		}
+	}
+
+	if _, err := env.store.GetPRById(context.Background(), "gitlab:acme/billing!7"); err == nil {
+		t.Fatalf("rejected webhook created a pr")
+	}
+}
+
+func TestGitlabWebhookIgnoresOtherEvents(t *testing.T) {
+	env := newGitlabEnv(t)
+
+	rec := env.gitlab("Push Hook", []byte(`{"object_kind":"push"}`), gitlabToken)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("push: status = %d, want 202, body %s", rec.Code, rec.Body)
+	}
+}
@@ -0,0 +1,74 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+
+	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/lock"
+
+	schema "github.com/LlirikP/pr_dispenser/internal/sql"
+)
+
+type Migrator struct {
+	provider *goose.Provider
+}
+
+func New(storage string, db *sql.DB) (*Migrator, error) {
+	var (
+		dialect goose.Dialect
+		fsys    fs.FS
+		err     error
+		opts    []goose.ProviderOption
+	)
+
+	switch storage {
+	case "postgres":
+		dialect = goose.DialectPostgres
+		fsys, err = fs.Sub(schema.Postgres, "schema")
+		if err != nil {
+			return nil, err
+		}
+
+		locker, err := lock.NewPostgresSessionLocker()
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, goose.WithSessionLocker(locker))
+
+	case "sqlite":
+		dialect = goose.DialectSQLite3
+		fsys, err = fs.Sub(schema.SQLite, "sqlite/schema")
+		if err != nil {
+			return nil, err
+		}
+
+	default:
+		return nil, fmt.Errorf("migrations are not supported for %s storage", storage)
+	}
+
+	provider, err := goose.NewProvider(dialect, db, fsys, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{provider: provider}, nil
+}
+
+func (m *Migrator) Up(ctx context.Context) ([]*goose.MigrationResult, error) {
+	return m.provider.Up(ctx)
+}
+
+func (m *Migrator) Down(ctx context.Context) (*goose.MigrationResult, error) {
+	return m.provider.Down(ctx)
+}
+
+func (m *Migrator) Status(ctx context.Context) ([]*goose.MigrationStatus, error) {
+	return m.provider.Status(ctx)
+}
+
+func (m *Migrator) Version(ctx context.Context) (current, target int64, err error) {
+	return m.provider.GetVersions(ctx)
+}
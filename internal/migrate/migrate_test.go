@@ -0,0 +1,151 @@
+package migrate_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"testing"
+
+	"github.com/pressly/goose/v3"
+
+	"github.com/LlirikP/pr_dispenser/internal/database/sqlite"
+	"github.com/LlirikP/pr_dispenser/internal/migrate"
+	schema "github.com/LlirikP/pr_dispenser/internal/sql"
+)
+
+func newMigrator(t *testing.T) (*migrate.Migrator, *sql.DB) {
+	t.Helper()
+
+	db, err := sqlite.OpenDB(filepath.Join(t.TempDir(), "dispenser.db"))
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	migrator, err := migrate.New("sqlite", db)
+	if err != nil {
+		t.Fatalf("new migrator: %v", err)
+	}
+	return migrator, db
+}
+
+// embedded counts the SQLite migrations shipped in the binary.
+func embedded(t *testing.T) int64 {
+	t.Helper()
+
+	files, err := fs.Glob(schema.SQLite, "sqlite/schema/*.sql")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("embedded migrations: %v %v", files, err)
+	}
+	return int64(len(files))
+}
+
+func requireVersion(t *testing.T, m *migrate.Migrator, current, target int64) {
+	t.Helper()
+
+	gotCurrent, gotTarget, err := m.Version(context.Background())
+	if err != nil {
+		t.Fatalf("version: %v", err)
+	}
+	if gotCurrent != current || gotTarget != target {
+		t.Fatalf("version = %d of %d, want %d of %d", gotCurrent, gotTarget, current, target)
+	}
+}
+
+// requireApplied checks that exactly the first n migrations are applied.
+func requireApplied(t *testing.T, m *migrate.Migrator, n int64) {
+	t.Helper()
+
+	statuses, err := m.Status(context.Background())
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	for i, st := range statuses {
+		version := int64(i) + 1
+		want := goose.StatePending
+		if version <= n {
+			want = goose.StateApplied
+		}
+		if st.Source.Version != version || st.State != want {
+			t.Fatalf("migration %d: version %d %s, want %s", i, st.Source.Version, st.State, want)
+		}
+	}
+}
+
+func hasTable(t *testing.T, db *sql.DB, name string) bool {
+	t.Helper()
+
+	var n int
+	err := db.QueryRow(`SELECT count(*) FROM sqlite_master WHERE type = 'table' AND name = ?`, name).Scan(&n)
+	if err != nil {
+		t.Fatalf("look up table %s: %v", name, err)
+	}
+	return n > 0
+}
+
+func TestUpAndDown(t *testing.T) {
+	ctx := context.Background()
+	m, db := newMigrator(t)
+	total := embedded(t)
+
+	requireVersion(t, m, 0, total)
+	requireApplied(t, m, 0)
+
+	results, err := m.Up(ctx)
+	if err != nil {
+		t.Fatalf("up: %v", err)
+	}
+	if int64(len(results)) != total {
+		t.Fatalf("applied %d migrations, want %d", len(results), total)
+	}
+	for i, r := range results {
+		if r.Source.Version != int64(i)+1 || r.Direction != "up" {
+			t.Fatalf("result %d = %s, want version %d up", i, r, i+1)
+		}
+	}
+	requireVersion(t, m, total, total)
+	requireApplied(t, m, total)
+	if !hasTable(t, db, "audit_log") {
+		t.Fatalf("audit_log missing after up")
+	}
+
+	if results, err := m.Up(ctx); err != nil || len(results) != 0 {
+		t.Fatalf("second up applied %v, err %v", results, err)
+	}
+
+	// Every step must undo cleanly, newest first.
+	for version := total; version > 0; version-- {
+		r, err := m.Down(ctx)
+		if err != nil {
+			t.Fatalf("down from %d: %v", version, err)
+		}
+		if r.Source.Version != version || r.Direction != "down" {
+			t.Fatalf("down = %s, want version %d down", r, version)
+		}
+		requireVersion(t, m, version-1, total)
+		requireApplied(t, m, version-1)
+	}
+	if hasTable(t, db, "teams") {
+		t.Fatalf("teams still exists after rolling everything back")
+	}
+
+	if _, err := m.Down(ctx); !errors.Is(err, goose.ErrNoNextVersion) {
+		t.Fatalf("down with nothing applied: err = %v, want %v", err, goose.ErrNoNextVersion)
+	}
+
+	// The schema comes back after a full round trip.
+	if results, err := m.Up(ctx); err != nil || int64(len(results)) != total {
+		t.Fatalf("up after down applied %d, err %v", len(results), err)
+	}
+	requireVersion(t, m, total, total)
+}
+
+func TestNewRejectsUnknownStorage(t *testing.T) {
+	_, db := newMigrator(t)
+
+	if _, err := migrate.New("memory", db); err == nil {
+		t.Fatalf("memory storage accepted")
+	}
+}
@@ -0,0 +1,178 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/LlirikP/pr_dispenser/internal/database"
+	"github.com/LlirikP/pr_dispenser/internal/events"
+	"github.com/LlirikP/pr_dispenser/internal/webhooks"
+)
+
+const (
+	StatusPending    = "PENDING"
+	StatusDispatched = "DISPATCHED"
+	StatusDead       = "DEAD"
+)
+
+const (
+	defaultInterval    = time.Second
+	defaultBatchSize   = 100
+	defaultMaxAttempts = 10
+	maxErrorLength     = 500
+)
+
+var errEmpty = errors.New("outbox is empty")
+
+func Write(ctx context.Context, q database.Querier, evs []events.Event) error {
+	for _, ev := range evs {
+		payload, err := json.Marshal(ev)
+		if err != nil {
+			return err
+		}
+
+		err = q.InsertOutboxEvent(ctx, database.InsertOutboxEventParams{
+			EventID:   ev.ID,
+			EventType: ev.Type,
+			PrID:      ev.PrID,
+			UserIds:   ev.UserIDs,
+			Payload:   payload,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type Relay struct {
+	store       database.Storage
+	Interval    time.Duration
+	BatchSize   int
+	MaxAttempts int32
+}
+
+func NewRelay(store database.Storage) *Relay {
+	return &Relay{
+		store:       store,
+		Interval:    defaultInterval,
+		BatchSize:   defaultBatchSize,
+		MaxAttempts: defaultMaxAttempts,
+	}
+}
+
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.Interval)
+	defer ticker.Stop()
+
+	for {
+		_, err := r.RelayPending(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("outbox relay failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *Relay) Sequence(ctx context.Context) (int64, error) {
+	var sequenced int64
+
+	err := r.store.ExecTx(ctx, func(q database.Querier) error {
+		if err := q.LockOutboxSequencer(ctx); err != nil {
+			return err
+		}
+
+		var err error
+		sequenced, err = q.SequenceOutboxEvents(ctx)
+		return err
+	})
+
+	return sequenced, err
+}
+
+func (r *Relay) RelayPending(ctx context.Context) (int, error) {
+	if _, err := r.Sequence(ctx); err != nil {
+		return 0, err
+	}
+
+	relayed := 0
+	for relayed < r.BatchSize {
+		ok, err := r.relayNext(ctx)
+		if err != nil || !ok {
+			return relayed, err
+		}
+		relayed++
+	}
+	return relayed, nil
+}
+
+func (r *Relay) relayNext(ctx context.Context) (bool, error) {
+	var (
+		entry     database.Outbox
+		fanOutErr error
+	)
+
+	err := r.store.ExecTx(ctx, func(q database.Querier) error {
+		var err error
+
+		entry, err = q.ClaimNextOutboxEvent(ctx)
+		if errors.Is(err, sql.ErrNoRows) {
+			return errEmpty
+		}
+		if err != nil {
+			return err
+		}
+
+		fanOutErr = webhooks.Enqueue(ctx, q, entry)
+		if fanOutErr != nil {
+			return fanOutErr
+		}
+
+		return q.MarkOutboxDispatched(ctx, entry.ID)
+	})
+
+	if errors.Is(err, errEmpty) {
+		return false, nil
+	}
+
+	if fanOutErr != nil {
+		return true, r.recordFailure(ctx, entry, fanOutErr)
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (r *Relay) recordFailure(ctx context.Context, entry database.Outbox, cause error) error {
+	attempt := entry.Attempts + 1
+	status := StatusPending
+	if attempt >= r.MaxAttempts {
+		status = StatusDead
+		log.Printf("outbox event %s (%s) dead-lettered after %d attempts: %v", entry.EventID, entry.EventType, attempt, cause)
+	}
+
+	msg := cause.Error()
+	if len(msg) > maxErrorLength {
+		msg = msg[:maxErrorLength]
+	}
+
+	return r.store.MarkOutboxAttemptFailed(ctx, database.MarkOutboxAttemptFailedParams{
+		ID:            entry.ID,
+		Status:        status,
+		LastError:     sql.NullString{String: msg, Valid: true},
+		NextAttemptAt: time.Now().Add(webhooks.Backoff(attempt)),
+	})
+}
@@ -0,0 +1,243 @@
+package outbox_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/LlirikP/pr_dispenser/internal/database"
+	"github.com/LlirikP/pr_dispenser/internal/database/memory"
+	"github.com/LlirikP/pr_dispenser/internal/events"
+	"github.com/LlirikP/pr_dispenser/internal/outbox"
+)
+
+var errFanOut = errors.New("fan-out failed")
+
+// relayStore fails the webhook fan-out of chosen events and records every
+// failed attempt the relay reports. With retryNow set a failed event is due
+// again straight away, so retries run without sleeping.
+type relayStore struct {
+	database.Storage
+
+	mu       sync.Mutex
+	failures map[string]int
+	retryNow bool
+	failed   []database.MarkOutboxAttemptFailedParams
+}
+
+type relayQuerier struct {
+	database.Querier
+	s *relayStore
+}
+
+func newRelayStore(t *testing.T) *relayStore {
+	t.Helper()
+
+	store := memory.New()
+	err := store.CreateWebhookSubscription(context.Background(), database.CreateWebhookSubscriptionParams{
+		ID:     "sub-1",
+		Url:    "https://example.com/hook",
+		Events: []string{},
+		Secret: "receiver-secret",
+	})
+	if err != nil {
+		t.Fatalf("create subscription: %v", err)
+	}
+
+	return &relayStore{Storage: store, failures: map[string]int{}}
+}
+
+func (s *relayStore) ExecTx(ctx context.Context, fn func(database.Querier) error) error {
+	return s.Storage.ExecTx(ctx, func(q database.Querier) error {
+		return fn(relayQuerier{Querier: q, s: s})
+	})
+}
+
+func (q relayQuerier) CreateWebhookDelivery(ctx context.Context, arg database.CreateWebhookDeliveryParams) error {
+	q.s.mu.Lock()
+	fail := q.s.failures[arg.EventID] > 0
+	if fail {
+		q.s.failures[arg.EventID]--
+	}
+	q.s.mu.Unlock()
+
+	if fail {
+		return errFanOut
+	}
+	return q.Querier.CreateWebhookDelivery(ctx, arg)
+}
+
+func (s *relayStore) MarkOutboxAttemptFailed(ctx context.Context, arg database.MarkOutboxAttemptFailedParams) error {
+	s.mu.Lock()
+	s.failed = append(s.failed, arg)
+	if s.retryNow {
+		arg.NextAttemptAt = time.Now()
+	}
+	s.mu.Unlock()
+
+	return s.Storage.MarkOutboxAttemptFailed(ctx, arg)
+}
+
+// record commits one transaction that writes an event per id, in order.
+func record(t *testing.T, s database.Storage, prID string, ids ...string) {
+	t.Helper()
+
+	err := s.ExecTx(context.Background(), func(q database.Querier) error {
+		return outbox.Write(context.Background(), q, newEvents(prID, ids...))
+	})
+	if err != nil {
+		t.Fatalf("write events: %v", err)
+	}
+}
+
+func newEvents(prID string, ids ...string) []events.Event {
+	evs := make([]events.Event, 0, len(ids))
+	for _, id := range ids {
+		evs = append(evs, events.Event{
+			ID:         id,
+			Type:       events.PRCreated,
+			PrID:       prID,
+			UserIDs:    []string{"u1"},
+			OccurredAt: time.Now(),
+		})
+	}
+	return evs
+}
+
+// enqueued lists the events fanned out to webhook deliveries, oldest first.
+func enqueued(t *testing.T, s database.Storage) []string {
+	t.Helper()
+
+	rows, err := s.ListWebhookDeliveries(context.Background(), database.ListWebhookDeliveriesParams{Limit: 100})
+	if err != nil {
+		t.Fatalf("list deliveries: %v", err)
+	}
+
+	ids := make([]string, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.EventID)
+	}
+	slices.Reverse(ids)
+	return ids
+}
+
+func relay(t *testing.T, r *outbox.Relay) int {
+	t.Helper()
+
+	n, err := r.RelayPending(context.Background())
+	if err != nil {
+		t.Fatalf("relay: %v", err)
+	}
+	return n
+}
+
+func TestRelayKeepsPerPROrder(t *testing.T) {
+	store := newRelayStore(t)
+	record(t, store, "pr-1", "e1")
+	record(t, store, "pr-2", "e2")
+	record(t, store, "pr-1", "e3")
+	record(t, store, "pr-2", "e4")
+
+	store.failures["e1"] = 1
+
+	// e1 fails and waits out its backoff; e3 must wait behind it while pr-2
+	// carries on.
+	r := outbox.NewRelay(store)
+	if n := relay(t, r); n != 3 {
+		t.Fatalf("relayed %d, want 3", n)
+	}
+	if got := enqueued(t, store); !slices.Equal(got, []string{"e2", "e4"}) {
+		t.Fatalf("enqueued %v, want [e2 e4]", got)
+	}
+	if n := relay(t, r); n != 0 {
+		t.Fatalf("relayed %d during e1's backoff, want 0", n)
+	}
+
+	// Pretend the backoff has passed.
+	failed := store.failed[0]
+	failed.NextAttemptAt = time.Now()
+	if err := store.Storage.MarkOutboxAttemptFailed(context.Background(), failed); err != nil {
+		t.Fatalf("expire backoff: %v", err)
+	}
+
+	if n := relay(t, r); n != 2 {
+		t.Fatalf("relayed %d, want 2", n)
+	}
+	if got := enqueued(t, store); !slices.Equal(got, []string{"e2", "e4", "e1", "e3"}) {
+		t.Fatalf("enqueued %v, want [e2 e4 e1 e3]", got)
+	}
+}
+
+func TestRelaySkipsRolledBackEvents(t *testing.T) {
+	store := newRelayStore(t)
+
+	errAbort := errors.New("abort")
+	err := store.ExecTx(context.Background(), func(q database.Querier) error {
+		if err := outbox.Write(context.Background(), q, newEvents("pr-1", "lost-1", "lost-2")); err != nil {
+			return err
+		}
+		return errAbort
+	})
+	if !errors.Is(err, errAbort) {
+		t.Fatalf("tx err = %v, want abort", err)
+	}
+
+	record(t, store, "pr-1", "kept")
+
+	r := outbox.NewRelay(store)
+	if n := relay(t, r); n != 1 {
+		t.Fatalf("relayed %d, want 1", n)
+	}
+	if got := enqueued(t, store); !slices.Equal(got, []string{"kept"}) {
+		t.Fatalf("enqueued %v, want [kept]", got)
+	}
+
+	// The rolled-back events never reach the stream either.
+	rows, err := store.GetStreamEventsAfter(context.Background(), database.GetStreamEventsAfterParams{BatchSize: 10})
+	if err != nil {
+		t.Fatalf("stream events: %v", err)
+	}
+	if len(rows) != 1 || rows[0].Seq != 1 {
+		t.Fatalf("stream = %+v, want only the kept event", rows)
+	}
+}
+
+func TestRelayDeadLettersAfterMaxAttempts(t *testing.T) {
+	store := newRelayStore(t)
+	store.retryNow = true
+	store.failures["doomed"] = 100
+
+	record(t, store, "pr-1", "doomed", "next")
+
+	r := outbox.NewRelay(store)
+	r.MaxAttempts = 3
+
+	// Three failed attempts, the last of them dead-letters the event and
+	// frees pr-1 for the event behind it.
+	if n := relay(t, r); n != 4 {
+		t.Fatalf("relayed %d, want 4", n)
+	}
+
+	var statuses []string
+	for _, f := range store.failed {
+		statuses = append(statuses, f.Status)
+		if f.LastError.String != errFanOut.Error() {
+			t.Fatalf("last error = %q, want %q", f.LastError.String, errFanOut)
+		}
+	}
+	want := []string{outbox.StatusPending, outbox.StatusPending, outbox.StatusDead}
+	if !slices.Equal(statuses, want) {
+		t.Fatalf("attempts = %v, want %v", statuses, want)
+	}
+
+	if got := enqueued(t, store); !slices.Equal(got, []string{"next"}) {
+		t.Fatalf("enqueued %v, want [next]", got)
+	}
+
+	if n := relay(t, r); n != 0 || len(store.failed) != 3 {
+		t.Fatalf("dead event was retried: relayed %d, %d failures", n, len(store.failed))
+	}
+}
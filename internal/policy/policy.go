@@ -0,0 +1,172 @@
+package policy
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/LlirikP/pr_dispenser/internal/database"
+	"github.com/LlirikP/pr_dispenser/internal/domain"
+)
+
+type Actor struct {
+	UserID string
+	Role   string
+	TeamID string
+}
+
+type Target struct {
+	UserID     string `json:"user_id"`
+	PrID       string `json:"pull_request_id"`
+	TeamName   string `json:"team_name"`
+	AuthorID   string `json:"author_id"`
+	ReviewerID string `json:"reviewer_id"`
+}
+
+type Rule func(ctx context.Context, q database.Querier, actor Actor, target Target) error
+
+func forbidden(msg string) *domain.Error {
+	return &domain.Error{Kind: domain.KindForbidden, Code: "FORBIDDEN", Message: msg}
+}
+
+func storageError(msg string, err error) *domain.Error {
+	return &domain.Error{Kind: domain.KindInternal, Code: "DB_ERROR", Message: msg, Err: err}
+}
+
+func SetUserActive(ctx context.Context, q database.Querier, actor Actor, target Target) error {
+	if actor.UserID == target.UserID {
+		return nil
+	}
+	return ManageUser(ctx, q, actor, target)
+}
+
+func ManageUser(ctx context.Context, q database.Querier, actor Actor, target Target) error {
+	if actor.Role == RoleAdmin {
+		return nil
+	}
+
+	if actor.Role != RoleTeamLead {
+		return forbidden("only admins and team leads can manage other users")
+	}
+
+	user, err := q.GetUserById(ctx, target.UserID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return storageError("failed to load user", err)
+	}
+
+	if user.TeamID != actor.TeamID {
+		return forbidden("team leads can only manage users of their own team")
+	}
+	return nil
+}
+
+func ManageTeam(ctx context.Context, q database.Querier, actor Actor, target Target) error {
+	if actor.Role == RoleAdmin {
+		return nil
+	}
+
+	if actor.Role != RoleTeamLead {
+		return forbidden("only admins and team leads can change team settings")
+	}
+
+	team, err := q.GetTeamByName(ctx, target.TeamName)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return storageError("failed to load team", err)
+	}
+
+	if team.ID != actor.TeamID {
+		return forbidden("team leads can only change settings of their own team")
+	}
+	return nil
+}
+
+func ReassignReviewer(ctx context.Context, q database.Querier, actor Actor, target Target) error {
+	if actor.Role == RoleAdmin {
+		return nil
+	}
+
+	if actor.Role != RoleTeamLead {
+		return forbidden("only admins and team leads can reassign reviewers")
+	}
+
+	author, found, err := prAuthor(ctx, q, target.PrID)
+	if err != nil || !found {
+		return err
+	}
+
+	if author.TeamID != actor.TeamID {
+		return forbidden("team leads can only reassign reviewers on their own team's pull requests")
+	}
+	return nil
+}
+
+func ManagePR(ctx context.Context, q database.Querier, actor Actor, target Target) error {
+	if actor.Role == RoleAdmin {
+		return nil
+	}
+
+	author, found, err := prAuthor(ctx, q, target.PrID)
+	if err != nil || !found {
+		return err
+	}
+
+	if author.ID == actor.UserID {
+		return nil
+	}
+	if actor.Role == RoleTeamLead && author.TeamID == actor.TeamID {
+		return nil
+	}
+	return forbidden("only the author, a lead of the author's team or an admin can change this pull request")
+}
+
+func CreatePR(ctx context.Context, q database.Querier, actor Actor, target Target) error {
+	if actor.Role == RoleAdmin || target.AuthorID == actor.UserID {
+		return nil
+	}
+
+	if actor.Role != RoleTeamLead {
+		return forbidden("pull requests can only be created by their author, a team lead or an admin")
+	}
+
+	author, err := q.GetUserById(ctx, target.AuthorID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return storageError("failed to load pr author", err)
+	}
+
+	if author.TeamID != actor.TeamID {
+		return forbidden("team leads can only create pull requests for their own team")
+	}
+	return nil
+}
+
+func SubmitReview(ctx context.Context, q database.Querier, actor Actor, target Target) error {
+	if actor.Role == RoleAdmin || target.ReviewerID == "" || target.ReviewerID == actor.UserID {
+		return nil
+	}
+	return forbidden("reviews can only be submitted as the authenticated user")
+}
+
+func prAuthor(ctx context.Context, q database.Querier, prID string) (database.User, bool, error) {
+	pr, err := q.GetPRById(ctx, prID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.User{}, false, nil
+	}
+	if err != nil {
+		return database.User{}, false, storageError("failed to load pull request", err)
+	}
+
+	author, err := q.GetUserById(ctx, pr.AuthorID)
+	if err != nil {
+		return database.User{}, false, storageError("failed to load pr author", err)
+	}
+	return author, true, nil
+}
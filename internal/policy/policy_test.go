@@ -0,0 +1,181 @@
+package policy_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/LlirikP/pr_dispenser/internal/database"
+	"github.com/LlirikP/pr_dispenser/internal/database/memory"
+	"github.com/LlirikP/pr_dispenser/internal/domain"
+	"github.com/LlirikP/pr_dispenser/internal/policy"
+)
+
+var (
+	admin     = policy.Actor{UserID: "admin", Role: policy.RoleAdmin, TeamID: "platform"}
+	lead      = policy.Actor{UserID: "lead", Role: policy.RoleTeamLead, TeamID: "backend"}
+	otherLead = policy.Actor{UserID: "other-lead", Role: policy.RoleTeamLead, TeamID: "platform"}
+	author    = policy.Actor{UserID: "author", Role: policy.RoleMember, TeamID: "backend"}
+	member    = policy.Actor{UserID: "member", Role: policy.RoleMember, TeamID: "backend"}
+)
+
+// brokenQuerier fails every user lookup, so rules that need one report a
+// storage error.
+type brokenQuerier struct {
+	database.Querier
+}
+
+func (brokenQuerier) GetUserById(context.Context, string) (database.User, error) {
+	return database.User{}, errors.New("connection reset")
+}
+
+func (brokenQuerier) GetTeamByName(context.Context, string) (database.Team, error) {
+	return database.Team{}, errors.New("connection reset")
+}
+
+func newPolicyStore(t *testing.T) *memory.Store {
+	t.Helper()
+
+	ctx := context.Background()
+	store := memory.New()
+
+	for _, team := range []string{"backend", "platform"} {
+		err := store.CreateTeam(ctx, database.CreateTeamParams{
+			ID:                team,
+			Teamname:          team,
+			ReviewerStrategy:  "least_loaded",
+			MaxOpenReviews:    5,
+			ReviewersRequired: 1,
+			ApprovalsRequired: 1,
+		})
+		if err != nil {
+			t.Fatalf("create team: %v", err)
+		}
+	}
+
+	for _, actor := range []policy.Actor{admin, lead, otherLead, author, member} {
+		err := store.UpsertUser(ctx, database.UpsertUserParams{
+			ID:           actor.UserID,
+			Username:     actor.UserID,
+			IsAvailable:  true,
+			TeamID:       actor.TeamID,
+			ReviewWeight: 1,
+			Role:         actor.Role,
+		})
+		if err != nil {
+			t.Fatalf("upsert user: %v", err)
+		}
+	}
+
+	err := store.CreatePR(ctx, database.CreatePRParams{ID: "pr-1", Title: "pr-1", AuthorID: author.UserID, ReviewersRequired: 1})
+	if err != nil {
+		t.Fatalf("create pr: %v", err)
+	}
+
+	return store
+}
+
+func TestRules(t *testing.T) {
+	store := newPolicyStore(t)
+
+	team := policy.Target{TeamName: "backend"}
+	user := policy.Target{UserID: member.UserID}
+	pr := policy.Target{PrID: "pr-1"}
+	create := policy.Target{AuthorID: author.UserID}
+	review := policy.Target{PrID: "pr-1", ReviewerID: member.UserID}
+
+	tests := []struct {
+		name    string
+		rule    policy.Rule
+		actor   policy.Actor
+		target  policy.Target
+		allowed bool
+	}{
+		{"manage team: admin", policy.ManageTeam, admin, team, true},
+		{"manage team: own lead", policy.ManageTeam, lead, team, true},
+		{"manage team: other lead", policy.ManageTeam, otherLead, team, false},
+		{"manage team: member", policy.ManageTeam, member, team, false},
+		{"manage team: unknown team", policy.ManageTeam, lead, policy.Target{TeamName: "ghost"}, true},
+
+		{"manage user: admin", policy.ManageUser, admin, user, true},
+		{"manage user: own lead", policy.ManageUser, lead, user, true},
+		{"manage user: other lead", policy.ManageUser, otherLead, user, false},
+		{"manage user: member", policy.ManageUser, member, user, false},
+		{"manage user: unknown user", policy.ManageUser, lead, policy.Target{UserID: "ghost"}, true},
+
+		{"set active: self", policy.SetUserActive, member, user, true},
+		{"set active: teammate", policy.SetUserActive, author, user, false},
+		{"set active: own lead", policy.SetUserActive, lead, user, true},
+		{"set active: other lead", policy.SetUserActive, otherLead, user, false},
+		{"set active: admin", policy.SetUserActive, admin, user, true},
+
+		{"reassign: admin", policy.ReassignReviewer, admin, pr, true},
+		{"reassign: own lead", policy.ReassignReviewer, lead, pr, true},
+		{"reassign: other lead", policy.ReassignReviewer, otherLead, pr, false},
+		{"reassign: author", policy.ReassignReviewer, author, pr, false},
+		{"reassign: unknown pr", policy.ReassignReviewer, lead, policy.Target{PrID: "ghost"}, true},
+
+		{"manage pr: admin", policy.ManagePR, admin, pr, true},
+		{"manage pr: author", policy.ManagePR, author, pr, true},
+		{"manage pr: own lead", policy.ManagePR, lead, pr, true},
+		{"manage pr: other lead", policy.ManagePR, otherLead, pr, false},
+		{"manage pr: member", policy.ManagePR, member, pr, false},
+		{"manage pr: unknown pr", policy.ManagePR, member, policy.Target{PrID: "ghost"}, true},
+
+		{"create pr: admin", policy.CreatePR, admin, create, true},
+		{"create pr: author", policy.CreatePR, author, create, true},
+		{"create pr: own lead", policy.CreatePR, lead, create, true},
+		{"create pr: other lead", policy.CreatePR, otherLead, create, false},
+		{"create pr: member", policy.CreatePR, member, create, false},
+		{"create pr: missing author", policy.CreatePR, member, policy.Target{}, false},
+
+		{"review: admin", policy.SubmitReview, admin, review, true},
+		{"review: reviewer", policy.SubmitReview, member, review, true},
+		{"review: implicit reviewer", policy.SubmitReview, author, pr, true},
+		{"review: someone else", policy.SubmitReview, author, review, false},
+		{"review: lead", policy.SubmitReview, lead, review, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.rule(context.Background(), store, tt.actor, tt.target)
+			if tt.allowed {
+				if err != nil {
+					t.Fatalf("err = %v, want allowed", err)
+				}
+				return
+			}
+
+			var derr *domain.Error
+			if !errors.As(err, &derr) || derr.Kind != domain.KindForbidden {
+				t.Fatalf("err = %v, want forbidden", err)
+			}
+		})
+	}
+}
+
+func TestRulesReportStorageErrors(t *testing.T) {
+	store := brokenQuerier{Querier: newPolicyStore(t)}
+
+	tests := []struct {
+		name   string
+		rule   policy.Rule
+		target policy.Target
+	}{
+		{"manage team", policy.ManageTeam, policy.Target{TeamName: "backend"}},
+		{"manage user", policy.ManageUser, policy.Target{UserID: member.UserID}},
+		{"reassign", policy.ReassignReviewer, policy.Target{PrID: "pr-1"}},
+		{"create pr", policy.CreatePR, policy.Target{AuthorID: author.UserID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.rule(context.Background(), store, otherLead, tt.target)
+
+			var derr *domain.Error
+			if !errors.As(err, &derr) || derr.Kind != domain.KindInternal {
+				t.Fatalf("err = %v, want a storage error", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package policy
+
+import "github.com/LlirikP/pr_dispenser/internal/auth"
+
+const (
+	RoleAdmin    = "admin"
+	RoleTeamLead = "team_lead"
+	RoleMember   = "member"
+
+	DefaultRole = RoleMember
+)
+
+func IsValidRole(role string) bool {
+	switch role {
+	case RoleAdmin, RoleTeamLead, RoleMember:
+		return true
+	}
+	return false
+}
+
+func ScopeForRole(role string) auth.Scope {
+	switch role {
+	case RoleAdmin:
+		return auth.ScopeAdmin
+	case RoleTeamLead:
+		return auth.ScopeTeamAdmin
+	default:
+		return auth.ScopeUser
+	}
+}
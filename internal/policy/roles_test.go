@@ -0,0 +1,31 @@
+package policy_test
+
+import (
+	"testing"
+
+	"github.com/LlirikP/pr_dispenser/internal/auth"
+	"github.com/LlirikP/pr_dispenser/internal/policy"
+)
+
+func TestRoles(t *testing.T) {
+	tests := []struct {
+		role  string
+		valid bool
+		scope auth.Scope
+	}{
+		{policy.RoleAdmin, true, auth.ScopeAdmin},
+		{policy.RoleTeamLead, true, auth.ScopeTeamAdmin},
+		{policy.RoleMember, true, auth.ScopeUser},
+		{"owner", false, auth.ScopeUser},
+		{"", false, auth.ScopeUser},
+	}
+
+	for _, tt := range tests {
+		if got := policy.IsValidRole(tt.role); got != tt.valid {
+			t.Fatalf("IsValidRole(%q) = %v, want %v", tt.role, got, tt.valid)
+		}
+		if got := policy.ScopeForRole(tt.role); got != tt.scope {
+			t.Fatalf("ScopeForRole(%q) = %q, want %q", tt.role, got, tt.scope)
+		}
+	}
+}
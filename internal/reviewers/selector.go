@@ -0,0 +1,41 @@
+package reviewers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/LlirikP/pr_dispenser/internal/database"
+)
+
+const (
+	StrategyRandom      = "random"
+	StrategyRoundRobin  = "round_robin"
+	StrategyLeastLoaded = "least_loaded"
+	StrategyWeighted    = "weighted"
+
+	DefaultStrategy = StrategyLeastLoaded
+)
+
+type ReviewerSelector interface {
+	Select(ctx context.Context, q database.Querier, team database.Team, candidates []string, count int) ([]string, error)
+}
+
+var selectors = map[string]ReviewerSelector{
+	StrategyRandom:      randomSelector{},
+	StrategyRoundRobin:  roundRobinSelector{},
+	StrategyLeastLoaded: leastLoadedSelector{},
+	StrategyWeighted:    weightedSelector{},
+}
+
+func IsValidStrategy(strategy string) bool {
+	_, ok := selectors[strategy]
+	return ok
+}
+
+func ForTeam(team database.Team) (ReviewerSelector, error) {
+	selector, ok := selectors[team.ReviewerStrategy]
+	if !ok {
+		return nil, fmt.Errorf("unknown reviewer strategy %q for team %s", team.ReviewerStrategy, team.Teamname)
+	}
+	return selector, nil
+}
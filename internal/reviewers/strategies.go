@@ -0,0 +1,121 @@
+package reviewers
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"sort"
+
+	"github.com/LlirikP/pr_dispenser/internal/database"
+)
+
+type randomSelector struct{}
+
+func (randomSelector) Select(_ context.Context, _ database.Querier, _ database.Team, candidates []string, count int) ([]string, error) {
+	pool := slices.Clone(candidates)
+	rand.Shuffle(len(pool), func(i, j int) {
+		pool[i], pool[j] = pool[j], pool[i]
+	})
+
+	return pool[:min(count, len(pool))], nil
+}
+
+type roundRobinSelector struct{}
+
+func (roundRobinSelector) Select(ctx context.Context, q database.Querier, team database.Team, candidates []string, count int) ([]string, error) {
+	if len(candidates) == 0 || count <= 0 {
+		return nil, nil
+	}
+
+	pool := slices.Clone(candidates)
+	slices.Sort(pool)
+
+	start := 0
+	if team.RrLastReviewerID.Valid {
+		start = sort.SearchStrings(pool, team.RrLastReviewerID.String)
+		if start < len(pool) && pool[start] == team.RrLastReviewerID.String {
+			start++
+		}
+	}
+
+	count = min(count, len(pool))
+	picked := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		picked = append(picked, pool[(start+i)%len(pool)])
+	}
+
+	err := q.SetTeamRoundRobinCursor(ctx, database.SetTeamRoundRobinCursorParams{
+		ID:               team.ID,
+		RrLastReviewerID: sql.NullString{String: picked[len(picked)-1], Valid: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return picked, nil
+}
+
+type leastLoadedSelector struct{}
+
+func (leastLoadedSelector) Select(ctx context.Context, q database.Querier, _ database.Team, candidates []string, count int) ([]string, error) {
+	rows, err := q.CountOpenReviews(ctx, candidates)
+	if err != nil {
+		return nil, err
+	}
+
+	load := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		load[row.ReviewerID] = row.OpenReviews
+	}
+
+	pool := slices.Clone(candidates)
+	rand.Shuffle(len(pool), func(i, j int) {
+		pool[i], pool[j] = pool[j], pool[i]
+	})
+
+	slices.SortStableFunc(pool, func(a, b string) int {
+		return cmp.Compare(load[a], load[b])
+	})
+
+	return pool[:min(count, len(pool))], nil
+}
+
+type weightedSelector struct{}
+
+func (weightedSelector) Select(ctx context.Context, q database.Querier, _ database.Team, candidates []string, count int) ([]string, error) {
+	rows, err := q.GetUserWeights(ctx, candidates)
+	if err != nil {
+		return nil, err
+	}
+
+	type keyed struct {
+		id  string
+		key float64
+	}
+
+	pool := make([]keyed, 0, len(rows))
+	for _, row := range rows {
+		if row.ReviewWeight <= 0 {
+			continue
+		}
+		// Efraimidis-Spirakis: sampling without replacement proportionally to weight.
+		pool = append(pool, keyed{
+			id:  row.ID,
+			key: math.Pow(rand.Float64(), 1/float64(row.ReviewWeight)),
+		})
+	}
+
+	slices.SortFunc(pool, func(a, b keyed) int {
+		return cmp.Compare(b.key, a.key)
+	})
+
+	picked := make([]string, 0, min(count, len(pool)))
+	for i := 0; i < count && i < len(pool); i++ {
+		picked = append(picked, pool[i].id)
+	}
+
+	return picked, nil
+}
@@ -0,0 +1,202 @@
+package reviewers_test
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/LlirikP/pr_dispenser/internal/database"
+	"github.com/LlirikP/pr_dispenser/internal/database/memory"
+	"github.com/LlirikP/pr_dispenser/internal/reviewers"
+)
+
+// newStore seeds one team using strategy with a member per weight, named u1,
+// u2 and so on.
+func newStore(t *testing.T, strategy string, weights ...int32) *memory.Store {
+	t.Helper()
+
+	ctx := context.Background()
+	store := memory.New()
+
+	err := store.CreateTeam(ctx, database.CreateTeamParams{
+		ID:                "backend",
+		Teamname:          "backend",
+		ReviewerStrategy:  strategy,
+		MaxOpenReviews:    10,
+		ReviewersRequired: 2,
+		ApprovalsRequired: 1,
+	})
+	if err != nil {
+		t.Fatalf("create team: %v", err)
+	}
+
+	for i, weight := range weights {
+		err := store.UpsertUser(ctx, database.UpsertUserParams{
+			ID:           fmt.Sprintf("u%d", i+1),
+			Username:     fmt.Sprintf("user%d", i+1),
+			IsAvailable:  true,
+			TeamID:       "backend",
+			ReviewWeight: weight,
+			Role:         "member",
+		})
+		if err != nil {
+			t.Fatalf("upsert user: %v", err)
+		}
+	}
+
+	return store
+}
+
+// selectFor loads the team afresh, as the domain code does before every
+// assignment, and runs its selector.
+func selectFor(t *testing.T, store *memory.Store, candidates []string, count int) []string {
+	t.Helper()
+
+	ctx := context.Background()
+	team, err := store.GetTeamByID(ctx, "backend")
+	if err != nil {
+		t.Fatalf("get team: %v", err)
+	}
+
+	selector, err := reviewers.ForTeam(team)
+	if err != nil {
+		t.Fatalf("selector: %v", err)
+	}
+
+	picked, err := selector.Select(ctx, store, team, candidates, count)
+	if err != nil {
+		t.Fatalf("select: %v", err)
+	}
+	return picked
+}
+
+func TestRoundRobinWrapsAround(t *testing.T) {
+	store := newStore(t, reviewers.StrategyRoundRobin, 1, 1, 1, 1)
+	candidates := []string{"u4", "u2", "u3"}
+
+	steps := []struct {
+		count int
+		want  []string
+	}{
+		{2, []string{"u2", "u3"}},
+		{2, []string{"u4", "u2"}},
+		{1, []string{"u3"}},
+		{5, []string{"u4", "u2", "u3"}},
+		{0, nil},
+		{1, []string{"u4"}},
+	}
+
+	for i, step := range steps {
+		if got := selectFor(t, store, candidates, step.count); !slices.Equal(got, step.want) {
+			t.Fatalf("step %d: picked %v, want %v", i, got, step.want)
+		}
+	}
+
+	// The cursor points at u4; once u4 is no longer a candidate the next
+	// reviewer in order is picked, wrapping to the start.
+	if got := selectFor(t, store, []string{"u2", "u3"}, 1); !slices.Equal(got, []string{"u2"}) {
+		t.Fatalf("picked %v after the cursor left, want [u2]", got)
+	}
+
+	team, err := store.GetTeamByID(context.Background(), "backend")
+	if err != nil {
+		t.Fatalf("get team: %v", err)
+	}
+	if team.RrLastReviewerID.String != "u2" {
+		t.Fatalf("cursor = %q, want u2", team.RrLastReviewerID.String)
+	}
+}
+
+func TestLeastLoadedPrefersIdleReviewers(t *testing.T) {
+	ctx := context.Background()
+	store := newStore(t, reviewers.StrategyLeastLoaded, 1, 1, 1, 1)
+
+	// u2 reviews two open PRs, u3 one, u4 none.
+	for i, reviewerIDs := range [][]string{{"u2", "u3"}, {"u2"}} {
+		prID := fmt.Sprintf("pr-%d", i)
+		if err := store.CreatePR(ctx, database.CreatePRParams{ID: prID, Title: prID, AuthorID: "u1", ReviewersRequired: 2}); err != nil {
+			t.Fatalf("create pr: %v", err)
+		}
+		for _, id := range reviewerIDs {
+			if err := store.AddReviewer(ctx, database.AddReviewerParams{PrID: prID, ReviewerID: id}); err != nil {
+				t.Fatalf("add reviewer: %v", err)
+			}
+		}
+	}
+
+	candidates := []string{"u2", "u3", "u4"}
+	if got := selectFor(t, store, candidates, 3); !slices.Equal(got, []string{"u4", "u3", "u2"}) {
+		t.Fatalf("picked %v, want [u4 u3 u2]", got)
+	}
+	if got := selectFor(t, store, candidates, 2); !slices.Equal(got, []string{"u4", "u3"}) {
+		t.Fatalf("picked %v, want [u4 u3]", got)
+	}
+
+	// u3 and u4 tie at one review each: both must win some of the time.
+	if err := store.AddReviewer(ctx, database.AddReviewerParams{PrID: "pr-1", ReviewerID: "u4"}); err != nil {
+		t.Fatalf("add reviewer: %v", err)
+	}
+
+	first := map[string]int{}
+	for i := 0; i < 200; i++ {
+		got := selectFor(t, store, candidates, 2)
+		if len(got) != 2 || got[0] == got[1] || slices.Contains(got, "u2") {
+			t.Fatalf("picked %v, want u3 and u4", got)
+		}
+		first[got[0]]++
+	}
+	if first["u3"] == 0 || first["u4"] == 0 {
+		t.Fatalf("tie always broken the same way: %v", first)
+	}
+}
+
+func TestWeightedSkipsZeroWeight(t *testing.T) {
+	store := newStore(t, reviewers.StrategyWeighted, 1, 0, 1, 3)
+	candidates := []string{"u2", "u3", "u4"}
+
+	first := map[string]int{}
+	for i := 0; i < 2000; i++ {
+		got := selectFor(t, store, candidates, 3)
+		if len(got) != 2 || slices.Contains(got, "u2") {
+			t.Fatalf("picked %v, want u3 and u4 only", got)
+		}
+		first[got[0]]++
+	}
+
+	// u4 weighs three times as much as u3, so it should come first about
+	// three times in four.
+	if first["u4"] < 1300 || first["u4"] > 1700 {
+		t.Fatalf("u4 picked first %d of 2000 times, want about 1500", first["u4"])
+	}
+
+	if got := selectFor(t, store, []string{"u2"}, 1); len(got) != 0 {
+		t.Fatalf("picked %v from zero-weight candidates only", got)
+	}
+}
+
+func TestRandomPicksDistinctCandidates(t *testing.T) {
+	store := newStore(t, reviewers.StrategyRandom, 1, 1, 1, 1)
+	candidates := []string{"u2", "u3", "u4"}
+
+	seen := map[string]bool{}
+	for i := 0; i < 100; i++ {
+		got := selectFor(t, store, candidates, 2)
+		if len(got) != 2 || got[0] == got[1] {
+			t.Fatalf("picked %v, want two distinct reviewers", got)
+		}
+		for _, id := range got {
+			if !slices.Contains(candidates, id) {
+				t.Fatalf("picked %s, not a candidate", id)
+			}
+			seen[id] = true
+		}
+	}
+	if len(seen) != len(candidates) {
+		t.Fatalf("only %v were ever picked", seen)
+	}
+
+	if got := selectFor(t, store, candidates, 5); len(got) != 3 {
+		t.Fatalf("picked %v, want all three candidates", got)
+	}
+}
@@ -0,0 +1,9 @@
+package sql
+
+import "embed"
+
+//go:embed schema/*.sql
+var Postgres embed.FS
+
+//go:embed sqlite/schema/*.sql
+var SQLite embed.FS
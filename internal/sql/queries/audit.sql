@@ -0,0 +1,17 @@
+-- name: InsertAuditEntry :exec
+INSERT INTO audit_log (actor, action, target_type, target_id, before, after, request_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7);
+
+-- name: ListAuditEntries :many
+SELECT id, actor, action, target_type, target_id, before, after, request_id, created_at
+FROM audit_log
+WHERE (sqlc.narg(actor)::text IS NULL OR actor = sqlc.narg(actor))
+  AND (sqlc.narg(action)::text IS NULL OR action = sqlc.narg(action))
+  AND (sqlc.narg(target_type)::text IS NULL OR target_type = sqlc.narg(target_type))
+  AND (sqlc.narg(target_id)::text IS NULL OR target_id = sqlc.narg(target_id))
+  AND (sqlc.narg(request_id)::text IS NULL OR request_id = sqlc.narg(request_id))
+  AND (sqlc.narg(since)::timestamptz IS NULL OR created_at >= sqlc.narg(since))
+  AND (sqlc.narg(until)::timestamptz IS NULL OR created_at < sqlc.narg(until))
+  AND (sqlc.narg(before_id)::bigint IS NULL OR id < sqlc.narg(before_id))
+ORDER BY id DESC
+LIMIT sqlc.arg(max_rows);
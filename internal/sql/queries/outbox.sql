@@ -0,0 +1,72 @@
+-- name: InsertOutboxEvent :exec
+INSERT INTO outbox (event_id, event_type, pr_id, user_ids, payload)
+VALUES ($1, $2, $3, $4, $5);
+
+-- name: ClaimNextOutboxEvent :one
+SELECT *
+FROM outbox o
+WHERE o.status = 'PENDING'
+  AND o.next_attempt_at <= NOW()
+  AND NOT EXISTS (
+      SELECT 1
+      FROM outbox prev
+      WHERE prev.pr_id = o.pr_id
+        AND prev.status = 'PENDING'
+        AND prev.id < o.id
+  )
+ORDER BY o.id
+LIMIT 1
+FOR UPDATE SKIP LOCKED;
+
+-- name: MarkOutboxDispatched :exec
+UPDATE outbox
+SET status = 'DISPATCHED',
+    attempts = attempts + 1,
+    last_error = NULL,
+    dispatched_at = NOW()
+WHERE id = $1;
+
+-- name: MarkOutboxAttemptFailed :exec
+UPDATE outbox
+SET status = sqlc.arg(status),
+    attempts = attempts + 1,
+    last_error = sqlc.arg(last_error),
+    next_attempt_at = sqlc.arg(next_attempt_at)
+WHERE id = sqlc.arg(id);
+
+-- name: LockOutboxSequencer :exec
+SELECT pg_advisory_xact_lock(hashtext('outbox_stream_seq'));
+
+-- name: SequenceOutboxEvents :execrows
+WITH base AS (
+    SELECT COALESCE(MAX(stream_seq), 0)::bigint AS seq
+    FROM outbox
+),
+pending AS (
+    SELECT id, ROW_NUMBER() OVER (ORDER BY id) AS rn
+    FROM outbox
+    WHERE stream_seq IS NULL
+)
+UPDATE outbox o
+SET stream_seq = base.seq + pending.rn
+FROM base, pending
+WHERE o.id = pending.id;
+
+-- name: GetLatestStreamSeq :one
+SELECT COALESCE(MAX(stream_seq), 0)::bigint
+FROM outbox;
+
+-- name: GetStreamEventsAfter :many
+SELECT o.stream_seq::bigint AS seq, o.event_type, o.payload
+FROM outbox o
+WHERE o.stream_seq > sqlc.arg(after_seq)::bigint
+  AND (sqlc.narg(pr_id)::text IS NULL OR o.pr_id = sqlc.narg(pr_id))
+  AND (sqlc.narg(user_id)::text IS NULL OR sqlc.narg(user_id)::text = ANY(o.user_ids))
+  AND (sqlc.narg(team_id)::text IS NULL OR EXISTS (
+      SELECT 1
+      FROM users u
+      WHERE u.id = ANY(o.user_ids)
+        AND u.team_id = sqlc.narg(team_id)
+  ))
+ORDER BY o.stream_seq
+LIMIT sqlc.arg(batch_size);
@@ -1,12 +1,18 @@
 -- name: CreatePR :exec
-INSERT INTO prs (id, title, author_id, status)
-VALUES ($1, $2, $3, 'OPEN');
+INSERT INTO prs (id, title, author_id, status, reviewers_required, is_draft)
+VALUES ($1, $2, $3, 'OPEN', $4, $5);
 
 -- name: GetPRById :one
-SELECT id, title, author_id, status, created_at, merged_at
+SELECT id, title, author_id, status, created_at, merged_at, reviewers_required, force_merged, force_merged_by, closed_at, is_draft
 FROM prs
 WHERE id = $1;
 
+-- name: GetPRsByIDs :many
+SELECT id, title, author_id, status, created_at, merged_at, reviewers_required, force_merged, force_merged_by, closed_at, is_draft
+FROM prs
+WHERE id = ANY(sqlc.arg(ids)::text[])
+ORDER BY id;
+
 -- name: AddReviewer :exec
 INSERT INTO pr_reviewers (pr_id, reviewer_id)
 VALUES ($1, $2)
@@ -21,12 +27,31 @@ SELECT reviewer_id
 FROM pr_reviewers
 WHERE pr_id = $1;
 
--- name: GetActiveTeamMembersExceptAuthor :many
-SELECT id
-FROM users
-WHERE team_id = $1
-  AND is_active = TRUE
-  AND id <> $2;
+-- name: GetAvailableTeamMembersExceptAuthor :many
+SELECT u.id
+FROM users u
+JOIN teams t ON t.id = u.team_id
+JOIN user_workload w ON w.user_id = u.id
+WHERE u.team_id = $1
+  AND u.is_available = TRUE
+  AND u.id <> $2
+  AND w.open_reviews < COALESCE(u.max_open_reviews, t.max_open_reviews);
+
+-- name: GetTeamCandidates :many
+SELECT u.id, u.review_weight, w.open_reviews,
+       COALESCE(u.max_open_reviews, t.max_open_reviews)::bigint AS capacity
+FROM users u
+JOIN teams t ON t.id = u.team_id
+JOIN user_workload w ON w.user_id = u.id
+WHERE u.team_id = $1
+  AND u.is_available = TRUE
+ORDER BY u.id;
+
+-- name: GetReviewersByPRs :many
+SELECT pr_id, reviewer_id
+FROM pr_reviewers
+WHERE pr_id = ANY(sqlc.arg(pr_ids)::text[])
+ORDER BY pr_id, reviewer_id;
 
 -- name: IsReviewerAssigned :one
 SELECT COUNT(*) > 0 AS assigned
@@ -36,7 +61,26 @@ WHERE pr_id = $1 AND reviewer_id = $2;
 -- name: MergePR :exec
 UPDATE prs
 SET status = 'MERGED',
-    merged_at = NOW()
+    merged_at = NOW(),
+    force_merged = $2,
+    force_merged_by = $3
+WHERE id = $1;
+
+-- name: ClosePR :exec
+UPDATE prs
+SET status = 'CLOSED',
+    closed_at = NOW()
+WHERE id = $1;
+
+-- name: ReopenPR :exec
+UPDATE prs
+SET status = 'OPEN',
+    closed_at = NULL
+WHERE id = $1;
+
+-- name: MarkPRReady :exec
+UPDATE prs
+SET is_draft = FALSE
 WHERE id = $1;
 
 -- name: CheckDuplicatePR :one
@@ -46,3 +90,41 @@ WHERE author_id = $1
   AND title = $2
   AND status = 'OPEN'
 LIMIT 1;
+
+-- name: CountOpenReviews :many
+SELECT r.reviewer_id, COUNT(*) AS open_reviews
+FROM pr_reviewers r
+JOIN prs ON prs.id = r.pr_id
+WHERE prs.status = 'OPEN'
+  AND r.reviewer_id = ANY(sqlc.arg(reviewer_ids)::text[])
+GROUP BY r.reviewer_id;
+
+-- name: GetOpenReviewsByReviewers :many
+SELECT r.pr_id, r.reviewer_id, prs.author_id
+FROM pr_reviewers r
+JOIN prs ON prs.id = r.pr_id
+WHERE prs.status = 'OPEN'
+  AND r.reviewer_id = ANY(sqlc.arg(reviewer_ids)::text[])
+ORDER BY r.pr_id, r.reviewer_id;
+
+-- name: GetReviewsByPR :many
+SELECT reviewer_id, state, assigned_at, reviewed_at
+FROM pr_reviewers
+WHERE pr_id = $1
+ORDER BY assigned_at, reviewer_id;
+
+-- name: SetReviewState :execrows
+UPDATE pr_reviewers
+SET state = $3,
+    reviewed_at = NOW()
+WHERE pr_id = $1 AND reviewer_id = $2;
+
+-- name: GetIneligibleReviewersByPR :many
+SELECT u.id, u.team_id
+FROM pr_reviewers r
+JOIN users u ON u.id = r.reviewer_id
+JOIN teams t ON t.id = u.team_id
+JOIN user_workload w ON w.user_id = u.id
+WHERE r.pr_id = $1
+  AND (u.is_available = FALSE OR w.open_reviews > COALESCE(u.max_open_reviews, t.max_open_reviews))
+ORDER BY u.id;
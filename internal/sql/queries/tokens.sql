@@ -0,0 +1,30 @@
+-- name: CreateAPIToken :exec
+INSERT INTO api_tokens (id, name, token_hash, scope, user_id)
+VALUES ($1, $2, $3, $4, $5);
+
+-- name: GetAPITokenByHash :one
+SELECT id, name, token_hash, scope, user_id, created_at, revoked_at
+FROM api_tokens
+WHERE token_hash = $1
+  AND revoked_at IS NULL;
+
+-- name: FindAPITokenByHash :one
+SELECT id, name, token_hash, scope, user_id, created_at, revoked_at
+FROM api_tokens
+WHERE token_hash = $1;
+
+-- name: GetAPITokenByID :one
+SELECT id, name, token_hash, scope, user_id, created_at, revoked_at
+FROM api_tokens
+WHERE id = $1;
+
+-- name: ListAPITokens :many
+SELECT id, name, token_hash, scope, user_id, created_at, revoked_at
+FROM api_tokens
+ORDER BY created_at, id;
+
+-- name: RevokeAPIToken :execrows
+UPDATE api_tokens
+SET revoked_at = NOW()
+WHERE id = $1
+  AND revoked_at IS NULL;
@@ -1,11 +1,41 @@
 -- name: GetUserById :one
-SELECT id, username, is_active, team_id
+SELECT id, username, is_available, team_id, review_weight, max_open_reviews, github_login, gitlab_username, role
 FROM users
 WHERE id = $1;
 
--- name: SetUserIsActive :exec
+-- name: GetUserByGithubLogin :one
+SELECT id, username, is_available, team_id, review_weight, max_open_reviews, github_login, gitlab_username, role
+FROM users
+WHERE github_login = $1;
+
+-- name: GetUserByGitlabUsername :one
+SELECT id, username, is_available, team_id, review_weight, max_open_reviews, github_login, gitlab_username, role
+FROM users
+WHERE gitlab_username = $1;
+
+-- name: GetUserOpenReviews :one
+SELECT open_reviews
+FROM user_workload
+WHERE user_id = $1;
+
+-- name: GetUserWeights :many
+SELECT id, review_weight
+FROM users
+WHERE id = ANY(sqlc.arg(ids)::text[]);
+
+-- name: SetUserAvailability :exec
 UPDATE users
-SET is_active = $2
+SET is_available = $2
+WHERE id = $1;
+
+-- name: SetUserMaxOpenReviews :exec
+UPDATE users
+SET max_open_reviews = $2
+WHERE id = $1;
+
+-- name: SetUserRole :exec
+UPDATE users
+SET role = $2
 WHERE id = $1;
 
 -- name: GetReviewPRs :many
@@ -13,8 +43,15 @@ SELECT
     prs.id AS pr_id,
     prs.title AS pr_title,
     prs.author_id,
-    prs.status
+    prs.status,
+    r.state AS review_state,
+    r.reviewed_at
 FROM pr_reviewers r
 JOIN prs ON prs.id = r.pr_id
 WHERE r.reviewer_id = $1
 ORDER BY prs.id;
+
+-- name: SetUsersAvailability :exec
+UPDATE users
+SET is_available = sqlc.arg(is_available)
+WHERE id = ANY(sqlc.arg(ids)::text[]);
@@ -0,0 +1,92 @@
+-- name: CreateWebhookSubscription :exec
+INSERT INTO webhook_subscriptions (id, url, events, secret)
+VALUES ($1, $2, $3, $4);
+
+-- name: ListWebhookSubscriptions :many
+SELECT id, url, events, secret, created_at
+FROM webhook_subscriptions
+ORDER BY created_at, id;
+
+-- name: GetWebhookSubscriptionByID :one
+SELECT id, url, events, secret, created_at
+FROM webhook_subscriptions
+WHERE id = $1;
+
+-- name: DeleteWebhookSubscription :execrows
+DELETE FROM webhook_subscriptions
+WHERE id = $1;
+
+-- name: GetSubscriptionsForEvent :many
+SELECT id, url, events, secret, created_at
+FROM webhook_subscriptions
+WHERE cardinality(events) = 0
+   OR sqlc.arg(event_type)::text = ANY(events);
+
+-- name: CreateWebhookDelivery :exec
+INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, pr_id, payload)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (subscription_id, event_id) DO NOTHING;
+
+-- name: ClaimDueWebhookDeliveries :many
+UPDATE webhook_deliveries d
+SET next_attempt_at = sqlc.arg(lease_until)
+FROM webhook_subscriptions s
+WHERE s.id = d.subscription_id
+  AND d.id IN (
+      SELECT w.id
+      FROM webhook_deliveries w
+      WHERE w.status = 'PENDING'
+        AND w.next_attempt_at <= NOW()
+        AND NOT EXISTS (
+            SELECT 1
+            FROM webhook_deliveries prev
+            WHERE prev.subscription_id = w.subscription_id
+              AND prev.pr_id = w.pr_id
+              AND prev.status = 'PENDING'
+              AND prev.id < w.id
+        )
+      ORDER BY w.id
+      LIMIT sqlc.arg(batch_size)
+      FOR UPDATE SKIP LOCKED
+  )
+RETURNING d.id, d.event_id, d.event_type, d.pr_id, d.payload, d.attempts, s.url, s.secret;
+
+-- name: MarkWebhookDelivered :exec
+UPDATE webhook_deliveries
+SET status = 'DELIVERED',
+    attempts = attempts + 1,
+    response_status = $2,
+    last_error = NULL,
+    delivered_at = NOW()
+WHERE id = $1;
+
+-- name: MarkWebhookAttemptFailed :exec
+UPDATE webhook_deliveries
+SET status = sqlc.arg(status),
+    attempts = attempts + 1,
+    response_status = sqlc.arg(response_status),
+    last_error = sqlc.arg(last_error),
+    next_attempt_at = sqlc.arg(next_attempt_at)
+WHERE id = sqlc.arg(id);
+
+-- name: ListWebhookDeliveries :many
+SELECT id, subscription_id, event_id, event_type, pr_id, payload, status, attempts, response_status, last_error, next_attempt_at, created_at, delivered_at
+FROM webhook_deliveries
+WHERE (sqlc.narg(subscription_id)::text IS NULL OR subscription_id = sqlc.narg(subscription_id))
+  AND (sqlc.narg(status)::text IS NULL OR status = sqlc.narg(status))
+ORDER BY id DESC
+LIMIT $1;
+
+-- name: GetWebhookDeliveryByID :one
+SELECT id, subscription_id, event_id, event_type, payload, status, attempts, response_status, last_error, next_attempt_at, created_at, delivered_at, pr_id
+FROM webhook_deliveries
+WHERE id = $1;
+
+-- name: RequeueWebhookDelivery :execrows
+UPDATE webhook_deliveries
+SET status = 'PENDING',
+    attempts = 0,
+    last_error = NULL,
+    next_attempt_at = NOW()
+WHERE id = $1
+  AND status = 'DEAD';
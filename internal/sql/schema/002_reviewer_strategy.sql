@@ -0,0 +1,18 @@
+-- +goose Up
+
+ALTER TABLE teams
+    ADD COLUMN reviewer_strategy TEXT NOT NULL DEFAULT 'random'
+        CHECK (reviewer_strategy IN ('random', 'round_robin', 'least_loaded', 'weighted')),
+    ADD COLUMN rr_last_reviewer_id TEXT NULL;
+
+ALTER TABLE users
+    ADD COLUMN review_weight INTEGER NOT NULL DEFAULT 1 CHECK (review_weight >= 0);
+
+-- +goose Down
+
+ALTER TABLE users
+    DROP COLUMN review_weight;
+
+ALTER TABLE teams
+    DROP COLUMN rr_last_reviewer_id,
+    DROP COLUMN reviewer_strategy;
@@ -0,0 +1,16 @@
+-- +goose Up
+
+ALTER TABLE teams
+    ALTER COLUMN reviewer_strategy SET DEFAULT 'least_loaded';
+
+-- Teams created before this migration got 'random' from the old default.
+UPDATE teams SET reviewer_strategy = 'least_loaded' WHERE reviewer_strategy = 'random';
+
+-- +goose Down
+
+-- Teams cannot be told apart from ones set to least_loaded explicitly, so
+-- all of them go back to the old default.
+UPDATE teams SET reviewer_strategy = 'random' WHERE reviewer_strategy = 'least_loaded';
+
+ALTER TABLE teams
+    ALTER COLUMN reviewer_strategy SET DEFAULT 'random';
@@ -0,0 +1,32 @@
+-- +goose Up
+
+-- is_active used to mean both "takes part in review" and "busy with an open PR".
+-- Availability is now set only by the user/admin, busyness is derived from open reviews.
+ALTER TABLE users
+    RENAME COLUMN is_active TO is_available;
+
+UPDATE users u
+SET is_available = TRUE
+WHERE u.is_available = FALSE
+  AND EXISTS (
+      SELECT 1
+      FROM pr_reviewers r
+      JOIN prs p ON p.id = r.pr_id
+      WHERE r.reviewer_id = u.id
+        AND p.status = 'OPEN'
+  );
+
+CREATE VIEW user_workload AS
+SELECT u.id AS user_id,
+       COUNT(p.id) AS open_reviews
+FROM users u
+LEFT JOIN pr_reviewers r ON r.reviewer_id = u.id
+LEFT JOIN prs p ON p.id = r.pr_id AND p.status = 'OPEN'
+GROUP BY u.id;
+
+-- +goose Down
+
+DROP VIEW user_workload;
+
+ALTER TABLE users
+    RENAME COLUMN is_available TO is_active;
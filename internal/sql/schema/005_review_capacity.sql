@@ -0,0 +1,15 @@
+-- +goose Up
+
+ALTER TABLE teams
+    ADD COLUMN max_open_reviews INTEGER NOT NULL DEFAULT 1 CHECK (max_open_reviews > 0);
+
+ALTER TABLE users
+    ADD COLUMN max_open_reviews INTEGER NULL CHECK (max_open_reviews > 0);
+
+-- +goose Down
+
+ALTER TABLE users
+    DROP COLUMN max_open_reviews;
+
+ALTER TABLE teams
+    DROP COLUMN max_open_reviews;
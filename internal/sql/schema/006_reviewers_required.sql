@@ -0,0 +1,15 @@
+-- +goose Up
+
+ALTER TABLE teams
+    ADD COLUMN reviewers_required INTEGER NOT NULL DEFAULT 2 CHECK (reviewers_required >= 0);
+
+ALTER TABLE prs
+    ADD COLUMN reviewers_required INTEGER NOT NULL DEFAULT 2 CHECK (reviewers_required >= 0);
+
+-- +goose Down
+
+ALTER TABLE prs
+    DROP COLUMN reviewers_required;
+
+ALTER TABLE teams
+    DROP COLUMN reviewers_required;
@@ -0,0 +1,13 @@
+-- +goose Up
+
+CREATE TABLE team_fallbacks (
+    team_id TEXT NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    fallback_team_id TEXT NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    priority INTEGER NOT NULL,
+    PRIMARY KEY (team_id, fallback_team_id),
+    CHECK (team_id <> fallback_team_id)
+);
+
+-- +goose Down
+
+DROP TABLE team_fallbacks;
@@ -0,0 +1,17 @@
+-- +goose Up
+
+ALTER TABLE teams
+    ADD COLUMN approvals_required INTEGER NOT NULL DEFAULT 1 CHECK (approvals_required >= 0);
+
+ALTER TABLE prs
+    ADD COLUMN force_merged BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN force_merged_by TEXT NULL;
+
+-- +goose Down
+
+ALTER TABLE prs
+    DROP COLUMN force_merged_by,
+    DROP COLUMN force_merged;
+
+ALTER TABLE teams
+    DROP COLUMN approvals_required;
@@ -0,0 +1,17 @@
+-- +goose Up
+
+ALTER TABLE prs
+    DROP CONSTRAINT prs_status_check,
+    ADD CONSTRAINT prs_status_check CHECK (status IN ('OPEN', 'MERGED', 'CLOSED')),
+    ADD COLUMN closed_at TIMESTAMPTZ NULL;
+
+-- +goose Down
+
+UPDATE prs
+SET status = 'OPEN'
+WHERE status = 'CLOSED';
+
+ALTER TABLE prs
+    DROP COLUMN closed_at,
+    DROP CONSTRAINT prs_status_check,
+    ADD CONSTRAINT prs_status_check CHECK (status IN ('OPEN', 'MERGED'));
@@ -0,0 +1,9 @@
+-- +goose Up
+
+ALTER TABLE prs
+    ADD COLUMN is_draft BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +goose Down
+
+ALTER TABLE prs
+    DROP COLUMN is_draft;
@@ -0,0 +1,9 @@
+-- +goose Up
+
+ALTER TABLE users
+    ADD COLUMN github_login TEXT NULL UNIQUE;
+
+-- +goose Down
+
+ALTER TABLE users
+    DROP COLUMN github_login;
@@ -0,0 +1,9 @@
+-- +goose Up
+
+ALTER TABLE users
+    ADD COLUMN gitlab_username TEXT NULL UNIQUE;
+
+-- +goose Down
+
+ALTER TABLE users
+    DROP COLUMN gitlab_username;
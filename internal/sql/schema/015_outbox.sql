@@ -0,0 +1,51 @@
+-- +goose Up
+
+CREATE TABLE outbox (
+    id BIGSERIAL PRIMARY KEY,
+    event_id TEXT NOT NULL UNIQUE,
+    event_type TEXT NOT NULL,
+    pr_id TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    status TEXT NOT NULL DEFAULT 'PENDING' CHECK (status IN ('PENDING', 'DISPATCHED', 'DEAD')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NULL,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    dispatched_at TIMESTAMPTZ NULL
+);
+
+CREATE INDEX outbox_pending_idx
+    ON outbox (pr_id, id)
+    WHERE status = 'PENDING';
+
+UPDATE webhook_deliveries
+SET status = 'DEAD'
+WHERE status = 'FAILED';
+
+ALTER TABLE webhook_deliveries
+    DROP CONSTRAINT webhook_deliveries_status_check,
+    ADD CONSTRAINT webhook_deliveries_status_check CHECK (status IN ('PENDING', 'DELIVERED', 'DEAD')),
+    ADD COLUMN pr_id TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX webhook_deliveries_ordering_idx
+    ON webhook_deliveries (subscription_id, pr_id, id)
+    WHERE status = 'PENDING';
+
+CREATE UNIQUE INDEX webhook_deliveries_event_idx
+    ON webhook_deliveries (subscription_id, event_id);
+
+-- +goose Down
+
+DROP INDEX webhook_deliveries_event_idx;
+DROP INDEX webhook_deliveries_ordering_idx;
+
+UPDATE webhook_deliveries
+SET status = 'FAILED'
+WHERE status = 'DEAD';
+
+ALTER TABLE webhook_deliveries
+    DROP COLUMN pr_id,
+    DROP CONSTRAINT webhook_deliveries_status_check,
+    ADD CONSTRAINT webhook_deliveries_status_check CHECK (status IN ('PENDING', 'DELIVERED', 'FAILED'));
+
+DROP TABLE outbox;
@@ -0,0 +1,21 @@
+-- +goose Up
+
+ALTER TABLE outbox
+    ADD COLUMN user_ids TEXT[] NOT NULL DEFAULT '{}',
+    ADD COLUMN stream_seq BIGINT NULL;
+
+CREATE UNIQUE INDEX outbox_stream_seq_idx
+    ON outbox (stream_seq);
+
+CREATE INDEX outbox_unsequenced_idx
+    ON outbox (id)
+    WHERE stream_seq IS NULL;
+
+-- +goose Down
+
+DROP INDEX outbox_unsequenced_idx;
+DROP INDEX outbox_stream_seq_idx;
+
+ALTER TABLE outbox
+    DROP COLUMN stream_seq,
+    DROP COLUMN user_ids;
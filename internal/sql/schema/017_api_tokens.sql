@@ -0,0 +1,15 @@
+-- +goose Up
+
+CREATE TABLE api_tokens (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    token_hash TEXT NOT NULL UNIQUE,
+    scope TEXT NOT NULL CHECK (scope IN ('admin', 'team-admin', 'user', 'webhook')),
+    user_id TEXT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    revoked_at TIMESTAMPTZ NULL
+);
+
+-- +goose Down
+
+DROP TABLE api_tokens;
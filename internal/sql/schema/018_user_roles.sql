@@ -0,0 +1,9 @@
+-- +goose Up
+
+ALTER TABLE users
+ADD COLUMN role TEXT NOT NULL DEFAULT 'member' CHECK (role IN ('admin', 'team_lead', 'member'));
+
+-- +goose Down
+
+ALTER TABLE users
+DROP COLUMN role;
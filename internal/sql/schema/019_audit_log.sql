@@ -0,0 +1,33 @@
+-- +goose Up
+
+CREATE TABLE audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    actor TEXT NOT NULL,
+    action TEXT NOT NULL,
+    target_type TEXT NOT NULL,
+    target_id TEXT NOT NULL,
+    before JSONB NOT NULL DEFAULT 'null',
+    after JSONB NOT NULL DEFAULT 'null',
+    request_id TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX audit_log_target_idx ON audit_log (target_type, target_id, id);
+CREATE INDEX audit_log_actor_idx ON audit_log (actor, id);
+
+-- +goose StatementBegin
+CREATE FUNCTION audit_log_immutable() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER audit_log_immutable
+BEFORE UPDATE OR DELETE ON audit_log
+FOR EACH ROW EXECUTE FUNCTION audit_log_immutable();
+
+-- +goose Down
+
+DROP TABLE audit_log;
+DROP FUNCTION audit_log_immutable();
@@ -0,0 +1,17 @@
+-- name: InsertAuditEntry :exec
+INSERT INTO audit_log (actor, action, target_type, target_id, before, after, request_id)
+VALUES (?, ?, ?, ?, ?, ?, ?);
+
+-- name: ListAuditEntries :many
+SELECT id, actor, action, target_type, target_id, before, after, request_id, created_at
+FROM audit_log
+WHERE (CAST(sqlc.narg(actor) AS TEXT) IS NULL OR actor = CAST(sqlc.narg(actor) AS TEXT))
+  AND (CAST(sqlc.narg(action) AS TEXT) IS NULL OR action = CAST(sqlc.narg(action) AS TEXT))
+  AND (CAST(sqlc.narg(target_type) AS TEXT) IS NULL OR target_type = CAST(sqlc.narg(target_type) AS TEXT))
+  AND (CAST(sqlc.narg(target_id) AS TEXT) IS NULL OR target_id = CAST(sqlc.narg(target_id) AS TEXT))
+  AND (CAST(sqlc.narg(request_id) AS TEXT) IS NULL OR request_id = CAST(sqlc.narg(request_id) AS TEXT))
+  AND (CAST(sqlc.narg(since) AS TEXT) IS NULL OR created_at >= CAST(sqlc.narg(since) AS TEXT))
+  AND (CAST(sqlc.narg(until) AS TEXT) IS NULL OR created_at < CAST(sqlc.narg(until) AS TEXT))
+  AND (CAST(sqlc.narg(before_id) AS INTEGER) IS NULL OR id < CAST(sqlc.narg(before_id) AS INTEGER))
+ORDER BY id DESC
+LIMIT sqlc.arg(max_rows);
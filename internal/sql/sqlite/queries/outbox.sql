@@ -0,0 +1,58 @@
+-- name: InsertOutboxEvent :exec
+INSERT INTO outbox (event_id, event_type, pr_id, user_ids, payload)
+VALUES (?, ?, ?, ?, ?);
+
+-- name: ClaimNextOutboxEvent :one
+SELECT *
+FROM outbox o
+WHERE o.status = 'PENDING'
+  AND o.next_attempt_at <= strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')
+  AND NOT EXISTS (
+      SELECT 1
+      FROM outbox prev
+      WHERE prev.pr_id = o.pr_id
+        AND prev.status = 'PENDING'
+        AND prev.id < o.id
+  )
+ORDER BY o.id
+LIMIT 1;
+
+-- name: MarkOutboxDispatched :exec
+UPDATE outbox
+SET status = 'DISPATCHED',
+    attempts = attempts + 1,
+    last_error = NULL,
+    dispatched_at = strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')
+WHERE id = ?;
+
+-- name: MarkOutboxAttemptFailed :exec
+UPDATE outbox
+SET status = sqlc.arg(status),
+    attempts = attempts + 1,
+    last_error = sqlc.arg(last_error),
+    next_attempt_at = sqlc.arg(next_attempt_at)
+WHERE id = sqlc.arg(id);
+
+-- name: SequenceOutboxEvents :execrows
+UPDATE outbox
+SET stream_seq = id
+WHERE stream_seq IS NULL;
+
+-- name: GetLatestStreamSeq :one
+SELECT CAST(COALESCE(MAX(stream_seq), 0) AS INTEGER)
+FROM outbox;
+
+-- name: GetStreamEventsAfter :many
+SELECT CAST(o.stream_seq AS INTEGER) AS seq, o.event_type, o.payload
+FROM outbox o
+WHERE o.stream_seq > CAST(sqlc.arg(after_seq) AS INTEGER)
+  AND (CAST(sqlc.narg(pr_id) AS TEXT) IS NULL OR o.pr_id = CAST(sqlc.narg(pr_id) AS TEXT))
+  AND (CAST(sqlc.narg(user_id) AS TEXT) IS NULL OR instr(o.user_ids, json_quote(CAST(sqlc.narg(user_id) AS TEXT))) > 0)
+  AND (CAST(sqlc.narg(team_id) AS TEXT) IS NULL OR EXISTS (
+      SELECT 1
+      FROM users u
+      WHERE instr(o.user_ids, json_quote(u.id)) > 0
+        AND u.team_id = CAST(sqlc.narg(team_id) AS TEXT)
+  ))
+ORDER BY o.stream_seq
+LIMIT sqlc.arg(batch_size);
@@ -0,0 +1,130 @@
+-- name: CreatePR :exec
+INSERT INTO prs (id, title, author_id, status, reviewers_required, is_draft)
+VALUES (?, ?, ?, 'OPEN', ?, ?);
+
+-- name: GetPRById :one
+SELECT id, title, author_id, status, created_at, merged_at, reviewers_required, force_merged, force_merged_by, closed_at, is_draft
+FROM prs
+WHERE id = ?;
+
+-- name: GetPRsByIDs :many
+SELECT id, title, author_id, status, created_at, merged_at, reviewers_required, force_merged, force_merged_by, closed_at, is_draft
+FROM prs
+WHERE id IN (sqlc.slice(ids))
+ORDER BY id;
+
+-- name: AddReviewer :exec
+INSERT INTO pr_reviewers (pr_id, reviewer_id)
+VALUES (?, ?)
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteReviewer :exec
+DELETE FROM pr_reviewers
+WHERE pr_id = ? AND reviewer_id = ?;
+
+-- name: GetReviewersByPR :many
+SELECT reviewer_id
+FROM pr_reviewers
+WHERE pr_id = ?;
+
+-- name: GetAvailableTeamMembersExceptAuthor :many
+SELECT u.id
+FROM users u
+JOIN teams t ON t.id = u.team_id
+JOIN user_workload w ON w.user_id = u.id
+WHERE u.team_id = sqlc.arg(team_id)
+  AND u.is_available = TRUE
+  AND u.id <> sqlc.arg(author_id)
+  AND w.open_reviews < COALESCE(u.max_open_reviews, t.max_open_reviews);
+
+-- name: GetTeamCandidates :many
+SELECT u.id, u.review_weight, w.open_reviews,
+       CAST(COALESCE(u.max_open_reviews, t.max_open_reviews) AS INTEGER) AS capacity
+FROM users u
+JOIN teams t ON t.id = u.team_id
+JOIN user_workload w ON w.user_id = u.id
+WHERE u.team_id = ?
+  AND u.is_available = TRUE
+ORDER BY u.id;
+
+-- name: GetReviewersByPRs :many
+SELECT pr_id, reviewer_id
+FROM pr_reviewers
+WHERE pr_id IN (sqlc.slice(pr_ids))
+ORDER BY pr_id, reviewer_id;
+
+-- name: IsReviewerAssigned :one
+SELECT COUNT(*) > 0 AS assigned
+FROM pr_reviewers
+WHERE pr_id = ? AND reviewer_id = ?;
+
+-- name: MergePR :exec
+UPDATE prs
+SET status = 'MERGED',
+    merged_at = strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'),
+    force_merged = sqlc.arg(force_merged),
+    force_merged_by = sqlc.arg(force_merged_by)
+WHERE id = sqlc.arg(id);
+
+-- name: ClosePR :exec
+UPDATE prs
+SET status = 'CLOSED',
+    closed_at = strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')
+WHERE id = ?;
+
+-- name: ReopenPR :exec
+UPDATE prs
+SET status = 'OPEN',
+    closed_at = NULL
+WHERE id = ?;
+
+-- name: MarkPRReady :exec
+UPDATE prs
+SET is_draft = FALSE
+WHERE id = ?;
+
+-- name: CheckDuplicatePR :one
+SELECT id
+FROM prs
+WHERE author_id = ?
+  AND title = ?
+  AND status = 'OPEN'
+LIMIT 1;
+
+-- name: CountOpenReviews :many
+SELECT r.reviewer_id, COUNT(*) AS open_reviews
+FROM pr_reviewers r
+JOIN prs ON prs.id = r.pr_id
+WHERE prs.status = 'OPEN'
+  AND r.reviewer_id IN (sqlc.slice(reviewer_ids))
+GROUP BY r.reviewer_id;
+
+-- name: GetOpenReviewsByReviewers :many
+SELECT r.pr_id, r.reviewer_id, prs.author_id
+FROM pr_reviewers r
+JOIN prs ON prs.id = r.pr_id
+WHERE prs.status = 'OPEN'
+  AND r.reviewer_id IN (sqlc.slice(reviewer_ids))
+ORDER BY r.pr_id, r.reviewer_id;
+
+-- name: GetReviewsByPR :many
+SELECT reviewer_id, state, assigned_at, reviewed_at
+FROM pr_reviewers
+WHERE pr_id = ?
+ORDER BY assigned_at, reviewer_id;
+
+-- name: SetReviewState :execrows
+UPDATE pr_reviewers
+SET state = sqlc.arg(state),
+    reviewed_at = strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')
+WHERE pr_id = sqlc.arg(pr_id) AND reviewer_id = sqlc.arg(reviewer_id);
+
+-- name: GetIneligibleReviewersByPR :many
+SELECT u.id, u.team_id
+FROM pr_reviewers r
+JOIN users u ON u.id = r.reviewer_id
+JOIN teams t ON t.id = u.team_id
+JOIN user_workload w ON w.user_id = u.id
+WHERE r.pr_id = ?
+  AND (u.is_available = FALSE OR w.open_reviews > COALESCE(u.max_open_reviews, t.max_open_reviews))
+ORDER BY u.id;
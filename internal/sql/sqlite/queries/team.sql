@@ -0,0 +1,78 @@
+-- name: CreateTeam :exec
+INSERT INTO teams (id, teamname, reviewer_strategy, max_open_reviews, reviewers_required, approvals_required)
+VALUES (?, ?, ?, ?, ?, ?);
+
+-- name: GetTeamByName :one
+SELECT id, teamname, reviewer_strategy, rr_last_reviewer_id, max_open_reviews, reviewers_required, approvals_required
+FROM teams
+WHERE teamname = ?;
+
+-- name: GetTeamByID :one
+SELECT id, teamname, reviewer_strategy, rr_last_reviewer_id, max_open_reviews, reviewers_required, approvals_required
+FROM teams
+WHERE id = ?;
+
+-- name: SetTeamReviewerStrategy :exec
+UPDATE teams
+SET reviewer_strategy = ?
+WHERE id = ?;
+
+-- name: SetTeamMaxOpenReviews :exec
+UPDATE teams
+SET max_open_reviews = ?
+WHERE id = ?;
+
+-- name: SetTeamReviewersRequired :exec
+UPDATE teams
+SET reviewers_required = ?
+WHERE id = ?;
+
+-- name: SetTeamApprovalsRequired :exec
+UPDATE teams
+SET approvals_required = ?
+WHERE id = ?;
+
+-- name: SetTeamRoundRobinCursor :exec
+UPDATE teams
+SET rr_last_reviewer_id = ?
+WHERE id = ?;
+
+-- name: UpsertUser :exec
+INSERT INTO users (id, username, is_available, team_id, review_weight, max_open_reviews, github_login, gitlab_username, role)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (id) DO UPDATE
+SET username = EXCLUDED.username,
+    is_available = EXCLUDED.is_available,
+    team_id = EXCLUDED.team_id,
+    review_weight = EXCLUDED.review_weight,
+    max_open_reviews = EXCLUDED.max_open_reviews,
+    github_login = COALESCE(EXCLUDED.github_login, users.github_login),
+    gitlab_username = COALESCE(EXCLUDED.gitlab_username, users.gitlab_username),
+    role = EXCLUDED.role;
+
+-- name: GetUsersByTeam :many
+SELECT u.id, u.username, u.is_available, u.team_id, u.review_weight, u.max_open_reviews, u.github_login, u.gitlab_username, u.role, w.open_reviews
+FROM users u
+JOIN user_workload w ON w.user_id = u.id
+WHERE u.team_id = ?
+ORDER BY u.username;
+
+-- name: GetTeamNameByID :one
+SELECT teamname
+FROM teams
+WHERE id = ?;
+
+-- name: GetTeamFallbacks :many
+SELECT t.id, t.teamname, t.reviewer_strategy, t.rr_last_reviewer_id, t.max_open_reviews, t.reviewers_required, t.approvals_required
+FROM team_fallbacks f
+JOIN teams t ON t.id = f.fallback_team_id
+WHERE f.team_id = ?
+ORDER BY f.priority;
+
+-- name: DeleteTeamFallbacks :exec
+DELETE FROM team_fallbacks
+WHERE team_id = ?;
+
+-- name: AddTeamFallback :exec
+INSERT INTO team_fallbacks (team_id, fallback_team_id, priority)
+VALUES (?, ?, ?);
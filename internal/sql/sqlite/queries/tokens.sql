@@ -0,0 +1,30 @@
+-- name: CreateAPIToken :exec
+INSERT INTO api_tokens (id, name, token_hash, scope, user_id)
+VALUES (?, ?, ?, ?, ?);
+
+-- name: GetAPITokenByHash :one
+SELECT id, name, token_hash, scope, user_id, created_at, revoked_at
+FROM api_tokens
+WHERE token_hash = ?
+  AND revoked_at IS NULL;
+
+-- name: FindAPITokenByHash :one
+SELECT id, name, token_hash, scope, user_id, created_at, revoked_at
+FROM api_tokens
+WHERE token_hash = ?;
+
+-- name: GetAPITokenByID :one
+SELECT id, name, token_hash, scope, user_id, created_at, revoked_at
+FROM api_tokens
+WHERE id = ?;
+
+-- name: ListAPITokens :many
+SELECT id, name, token_hash, scope, user_id, created_at, revoked_at
+FROM api_tokens
+ORDER BY created_at, id;
+
+-- name: RevokeAPIToken :execrows
+UPDATE api_tokens
+SET revoked_at = strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')
+WHERE id = ?
+  AND revoked_at IS NULL;
@@ -0,0 +1,57 @@
+-- name: GetUserById :one
+SELECT id, username, is_available, team_id, review_weight, max_open_reviews, github_login, gitlab_username, role
+FROM users
+WHERE id = ?;
+
+-- name: GetUserByGithubLogin :one
+SELECT id, username, is_available, team_id, review_weight, max_open_reviews, github_login, gitlab_username, role
+FROM users
+WHERE github_login = ?;
+
+-- name: GetUserByGitlabUsername :one
+SELECT id, username, is_available, team_id, review_weight, max_open_reviews, github_login, gitlab_username, role
+FROM users
+WHERE gitlab_username = ?;
+
+-- name: GetUserOpenReviews :one
+SELECT open_reviews
+FROM user_workload
+WHERE user_id = ?;
+
+-- name: GetUserWeights :many
+SELECT id, review_weight
+FROM users
+WHERE id IN (sqlc.slice(ids));
+
+-- name: SetUserAvailability :exec
+UPDATE users
+SET is_available = ?
+WHERE id = ?;
+
+-- name: SetUserMaxOpenReviews :exec
+UPDATE users
+SET max_open_reviews = ?
+WHERE id = ?;
+
+-- name: SetUserRole :exec
+UPDATE users
+SET role = ?
+WHERE id = ?;
+
+-- name: GetReviewPRs :many
+SELECT
+    prs.id AS pr_id,
+    prs.title AS pr_title,
+    prs.author_id,
+    prs.status,
+    r.state AS review_state,
+    r.reviewed_at
+FROM pr_reviewers r
+JOIN prs ON prs.id = r.pr_id
+WHERE r.reviewer_id = ?
+ORDER BY prs.id;
+
+-- name: SetUsersAvailability :exec
+UPDATE users
+SET is_available = sqlc.arg(is_available)
+WHERE id IN (sqlc.slice(ids));
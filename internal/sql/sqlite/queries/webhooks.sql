@@ -0,0 +1,90 @@
+-- name: CreateWebhookSubscription :exec
+INSERT INTO webhook_subscriptions (id, url, events, secret)
+VALUES (?, ?, ?, ?);
+
+-- name: ListWebhookSubscriptions :many
+SELECT id, url, events, secret, created_at
+FROM webhook_subscriptions
+ORDER BY created_at, id;
+
+-- name: DeleteWebhookSubscription :execrows
+DELETE FROM webhook_subscriptions
+WHERE id = ?;
+
+-- name: GetSubscriptionsForEvent :many
+SELECT id, url, events, secret, created_at
+FROM webhook_subscriptions s
+WHERE json_array_length(s.events) = 0
+   OR instr(s.events, json_quote(CAST(sqlc.arg(event_type) AS TEXT))) > 0
+ORDER BY created_at, id;
+
+-- name: CreateWebhookDelivery :exec
+INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, pr_id, payload)
+VALUES (?, ?, ?, ?, ?)
+ON CONFLICT (subscription_id, event_id) DO NOTHING;
+
+-- name: ClaimDueWebhookDeliveries :many
+UPDATE webhook_deliveries
+SET next_attempt_at = sqlc.arg(lease_until)
+WHERE id IN (
+    SELECT w.id
+    FROM webhook_deliveries w
+    WHERE w.status = 'PENDING'
+      AND w.next_attempt_at <= strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')
+      AND NOT EXISTS (
+          SELECT 1
+          FROM webhook_deliveries prev
+          WHERE prev.subscription_id = w.subscription_id
+            AND prev.pr_id = w.pr_id
+            AND prev.status = 'PENDING'
+            AND prev.id < w.id
+      )
+    ORDER BY w.id
+    LIMIT sqlc.arg(batch_size)
+)
+RETURNING id, subscription_id, event_id, event_type, pr_id, payload, attempts;
+
+-- name: GetWebhookSubscriptionByID :one
+SELECT id, url, events, secret, created_at
+FROM webhook_subscriptions
+WHERE id = ?;
+
+-- name: MarkWebhookDelivered :exec
+UPDATE webhook_deliveries
+SET status = 'DELIVERED',
+    attempts = attempts + 1,
+    response_status = sqlc.arg(response_status),
+    last_error = NULL,
+    delivered_at = strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')
+WHERE id = sqlc.arg(id);
+
+-- name: MarkWebhookAttemptFailed :exec
+UPDATE webhook_deliveries
+SET status = sqlc.arg(status),
+    attempts = attempts + 1,
+    response_status = sqlc.arg(response_status),
+    last_error = sqlc.arg(last_error),
+    next_attempt_at = sqlc.arg(next_attempt_at)
+WHERE id = sqlc.arg(id);
+
+-- name: ListWebhookDeliveries :many
+SELECT id, subscription_id, event_id, event_type, pr_id, payload, status, attempts, response_status, last_error, next_attempt_at, created_at, delivered_at
+FROM webhook_deliveries
+WHERE (CAST(sqlc.narg(subscription_id) AS TEXT) IS NULL OR subscription_id = CAST(sqlc.narg(subscription_id) AS TEXT))
+  AND (CAST(sqlc.narg(status) AS TEXT) IS NULL OR status = CAST(sqlc.narg(status) AS TEXT))
+ORDER BY id DESC
+LIMIT sqlc.arg(limit);
+
+-- name: GetWebhookDeliveryByID :one
+SELECT id, subscription_id, event_id, event_type, pr_id, payload, status, attempts, response_status, last_error, next_attempt_at, created_at, delivered_at
+FROM webhook_deliveries
+WHERE id = ?;
+
+-- name: RequeueWebhookDelivery :execrows
+UPDATE webhook_deliveries
+SET status = 'PENDING',
+    attempts = 0,
+    last_error = NULL,
+    next_attempt_at = strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')
+WHERE id = ?
+  AND status = 'DEAD';
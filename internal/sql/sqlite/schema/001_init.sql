@@ -0,0 +1,137 @@
+-- +goose Up
+
+CREATE TABLE teams (
+    id TEXT PRIMARY KEY,
+    teamname TEXT UNIQUE NOT NULL,
+    reviewer_strategy TEXT NOT NULL DEFAULT 'least_loaded'
+        CHECK (reviewer_strategy IN ('random', 'round_robin', 'least_loaded', 'weighted')),
+    rr_last_reviewer_id TEXT,
+    max_open_reviews INTEGER NOT NULL DEFAULT 1 CHECK (max_open_reviews > 0),
+    reviewers_required INTEGER NOT NULL DEFAULT 2 CHECK (reviewers_required >= 0),
+    approvals_required INTEGER NOT NULL DEFAULT 1 CHECK (approvals_required >= 0)
+);
+
+CREATE TABLE users (
+    id TEXT PRIMARY KEY,
+    username TEXT NOT NULL,
+    is_available BOOLEAN NOT NULL DEFAULT TRUE,
+    team_id TEXT NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    review_weight INTEGER NOT NULL DEFAULT 1 CHECK (review_weight >= 0),
+    max_open_reviews INTEGER CHECK (max_open_reviews > 0),
+    github_login TEXT UNIQUE,
+    gitlab_username TEXT UNIQUE
+);
+
+CREATE TABLE prs (
+    id TEXT PRIMARY KEY,
+    title TEXT NOT NULL,
+    author_id TEXT NOT NULL REFERENCES users(id) ON DELETE RESTRICT,
+    status TEXT NOT NULL CHECK (status IN ('OPEN', 'MERGED', 'CLOSED')),
+    created_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    merged_at DATETIME,
+    reviewers_required INTEGER NOT NULL DEFAULT 2 CHECK (reviewers_required >= 0),
+    force_merged BOOLEAN NOT NULL DEFAULT FALSE,
+    force_merged_by TEXT,
+    closed_at DATETIME,
+    is_draft BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+CREATE TABLE pr_reviewers (
+    pr_id TEXT NOT NULL REFERENCES prs(id) ON DELETE CASCADE,
+    reviewer_id TEXT NOT NULL REFERENCES users(id) ON DELETE RESTRICT,
+    state TEXT NOT NULL DEFAULT 'PENDING'
+        CHECK (state IN ('PENDING', 'APPROVED', 'CHANGES_REQUESTED', 'COMMENTED')),
+    assigned_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    reviewed_at DATETIME,
+    PRIMARY KEY (pr_id, reviewer_id)
+);
+
+CREATE VIEW user_workload AS
+SELECT u.id AS user_id,
+       COUNT(p.id) AS open_reviews
+FROM users u
+LEFT JOIN pr_reviewers r ON r.reviewer_id = u.id
+LEFT JOIN prs p ON p.id = r.pr_id AND p.status = 'OPEN'
+GROUP BY u.id;
+
+CREATE TABLE team_fallbacks (
+    team_id TEXT NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    fallback_team_id TEXT NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    priority INTEGER NOT NULL,
+    PRIMARY KEY (team_id, fallback_team_id),
+    CHECK (team_id <> fallback_team_id)
+);
+
+CREATE TABLE webhook_subscriptions (
+    id TEXT PRIMARY KEY,
+    url TEXT NOT NULL,
+    events TEXT NOT NULL DEFAULT '[]',
+    secret TEXT NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+CREATE TABLE webhook_deliveries (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    subscription_id TEXT NOT NULL REFERENCES webhook_subscriptions(id) ON DELETE CASCADE,
+    event_id TEXT NOT NULL,
+    event_type TEXT NOT NULL,
+    pr_id TEXT NOT NULL DEFAULT '',
+    payload TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'PENDING' CHECK (status IN ('PENDING', 'DELIVERED', 'DEAD')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    response_status INTEGER,
+    last_error TEXT,
+    next_attempt_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    created_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    delivered_at DATETIME
+);
+
+CREATE INDEX webhook_deliveries_due_idx
+    ON webhook_deliveries (next_attempt_at)
+    WHERE status = 'PENDING';
+
+CREATE INDEX webhook_deliveries_ordering_idx
+    ON webhook_deliveries (subscription_id, pr_id, id)
+    WHERE status = 'PENDING';
+
+CREATE UNIQUE INDEX webhook_deliveries_event_idx
+    ON webhook_deliveries (subscription_id, event_id);
+
+CREATE TABLE outbox (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    event_id TEXT NOT NULL UNIQUE,
+    event_type TEXT NOT NULL,
+    pr_id TEXT NOT NULL,
+    user_ids TEXT NOT NULL DEFAULT '[]',
+    payload TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'PENDING' CHECK (status IN ('PENDING', 'DISPATCHED', 'DEAD')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    created_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    dispatched_at DATETIME,
+    stream_seq INTEGER
+);
+
+CREATE INDEX outbox_pending_idx
+    ON outbox (pr_id, id)
+    WHERE status = 'PENDING';
+
+CREATE UNIQUE INDEX outbox_stream_seq_idx
+    ON outbox (stream_seq);
+
+CREATE INDEX outbox_unsequenced_idx
+    ON outbox (id)
+    WHERE stream_seq IS NULL;
+
+-- +goose Down
+
+DROP TABLE outbox;
+DROP TABLE webhook_deliveries;
+DROP TABLE webhook_subscriptions;
+DROP TABLE team_fallbacks;
+DROP VIEW user_workload;
+DROP TABLE pr_reviewers;
+DROP TABLE prs;
+DROP TABLE users;
+DROP TABLE teams;
@@ -0,0 +1,15 @@
+-- +goose Up
+
+CREATE TABLE api_tokens (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    token_hash TEXT NOT NULL UNIQUE,
+    scope TEXT NOT NULL CHECK (scope IN ('admin', 'team-admin', 'user', 'webhook')),
+    user_id TEXT REFERENCES users(id) ON DELETE CASCADE,
+    created_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    revoked_at DATETIME
+);
+
+-- +goose Down
+
+DROP TABLE api_tokens;
@@ -0,0 +1,178 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/LlirikP/pr_dispenser/internal/database"
+)
+
+const (
+	StatusPending   = "PENDING"
+	StatusDelivered = "DELIVERED"
+	StatusDead      = "DEAD"
+
+	SignatureHeader = "X-Dispenser-Signature-256"
+	EventHeader     = "X-Dispenser-Event"
+	DeliveryHeader  = "X-Dispenser-Delivery"
+)
+
+const (
+	defaultInterval    = 2 * time.Second
+	defaultBatchSize   = 20
+	defaultMaxAttempts = 8
+	defaultTimeout     = 10 * time.Second
+	baseBackoff        = 5 * time.Second
+	maxBackoff         = time.Hour
+	maxErrorLength     = 500
+)
+
+type Dispatcher struct {
+	q           database.Querier
+	client      *http.Client
+	Interval    time.Duration
+	BatchSize   int32
+	MaxAttempts int32
+}
+
+func NewDispatcher(q database.Querier) *Dispatcher {
+	return &Dispatcher{
+		q:           q,
+		client:      &http.Client{Timeout: defaultTimeout},
+		Interval:    defaultInterval,
+		BatchSize:   defaultBatchSize,
+		MaxAttempts: defaultMaxAttempts,
+	}
+}
+
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func Backoff(attempt int32) time.Duration {
+	delay := baseBackoff
+	for i := int32(1); i < attempt; i++ {
+		delay *= 2
+		if delay >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return delay
+}
+
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.Interval)
+	defer ticker.Stop()
+
+	for {
+		_, err := d.DispatchDue(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("webhook dispatch failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// lease is how long claimed deliveries stay hidden from other dispatchers.
+// A batch is posted one delivery after another, so the last one may only be
+// sent after every earlier one has used up its timeout.
+func (d *Dispatcher) lease() time.Duration {
+	return d.client.Timeout*time.Duration(max(d.BatchSize, 1)) + d.Interval
+}
+
+func (d *Dispatcher) DispatchDue(ctx context.Context) (int, error) {
+	due, err := d.q.ClaimDueWebhookDeliveries(ctx, database.ClaimDueWebhookDeliveriesParams{
+		LeaseUntil: time.Now().Add(d.lease()),
+		BatchSize:  d.BatchSize,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, delivery := range due {
+		d.deliver(ctx, delivery)
+	}
+
+	return len(due), nil
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, delivery database.ClaimDueWebhookDeliveriesRow) {
+	status, err := d.post(ctx, delivery)
+	if err == nil {
+		err = d.q.MarkWebhookDelivered(ctx, database.MarkWebhookDeliveredParams{
+			ID:             delivery.ID,
+			ResponseStatus: sql.NullInt32{Int32: int32(status), Valid: true},
+		})
+		if err != nil {
+			log.Printf("failed to mark webhook delivery %d delivered: %v", delivery.ID, err)
+		}
+		return
+	}
+
+	attempt := delivery.Attempts + 1
+	next := StatusPending
+	if attempt >= d.MaxAttempts {
+		next = StatusDead
+		log.Printf("webhook delivery %d to %s dead-lettered after %d attempts: %v", delivery.ID, delivery.Url, attempt, err)
+	}
+
+	msg := err.Error()
+	if len(msg) > maxErrorLength {
+		msg = msg[:maxErrorLength]
+	}
+
+	err = d.q.MarkWebhookAttemptFailed(ctx, database.MarkWebhookAttemptFailedParams{
+		ID:             delivery.ID,
+		Status:         next,
+		ResponseStatus: sql.NullInt32{Int32: int32(status), Valid: status != 0},
+		LastError:      sql.NullString{String: msg, Valid: true},
+		NextAttemptAt:  time.Now().Add(Backoff(attempt)),
+	})
+	if err != nil {
+		log.Printf("failed to record webhook delivery %d failure: %v", delivery.ID, err)
+	}
+}
+
+func (d *Dispatcher) post(ctx context.Context, delivery database.ClaimDueWebhookDeliveriesRow) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Url, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "pr-dispenser-webhooks")
+	req.Header.Set(EventHeader, delivery.EventType)
+	req.Header.Set(DeliveryHeader, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(SignatureHeader, Sign(delivery.Secret, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("receiver responded with %s", resp.Status)
+	}
+
+	return resp.StatusCode, nil
+}
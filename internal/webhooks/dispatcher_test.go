@@ -0,0 +1,336 @@
+package webhooks_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/LlirikP/pr_dispenser/internal/database"
+	"github.com/LlirikP/pr_dispenser/internal/database/memory"
+	"github.com/LlirikP/pr_dispenser/internal/webhooks"
+)
+
+const receiverSecret = "receiver-secret"
+
+type receivedRequest struct {
+	header http.Header
+	body   []byte
+}
+
+type receiver struct {
+	mu       sync.Mutex
+	statuses []int
+	received []receivedRequest
+	server   *httptest.Server
+}
+
+func newReceiver(t *testing.T, statuses ...int) *receiver {
+	t.Helper()
+
+	r := &receiver{statuses: statuses}
+	r.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+
+		r.mu.Lock()
+		r.received = append(r.received, receivedRequest{header: req.Header.Clone(), body: body})
+		status := http.StatusOK
+		if len(r.statuses) > 0 {
+			status = r.statuses[0]
+			if len(r.statuses) > 1 {
+				r.statuses = r.statuses[1:]
+			}
+		}
+		r.mu.Unlock()
+
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(r.server.Close)
+
+	return r
+}
+
+func (r *receiver) requests() []receivedRequest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.received)
+}
+
+// retryClock records the backoff the dispatcher asks for and makes the
+// delivery due again straight away, so retries run without sleeping.
+type retryClock struct {
+	database.Querier
+
+	mu     sync.Mutex
+	delays []time.Duration
+}
+
+func (c *retryClock) MarkWebhookAttemptFailed(ctx context.Context, arg database.MarkWebhookAttemptFailedParams) error {
+	c.mu.Lock()
+	c.delays = append(c.delays, time.Until(arg.NextAttemptAt))
+	c.mu.Unlock()
+
+	arg.NextAttemptAt = time.Now()
+	return c.Querier.MarkWebhookAttemptFailed(ctx, arg)
+}
+
+func enqueue(t *testing.T, q database.Querier, url string) {
+	t.Helper()
+
+	ctx := context.Background()
+	err := q.CreateWebhookSubscription(ctx, database.CreateWebhookSubscriptionParams{
+		ID:     "sub-1",
+		Url:    url,
+		Events: []string{},
+		Secret: receiverSecret,
+	})
+	if err != nil {
+		t.Fatalf("create subscription: %v", err)
+	}
+
+	err = webhooks.Enqueue(ctx, q, database.Outbox{
+		EventID:   "event-1",
+		EventType: "pr.merged",
+		PrID:      "pr-1",
+		Payload:   json.RawMessage(`{"event_id":"event-1","type":"pr.merged","pull_request_id":"pr-1"}`),
+	})
+	if err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+}
+
+func onlyDelivery(t *testing.T, q database.Querier) database.ListWebhookDeliveriesRow {
+	t.Helper()
+
+	rows, err := q.ListWebhookDeliveries(context.Background(), database.ListWebhookDeliveriesParams{Limit: 10})
+	if err != nil {
+		t.Fatalf("list deliveries: %v", err)
+	}
+	if len(rows) != 1 {
+		t.Fatalf("deliveries = %+v, want 1", rows)
+	}
+	return rows[0]
+}
+
+func dispatch(t *testing.T, d *webhooks.Dispatcher) int {
+	t.Helper()
+
+	n, err := d.DispatchDue(context.Background())
+	if err != nil {
+		t.Fatalf("dispatch: %v", err)
+	}
+	return n
+}
+
+func TestDispatcherSignsDeliveries(t *testing.T) {
+	store := memory.New()
+	recv := newReceiver(t, http.StatusNoContent)
+	enqueue(t, store, recv.server.URL)
+
+	d := webhooks.NewDispatcher(store)
+	if n := dispatch(t, d); n != 1 {
+		t.Fatalf("dispatched %d, want 1", n)
+	}
+
+	requests := recv.requests()
+	if len(requests) != 1 {
+		t.Fatalf("receiver got %d requests, want 1", len(requests))
+	}
+	req := requests[0]
+
+	mac := hmac.New(sha256.New, []byte(receiverSecret))
+	mac.Write(req.body)
+	want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	if got := req.header.Get(webhooks.SignatureHeader); !hmac.Equal([]byte(got), []byte(want)) {
+		t.Fatalf("signature = %q, want %q", got, want)
+	}
+	if got := req.header.Get(webhooks.EventHeader); got != "pr.merged" {
+		t.Fatalf("event header = %q", got)
+	}
+	if got := req.header.Get("Content-Type"); got != "application/json" {
+		t.Fatalf("content type = %q", got)
+	}
+
+	delivery := onlyDelivery(t, store)
+	if got := req.header.Get(webhooks.DeliveryHeader); got != strconv.FormatInt(delivery.ID, 10) {
+		t.Fatalf("delivery header = %q, want %d", got, delivery.ID)
+	}
+	if delivery.Status != webhooks.StatusDelivered || delivery.Attempts != 1 || delivery.ResponseStatus.Int32 != http.StatusNoContent || !delivery.DeliveredAt.Valid {
+		t.Fatalf("delivery = %+v", delivery)
+	}
+
+	if n := dispatch(t, d); n != 0 {
+		t.Fatalf("delivered delivery was dispatched again")
+	}
+}
+
+func TestDispatcherHoldsFailedDeliveryUntilBackoff(t *testing.T) {
+	store := memory.New()
+	recv := newReceiver(t, http.StatusServiceUnavailable)
+	enqueue(t, store, recv.server.URL)
+
+	d := webhooks.NewDispatcher(store)
+	dispatch(t, d)
+
+	delivery := onlyDelivery(t, store)
+	if delivery.Status != webhooks.StatusPending || delivery.Attempts != 1 || delivery.ResponseStatus.Int32 != http.StatusServiceUnavailable || !delivery.LastError.Valid {
+		t.Fatalf("delivery after a 503 = %+v", delivery)
+	}
+	if delay := time.Until(delivery.NextAttemptAt); delay < webhooks.Backoff(1)-time.Second || delay > webhooks.Backoff(1) {
+		t.Fatalf("next attempt in %v, want about %v", delay, webhooks.Backoff(1))
+	}
+
+	if n := dispatch(t, d); n != 0 {
+		t.Fatalf("delivery was retried before its backoff expired")
+	}
+	if got := len(recv.requests()); got != 1 {
+		t.Fatalf("receiver got %d requests, want 1", got)
+	}
+}
+
+func TestDispatcherRetriesWithBackoff(t *testing.T) {
+	store := memory.New()
+	recv := newReceiver(t, http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusOK)
+	enqueue(t, store, recv.server.URL)
+
+	clock := &retryClock{Querier: store}
+	d := webhooks.NewDispatcher(clock)
+	for i := 0; i < 3; i++ {
+		if n := dispatch(t, d); n != 1 {
+			t.Fatalf("attempt %d: dispatched %d, want 1", i+1, n)
+		}
+	}
+
+	if got := len(recv.requests()); got != 3 {
+		t.Fatalf("receiver got %d requests, want 3", got)
+	}
+
+	delivery := onlyDelivery(t, store)
+	if delivery.Status != webhooks.StatusDelivered || delivery.Attempts != 3 || delivery.ResponseStatus.Int32 != http.StatusOK || delivery.LastError.Valid {
+		t.Fatalf("delivery after retries = %+v", delivery)
+	}
+
+	if len(clock.delays) != 2 {
+		t.Fatalf("recorded %d backoffs, want 2", len(clock.delays))
+	}
+	for i, delay := range clock.delays {
+		want := webhooks.Backoff(int32(i + 1))
+		if delay < want-time.Second || delay > want {
+			t.Fatalf("backoff %d = %v, want about %v", i+1, delay, want)
+		}
+	}
+}
+
+func TestDispatcherDeadLettersAfterMaxAttempts(t *testing.T) {
+	store := memory.New()
+	recv := newReceiver(t, http.StatusInternalServerError)
+	enqueue(t, store, recv.server.URL)
+
+	d := webhooks.NewDispatcher(&retryClock{Querier: store})
+	d.MaxAttempts = 3
+
+	for i := 0; i < 3; i++ {
+		if n := dispatch(t, d); n != 1 {
+			t.Fatalf("attempt %d: dispatched %d, want 1", i+1, n)
+		}
+	}
+
+	delivery := onlyDelivery(t, store)
+	if delivery.Status != webhooks.StatusDead || delivery.Attempts != 3 || delivery.ResponseStatus.Int32 != http.StatusInternalServerError {
+		t.Fatalf("delivery = %+v, want DEAD after 3 attempts", delivery)
+	}
+
+	if n := dispatch(t, d); n != 0 {
+		t.Fatalf("dead delivery was dispatched again")
+	}
+	if got := len(recv.requests()); got != 3 {
+		t.Fatalf("receiver got %d requests, want 3", got)
+	}
+
+	requeued, err := store.RequeueWebhookDelivery(context.Background(), delivery.ID)
+	if err != nil || requeued != 1 {
+		t.Fatalf("requeue = %d, %v", requeued, err)
+	}
+	if n := dispatch(t, d); n != 1 {
+		t.Fatalf("requeued delivery was not dispatched")
+	}
+}
+
+func TestDispatcherRecordsConnectionErrors(t *testing.T) {
+	store := memory.New()
+	recv := newReceiver(t)
+	url := recv.server.URL
+	recv.server.Close()
+	enqueue(t, store, url)
+
+	dispatch(t, webhooks.NewDispatcher(store))
+
+	delivery := onlyDelivery(t, store)
+	if delivery.Status != webhooks.StatusPending || delivery.Attempts != 1 || delivery.ResponseStatus != (sql.NullInt32{}) || !delivery.LastError.Valid {
+		t.Fatalf("delivery = %+v", delivery)
+	}
+}
+
+// leaseRecorder records how far ahead each claim leases its deliveries.
+type leaseRecorder struct {
+	database.Querier
+
+	leases []time.Duration
+}
+
+func (l *leaseRecorder) ClaimDueWebhookDeliveries(ctx context.Context, arg database.ClaimDueWebhookDeliveriesParams) ([]database.ClaimDueWebhookDeliveriesRow, error) {
+	l.leases = append(l.leases, time.Until(arg.LeaseUntil))
+	return l.Querier.ClaimDueWebhookDeliveries(ctx, arg)
+}
+
+func TestDispatcherLeaseCoversWholeBatch(t *testing.T) {
+	rec := &leaseRecorder{Querier: memory.New()}
+	d := webhooks.NewDispatcher(rec)
+	d.Interval = 0
+
+	for _, size := range []int32{1, 4, 20} {
+		d.BatchSize = size
+		dispatch(t, d)
+	}
+
+	// Deliveries are posted one at a time, so the lease has to outlast a
+	// timeout per delivery in the batch.
+	perDelivery := rec.leases[0]
+	for i, size := range []int32{4, 20} {
+		want := perDelivery * time.Duration(size)
+		if got := rec.leases[i+1]; got < want-time.Second || got > want+time.Second {
+			t.Fatalf("lease for a batch of %d = %v, want about %v", size, got, want)
+		}
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempt int32
+		want    time.Duration
+	}{
+		{1, 5 * time.Second},
+		{2, 10 * time.Second},
+		{3, 20 * time.Second},
+		{10, 2560 * time.Second},
+		{11, time.Hour},
+		{50, time.Hour},
+	}
+
+	for _, tt := range tests {
+		if got := webhooks.Backoff(tt.attempt); got != tt.want {
+			t.Fatalf("Backoff(%d) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+}
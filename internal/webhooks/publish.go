@@ -0,0 +1,29 @@
+package webhooks
+
+import (
+	"context"
+
+	"github.com/LlirikP/pr_dispenser/internal/database"
+)
+
+func Enqueue(ctx context.Context, q database.Querier, entry database.Outbox) error {
+	subscriptions, err := q.GetSubscriptionsForEvent(ctx, entry.EventType)
+	if err != nil {
+		return err
+	}
+
+	for _, sub := range subscriptions {
+		err = q.CreateWebhookDelivery(ctx, database.CreateWebhookDeliveryParams{
+			SubscriptionID: sub.ID,
+			EventID:        entry.EventID,
+			EventType:      entry.EventType,
+			PrID:           entry.PrID,
+			Payload:        entry.Payload,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}